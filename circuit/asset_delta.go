@@ -52,14 +52,21 @@ func EmptyGasDeltaConstraints(assetId Variable) GasDeltaConstraints {
 	}
 }
 
+/*
+	UpdateAccounts: accounts after the deltas, the assets are copied so that
+	the accounts before are left untouched
+*/
 func UpdateAccounts(
 	api API,
-	accountInfos [NbAccountsPerTx]types.AccountConstraints,
-	accountDeltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
-) (AccountsInfoAfter [NbAccountsPerTx]types.AccountConstraints) {
-	AccountsInfoAfter = accountInfos
-	for i := 0; i < NbAccountsPerTx; i++ {
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
+	accountInfos []types.AccountConstraints,
+	accountDeltas [][]AccountAssetDeltaConstraints,
+) (AccountsInfoAfter []types.AccountConstraints) {
+	AccountsInfoAfter = make([]types.AccountConstraints, len(accountInfos))
+	for i := 0; i < len(accountInfos); i++ {
+		AccountsInfoAfter[i] = accountInfos[i]
+		AccountsInfoAfter[i].AssetsInfo = make([]types.AccountAssetConstraints, len(accountInfos[i].AssetsInfo))
+		copy(AccountsInfoAfter[i].AssetsInfo, accountInfos[i].AssetsInfo)
+		for j := 0; j < len(accountInfos[i].AssetsInfo); j++ {
 			AccountsInfoAfter[i].AssetsInfo[j].Balance = api.Add(
 				accountInfos[i].AssetsInfo[j].Balance,
 				accountDeltas[i][j].BalanceDelta)
@@ -138,6 +145,64 @@ func GetAssetDeltasFromCreateCollection(
 	return deltas, gasDeltas
 }
 
+func GetAssetDeltasFromChangePubKey(
+	api API,
+	txInfo ChangePubKeyTxConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account, the fee of priority ops is zero
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 1; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, gasDeltas
+}
+
+func GetAssetDeltasFromBatchTransfer(
+	api API,
+	txInfo BatchTransferTxConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	totalAmount := Variable(0)
+	for i := 0; i < types.NbBatchTransferRecipients; i++ {
+		totalAmount = api.Add(totalAmount, txInfo.AssetAmounts[i])
+		// to accounts
+		deltas[i+1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			{
+				BalanceDelta:             txInfo.AssetAmounts[i],
+				OfferCanceledOrFinalized: types.ZeroInt,
+			},
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             api.Neg(totalAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		// asset Gas
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, gasDeltas
+}
+
 func GetNftDeltaFromDepositNft(
 	txInfo DepositNftTxConstraints,
 ) (nftDelta NftDeltaConstraints) {
@@ -297,7 +362,7 @@ func GetAssetDeltasAndNftDeltaFromAtomicMatch(
 	api API,
 	flag Variable,
 	txInfo AtomicMatchTxConstraints,
-	accountsBefore [NbAccountsPerTx]types.AccountConstraints,
+	accountsBefore []types.AccountConstraints,
 	nftBefore NftConstraints,
 ) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
 	nftDelta NftDeltaConstraints,
@@ -398,7 +463,7 @@ func GetAssetDeltasFromCancelOffer(
 	api API,
 	flag Variable,
 	txInfo CancelOfferTxConstraints,
-	accountsBefore [NbAccountsPerTx]types.AccountConstraints,
+	accountsBefore []types.AccountConstraints,
 ) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
 	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
 	// from account
@@ -472,6 +537,42 @@ func GetAssetDeltasAndNftDeltaFromWithdrawNft(
 	return deltas, nftDelta, gasDeltas
 }
 
+/*
+	GetAssetDeltasAndNftDeltaFromBurnNft: the burnt nft leaf becomes an empty leaf
+*/
+func GetAssetDeltasAndNftDeltaFromBurnNft(
+	api API,
+	txInfo BurnNftTxConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	nftDelta NftDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 1; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	nftDelta = NftDeltaConstraints{
+		CreatorAccountIndex: types.ZeroInt,
+		OwnerAccountIndex:   types.ZeroInt,
+		NftContentHash:      types.ZeroInt,
+		NftL1Address:        types.ZeroInt,
+		NftL1TokenId:        types.ZeroInt,
+		CreatorTreasuryRate: types.ZeroInt,
+		CollectionId:        types.ZeroInt,
+	}
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, nftDelta, gasDeltas
+}
+
 func GetAssetDeltasFromFullExit(
 	api API,
 	txInfo FullExitTxConstraints,
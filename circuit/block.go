@@ -20,6 +20,7 @@ package circuit
 type Block struct {
 	BlockNumber     int64
 	CreatedAt       int64
+	ChainId         int64
 	OldStateRoot    []byte
 	NewStateRoot    []byte
 	BlockCommitment []byte
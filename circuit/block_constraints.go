@@ -28,6 +28,7 @@ import (
 type BlockConstraints struct {
 	BlockNumber     Variable
 	CreatedAt       Variable
+	ChainId         Variable // chain the txs are signed for
 	OldStateRoot    Variable
 	NewStateRoot    Variable
 	BlockCommitment Variable `gnark:",public"`
@@ -36,6 +37,9 @@ type BlockConstraints struct {
 	Gas             GasConstraints
 	GasAssetIds     []int64
 	GasAccountIndex int64
+	// hash and depth of the state trees
+	HashType types.HashType
+	Config   CircuitConfig
 }
 
 func (circuit BlockConstraints) Define(api API) error {
@@ -61,16 +65,18 @@ func VerifyBlock(
 		onChainOpsCount Variable
 		isOnChainOp     Variable
 		roots           [types.NbRoots]Variable
-		count           = 4
-		gasDeltas       [NbGasAssetsPerTx]GasDeltaConstraints
+		count           = 5
+		gasDeltas       []GasDeltaConstraints
 		needGas         Variable
 	)
-	pendingCommitmentData := make([]Variable, types.PubDataSizePerTx*block.TxsCount+5)
+	pendingCommitmentData := make([]Variable, block.Config.PubDataSizePerTx*block.TxsCount+6)
 	// write basic info into hFunc
 	pendingCommitmentData[0] = block.BlockNumber
 	pendingCommitmentData[1] = block.CreatedAt
-	pendingCommitmentData[2] = block.OldStateRoot
-	pendingCommitmentData[3] = block.NewStateRoot
+	pendingCommitmentData[2] = block.ChainId
+	pendingCommitmentData[3] = block.OldStateRoot
+	pendingCommitmentData[4] = block.NewStateRoot
+	api.ToBinary(block.ChainId, types.ChainIdBits)
 	api.AssertIsEqual(block.OldStateRoot, block.Txs[0].StateRootBefore)
 
 	gasAssetCount := len(block.GasAssetIds)
@@ -80,12 +86,12 @@ func VerifyBlock(
 	}
 
 	onChainOpsCount = 0
-	isOnChainOp, pendingPubData, roots, gasDeltas, err := VerifyTransaction(api, block.Txs[0], hFunc, block.CreatedAt, block.GasAssetIds)
+	isOnChainOp, pendingPubData, roots, gasDeltas, err := VerifyTransaction(api, block.Txs[0], hFunc, block.HashType, block.Config, block.ChainId, block.CreatedAt, block.GasAssetIds)
 	if err != nil {
 		log.Println("unable to verify transaction, err:", err)
 		return err
 	}
-	for i := 0; i < types.PubDataSizePerTx; i++ {
+	for i := 0; i < block.Config.PubDataSizePerTx; i++ {
 		pendingCommitmentData[count] = pendingPubData[i]
 		count++
 	}
@@ -93,7 +99,7 @@ func VerifyBlock(
 
 	matched := Variable(0)
 	for i := 0; i < gasAssetCount; i++ {
-		for j := 0; j < NbGasAssetsPerTx; j++ {
+		for j := 0; j < len(gasDeltas); j++ {
 			found := api.IsZero(api.Sub(block.GasAssetIds[i], gasDeltas[j].AssetId))
 			delta := api.Select(found, gasDeltas[j].BalanceDelta, types.ZeroInt)
 			blockGasDeltas[i] = api.Add(blockGasDeltas[i], delta)
@@ -105,12 +111,19 @@ func VerifyBlock(
 	for i := 1; i < block.TxsCount; i++ {
 		api.AssertIsEqual(block.Txs[i-1].StateRootAfter, block.Txs[i].StateRootBefore)
 		hFunc.Reset()
-		isOnChainOp, pendingPubData, roots, gasDeltas, err = VerifyTransaction(api, block.Txs[i], hFunc, block.CreatedAt, block.GasAssetIds)
+		var txRoots [types.NbRoots]Variable
+		isOnChainOp, pendingPubData, txRoots, gasDeltas, err = VerifyTransaction(api, block.Txs[i], hFunc, block.HashType, block.Config, block.ChainId, block.CreatedAt, block.GasAssetIds)
 		if err != nil {
 			log.Println("unable to verify transaction, err:", err)
 			return err
 		}
-		for j := 0; j < types.PubDataSizePerTx; j++ {
+		// the roots of an empty tx are not checked, the gas account is
+		// credited against the roots of the last non-empty tx
+		isEmptyTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeEmptyTx))
+		for j := 0; j < types.NbRoots; j++ {
+			roots[j] = api.Select(isEmptyTx, roots[j], txRoots[j])
+		}
+		for j := 0; j < block.Config.PubDataSizePerTx; j++ {
 			pendingCommitmentData[count] = pendingPubData[j]
 			count++
 		}
@@ -118,7 +131,7 @@ func VerifyBlock(
 
 		matched = Variable(0)
 		for i := 0; i < gasAssetCount; i++ {
-			for j := 0; j < NbGasAssetsPerTx; j++ {
+			for j := 0; j < len(gasDeltas); j++ {
 				found := api.IsZero(api.Sub(block.GasAssetIds[i], gasDeltas[j].AssetId))
 				delta := api.Select(found, gasDeltas[j].BalanceDelta, types.ZeroInt)
 				blockGasDeltas[i] = api.Add(blockGasDeltas[i], delta)
@@ -128,6 +141,8 @@ func VerifyBlock(
 		api.AssertIsEqual(matched, 1)
 	}
 
+	// the gas account is credited as soon as one tx of the block pays gas,
+	// whatever the position of that tx in the block
 	needGas = Variable(0)
 	for i := 0; i < block.TxsCount; i++ {
 		transferTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeTransfer))
@@ -138,43 +153,60 @@ func VerifyBlock(
 		atomicMatchTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeAtomicMatch))
 		withdrawNftTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeWithdrawNft))
 		transferNft := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeTransferNft))
-		needGas = api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(transferTx, withdrawTx), createCollectionTx), mintNftTx), cancelOfferTx), atomicMatchTx), withdrawNftTx), transferNft)
+		changePubKeyTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeChangePubKey))
+		changePubKeyTx = api.And(changePubKeyTx, api.Sub(1, block.Txs[i].ChangePubKeyTxInfo.IsPriorityOp))
+		batchTransferTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeBatchTransfer))
+		swapTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeSwap))
+		addLiquidityTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeAddLiquidity))
+		removeLiquidityTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeRemoveLiquidity))
+		burnNftTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeBurnNft))
+		updateCollectionTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeUpdateCollection))
+		transferCollectionTx := api.IsZero(api.Sub(block.Txs[i].TxType, types.TxTypeTransferCollection))
+		isGasTx := api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(transferTx, withdrawTx), createCollectionTx), mintNftTx), cancelOfferTx), atomicMatchTx), withdrawNftTx), transferNft), changePubKeyTx), batchTransferTx)
+		isGasTx = api.Or(api.Or(api.Or(api.Or(api.Or(api.Or(isGasTx, swapTx), addLiquidityTx), removeLiquidityTx), burnNftTx), updateCollectionTx), transferCollectionTx)
+		needGas = api.Or(needGas, isGasTx)
 	}
 
 	types.IsVariableEqual(api, needGas, block.Gas.AccountInfoBefore.AccountIndex, block.GasAccountIndex)
-	roots[0], err = VerifyGas(api, block.Gas, needGas, blockGasDeltas, hFunc, roots[0])
+	treeHFunc, err := types.NewHash(api, block.HashType)
+	if err != nil {
+		return err
+	}
+	roots[0], err = VerifyGas(api, block.Gas, block.Config, needGas, blockGasDeltas, treeHFunc, roots[0])
 	if err != nil {
 		log.Println("unable to verify gas, err:", err)
 		return err
 	}
-	hFunc.Reset()
+	treeHFunc.Reset()
 	for i := 0; i < types.NbRoots; i++ {
-		hFunc.Write(
+		treeHFunc.Write(
 			roots[i],
 		)
 	}
-	newStateRoot := hFunc.Sum()
+	newStateRoot := treeHFunc.Sum()
 	types.IsVariableEqual(api, needGas, block.NewStateRoot, newStateRoot)
 
 	notNeedGas := api.Xor(1, needGas)
 	types.IsVariableEqual(api, notNeedGas, block.NewStateRoot, block.Txs[block.TxsCount-1].StateRootAfter)
 
 	pendingCommitmentData[count] = onChainOpsCount
-	commitments, _ := api.Compiler().NewHint(types.Keccak256, 1, pendingCommitmentData[:]...)
-	api.AssertIsEqual(commitments[0], block.BlockCommitment)
+	commitment := types.Keccak256Words(api, pendingCommitmentData)
+	api.AssertIsEqual(commitment, block.BlockCommitment)
 	return nil
 }
 
-func SetBlockWitness(oBlock *Block) (witness BlockConstraints, err error) {
+func SetBlockWitness(oBlock *Block, config CircuitConfig) (witness BlockConstraints, err error) {
 	witness = BlockConstraints{
 		BlockNumber:     oBlock.BlockNumber,
 		CreatedAt:       oBlock.CreatedAt,
+		ChainId:         oBlock.ChainId,
 		OldStateRoot:    oBlock.OldStateRoot,
 		NewStateRoot:    oBlock.NewStateRoot,
 		BlockCommitment: oBlock.BlockCommitment,
+		Config:          config,
 	}
 	for i := 0; i < len(oBlock.Txs); i++ {
-		tx, err := SetTxWitness(oBlock.Txs[i])
+		tx, err := SetTxWitness(oBlock.Txs[i], config)
 		witness.Txs = append(witness.Txs, tx)
 		if err != nil {
 			log.Println("fail to set tx witness: ", err.Error())
@@ -182,7 +214,7 @@ func SetBlockWitness(oBlock *Block) (witness BlockConstraints, err error) {
 		}
 	}
 
-	witness.Gas, err = SetGasWitness(oBlock.Gas)
+	witness.Gas, err = SetGasWitness(oBlock.Gas, config)
 	if err != nil {
 		log.Println("fail to set gas witness: ", err.Error())
 		return witness, err
@@ -190,7 +222,7 @@ func SetBlockWitness(oBlock *Block) (witness BlockConstraints, err error) {
 	return witness, nil
 }
 
-func GetZeroTxConstraint() TxConstraints {
+func GetZeroTxConstraint(config CircuitConfig) TxConstraints {
 	var zeroTxConstraint TxConstraints
 	zeroTxConstraint.TxType = 0
 	zeroTxConstraint.RegisterZnsTxInfo = types.EmptyRegisterZnsTxWitness()
@@ -206,18 +238,42 @@ func GetZeroTxConstraint() TxConstraints {
 	zeroTxConstraint.WithdrawNftTxInfo = types.EmptyWithdrawNftTxWitness()
 	zeroTxConstraint.FullExitTxInfo = types.EmptyFullExitTxWitness()
 	zeroTxConstraint.FullExitNftTxInfo = types.EmptyFullExitNftTxWitness()
+	zeroTxConstraint.ChangePubKeyTxInfo = types.EmptyChangePubKeyTxWitness()
+	zeroTxConstraint.BatchTransferTxInfo = types.EmptyBatchTransferTxWitness()
+	zeroTxConstraint.CreatePairTxInfo = types.EmptyCreatePairTxWitness()
+	zeroTxConstraint.SwapTxInfo = types.EmptySwapTxWitness()
+	zeroTxConstraint.AddLiquidityTxInfo = types.EmptyAddLiquidityTxWitness()
+	zeroTxConstraint.RemoveLiquidityTxInfo = types.EmptyRemoveLiquidityTxWitness()
+	zeroTxConstraint.BurnNftTxInfo = types.EmptyBurnNftTxWitness()
+	zeroTxConstraint.UpdateCollectionTxInfo = types.EmptyUpdateCollectionTxWitness()
+	zeroTxConstraint.TransferCollectionTxInfo = types.EmptyTransferCollectionTxWitness()
 	zeroTxConstraint.Signature = EmptySignatureWitness()
 	zeroTxConstraint.Nonce = 0
 	zeroTxConstraint.ExpiredAt = 0
+	zeroTxConstraint.Config = config
 
 	// set common account & merkle parts
 	// account root before
 	zeroTxConstraint.AccountRootBefore = 0
+	zeroTxConstraint.LiquidityRootBefore = 0
 	zeroTxConstraint.NftRootBefore = 0
+	zeroTxConstraint.CollectionRootBefore = 0
 	zeroTxConstraint.StateRootBefore = 0
 	zeroTxConstraint.StateRootAfter = 0
 
 	// before
+	zeroTxConstraint.LiquidityBefore = LiquidityConstraints{
+		PairIndex:            0,
+		AssetAId:             0,
+		AssetA:               0,
+		AssetBId:             0,
+		AssetB:               0,
+		LpAssetId:            0,
+		LpAmount:             0,
+		FeeRate:              0,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         0,
+	}
 	zeroTxConstraint.NftBefore = NftConstraints{
 		NftIndex:            0,
 		NftContentHash:      0,
@@ -228,8 +284,17 @@ func GetZeroTxConstraint() TxConstraints {
 		CreatorTreasuryRate: 0,
 		CollectionId:        0,
 	}
-	// account before info, size is 4
-	for i := 0; i < NbAccountsPerTx; i++ {
+	zeroTxConstraint.CollectionBefore = CollectionConstraints{
+		CollectionId:        0,
+		OwnerAccountIndex:   0,
+		MetadataHash:        0,
+		CreatorTreasuryRate: 0,
+	}
+	// account before info, NbAccountsPerTx accounts of the config
+	zeroTxConstraint.AccountsInfoBefore = make([]types.AccountConstraints, config.NbAccountsPerTx)
+	zeroTxConstraint.MerkleProofsAccountAssetsBefore = make([][][]Variable, config.NbAccountsPerTx)
+	zeroTxConstraint.MerkleProofsAccountBefore = make([][]Variable, config.NbAccountsPerTx)
+	for i := 0; i < config.NbAccountsPerTx; i++ {
 		// set witness
 		zeroAccountConstraint := types.AccountConstraints{
 			AccountIndex:    0,
@@ -238,9 +303,10 @@ func GetZeroTxConstraint() TxConstraints {
 			Nonce:           0,
 			CollectionNonce: 0,
 			AssetRoot:       0,
+			AssetsInfo:      make([]types.AccountAssetConstraints, config.NbAccountAssetsPerAccount),
 		}
 		// set assets witness
-		for i := 0; i < NbAccountAssetsPerAccount; i++ {
+		for i := 0; i < config.NbAccountAssetsPerAccount; i++ {
 			zeroAccountConstraint.AssetsInfo[i] = types.AccountAssetConstraints{
 				AssetId:                  0,
 				Balance:                  0,
@@ -249,20 +315,19 @@ func GetZeroTxConstraint() TxConstraints {
 		}
 		// accounts info before
 		zeroTxConstraint.AccountsInfoBefore[i] = zeroAccountConstraint
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
-			for k := 0; k < AssetMerkleLevels; k++ {
-				// account assets before
-				zeroTxConstraint.MerkleProofsAccountAssetsBefore[i][j][k] = 0
-			}
-		}
-		for j := 0; j < AccountMerkleLevels; j++ {
-			// account before
-			zeroTxConstraint.MerkleProofsAccountBefore[i][j] = 0
+		zeroTxConstraint.MerkleProofsAccountAssetsBefore[i] = make([][]Variable, config.NbAccountAssetsPerAccount)
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			// account assets before
+			zeroTxConstraint.MerkleProofsAccountAssetsBefore[i][j] = zeroMerkleProof(config.AssetMerkleLevels)
 		}
+		// account before
+		zeroTxConstraint.MerkleProofsAccountBefore[i] = zeroMerkleProof(config.AccountMerkleLevels)
 	}
-	for i := 0; i < NftMerkleLevels; i++ {
-		// nft assets before
-		zeroTxConstraint.MerkleProofsNftBefore[i] = 0
-	}
+	// liquidity before
+	zeroTxConstraint.MerkleProofsLiquidityBefore = zeroMerkleProof(config.LiquidityMerkleLevels)
+	// nft assets before
+	zeroTxConstraint.MerkleProofsNftBefore = zeroMerkleProof(config.NftMerkleLevels)
+	// collection before
+	zeroTxConstraint.MerkleProofsCollectionBefore = zeroMerkleProof(config.CollectionMerkleLevels)
 	return zeroTxConstraint
 }
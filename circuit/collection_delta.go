@@ -0,0 +1,121 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	CollectionDeltaConstraints: the collection leaf after the tx, the collection id never changes
+*/
+type CollectionDeltaConstraints struct {
+	OwnerAccountIndex   Variable
+	MetadataHash        Variable
+	CreatorTreasuryRate Variable
+}
+
+func EmptyCollectionDeltaConstraints(collection CollectionConstraints) CollectionDeltaConstraints {
+	return CollectionDeltaConstraints{
+		OwnerAccountIndex:   collection.OwnerAccountIndex,
+		MetadataHash:        collection.MetadataHash,
+		CreatorTreasuryRate: collection.CreatorTreasuryRate,
+	}
+}
+
+func UpdateCollection(
+	collection CollectionConstraints,
+	collectionDelta CollectionDeltaConstraints,
+) (collectionAfter CollectionConstraints) {
+	collectionAfter = collection
+	collectionAfter.OwnerAccountIndex = collectionDelta.OwnerAccountIndex
+	collectionAfter.MetadataHash = collectionDelta.MetadataHash
+	collectionAfter.CreatorTreasuryRate = collectionDelta.CreatorTreasuryRate
+	return collectionAfter
+}
+
+func GetCollectionDeltaFromCreateCollection(
+	txInfo CreateCollectionTxConstraints,
+) (collectionDelta CollectionDeltaConstraints) {
+	collectionDelta = CollectionDeltaConstraints{
+		OwnerAccountIndex:   txInfo.AccountIndex,
+		MetadataHash:        txInfo.MetadataHash,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+	}
+	return collectionDelta
+}
+
+func GetAssetDeltasAndCollectionDeltaFromUpdateCollection(
+	api API,
+	txInfo UpdateCollectionTxConstraints,
+	collectionBefore CollectionConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	collectionDelta CollectionDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 1; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	collectionDelta = CollectionDeltaConstraints{
+		OwnerAccountIndex:   collectionBefore.OwnerAccountIndex,
+		MetadataHash:        txInfo.MetadataHash,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+	}
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, collectionDelta, gasDeltas
+}
+
+func GetAssetDeltasAndCollectionDeltaFromTransferCollection(
+	api API,
+	txInfo TransferCollectionTxConstraints,
+	collectionBefore CollectionConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	collectionDelta CollectionDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 1; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	collectionDelta = CollectionDeltaConstraints{
+		OwnerAccountIndex:   txInfo.ToAccountIndex,
+		MetadataHash:        collectionBefore.MetadataHash,
+		CreatorTreasuryRate: collectionBefore.CreatorTreasuryRate,
+	}
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, collectionDelta, gasDeltas
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	ComputeTxPubData: pub data VerifyTransaction collects from a tx, empty txs have zero pub data
+*/
+func ComputeTxPubData(oTx *Tx) (pubData [types.PubDataSizePerTx]*big.Int, err error) {
+	var missing bool
+	switch oTx.TxType {
+	case types.TxTypeEmptyTx:
+		for i := 0; i < types.PubDataSizePerTx; i++ {
+			pubData[i] = big.NewInt(0)
+		}
+		return pubData, nil
+	case types.TxTypeRegisterZns:
+		if missing = oTx.RegisterZnsTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromRegisterZNS(oTx.RegisterZnsTxInfo)
+		}
+	case types.TxTypeDeposit:
+		if missing = oTx.DepositTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromDeposit(oTx.DepositTxInfo)
+		}
+	case types.TxTypeDepositNft:
+		if missing = oTx.DepositNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromDepositNft(oTx.DepositNftTxInfo)
+		}
+	case types.TxTypeTransfer:
+		if missing = oTx.TransferTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromTransfer(oTx.TransferTxInfo)
+		}
+	case types.TxTypeWithdraw:
+		if missing = oTx.WithdrawTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromWithdraw(oTx.WithdrawTxInfo)
+		}
+	case types.TxTypeCreateCollection:
+		if missing = oTx.CreateCollectionTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromCreateCollection(oTx.CreateCollectionTxInfo)
+		}
+	case types.TxTypeMintNft:
+		if missing = oTx.MintNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromMintNft(oTx.MintNftTxInfo)
+		}
+	case types.TxTypeTransferNft:
+		if missing = oTx.TransferNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromTransferNft(oTx.TransferNftTxInfo)
+		}
+	case types.TxTypeAtomicMatch:
+		if missing = oTx.AtomicMatchTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromAtomicMatch(oTx.AtomicMatchTxInfo)
+		}
+	case types.TxTypeCancelOffer:
+		if missing = oTx.CancelOfferTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromCancelOffer(oTx.CancelOfferTxInfo)
+		}
+	case types.TxTypeWithdrawNft:
+		if missing = oTx.WithdrawNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromWithdrawNft(oTx.WithdrawNftTxInfo)
+		}
+	case types.TxTypeFullExit:
+		if missing = oTx.FullExitTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromFullExit(oTx.FullExitTxInfo)
+		}
+	case types.TxTypeFullExitNft:
+		if missing = oTx.FullExitNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromFullExitNft(oTx.FullExitNftTxInfo)
+		}
+	case types.TxTypeChangePubKey:
+		if missing = oTx.ChangePubKeyTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromChangePubKey(oTx.ChangePubKeyTxInfo)
+		}
+	case types.TxTypeBatchTransfer:
+		if missing = oTx.BatchTransferTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromBatchTransfer(oTx.BatchTransferTxInfo)
+		}
+	case types.TxTypeCreatePair:
+		if missing = oTx.CreatePairTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromCreatePair(oTx.CreatePairTxInfo)
+		}
+	case types.TxTypeSwap:
+		if missing = oTx.SwapTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromSwap(oTx.SwapTxInfo)
+		}
+	case types.TxTypeAddLiquidity:
+		if missing = oTx.AddLiquidityTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromAddLiquidity(oTx.AddLiquidityTxInfo)
+		}
+	case types.TxTypeRemoveLiquidity:
+		if missing = oTx.RemoveLiquidityTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromRemoveLiquidity(oTx.RemoveLiquidityTxInfo)
+		}
+	case types.TxTypeBurnNft:
+		if missing = oTx.BurnNftTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromBurnNft(oTx.BurnNftTxInfo)
+		}
+	case types.TxTypeUpdateCollection:
+		if missing = oTx.UpdateCollectionTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromUpdateCollection(oTx.UpdateCollectionTxInfo)
+		}
+	case types.TxTypeTransferCollection:
+		if missing = oTx.TransferCollectionTxInfo == nil; !missing {
+			pubData, err = types.ComputePubDataFromTransferCollection(oTx.TransferCollectionTxInfo)
+		}
+	default:
+		log.Println("[ComputeTxPubData] invalid tx type:", oTx.TxType)
+		return pubData, fmt.Errorf("[ComputeTxPubData] invalid tx type %d", oTx.TxType)
+	}
+	if missing {
+		log.Println("[ComputeTxPubData] tx info is missing for tx type:", oTx.TxType)
+		return pubData, fmt.Errorf("[ComputeTxPubData] tx info is missing for tx type %d", oTx.TxType)
+	}
+	return pubData, err
+}
+
+/*
+	IsOnChainOp: whether VerifyTransaction counts the tx as an on-chain operation
+*/
+func IsOnChainOp(oTx *Tx) bool {
+	switch oTx.TxType {
+	case types.TxTypeRegisterZns, types.TxTypeDeposit, types.TxTypeDepositNft, types.TxTypeWithdraw,
+		types.TxTypeWithdrawNft, types.TxTypeFullExit, types.TxTypeFullExitNft, types.TxTypeCreatePair:
+		return true
+	case types.TxTypeChangePubKey:
+		return isPriorityOpChangePubKey(oTx)
+	default:
+		return false
+	}
+}
+
+/*
+	IsLayer2Tx: whether VerifyTransaction checks the nonce, expiry and signature of
+	the tx, these txs pay gas
+*/
+func IsLayer2Tx(oTx *Tx) bool {
+	switch oTx.TxType {
+	case types.TxTypeTransfer, types.TxTypeWithdraw, types.TxTypeCreateCollection, types.TxTypeMintNft,
+		types.TxTypeTransferNft, types.TxTypeAtomicMatch, types.TxTypeCancelOffer, types.TxTypeWithdrawNft,
+		types.TxTypeBatchTransfer, types.TxTypeSwap, types.TxTypeAddLiquidity, types.TxTypeRemoveLiquidity,
+		types.TxTypeBurnNft, types.TxTypeUpdateCollection, types.TxTypeTransferCollection:
+		return true
+	case types.TxTypeChangePubKey:
+		return !isPriorityOpChangePubKey(oTx)
+	default:
+		return false
+	}
+}
+
+func isPriorityOpChangePubKey(oTx *Tx) bool {
+	return oTx.ChangePubKeyTxInfo != nil && oTx.ChangePubKeyTxInfo.IsPriorityOp != 0
+}
+
+/*
+	ComputeBlockCommitment: keccak hash VerifyBlock checks the block commitment against,
+	computed over the block number, creation time, chain id, old and new state roots, the pub
+	data of every tx padded with zeros to the pub data size of the config and the number
+	of on-chain operations, each as a 32 bytes word
+*/
+func ComputeBlockCommitment(oBlock *Block, config CircuitConfig) (commitment []byte, err error) {
+	if oBlock == nil || len(oBlock.Txs) == 0 {
+		log.Println("[ComputeBlockCommitment] block should contain at least one tx")
+		return nil, errors.New("[ComputeBlockCommitment] block should contain at least one tx")
+	}
+	if err = config.Validate(); err != nil {
+		log.Println("[ComputeBlockCommitment] invalid circuit config:", err)
+		return nil, err
+	}
+	pendingCommitmentData := make([]*big.Int, 0, config.PubDataSizePerTx*len(oBlock.Txs)+6)
+	for _, x := range []interface{}{oBlock.BlockNumber, oBlock.CreatedAt, oBlock.ChainId, oBlock.OldStateRoot, oBlock.NewStateRoot} {
+		value, err := types.ToFieldElement(x)
+		if err != nil {
+			log.Println("[ComputeBlockCommitment] invalid block info:", err)
+			return nil, err
+		}
+		pendingCommitmentData = append(pendingCommitmentData, value)
+	}
+	onChainOpsCount := int64(0)
+	for i, oTx := range oBlock.Txs {
+		if oTx == nil {
+			return nil, fmt.Errorf("[ComputeBlockCommitment] tx %d is nil", i)
+		}
+		pubData, err := ComputeTxPubData(oTx)
+		if err != nil {
+			log.Println("[ComputeBlockCommitment] unable to compute pub data of tx", i, ":", err)
+			return nil, err
+		}
+		pendingCommitmentData = append(pendingCommitmentData, pubData[:]...)
+		for j := types.PubDataSizePerTx; j < config.PubDataSizePerTx; j++ {
+			pendingCommitmentData = append(pendingCommitmentData, big.NewInt(0))
+		}
+		if IsOnChainOp(oTx) {
+			onChainOpsCount++
+		}
+	}
+	pendingCommitmentData = append(pendingCommitmentData, big.NewInt(onChainOpsCount))
+
+	// keccak of the words as 32 bytes big endian integers, the packing of types.Keccak256Words
+	buf := make([]byte, 0, 32*len(pendingCommitmentData))
+	for _, word := range pendingCommitmentData {
+		buf = append(buf, word.FillBytes(make([]byte, 32))...)
+	}
+	return crypto.Keccak256(buf), nil
+}
@@ -0,0 +1,561 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+// rules of the block circuit a witness can break
+const (
+	RuleWitness               = "witness"
+	RuleMerkleProofLevels     = "merkle proof levels"
+	RuleStateRootBefore       = "state root before"
+	RuleNonce                 = "nonce"
+	RuleSignature             = "signature"
+	RuleExpiredAt             = "expired at"
+	RuleBalance               = "balance"
+	RuleNft                   = "nft"
+	RuleTreeIndex             = "tree index"
+	RuleAssetMerkleProof      = "asset merkle proof"
+	RuleAccountMerkleProof    = "account merkle proof"
+	RuleLiquidityMerkleProof  = "liquidity merkle proof"
+	RuleNftMerkleProof        = "nft merkle proof"
+	RuleCollectionMerkleProof = "collection merkle proof"
+	RuleStateRootAfter        = "state root after"
+	RuleChainId               = "chain id"
+	RuleOldStateRoot          = "old state root"
+	RuleStateRootChain        = "state root chain"
+	RuleGasAsset              = "gas asset"
+	RuleGasAccount            = "gas account"
+	RuleNewStateRoot          = "new state root"
+	RuleCommitment            = "commitment"
+	// reported by SolveTx, the error of the test engine tells which constraint failed
+	RuleConstraint = "constraint"
+)
+
+/*
+	TxError: the first rule a block witness breaks, TxIndex is -1 for the rules of the block itself
+*/
+type TxError struct {
+	TxIndex int
+	TxType  uint8
+	Rule    string
+	Err     error
+}
+
+func (e *TxError) Error() string {
+	if e.TxIndex < 0 {
+		return fmt.Sprintf("block breaks the %s rule: %v", e.Rule, e.Err)
+	}
+	return fmt.Sprintf("tx %d of type %d breaks the %s rule: %v", e.TxIndex, e.TxType, e.Rule, e.Err)
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	Checker: replays the checks of VerifyBlock and VerifyTransaction on native values,
+	a block the circuit doesn't solve is reported with the tx and the rule it breaks.
+	Rules of the Verify*Tx functions that aren't listed above (matching account indexes,
+	asset ids, offer params...) are left to SolveTx.
+*/
+type Checker struct {
+	GasAssetIds     []int64
+	GasAccountIndex int64
+	HashType        types.HashType
+	Config          types.CircuitConfig
+}
+
+func NewChecker(gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig) (checker *Checker, err error) {
+	if len(gasAssetIds) == 0 {
+		log.Println("[NewChecker] gas asset ids should not be empty")
+		return nil, errors.New("[NewChecker] gas asset ids should not be empty")
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err = types.NewNativeHash(hashType); err != nil {
+		return nil, err
+	}
+	return &Checker{
+		GasAssetIds:     gasAssetIds,
+		GasAccountIndex: gasAccountIndex,
+		HashType:        hashType,
+		Config:          config,
+	}, nil
+}
+
+/*
+	CheckBlock: returns a *TxError for the first rule of the block circuit the block breaks
+*/
+func (c *Checker) CheckBlock(oBlock *circuit.Block) error {
+	err := c.checkBlock(oBlock)
+	if err != nil {
+		log.Println("[CheckBlock]", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	CheckTx: returns a *TxError for the first rule of VerifyTransaction the tx breaks,
+	the tx index is 0 for a tx checked alone
+*/
+func (c *Checker) CheckTx(oTx *circuit.Tx, chainId int64, blockCreatedAt int64) error {
+	_, err := c.checkTx(0, oTx, chainId, blockCreatedAt)
+	if err != nil {
+		log.Println("[CheckTx]", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	txCircuit: a single tx verified with the parameters of a block
+*/
+type txCircuit struct {
+	Tx             circuit.TxConstraints
+	ChainId        circuit.Variable
+	BlockCreatedAt circuit.Variable
+	GasAssetIds    []int64
+	HashType       types.HashType
+	Config         types.CircuitConfig
+}
+
+func (tc txCircuit) Define(api circuit.API) error {
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	_, _, _, _, err = circuit.VerifyTransaction(api, tc.Tx, hFunc, tc.HashType, tc.Config, tc.ChainId, tc.BlockCreatedAt, tc.GasAssetIds)
+	return err
+}
+
+/*
+	SolveTx: run the TxConstraints of a tx through the gnark test engine, the error
+	names the failing assertion and the stack of the constraint that failed
+*/
+func (c *Checker) SolveTx(oTx *circuit.Tx, chainId int64, blockCreatedAt int64) error {
+	if oTx == nil {
+		log.Println("[SolveTx] invalid tx")
+		return errors.New("[SolveTx] invalid tx")
+	}
+	witness, err := circuit.SetTxWitness(oTx, c.Config)
+	if err != nil {
+		return &TxError{TxType: oTx.TxType, Rule: RuleWitness, Err: err}
+	}
+	txConstraints := txCircuit{
+		Tx:          circuit.GetZeroTxConstraint(c.Config),
+		GasAssetIds: c.GasAssetIds,
+		HashType:    c.HashType,
+		Config:      c.Config,
+	}
+	assignment := txCircuit{
+		Tx:             witness,
+		ChainId:        chainId,
+		BlockCreatedAt: blockCreatedAt,
+		GasAssetIds:    c.GasAssetIds,
+		HashType:       c.HashType,
+		Config:         c.Config,
+	}
+	if err = test.IsSolved(&txConstraints, &assignment, ecc.BN254, backend.GROTH16); err != nil {
+		log.Println("[SolveTx] tx is not solved:", err)
+		return &TxError{TxType: oTx.TxType, Rule: RuleConstraint, Err: err}
+	}
+	return nil
+}
+
+func (c *Checker) checkBlock(oBlock *circuit.Block) error {
+	if oBlock == nil || len(oBlock.Txs) == 0 || oBlock.Gas == nil {
+		return &TxError{TxIndex: -1, Rule: RuleWitness, Err: errors.New("block should contain txs and gas")}
+	}
+	if oBlock.ChainId < 0 || oBlock.ChainId >= 1<<types.ChainIdBits {
+		return &TxError{TxIndex: -1, Rule: RuleChainId, Err: fmt.Errorf("chain id %d doesn't fit in %d bits", oBlock.ChainId, types.ChainIdBits)}
+	}
+	var (
+		r         fieldReader
+		gasDeltas = make([]*big.Int, len(c.GasAssetIds))
+		last      *txReplay
+		needGas   bool
+	)
+	for i := range gasDeltas {
+		gasDeltas[i] = big.NewInt(0)
+	}
+	for i, oTx := range oBlock.Txs {
+		if oTx == nil {
+			return &TxError{TxIndex: i, Rule: RuleWitness, Err: errors.New("tx is nil")}
+		}
+		if i == 0 && r.value(oBlock.OldStateRoot).Cmp(r.value(oTx.StateRootBefore)) != 0 {
+			return &TxError{TxIndex: -1, Rule: RuleOldStateRoot, Err: errors.New("old state root doesn't match the state root before the first tx")}
+		}
+		if i > 0 && r.value(oBlock.Txs[i-1].StateRootAfter).Cmp(r.value(oTx.StateRootBefore)) != 0 {
+			return &TxError{TxIndex: i, TxType: oTx.TxType, Rule: RuleStateRootChain, Err: errors.New("state root before doesn't match the state root after the previous tx")}
+		}
+		p, err := c.checkTx(i, oTx, oBlock.ChainId, oBlock.CreatedAt)
+		if err != nil {
+			return err
+		}
+		matched := false
+		for k, gasAssetId := range c.GasAssetIds {
+			for _, delta := range p.gasDeltas {
+				if delta.assetId.Cmp(big.NewInt(gasAssetId)) == 0 {
+					gasDeltas[k].Add(gasDeltas[k], delta.balanceDelta)
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return &TxError{TxIndex: i, TxType: oTx.TxType, Rule: RuleGasAsset, Err: fmt.Errorf("gas is paid in asset %s, gas assets are %v", p.gasDeltas[0].assetId, c.GasAssetIds)}
+		}
+		needGas = needGas || p.isLayer2
+		// the gas account is credited against the roots of the last non-empty tx
+		if last == nil || oTx.TxType != types.TxTypeEmptyTx {
+			last = p
+		}
+	}
+	if r.err != nil {
+		return &TxError{TxIndex: -1, Rule: RuleWitness, Err: r.err}
+	}
+	// the gas account is credited when any tx of the block pays gas
+	newStateRoot := r.value(oBlock.Txs[len(oBlock.Txs)-1].StateRootAfter)
+	if needGas {
+		hFunc, err := types.NewNativeHash(c.HashType)
+		if err != nil {
+			return &TxError{TxIndex: -1, Rule: RuleWitness, Err: err}
+		}
+		gasAccountRoot, err := c.checkGas(hFunc, oBlock.Gas, last.accountRootAfter, gasDeltas)
+		if err != nil {
+			return err
+		}
+		newStateRoot = circuit.HashElements(hFunc, gasAccountRoot, last.liquidityRootAfter, last.nftRootAfter, last.collectionRootAfter)
+	}
+	if r.value(oBlock.NewStateRoot).Cmp(newStateRoot) != 0 {
+		return &TxError{TxIndex: -1, Rule: RuleNewStateRoot, Err: fmt.Errorf("new state root should be %x", toFieldBytes(newStateRoot))}
+	}
+	commitment, err := circuit.ComputeBlockCommitment(oBlock, c.Config)
+	if err != nil {
+		return &TxError{TxIndex: -1, Rule: RuleWitness, Err: err}
+	}
+	if r.value(oBlock.BlockCommitment).Cmp(r.value(commitment)) != 0 {
+		return &TxError{TxIndex: -1, Rule: RuleCommitment, Err: fmt.Errorf("block commitment should be %x", commitment)}
+	}
+	if r.err != nil {
+		return &TxError{TxIndex: -1, Rule: RuleWitness, Err: r.err}
+	}
+	return nil
+}
+
+/*
+	checkGas: same as VerifyGas when the block pays gas, returns the account root after the gas account is credited
+*/
+func (c *Checker) checkGas(hFunc hash.Hash, oGas *circuit.Gas, accountRoot *big.Int, gasDeltas []*big.Int) (newAccountRoot *big.Int, err error) {
+	fail := func(rule string, err error) (*big.Int, error) {
+		return nil, &TxError{TxIndex: -1, Rule: rule, Err: err}
+	}
+	if oGas.GasAssetCount != len(c.GasAssetIds) || len(oGas.MerkleProofsAccountAssetsBefore) != len(c.GasAssetIds) {
+		return fail(RuleWitness, fmt.Errorf("gas should have %d assets", len(c.GasAssetIds)))
+	}
+	if len(oGas.MerkleProofsAccountBefore) != c.Config.AccountMerkleLevels {
+		return fail(RuleMerkleProofLevels, fmt.Errorf("gas account merkle proof has %d nodes, expected %d", len(oGas.MerkleProofsAccountBefore), c.Config.AccountMerkleLevels))
+	}
+	for i, proof := range oGas.MerkleProofsAccountAssetsBefore {
+		if len(proof) != c.Config.AssetMerkleLevels {
+			return fail(RuleMerkleProofLevels, fmt.Errorf("gas asset %d merkle proof has %d nodes, expected %d", i, len(proof), c.Config.AssetMerkleLevels))
+		}
+	}
+	witness, err := circuit.SetGasWitness(oGas, c.Config)
+	if err != nil {
+		return fail(RuleWitness, err)
+	}
+	var r fieldReader
+	account := witness.AccountInfoBefore
+	if r.value(account.AccountIndex).Cmp(big.NewInt(c.GasAccountIndex)) != 0 {
+		return fail(RuleGasAccount, fmt.Errorf("gas account should be %d", c.GasAccountIndex))
+	}
+	if r.value(account.AccountNameHash).Sign() == 0 {
+		return fail(RuleGasAccount, errors.New("gas account doesn't exist"))
+	}
+	assetRoot := r.value(account.AssetRoot)
+	for i, asset := range account.AssetsInfo {
+		assetId := r.value(asset.AssetId)
+		proof := r.proof(witness.MerkleProofsAccountAssetsBefore[i])
+		leaf := assetLeaf{balance: r.value(asset.Balance), offerCanceledOrFinalized: r.value(asset.OfferCanceledOrFinalized)}
+		if computeRoot(hFunc, leaf.hash(hFunc), proof, assetId).Cmp(assetRoot) != 0 {
+			return fail(RuleGasAccount, fmt.Errorf("merkle proof of gas asset %s doesn't match the asset root", assetId))
+		}
+		leaf.balance = new(big.Int).Add(leaf.balance, gasDeltas[i])
+		assetRoot = computeRoot(hFunc, leaf.hash(hFunc), proof, assetId)
+	}
+	accountIndex := r.value(account.AccountIndex)
+	proof := r.proof(witness.MerkleProofsAccountBefore)
+	leaf := accountLeaf{
+		nameHash:        r.value(account.AccountNameHash),
+		pkX:             r.value(account.AccountPk.A.X),
+		pkY:             r.value(account.AccountPk.A.Y),
+		nonce:           r.value(account.Nonce),
+		collectionNonce: r.value(account.CollectionNonce),
+	}
+	if computeRoot(hFunc, leaf.hash(hFunc, r.value(account.AssetRoot)), proof, accountIndex).Cmp(accountRoot) != 0 {
+		return fail(RuleGasAccount, errors.New("merkle proof of the gas account doesn't match the account root"))
+	}
+	if r.err != nil {
+		return fail(RuleWitness, r.err)
+	}
+	return computeRoot(hFunc, leaf.hash(hFunc, assetRoot), proof, accountIndex), nil
+}
+
+/*
+	checkTx: same checks as VerifyTransaction, in the same order
+*/
+func (c *Checker) checkTx(index int, oTx *circuit.Tx, chainId int64, blockCreatedAt int64) (p *txReplay, err error) {
+	if oTx == nil {
+		return nil, &TxError{TxIndex: index, Rule: RuleWitness, Err: errors.New("tx is nil")}
+	}
+	fail := func(rule string, format string, a ...interface{}) (*txReplay, error) {
+		return nil, &TxError{TxIndex: index, TxType: oTx.TxType, Rule: rule, Err: fmt.Errorf(format, a...)}
+	}
+	if err = c.checkProofLevels(oTx); err != nil {
+		return fail(RuleMerkleProofLevels, "%v", err)
+	}
+	if oTx.AccountsInfoBefore[0] == nil {
+		return fail(RuleWitness, "accounts before should be set")
+	}
+	witness, err := circuit.SetTxWitness(oTx, c.Config)
+	if err != nil {
+		return fail(RuleWitness, "%v", err)
+	}
+	hFunc, err := types.NewNativeHash(c.HashType)
+	if err != nil {
+		return fail(RuleWitness, "%v", err)
+	}
+	p = newTxReplay(witness, oTx)
+	r := &p.r
+	isEmptyTx := oTx.TxType == types.TxTypeEmptyTx
+	isLayer2 := p.isLayer2
+
+	// state root before
+	accountRoot := r.value(witness.AccountRootBefore)
+	liquidityRoot := r.value(witness.LiquidityRootBefore)
+	nftRoot := r.value(witness.NftRootBefore)
+	collectionRoot := r.value(witness.CollectionRootBefore)
+	if !isEmptyTx && circuit.HashElements(hFunc, accountRoot, liquidityRoot, nftRoot, collectionRoot).Cmp(r.value(witness.StateRootBefore)) != 0 {
+		return fail(RuleStateRootBefore, "state root before doesn't match the account, liquidity, nft and collection roots before")
+	}
+	if isLayer2 {
+		// nonce
+		nonce := r.value(witness.Nonce)
+		if nonce.Cmp(p.accountsBefore[0].nonce) != 0 {
+			return fail(RuleNonce, "nonce is %s, account %s has nonce %s", nonce, p.accountsBefore[0].accountIndex, p.accountsBefore[0].nonce)
+		}
+		// signature
+		txHash, err := circuit.ComputeTxHash(oTx, chainId)
+		if err != nil {
+			return fail(RuleWitness, "%v", err)
+		}
+		if err = verifySignature(oTx.AccountsInfoBefore[0].AccountPk, oTx.Signature, txHash); err != nil {
+			return fail(RuleSignature, "tx signature of account %s: %v", p.accountsBefore[0].accountIndex, err)
+		}
+		// expiry
+		expiredAt := r.value(witness.ExpiredAt)
+		if big.NewInt(blockCreatedAt).Cmp(expiredAt) > 0 {
+			return fail(RuleExpiredAt, "tx expired at %s, block is created at %d", expiredAt, blockCreatedAt)
+		}
+	}
+	if oTx.TxType == types.TxTypeAtomicMatch {
+		if err = c.checkOffers(oTx, chainId, blockCreatedAt); err != nil {
+			return fail(RuleSignature, "%v", err)
+		}
+	}
+	if oTx.TxType == types.TxTypeBurnNft {
+		// nft, only an existing layer 2 nft of the account is burnt
+		if p.nftBefore.nftContentHash.Sign() == 0 {
+			return fail(RuleNft, "nft %s doesn't exist", p.nftBefore.nftIndex)
+		}
+		if p.nftBefore.ownerAccountIndex.Cmp(p.accountsBefore[0].accountIndex) != 0 {
+			return fail(RuleNft, "nft %s is owned by account %s", p.nftBefore.nftIndex, p.nftBefore.ownerAccountIndex)
+		}
+		if p.nftBefore.nftL1Address.Sign() != 0 {
+			return fail(RuleNft, "nft %s is deposited from layer 1", p.nftBefore.nftIndex)
+		}
+	}
+	if r.err != nil {
+		return fail(RuleWitness, "%v", r.err)
+	}
+
+	// balances
+	p.applyDeltas(c.GasAssetIds[0])
+	for i := range p.accountsAfter {
+		for j, asset := range p.accountsAfter[i].assets {
+			if asset.balance.Sign() < 0 {
+				return fail(RuleBalance, "account %s has %s of asset %s, the tx takes %s",
+					p.accountsBefore[i].accountIndex, p.accountsBefore[i].assets[j].balance, asset.assetId,
+					new(big.Int).Sub(p.accountsBefore[i].assets[j].balance, asset.balance))
+			}
+		}
+	}
+
+	// merkle paths, each slot is proven against the roots updated by the previous ones
+	newAccountRoot := accountRoot
+	for i := range p.accountsBefore {
+		before, after := p.accountsBefore[i], p.accountsAfter[i]
+		newAssetRoot := before.assetRoot
+		for j := range before.assets {
+			assetId := before.assets[j].assetId
+			if assetId.Cmp(big.NewInt(c.Config.LastAccountAssetId())) > 0 {
+				return fail(RuleTreeIndex, "asset id %s is larger than %d", assetId, c.Config.LastAccountAssetId())
+			}
+			proof := r.proof(witness.MerkleProofsAccountAssetsBefore[i][j])
+			if !isEmptyTx && computeRoot(hFunc, before.assets[j].hash(hFunc), proof, assetId).Cmp(newAssetRoot) != 0 {
+				return fail(RuleAssetMerkleProof, "merkle proof of asset %s of account %s in slot %d doesn't match the asset root", assetId, before.accountIndex, i)
+			}
+			newAssetRoot = computeRoot(hFunc, after.assets[j].hash(hFunc), proof, assetId)
+		}
+		if before.accountIndex.Cmp(big.NewInt(c.Config.LastAccountIndex())) > 0 {
+			return fail(RuleTreeIndex, "account index %s is larger than %d", before.accountIndex, c.Config.LastAccountIndex())
+		}
+		proof := r.proof(witness.MerkleProofsAccountBefore[i])
+		if !isEmptyTx && computeRoot(hFunc, before.hash(hFunc, before.assetRoot), proof, before.accountIndex).Cmp(newAccountRoot) != 0 {
+			return fail(RuleAccountMerkleProof, "merkle proof of account %s in slot %d doesn't match the account root", before.accountIndex, i)
+		}
+		newAccountRoot = computeRoot(hFunc, after.hash(hFunc, newAssetRoot), proof, before.accountIndex)
+	}
+	pairIndex := p.liquidityBefore.pairIndex
+	if pairIndex.Cmp(big.NewInt(c.Config.LastPairIndex())) > 0 {
+		return fail(RuleTreeIndex, "pair index %s is larger than %d", pairIndex, c.Config.LastPairIndex())
+	}
+	proof := r.proof(witness.MerkleProofsLiquidityBefore)
+	if !isEmptyTx && computeRoot(hFunc, p.liquidityBefore.hash(hFunc), proof, pairIndex).Cmp(liquidityRoot) != 0 {
+		return fail(RuleLiquidityMerkleProof, "merkle proof of pair %s doesn't match the liquidity root", pairIndex)
+	}
+	newLiquidityRoot := computeRoot(hFunc, p.liquidityAfter.hash(hFunc), proof, pairIndex)
+	nftIndex := p.nftBefore.nftIndex
+	if nftIndex.Cmp(big.NewInt(c.Config.LastNftIndex())) > 0 {
+		return fail(RuleTreeIndex, "nft index %s is larger than %d", nftIndex, c.Config.LastNftIndex())
+	}
+	proof = r.proof(witness.MerkleProofsNftBefore)
+	if !isEmptyTx && computeRoot(hFunc, p.nftBefore.hash(hFunc), proof, nftIndex).Cmp(nftRoot) != 0 {
+		return fail(RuleNftMerkleProof, "merkle proof of nft %s doesn't match the nft root", nftIndex)
+	}
+	newNftRoot := computeRoot(hFunc, p.nftAfter.hash(hFunc), proof, nftIndex)
+	collectionId := p.collectionBefore.collectionId
+	if collectionId.Cmp(big.NewInt(c.Config.LastCollectionId())) > 0 {
+		return fail(RuleTreeIndex, "collection id %s is larger than %d", collectionId, c.Config.LastCollectionId())
+	}
+	proof = r.proof(witness.MerkleProofsCollectionBefore)
+	if !isEmptyTx && computeRoot(hFunc, p.collectionBefore.hash(hFunc), proof, collectionId).Cmp(collectionRoot) != 0 {
+		return fail(RuleCollectionMerkleProof, "merkle proof of collection %s doesn't match the collection root", collectionId)
+	}
+	newCollectionRoot := computeRoot(hFunc, p.collectionAfter.hash(hFunc), proof, collectionId)
+
+	// state root after
+	newStateRoot := circuit.HashElements(hFunc, newAccountRoot, newLiquidityRoot, newNftRoot, newCollectionRoot)
+	if !isEmptyTx && newStateRoot.Cmp(r.value(witness.StateRootAfter)) != 0 {
+		return fail(RuleStateRootAfter, "state root after should be %x", toFieldBytes(newStateRoot))
+	}
+	if isEmptyTx && r.value(witness.StateRootBefore).Cmp(r.value(witness.StateRootAfter)) != 0 {
+		return fail(RuleStateRootAfter, "state root after of an empty tx should be the state root before")
+	}
+	if r.err != nil {
+		return fail(RuleWitness, "%v", r.err)
+	}
+	p.accountRootAfter = newAccountRoot
+	p.liquidityRootAfter = newLiquidityRoot
+	p.nftRootAfter = newNftRoot
+	p.collectionRootAfter = newCollectionRoot
+	return p, nil
+}
+
+/*
+	checkOffers: the offers of an atomic match are signed by their accounts unless they submit the tx
+*/
+func (c *Checker) checkOffers(oTx *circuit.Tx, chainId int64, blockCreatedAt int64) error {
+	txInfo := oTx.AtomicMatchTxInfo
+	if txInfo == nil || txInfo.BuyOffer == nil || txInfo.SellOffer == nil {
+		return errors.New("offers should be set")
+	}
+	offers := []struct {
+		name string
+		oTx  *types.OfferTx
+		slot int
+	}{
+		{"buy", txInfo.BuyOffer, 1},
+		{"sell", txInfo.SellOffer, 2},
+	}
+	for _, o := range offers {
+		if o.oTx.AccountIndex == txInfo.AccountIndex {
+			continue
+		}
+		account := oTx.AccountsInfoBefore[o.slot]
+		if account == nil {
+			return fmt.Errorf("account of the %s offer should be set", o.name)
+		}
+		offerHash, err := circuit.ComputeOfferHash(o.oTx, chainId)
+		if err != nil {
+			return err
+		}
+		if err = verifySignature(account.AccountPk, o.oTx.Sig, offerHash); err != nil {
+			return fmt.Errorf("%s offer signature of account %d: %v", o.name, o.oTx.AccountIndex, err)
+		}
+	}
+	return nil
+}
+
+func (c *Checker) checkProofLevels(oTx *circuit.Tx) error {
+	if len(oTx.AccountsInfoBefore) != c.Config.NbAccountsPerTx || len(oTx.MerkleProofsAccountAssetsBefore) != c.Config.NbAccountsPerTx ||
+		len(oTx.MerkleProofsAccountBefore) != c.Config.NbAccountsPerTx {
+		return fmt.Errorf("tx has %d account slots, expected %d", len(oTx.AccountsInfoBefore), c.Config.NbAccountsPerTx)
+	}
+	for i := 0; i < c.Config.NbAccountsPerTx; i++ {
+		if oTx.AccountsInfoBefore[i] == nil || len(oTx.AccountsInfoBefore[i].AssetsInfo) != c.Config.NbAccountAssetsPerAccount ||
+			len(oTx.MerkleProofsAccountAssetsBefore[i]) != c.Config.NbAccountAssetsPerAccount {
+			return fmt.Errorf("slot %d should have %d asset slots", i, c.Config.NbAccountAssetsPerAccount)
+		}
+		for j := 0; j < c.Config.NbAccountAssetsPerAccount; j++ {
+			if len(oTx.MerkleProofsAccountAssetsBefore[i][j]) != c.Config.AssetMerkleLevels {
+				return fmt.Errorf("merkle proof of asset %d of slot %d has %d nodes, expected %d", j, i, len(oTx.MerkleProofsAccountAssetsBefore[i][j]), c.Config.AssetMerkleLevels)
+			}
+		}
+		if len(oTx.MerkleProofsAccountBefore[i]) != c.Config.AccountMerkleLevels {
+			return fmt.Errorf("merkle proof of slot %d has %d nodes, expected %d", i, len(oTx.MerkleProofsAccountBefore[i]), c.Config.AccountMerkleLevels)
+		}
+	}
+	if len(oTx.MerkleProofsLiquidityBefore) != c.Config.LiquidityMerkleLevels {
+		return fmt.Errorf("liquidity merkle proof has %d nodes, expected %d", len(oTx.MerkleProofsLiquidityBefore), c.Config.LiquidityMerkleLevels)
+	}
+	if len(oTx.MerkleProofsNftBefore) != c.Config.NftMerkleLevels {
+		return fmt.Errorf("nft merkle proof has %d nodes, expected %d", len(oTx.MerkleProofsNftBefore), c.Config.NftMerkleLevels)
+	}
+	if len(oTx.MerkleProofsCollectionBefore) != c.Config.CollectionMerkleLevels {
+		return fmt.Errorf("collection merkle proof has %d nodes, expected %d", len(oTx.MerkleProofsCollectionBefore), c.Config.CollectionMerkleLevels)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package debugger
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbnb-crypto/state"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+const (
+	testBlockCreatedAt = 1633400952228
+	testChainId        = 1
+)
+
+/*
+	newTestBlock: register the gas account and alice, deposit to alice and transfer to the gas account
+*/
+func newTestBlock(t *testing.T) (checker *Checker, oBlock *circuit.Block) {
+	s, err := state.NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+
+	var nameHashes [3][]byte
+	for i, name := range []string{"", "gas", "alice"} {
+		hFunc := mimc.NewMiMC()
+		hFunc.Write(txtypes.PaddingStringToBytes32(name))
+		nameHashes[i] = hFunc.Sum(nil)
+	}
+	gasSk, err := curve.GenerateEddsaPrivateKey("gas seed for the debugger tests")
+	require.NoError(t, err)
+	aliceSk, err := curve.GenerateEddsaPrivateKey("alice seed for the debugger tests")
+	require.NoError(t, err)
+	transfer := &txtypes.TransferTxInfo{
+		FromAccountIndex:  2,
+		ToAccountIndex:    1,
+		ToAccountNameHash: hex.EncodeToString(nameHashes[1]),
+		AssetId:           0,
+		AssetAmount:       big.NewInt(1000),
+		GasAccountIndex:   1,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(10),
+		CallDataHash:      mimc.NewMiMC().Sum(nil),
+		ExpiredAt:         testBlockCreatedAt + 3600000,
+		Nonce:             0,
+		ChainId:           testChainId,
+	}
+	msgHash, err := transfer.Hash(mimc.NewMiMC())
+	require.NoError(t, err)
+	transfer.Sig, err = aliceSk.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+
+	txInfos := []txtypes.TxInfo{
+		&txtypes.RegisterZnsTxInfo{
+			TxType:          txtypes.TxTypeRegisterZns,
+			AccountIndex:    1,
+			AccountName:     "gas",
+			AccountNameHash: nameHashes[1],
+			PubKey:          hex.EncodeToString(gasSk.PublicKey.Bytes()),
+		},
+		&txtypes.RegisterZnsTxInfo{
+			TxType:          txtypes.TxTypeRegisterZns,
+			AccountIndex:    2,
+			AccountName:     "alice",
+			AccountNameHash: nameHashes[2],
+			PubKey:          hex.EncodeToString(aliceSk.PublicKey.Bytes()),
+		},
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    2,
+			AccountNameHash: nameHashes[2],
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		transfer,
+	}
+	for i, txInfo := range txInfos {
+		_, err = b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+	}
+	oBlock, err = b.Seal()
+	require.NoError(t, err)
+	checker, err = NewChecker(s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	require.NoError(t, err)
+	return checker, oBlock
+}
+
+func TestCheckBlock(t *testing.T) {
+	checker, oBlock := newTestBlock(t)
+	require.NoError(t, checker.CheckBlock(oBlock))
+	for _, oTx := range oBlock.Txs {
+		assert.NoError(t, checker.CheckTx(oTx, testChainId, testBlockCreatedAt))
+	}
+
+	testCases := []struct {
+		rule    string
+		txIndex int
+		corrupt func(oBlock *circuit.Block)
+	}{
+		{RuleNonce, 3, func(oBlock *circuit.Block) { oBlock.Txs[3].Nonce = 1 }},
+		{RuleSignature, 3, func(oBlock *circuit.Block) { oBlock.Txs[3].ExpiredAt++ }},
+		{RuleSignature, 3, func(oBlock *circuit.Block) { oBlock.ChainId = 56 }},
+		{RuleExpiredAt, 3, func(oBlock *circuit.Block) { oBlock.CreatedAt = oBlock.Txs[3].ExpiredAt + 1 }},
+		{RuleBalance, 3, func(oBlock *circuit.Block) { oBlock.Txs[3].AccountsInfoBefore[0].AssetsInfo[0].Balance = big.NewInt(100) }},
+		{RuleAssetMerkleProof, 2, func(oBlock *circuit.Block) { oBlock.Txs[2].MerkleProofsAccountAssetsBefore[0][0][3] = []byte{1} }},
+		{RuleAccountMerkleProof, 3, func(oBlock *circuit.Block) { oBlock.Txs[3].MerkleProofsAccountBefore[1][0] = []byte{1} }},
+		{RuleMerkleProofLevels, 1, func(oBlock *circuit.Block) { oBlock.Txs[1].MerkleProofsNftBefore = oBlock.Txs[1].MerkleProofsNftBefore[1:] }},
+		{RuleStateRootBefore, 0, func(oBlock *circuit.Block) { oBlock.Txs[0].AccountRootBefore = []byte{1} }},
+		{RuleStateRootAfter, 3, func(oBlock *circuit.Block) { oBlock.Txs[3].StateRootAfter = []byte{1} }},
+		{RuleStateRootChain, 2, func(oBlock *circuit.Block) { oBlock.Txs[2].StateRootBefore = []byte{1} }},
+		{RuleOldStateRoot, -1, func(oBlock *circuit.Block) { oBlock.OldStateRoot = []byte{1} }},
+		{RuleGasAccount, -1, func(oBlock *circuit.Block) { oBlock.Gas.AccountInfoBefore.AssetsInfo[0].Balance = big.NewInt(1) }},
+		{RuleNewStateRoot, -1, func(oBlock *circuit.Block) { oBlock.NewStateRoot = []byte{1} }},
+		{RuleCommitment, -1, func(oBlock *circuit.Block) { oBlock.BlockCommitment = []byte{1} }},
+	}
+	for _, testCase := range testCases {
+		checker, oBlock := newTestBlock(t)
+		testCase.corrupt(oBlock)
+		err := checker.CheckBlock(oBlock)
+		var txErr *TxError
+		require.True(t, errors.As(err, &txErr), "%s: %v", testCase.rule, err)
+		assert.Equal(t, testCase.rule, txErr.Rule, txErr.Error())
+		assert.Equal(t, testCase.txIndex, txErr.TxIndex, txErr.Error())
+		if testCase.txIndex >= 0 {
+			assert.Equal(t, oBlock.Txs[testCase.txIndex].TxType, txErr.TxType)
+		}
+	}
+}
+
+func TestSolveTx(t *testing.T) {
+	checker, oBlock := newTestBlock(t)
+	transfer := oBlock.Txs[3]
+	require.NoError(t, checker.SolveTx(transfer, testChainId, testBlockCreatedAt))
+
+	transfer.Nonce = 1
+	err := checker.SolveTx(transfer, testChainId, testBlockCreatedAt)
+	var txErr *TxError
+	require.True(t, errors.As(err, &txErr))
+	assert.Equal(t, RuleConstraint, txErr.Rule)
+	assert.Equal(t, uint8(types.TxTypeTransfer), txErr.TxType)
+	// the checker names the rule the test engine fails on
+	err = checker.CheckTx(transfer, testChainId, testBlockCreatedAt)
+	require.True(t, errors.As(err, &txErr))
+	assert.Equal(t, RuleNonce, txErr.Rule)
+}
@@ -0,0 +1,544 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package debugger
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	fieldReader: reads witness variables as the field elements they are assigned to,
+	the first invalid variable is kept and the following reads return 0
+*/
+type fieldReader struct {
+	err error
+}
+
+func (r *fieldReader) value(x circuit.Variable) *big.Int {
+	if r.err != nil {
+		return big.NewInt(0)
+	}
+	v, err := types.ToFieldElement(x)
+	if err != nil {
+		r.err = err
+		return big.NewInt(0)
+	}
+	return v
+}
+
+type assetLeaf struct {
+	assetId                  *big.Int
+	balance                  *big.Int
+	offerCanceledOrFinalized *big.Int
+}
+
+type accountLeaf struct {
+	accountIndex    *big.Int
+	nameHash        *big.Int
+	pkX             *big.Int
+	pkY             *big.Int
+	nonce           *big.Int
+	collectionNonce *big.Int
+	assetRoot       *big.Int
+	assets          []assetLeaf
+}
+
+type nftLeaf struct {
+	nftIndex            *big.Int
+	creatorAccountIndex *big.Int
+	ownerAccountIndex   *big.Int
+	nftContentHash      *big.Int
+	nftL1Address        *big.Int
+	nftL1TokenId        *big.Int
+	creatorTreasuryRate *big.Int
+	collectionId        *big.Int
+}
+
+type liquidityLeaf struct {
+	pairIndex            *big.Int
+	assetAId             *big.Int
+	assetA               *big.Int
+	assetBId             *big.Int
+	assetB               *big.Int
+	lpAssetId            *big.Int
+	lpAmount             *big.Int
+	feeRate              *big.Int
+	treasuryAccountIndex *big.Int
+	treasuryRate         *big.Int
+}
+
+type collectionLeaf struct {
+	collectionId        *big.Int
+	ownerAccountIndex   *big.Int
+	metadataHash        *big.Int
+	creatorTreasuryRate *big.Int
+}
+
+type gasDelta struct {
+	assetId      *big.Int
+	balanceDelta *big.Int
+}
+
+func (r *fieldReader) account(acc types.AccountConstraints) (leaf accountLeaf) {
+	leaf = accountLeaf{
+		accountIndex:    r.value(acc.AccountIndex),
+		nameHash:        r.value(acc.AccountNameHash),
+		pkX:             r.value(acc.AccountPk.A.X),
+		pkY:             r.value(acc.AccountPk.A.Y),
+		nonce:           r.value(acc.Nonce),
+		collectionNonce: r.value(acc.CollectionNonce),
+		assetRoot:       r.value(acc.AssetRoot),
+		assets:          make([]assetLeaf, len(acc.AssetsInfo)),
+	}
+	for j := range leaf.assets {
+		leaf.assets[j] = assetLeaf{
+			assetId:                  r.value(acc.AssetsInfo[j].AssetId),
+			balance:                  r.value(acc.AssetsInfo[j].Balance),
+			offerCanceledOrFinalized: r.value(acc.AssetsInfo[j].OfferCanceledOrFinalized),
+		}
+	}
+	return leaf
+}
+
+func (r *fieldReader) nft(nft circuit.NftConstraints) nftLeaf {
+	return nftLeaf{
+		nftIndex:            r.value(nft.NftIndex),
+		creatorAccountIndex: r.value(nft.CreatorAccountIndex),
+		ownerAccountIndex:   r.value(nft.OwnerAccountIndex),
+		nftContentHash:      r.value(nft.NftContentHash),
+		nftL1Address:        r.value(nft.NftL1Address),
+		nftL1TokenId:        r.value(nft.NftL1TokenId),
+		creatorTreasuryRate: r.value(nft.CreatorTreasuryRate),
+		collectionId:        r.value(nft.CollectionId),
+	}
+}
+
+func (r *fieldReader) liquidity(liquidity circuit.LiquidityConstraints) liquidityLeaf {
+	return liquidityLeaf{
+		pairIndex:            r.value(liquidity.PairIndex),
+		assetAId:             r.value(liquidity.AssetAId),
+		assetA:               r.value(liquidity.AssetA),
+		assetBId:             r.value(liquidity.AssetBId),
+		assetB:               r.value(liquidity.AssetB),
+		lpAssetId:            r.value(liquidity.LpAssetId),
+		lpAmount:             r.value(liquidity.LpAmount),
+		feeRate:              r.value(liquidity.FeeRate),
+		treasuryAccountIndex: r.value(liquidity.TreasuryAccountIndex),
+		treasuryRate:         r.value(liquidity.TreasuryRate),
+	}
+}
+
+func (r *fieldReader) collection(collection circuit.CollectionConstraints) collectionLeaf {
+	return collectionLeaf{
+		collectionId:        r.value(collection.CollectionId),
+		ownerAccountIndex:   r.value(collection.OwnerAccountIndex),
+		metadataHash:        r.value(collection.MetadataHash),
+		creatorTreasuryRate: r.value(collection.CreatorTreasuryRate),
+	}
+}
+
+func (leaf assetLeaf) hash(hFunc hash.Hash) *big.Int {
+	return circuit.ComputeAssetLeafHash(hFunc, leaf.balance, leaf.offerCanceledOrFinalized)
+}
+
+func (leaf accountLeaf) hash(hFunc hash.Hash, assetRoot *big.Int) *big.Int {
+	return circuit.ComputeAccountLeafHash(hFunc, leaf.nameHash, leaf.pkX, leaf.pkY, leaf.nonce, leaf.collectionNonce, assetRoot)
+}
+
+func (leaf nftLeaf) hash(hFunc hash.Hash) *big.Int {
+	return circuit.ComputeNftLeafHash(
+		hFunc,
+		leaf.creatorAccountIndex,
+		leaf.ownerAccountIndex,
+		leaf.nftContentHash,
+		leaf.nftL1Address,
+		leaf.nftL1TokenId,
+		leaf.creatorTreasuryRate,
+		leaf.collectionId,
+	)
+}
+
+func (leaf liquidityLeaf) hash(hFunc hash.Hash) *big.Int {
+	return circuit.ComputeLiquidityLeafHash(
+		hFunc,
+		leaf.assetAId,
+		leaf.assetA,
+		leaf.assetBId,
+		leaf.assetB,
+		leaf.lpAssetId,
+		leaf.lpAmount,
+		leaf.feeRate,
+		leaf.treasuryAccountIndex,
+		leaf.treasuryRate,
+	)
+}
+
+func (leaf collectionLeaf) hash(hFunc hash.Hash) *big.Int {
+	return circuit.ComputeCollectionLeafHash(hFunc, leaf.ownerAccountIndex, leaf.metadataHash, leaf.creatorTreasuryRate)
+}
+
+/*
+	update: same as circuit.UpdateNft, every field but the index is replaced
+*/
+func (leaf nftLeaf) update(creatorAccountIndex, ownerAccountIndex, nftContentHash, nftL1Address, nftL1TokenId, creatorTreasuryRate, collectionId *big.Int) nftLeaf {
+	return nftLeaf{
+		nftIndex:            leaf.nftIndex,
+		creatorAccountIndex: creatorAccountIndex,
+		ownerAccountIndex:   ownerAccountIndex,
+		nftContentHash:      nftContentHash,
+		nftL1Address:        nftL1Address,
+		nftL1TokenId:        nftL1TokenId,
+		creatorTreasuryRate: creatorTreasuryRate,
+		collectionId:        collectionId,
+	}
+}
+
+/*
+	txReplay: native values of a tx witness and of the leaves it writes
+*/
+type txReplay struct {
+	r                fieldReader
+	tx               circuit.TxConstraints
+	txType           uint8
+	isLayer2         bool
+	accountsBefore   []accountLeaf
+	accountsAfter    []accountLeaf
+	liquidityBefore  liquidityLeaf
+	liquidityAfter   liquidityLeaf
+	nftBefore        nftLeaf
+	nftAfter         nftLeaf
+	collectionBefore collectionLeaf
+	collectionAfter  collectionLeaf
+	gasDeltas        [circuit.NbGasAssetsPerTx]gasDelta
+	// roots computed from the leaves after the tx
+	accountRootAfter    *big.Int
+	liquidityRootAfter  *big.Int
+	nftRootAfter        *big.Int
+	collectionRootAfter *big.Int
+}
+
+func newTxReplay(tx circuit.TxConstraints, oTx *circuit.Tx) *txReplay {
+	p := &txReplay{tx: tx, txType: oTx.TxType, isLayer2: circuit.IsLayer2Tx(oTx)}
+	p.accountsBefore = make([]accountLeaf, len(tx.AccountsInfoBefore))
+	for i := range p.accountsBefore {
+		p.accountsBefore[i] = p.r.account(tx.AccountsInfoBefore[i])
+	}
+	p.liquidityBefore = p.r.liquidity(tx.LiquidityBefore)
+	p.nftBefore = p.r.nft(tx.NftBefore)
+	p.collectionBefore = p.r.collection(tx.CollectionBefore)
+	return p
+}
+
+/*
+	applyDeltas: native counterpart of the deltas of VerifyTransaction,
+	amounts are unpacked the way the Verify*Tx functions unpack them
+*/
+func (p *txReplay) applyDeltas(gasAssetId int64) {
+	var (
+		r             = &p.r
+		tx            = p.tx
+		balanceDeltas [circuit.NbAccountsPerTx][circuit.NbAccountAssetsPerAccount]*big.Int
+	)
+	p.accountsAfter = make([]accountLeaf, len(p.accountsBefore))
+	for i, account := range p.accountsBefore {
+		p.accountsAfter[i] = account
+		p.accountsAfter[i].assets = append([]assetLeaf(nil), account.assets...)
+	}
+	p.liquidityAfter = p.liquidityBefore
+	p.nftAfter = p.nftBefore
+	p.collectionAfter = p.collectionBefore
+	for i := range p.gasDeltas {
+		p.gasDeltas[i] = gasDelta{assetId: big.NewInt(gasAssetId), balanceDelta: big.NewInt(0)}
+	}
+	setGas := func(gasFeeAssetId circuit.Variable, gasFeeAssetAmount *big.Int) {
+		for i := range p.gasDeltas {
+			p.gasDeltas[i] = gasDelta{assetId: r.value(gasFeeAssetId), balanceDelta: big.NewInt(0)}
+		}
+		p.gasDeltas[0].balanceDelta = gasFeeAssetAmount
+	}
+	zero := big.NewInt(0)
+	switch p.txType {
+	case types.TxTypeRegisterZns:
+		p.accountsAfter[0].nameHash = r.value(tx.RegisterZnsTxInfo.AccountNameHash)
+		p.accountsAfter[0].pkX = r.value(tx.RegisterZnsTxInfo.PubKey.A.X)
+		p.accountsAfter[0].pkY = r.value(tx.RegisterZnsTxInfo.PubKey.A.Y)
+	case types.TxTypeDeposit:
+		balanceDeltas[0][0] = r.value(tx.DepositTxInfo.AssetAmount)
+	case types.TxTypeDepositNft:
+		txInfo := tx.DepositNftTxInfo
+		p.nftAfter = p.nftBefore.update(
+			r.value(txInfo.CreatorAccountIndex), r.value(txInfo.AccountIndex), r.value(txInfo.NftContentHash),
+			r.value(txInfo.NftL1Address), r.value(txInfo.NftL1TokenId), r.value(txInfo.CreatorTreasuryRate), r.value(txInfo.CollectionId),
+		)
+	case types.TxTypeTransfer:
+		txInfo := tx.TransferTxInfo
+		amount := unpackAmount(r.value(txInfo.AssetAmount))
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(amount)
+		balanceDeltas[0][1] = new(big.Int).Neg(fee)
+		balanceDeltas[1][0] = amount
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeWithdraw:
+		txInfo := tx.WithdrawTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(r.value(txInfo.AssetAmount))
+		balanceDeltas[0][1] = new(big.Int).Neg(fee)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeCreateCollection:
+		txInfo := tx.CreateCollectionTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.collectionAfter.ownerAccountIndex = r.value(txInfo.AccountIndex)
+		p.collectionAfter.metadataHash = r.value(txInfo.MetadataHash)
+		p.collectionAfter.creatorTreasuryRate = r.value(txInfo.CreatorTreasuryRate)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeMintNft:
+		txInfo := tx.MintNftTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.nftAfter = p.nftBefore.update(
+			r.value(txInfo.CreatorAccountIndex), r.value(txInfo.ToAccountIndex), r.value(txInfo.NftContentHash),
+			zero, zero, r.value(txInfo.CreatorTreasuryRate), r.value(txInfo.CollectionId),
+		)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeTransferNft:
+		txInfo := tx.TransferNftTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.nftAfter.ownerAccountIndex = r.value(txInfo.ToAccountIndex)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeAtomicMatch:
+		txInfo := tx.AtomicMatchTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		amount := unpackAmount(r.value(txInfo.BuyOffer.AssetAmount))
+		creatorAmount := divRateBase(new(big.Int).Mul(amount, p.nftBefore.creatorTreasuryRate))
+		treasuryAmount := divRateBase(new(big.Int).Mul(amount, r.value(txInfo.BuyOffer.TreasuryRate)))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		balanceDeltas[1][0] = new(big.Int).Neg(amount)
+		balanceDeltas[2][0] = new(big.Int).Sub(amount, new(big.Int).Add(creatorAmount, treasuryAmount))
+		balanceDeltas[3][0] = creatorAmount
+		p.accountsAfter[1].assets[1].offerCanceledOrFinalized = setOfferBit(p.accountsBefore[1].assets[1].offerCanceledOrFinalized, r.value(txInfo.BuyOffer.OfferId))
+		p.accountsAfter[2].assets[1].offerCanceledOrFinalized = setOfferBit(p.accountsBefore[2].assets[1].offerCanceledOrFinalized, r.value(txInfo.SellOffer.OfferId))
+		p.nftAfter.ownerAccountIndex = r.value(txInfo.BuyOffer.AccountIndex)
+		p.gasDeltas[0] = gasDelta{assetId: r.value(txInfo.BuyOffer.AssetId), balanceDelta: unpackAmount(r.value(txInfo.TreasuryAmount))}
+		p.gasDeltas[1] = gasDelta{assetId: r.value(txInfo.GasFeeAssetId), balanceDelta: fee}
+	case types.TxTypeCancelOffer:
+		txInfo := tx.CancelOfferTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.accountsAfter[0].assets[1].offerCanceledOrFinalized = setOfferBit(p.accountsBefore[0].assets[1].offerCanceledOrFinalized, r.value(txInfo.OfferId))
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeWithdrawNft:
+		txInfo := tx.WithdrawNftTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.nftAfter = p.nftBefore.update(zero, zero, zero, zero, zero, zero, zero)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeFullExit:
+		balanceDeltas[0][0] = new(big.Int).Neg(r.value(tx.FullExitTxInfo.AssetAmount))
+	case types.TxTypeFullExitNft:
+		p.nftAfter = p.nftBefore.update(zero, zero, zero, zero, zero, zero, zero)
+	case types.TxTypeChangePubKey:
+		txInfo := tx.ChangePubKeyTxInfo
+		p.accountsAfter[0].pkX = r.value(txInfo.PubKey.A.X)
+		p.accountsAfter[0].pkY = r.value(txInfo.PubKey.A.Y)
+		if p.isLayer2 {
+			fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+			balanceDeltas[0][0] = new(big.Int).Neg(fee)
+			setGas(txInfo.GasFeeAssetId, fee)
+		}
+	case types.TxTypeBatchTransfer:
+		txInfo := tx.BatchTransferTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		totalAmount := big.NewInt(0)
+		for i := range txInfo.AssetAmounts {
+			amount := unpackAmount(r.value(txInfo.AssetAmounts[i]))
+			totalAmount.Add(totalAmount, amount)
+			balanceDeltas[i+1][0] = amount
+		}
+		balanceDeltas[0][0] = new(big.Int).Neg(totalAmount)
+		balanceDeltas[0][1] = new(big.Int).Neg(fee)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeCreatePair:
+		txInfo := tx.CreatePairTxInfo
+		p.liquidityAfter = liquidityLeaf{
+			pairIndex:            p.liquidityBefore.pairIndex,
+			assetAId:             r.value(txInfo.AssetAId),
+			assetA:               zero,
+			assetBId:             r.value(txInfo.AssetBId),
+			assetB:               zero,
+			lpAssetId:            r.value(txInfo.LpAssetId),
+			lpAmount:             zero,
+			feeRate:              r.value(txInfo.FeeRate),
+			treasuryAccountIndex: r.value(txInfo.TreasuryAccountIndex),
+			treasuryRate:         r.value(txInfo.TreasuryRate),
+		}
+	case types.TxTypeSwap:
+		txInfo := tx.SwapTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		amountIn := r.value(txInfo.AssetAAmount)
+		amountOut := r.value(txInfo.AssetBAmountDelta)
+		poolDeltaIn := new(big.Int).Sub(amountIn, r.value(txInfo.TreasuryAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(amountIn)
+		balanceDeltas[0][1] = amountOut
+		balanceDeltas[1][0] = r.value(txInfo.TreasuryAmount)
+		balanceDeltas[3][1] = new(big.Int).Neg(fee)
+		if r.value(txInfo.AssetAId).Cmp(p.liquidityBefore.assetAId) == 0 {
+			p.liquidityAfter.assetA = new(big.Int).Add(p.liquidityBefore.assetA, poolDeltaIn)
+			p.liquidityAfter.assetB = new(big.Int).Sub(p.liquidityBefore.assetB, amountOut)
+		} else {
+			p.liquidityAfter.assetA = new(big.Int).Sub(p.liquidityBefore.assetA, amountOut)
+			p.liquidityAfter.assetB = new(big.Int).Add(p.liquidityBefore.assetB, poolDeltaIn)
+		}
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeAddLiquidity:
+		txInfo := tx.AddLiquidityTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(r.value(txInfo.AssetAAmount))
+		balanceDeltas[0][1] = new(big.Int).Neg(r.value(txInfo.AssetBAmount))
+		balanceDeltas[3][0] = r.value(txInfo.LpAmount)
+		balanceDeltas[3][1] = new(big.Int).Neg(fee)
+		p.liquidityAfter.assetA = new(big.Int).Add(p.liquidityBefore.assetA, r.value(txInfo.AssetAAmount))
+		p.liquidityAfter.assetB = new(big.Int).Add(p.liquidityBefore.assetB, r.value(txInfo.AssetBAmount))
+		p.liquidityAfter.lpAmount = new(big.Int).Add(p.liquidityBefore.lpAmount, r.value(txInfo.LpAmount))
+		if p.liquidityBefore.lpAmount.Sign() == 0 {
+			p.liquidityAfter.lpAmount.Add(p.liquidityAfter.lpAmount, big.NewInt(types.MinimumLiquidity))
+		}
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeRemoveLiquidity:
+		txInfo := tx.RemoveLiquidityTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = r.value(txInfo.AssetAAmountDelta)
+		balanceDeltas[0][1] = r.value(txInfo.AssetBAmountDelta)
+		balanceDeltas[3][0] = new(big.Int).Neg(r.value(txInfo.LpAmount))
+		balanceDeltas[3][1] = new(big.Int).Neg(fee)
+		p.liquidityAfter.assetA = new(big.Int).Sub(p.liquidityBefore.assetA, r.value(txInfo.AssetAAmountDelta))
+		p.liquidityAfter.assetB = new(big.Int).Sub(p.liquidityBefore.assetB, r.value(txInfo.AssetBAmountDelta))
+		p.liquidityAfter.lpAmount = new(big.Int).Sub(p.liquidityBefore.lpAmount, r.value(txInfo.LpAmount))
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeBurnNft:
+		txInfo := tx.BurnNftTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.nftAfter = p.nftBefore.update(zero, zero, zero, zero, zero, zero, zero)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeUpdateCollection:
+		txInfo := tx.UpdateCollectionTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.collectionAfter.metadataHash = r.value(txInfo.MetadataHash)
+		p.collectionAfter.creatorTreasuryRate = r.value(txInfo.CreatorTreasuryRate)
+		setGas(txInfo.GasFeeAssetId, fee)
+	case types.TxTypeTransferCollection:
+		txInfo := tx.TransferCollectionTxInfo
+		fee := unpackAmount(r.value(txInfo.GasFeeAssetAmount))
+		balanceDeltas[0][0] = new(big.Int).Neg(fee)
+		p.collectionAfter.ownerAccountIndex = r.value(txInfo.ToAccountIndex)
+		setGas(txInfo.GasFeeAssetId, fee)
+	}
+	for i := range balanceDeltas {
+		for j, delta := range balanceDeltas[i] {
+			if delta != nil {
+				p.accountsAfter[i].assets[j].balance = new(big.Int).Add(p.accountsBefore[i].assets[j].balance, delta)
+			}
+		}
+	}
+	if p.isLayer2 {
+		p.accountsAfter[0].nonce = new(big.Int).Add(p.accountsBefore[0].nonce, big.NewInt(1))
+	}
+}
+
+/*
+	unpackAmount: same as types.UnpackAmount and types.UnpackFee
+*/
+func unpackAmount(packed *big.Int) *big.Int {
+	mantissa := new(big.Int).Rsh(packed, 5)
+	exponent := new(big.Int).And(packed, big.NewInt(31))
+	return mantissa.Mul(mantissa, new(big.Int).Exp(big.NewInt(10), exponent, nil))
+}
+
+/*
+	divRateBase: api.Div is a field division, it only matches the integer one for exact divisions
+*/
+func divRateBase(x *big.Int) *big.Int {
+	rateBase := big.NewInt(circuit.RateBase)
+	if new(big.Int).Mod(x, rateBase).Sign() == 0 {
+		return new(big.Int).Div(x, rateBase)
+	}
+	res := new(big.Int).ModInverse(rateBase, fr.Modulus())
+	res.Mul(res, x)
+	return res.Mod(res, fr.Modulus())
+}
+
+/*
+	setOfferBit: mark the offer as canceled or finalized, offers are indexed by OfferId % OfferSizePerAsset
+*/
+func setOfferBit(offers *big.Int, offerId *big.Int) *big.Int {
+	offerIndex := new(big.Int).Mod(offerId, big.NewInt(circuit.OfferSizePerAsset))
+	return new(big.Int).SetBit(offers, int(offerIndex.Int64()), 1)
+}
+
+/*
+	computeRoot: same as types.UpdateMerkleProof, the helper bits are the bits of the index
+*/
+func computeRoot(hFunc hash.Hash, leaf *big.Int, proof []*big.Int, index *big.Int) *big.Int {
+	node := leaf
+	for i := 0; i < len(proof); i++ {
+		if index.Bit(i) == 1 {
+			node = circuit.HashElements(hFunc, proof[i], node)
+		} else {
+			node = circuit.HashElements(hFunc, node, proof[i])
+		}
+	}
+	return node
+}
+
+func verifySignature(pk *eddsa.PublicKey, sig *circuit.Signature, msgHash *big.Int) error {
+	if pk == nil || sig == nil {
+		return errors.New("missing public key or signature")
+	}
+	isValid, err := pk.Verify(sig.Bytes(), toFieldBytes(msgHash), mimc.NewMiMC())
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.New("signature doesn't match the public key")
+	}
+	return nil
+}
+
+func toFieldBytes(a *big.Int) []byte {
+	return new(big.Int).Mod(a, fr.Modulus()).FillBytes(make([]byte, 32))
+}
+
+func (r *fieldReader) proof(proof []circuit.Variable) []*big.Int {
+	nodes := make([]*big.Int, len(proof))
+	for i := range proof {
+		nodes[i] = r.value(proof[i])
+	}
+	return nodes
+}
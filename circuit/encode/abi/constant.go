@@ -13,6 +13,9 @@ func (abiId AbiId) ToBigIntRegular(res *big.Int) *big.Int {
 const GeneralABIJSON = "[{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"components\":[{\"internalType\":\"uint8\",\"name\":\"OfferType\",\"type\":\"uint8\"},{\"internalType\":\"uint24\",\"name\":\"OfferId\",\"type\":\"uint24\"},{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"NftIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint40\",\"name\":\"packedAmount\",\"type\":\"uint40\"},{\"internalType\":\"uint64\",\"name\":\"OfferListedAt\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"OfferExpiredAt\",\"type\":\"uint64\"},{\"internalType\":\"bytes16\",\"name\":\"SigRx\",\"type\":\"bytes16\"},{\"internalType\":\"bytes16\",\"name\":\"SigRy\",\"type\":\"bytes16\"},{\"internalType\":\"bytes32\",\"name\":\"SigS\",\"type\":\"bytes32\"}],\"internalType\":\"struct Storage.Offer\",\"name\":\"BuyerOffer\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"uint8\",\"name\":\"OfferType\",\"type\":\"uint8\"},{\"internalType\":\"uint24\",\"name\":\"OfferId\",\"type\":\"uint24\"},{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"NftIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint40\",\"name\":\"packedAmount\",\"type\":\"uint40\"},{\"internalType\":\"uint64\",\"name\":\"OfferListedAt\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"OfferExpiredAt\",\"type\":\"uint64\"},{\"internalType\":\"bytes16\",\"name\":\"SigRx\",\"type\":\"bytes16\"},{\"internalType\":\"bytes16\",\"name\":\"SigRy\",\"type\":\"bytes16\"},{\"internalType\":\"bytes32\",\"name\":\"SigS\",\"type\":\"bytes32\"}],\"internalType\":\"struct Storage.Offer\",\"name\":\"SellerOffer\",\"type\":\"tuple\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"AtomicMatch\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint24\",\"name\":\"OfferId\",\"type\":\"uint24\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"CancelOffer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"CreateCollection\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"CreatorAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ToAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"bytes32\",\"name\":\"ToAccountNameHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"NftContentHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"uint32\",\"name\":\"CreatorTreasureRate\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"NftCollectionId\",\"type\":\"uint32\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"MintNft\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"FromAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ToAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"bytes32\",\"name\":\"ToAccountNameHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint16\",\"name\":\"AssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint40\",\"name\":\"packedAmount\",\"type\":\"uint40\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"bytes32\",\"name\":\"CallDataHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"Transfer\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"FromAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ToAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"bytes32\",\"name\":\"ToAccountNameHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint40\",\"name\":\"NftIndex\",\"type\":\"uint40\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"bytes32\",\"name\":\"CallDataHash\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"TransferNft\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"FromAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"AssetId\",\"type\":\"uint16\"},{\"internalType\":\"bytes16\",\"name\":\"AssetAmount\",\"type\":\"bytes16\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"bytes20\",\"name\":\"ToAddress\",\"type\":\"bytes20\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"Withdraw\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint32\",\"name\":\"AccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint40\",\"name\":\"NftIndex\",\"type\":\"uint40\"},{\"internalType\":\"bytes20\",\"name\":\"ToAddress\",\"type\":\"bytes20\"},{\"internalType\":\"uint32\",\"name\":\"GasAccountIndex\",\"type\":\"uint32\"},{\"internalType\":\"uint16\",\"name\":\"GasFeeAssetId\",\"type\":\"uint16\"},{\"internalType\":\"uint16\",\"name\":\"packedFee\",\"type\":\"uint16\"},{\"internalType\":\"uint64\",\"name\":\"ExpireAt\",\"type\":\"uint64\"},{\"internalType\":\"uint32\",\"name\":\"Nonce\",\"type\":\"uint32\"},{\"internalType\":\"uint32\",\"name\":\"ChainId\",\"type\":\"uint32\"}],\"name\":\"WithdrawNft\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
 const AbiEncodeEmptyByte = 0xffff
 
+// bytes past the end of an encoding
+const AbiEncodeEndByte = 256
+
 const (
 	DefaultAbi AbiId = iota
 	TransferAbi
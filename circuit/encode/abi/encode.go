@@ -23,7 +23,7 @@ func (circuit *AbiCircuit) Define(api frontend.API) error {
 	}
 
 	for i := range res {
-		validRes := api.Select(api.IsZero(api.Sub(res[i], 256)), 0, res[i])
+		validRes := api.Select(api.IsZero(api.Sub(res[i], AbiEncodeEndByte)), 0, res[i])
 		api.AssertIsEqual(validRes, circuit.Bytes[i])
 	}
 	return nil
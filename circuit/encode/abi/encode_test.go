@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
@@ -45,7 +46,7 @@ func TestAbiEncodeTransfer(t *testing.T) {
 	w.Values[37] = uint16(1)
 	w.Values[38] = uint16(1)
 
-	bytesLast := [32]byte{'0'}
+	bytesLast := [32]byte{'1'}
 	wrappedLast := WrapToAbiBytes32(bytesLast)
 	for i := range wrappedLast {
 		w.Values[39+i] = wrappedLast[i]
@@ -311,7 +312,7 @@ func TestAbiEncodeTransferNft(t *testing.T) {
 	w.Values[35] = uint32(1)
 	w.Values[36] = uint16(1)
 	w.Values[37] = uint16(1)
-	bytesLast := [32]byte{'0'}
+	bytesLast := [32]byte{'1'}
 	wrappedLast := WrapToAbiBytes32(bytesLast)
 	for i := range wrappedLast {
 		w.Values[38+i] = wrappedLast[i]
@@ -375,24 +376,24 @@ func TestAbiEncodeMintNft(t *testing.T) {
 	for i := range wrappedFirst {
 		w.Values[2+i] = wrappedFirst[i]
 	}
-	bytesLast := [32]byte{'0'}
+	bytesLast := [32]byte{'1'}
 	wrappedLast := WrapToAbiBytes32(bytesLast)
 	for i := range wrappedLast {
 		w.Values[34+i] = wrappedLast[i]
 	}
-	w.Values[68] = uint32(1)
-	w.Values[69] = uint16(1)
-	w.Values[70] = uint16(1)
-	w.Values[71] = uint32(1)
+	w.Values[66] = uint32(1)
+	w.Values[67] = uint16(1)
+	w.Values[68] = uint16(1)
+	w.Values[69] = uint32(1)
+	w.Values[70] = uint32(1)
+	w.Values[71] = uint64(1)
 	w.Values[72] = uint32(1)
-	w.Values[73] = uint64(1)
-	w.Values[74] = uint32(1)
-	w.Values[75] = uint32(1)
+	w.Values[73] = uint32(1)
 
 	a, err := abi2.JSON(strings.NewReader(GeneralABIJSON))
 	assert.NoError(t, err)
 
-	b, err := a.Pack("MintNft", w.Values[0].(uint32), w.Values[1].(uint32), bytesFirst, bytesLast, w.Values[68].(uint32), w.Values[69].(uint16), w.Values[70].(uint16), w.Values[71].(uint32), w.Values[72].(uint32), w.Values[73].(uint64), w.Values[74].(uint32), w.Values[75].(uint32))
+	b, err := a.Pack("MintNft", w.Values[0].(uint32), w.Values[1].(uint32), bytesFirst, bytesLast, w.Values[66].(uint32), w.Values[67].(uint16), w.Values[68].(uint16), w.Values[69].(uint32), w.Values[70].(uint32), w.Values[71].(uint64), w.Values[72].(uint32), w.Values[73].(uint32))
 
 	assert.NoError(t, err)
 
@@ -561,3 +562,39 @@ func TestAbiEncodeAtomicMatch(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestAbiEncodeConstraints(t *testing.T) {
+	witness := func(values ...frontend.Variable) AbiCircuit {
+		w := DefaultCircuit()
+		w.AbiId = int(CreateCollectionAbi)
+		copy(w.Values, values)
+		for i := range w.Bytes {
+			w.Bytes[i] = 0
+		}
+		a, err := abi2.JSON(strings.NewReader(GeneralABIJSON))
+		assert.NoError(t, err)
+		b, err := a.Pack("CreateCollection", uint32(1), uint32(2), uint16(3), uint16(4), uint64(5), uint32(6), uint32(7))
+		assert.NoError(t, err)
+		for i := range b {
+			w.Bytes[i] = b[i]
+		}
+		return w
+	}
+	circuit := DefaultCircuit()
+
+	w := witness(1, 2, 3, 4, 5, 6, 7)
+	assert.NoError(t, test.IsSolved(&circuit, &w, ecc.BN254, backend.PLONK))
+
+	// bytes not matching the values
+	w = witness(1, 2, 3, 4, 5, 6, 8)
+	assert.Error(t, test.IsSolved(&circuit, &w, ecc.BN254, backend.PLONK))
+
+	// GasFeeAssetId is a uint16, 3 + 2^16 has the same low bytes
+	w = witness(1, 2, 3+(1<<16), 4, 5, 6, 7)
+	assert.Error(t, test.IsSolved(&circuit, &w, ecc.BN254, backend.PLONK))
+
+	// unknown abi id
+	w = witness(1, 2, 3, 4, 5, 6, 7)
+	w.AbiId = 9
+	assert.Error(t, test.IsSolved(&circuit, &w, ecc.BN254, backend.PLONK))
+}
@@ -43,3 +43,26 @@ func NewContext(api frontend.API, defaultApiFlag,
 		cancelOfferApiFlag:      cancelOfferApiFlag,
 	}, api: api}
 }
+
+func (f Flags) flag(abiId AbiId) frontend.Variable {
+	switch abiId {
+	case TransferAbi:
+		return f.transferApiFlag
+	case WithdrawAbi:
+		return f.withdrawApiFlag
+	case CreateCollectionAbi:
+		return f.createCollectionApiFlag
+	case WithdrawNftAbi:
+		return f.withdrawNftApiFlag
+	case TransferNftAbi:
+		return f.transferNftApiFlag
+	case MintNftAbi:
+		return f.mintNftApiFlag
+	case AtomicMatchAbi:
+		return f.atomicMatchApiFlag
+	case CancelOfferAbi:
+		return f.cancelOfferApiFlag
+	default:
+		return f.defaultApiFlag
+	}
+}
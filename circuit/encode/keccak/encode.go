@@ -1,14 +1,10 @@
 package abi
 
 import (
-	"math/big"
-
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/bnb-chain/zkbnb-crypto/circuit/encode/abi"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
 )
 
 type KeccakCircuit struct {
@@ -18,10 +14,6 @@ type KeccakCircuit struct {
 	Name          frontend.Variable   `gnark:",public"` // abi object
 }
 
-func init() {
-	hint.Register(GenerateKeccakHint)
-}
-
 func (circuit *KeccakCircuit) Define(api frontend.API) error {
 	encoder, err := abi.NewAbiEncoder(api, circuit.AbiId)
 	if err != nil {
@@ -33,27 +25,15 @@ func (circuit *KeccakCircuit) Define(api frontend.API) error {
 		return err
 	}
 
-	keccakRes, err := api.Compiler().NewHint(GenerateKeccakHint, 32, res...)
+	// the encoding is followed by empty bytes only, an empty byte before its end isn't a byte
+	length := frontend.Variable(0)
+	for i := range res {
+		length = api.Add(length, api.Sub(1, api.IsZero(api.Sub(res[i], abi.AbiEncodeEndByte))))
+	}
+	keccakRes := types.Keccak256Bytes(api, res, length)
 
 	for i := range keccakRes {
 		api.AssertIsEqual(keccakRes[i], circuit.Keccaa256Hash[i])
 	}
 	return nil
 }
-
-func GenerateKeccakHint(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
-	preImageBytes := make([]byte, 0)
-
-	for _, bi := range inputs {
-		if len(bi.Bytes()) > 1 {
-			continue
-		}
-		preImageBytes = append(preImageBytes, uint8(bi.Uint64()))
-	}
-
-	keccakSum := crypto.Keccak256(preImageBytes)
-	for i := range keccakSum {
-		results[i].SetUint64(uint64(keccakSum[i]))
-	}
-	return nil
-}
@@ -1,15 +1,13 @@
 package abi
 
 import (
-	"fmt"
 	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/test"
 	abiEth "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -19,15 +17,6 @@ import (
 )
 
 func TestAbiEncodeTransfer(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.TransferAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -71,17 +60,7 @@ func TestAbiEncodeTransfer(t *testing.T) {
 
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
@@ -99,16 +78,18 @@ func DefaultCircuit() (circuit KeccakCircuit) {
 	return circuit
 }
 
-func TestAbiEncodeWithdraw(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
+// the in circuit keccak makes the circuit too large to be proven in a test, it is only solved
+func assertKeccakSolved(t *testing.T, w *KeccakCircuit) {
+	circuit := DefaultCircuit()
+	assert.NoError(t, test.IsSolved(&circuit, w, ecc.BN254, backend.PLONK))
 
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
+	wrongHash := *w
+	wrongHash.Keccaa256Hash = append([]frontend.Variable{}, w.Keccaa256Hash...)
+	wrongHash.Keccaa256Hash[0] = w.Keccaa256Hash[0].(byte) ^ 1
+	assert.Error(t, test.IsSolved(&circuit, &wrongHash, ecc.BN254, backend.PLONK))
+}
 
+func TestAbiEncodeWithdraw(t *testing.T) {
 	var w KeccakCircuit
 	w.AbiId = int(abi.WithdrawAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -150,29 +131,10 @@ func TestAbiEncodeWithdraw(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 }
 
 func TestAbiEncodeCreateCollection(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.CreateCollectionAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -201,30 +163,11 @@ func TestAbiEncodeCreateCollection(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
 func TestAbiEncodeWithdrawNft(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.WithdrawNftAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -259,30 +202,11 @@ func TestAbiEncodeWithdrawNft(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
 func TestAbiEncodeTransferNft(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.TransferNftAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -323,30 +247,11 @@ func TestAbiEncodeTransferNft(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
 func TestAbiEncodeMintNft(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.MintNftAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -366,19 +271,19 @@ func TestAbiEncodeMintNft(t *testing.T) {
 	for i := range wrappedLast {
 		w.Values[34+i] = wrappedLast[i]
 	}
-	w.Values[68] = uint32(1)
-	w.Values[69] = uint16(1)
-	w.Values[70] = uint16(1)
-	w.Values[71] = uint32(1)
+	w.Values[66] = uint32(1)
+	w.Values[67] = uint16(1)
+	w.Values[68] = uint16(1)
+	w.Values[69] = uint32(1)
+	w.Values[70] = uint32(1)
+	w.Values[71] = uint64(1)
 	w.Values[72] = uint32(1)
-	w.Values[73] = uint64(1)
-	w.Values[74] = uint32(1)
-	w.Values[75] = uint32(1)
+	w.Values[73] = uint32(1)
 
 	a, err := abiEth.JSON(strings.NewReader(abi.GeneralABIJSON))
 	assert.NoError(t, err)
 
-	b, err := a.Pack("MintNft", w.Values[0].(uint32), w.Values[1].(uint32), bytesFirst, bytesLast, w.Values[68].(uint32), w.Values[69].(uint16), w.Values[70].(uint16), w.Values[71].(uint32), w.Values[72].(uint32), w.Values[73].(uint64), w.Values[74].(uint32), w.Values[75].(uint32))
+	b, err := a.Pack("MintNft", w.Values[0].(uint32), w.Values[1].(uint32), bytesFirst, bytesLast, w.Values[66].(uint32), w.Values[67].(uint16), w.Values[68].(uint16), w.Values[69].(uint32), w.Values[70].(uint32), w.Values[71].(uint64), w.Values[72].(uint32), w.Values[73].(uint32))
 
 	assert.NoError(t, err)
 
@@ -388,30 +293,11 @@ func TestAbiEncodeMintNft(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
 func TestAbiEncodeCancelOffer(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.CancelOfferAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -441,30 +327,11 @@ func TestAbiEncodeCancelOffer(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
 
 func TestAbiEncodeAtomicMatch(t *testing.T) {
-	// Compile circuit
-	var circuit KeccakCircuit = DefaultCircuit()
-	_scs, _ := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
-	fmt.Println("Schema:", _scs.GetSchema())
-	fmt.Println("SCs:", len(_scs.GetConstraints()))
-
-	srs, _ := test.NewKZGSRS(_scs)
-	pk, vk, _ := plonk.Setup(_scs, srs)
-
 	var w KeccakCircuit
 	w.AbiId = int(abi.AtomicMatchAbi)
 	w.Values = make([]frontend.Variable, 255)
@@ -522,16 +389,6 @@ func TestAbiEncodeAtomicMatch(t *testing.T) {
 	}
 	w.Name = 1
 
-	witnessFull, err := frontend.NewWitness(&w, ecc.BN254)
-	assert.NoError(t, err)
-
-	proof, err := plonk.Prove(_scs, pk, witnessFull)
-	assert.NoError(t, err)
-
-	witnessPublic, err := frontend.NewWitness(&w, ecc.BN254, frontend.PublicOnly())
-	assert.NoError(t, err)
-
-	err = plonk.Verify(proof, vk, witnessPublic)
-	assert.NoError(t, err)
+	assertKeccakSolved(t, &w)
 
 }
@@ -0,0 +1,53 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	Exodus: balance of an account asset in the last verified state, proven to
+	withdraw on layer 1 once the rollup is frozen. The assets of AccountInfo are ignored.
+*/
+type Exodus struct {
+	StateRoot                []byte
+	AccountRoot              []byte
+	LiquidityRoot            []byte
+	NftRoot                  []byte
+	CollectionRoot           []byte
+	AccountInfo              *types.Account
+	Asset                    *types.AccountAsset
+	MerkleProofsAccountAsset [][]byte
+	MerkleProofsAccount      [][]byte
+}
+
+/*
+	ExodusNft: nft owned by an account in the last verified state
+*/
+type ExodusNft struct {
+	StateRoot           []byte
+	AccountRoot         []byte
+	LiquidityRoot       []byte
+	NftRoot             []byte
+	CollectionRoot      []byte
+	AccountInfo         *types.Account
+	Nft                 *types.Nft
+	MerkleProofsAccount [][]byte
+	MerkleProofsNft     [][]byte
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"errors"
+	"log"
+
+	"github.com/consensys/gnark/std/signature/eddsa"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	ExodusConstraints: the asset balance of an account is a leaf of the state root,
+	the owner, asset id and balance are public so that they can be withdrawn on layer 1
+*/
+type ExodusConstraints struct {
+	StateRoot       Variable `gnark:",public"`
+	AccountIndex    Variable `gnark:",public"`
+	AccountNameHash Variable `gnark:",public"`
+	AssetId         Variable `gnark:",public"`
+	Balance         Variable `gnark:",public"`
+
+	AccountRoot              Variable
+	LiquidityRoot            Variable
+	NftRoot                  Variable
+	CollectionRoot           Variable
+	AccountPk                eddsa.PublicKey
+	Nonce                    Variable
+	// frozen collection nonce, part of the account leaf
+	CollectionNonce          Variable
+	AssetRoot                Variable
+	OfferCanceledOrFinalized Variable
+	MerkleProofsAccountAsset []Variable
+	MerkleProofsAccount      []Variable
+	// hash and depth of the state trees
+	HashType types.HashType
+	Config   CircuitConfig
+}
+
+/*
+	NewExodusConstraints: construct an empty exodus circuit for the given trees
+*/
+func NewExodusConstraints(config CircuitConfig, hashType types.HashType) ExodusConstraints {
+	return ExodusConstraints{
+		MerkleProofsAccountAsset: zeroMerkleProof(config.AssetMerkleLevels),
+		MerkleProofsAccount:      zeroMerkleProof(config.AccountMerkleLevels),
+		HashType:                 hashType,
+		Config:                   config,
+	}
+}
+
+func (circuit ExodusConstraints) Define(api API) error {
+	return VerifyExodus(api, circuit)
+}
+
+func VerifyExodus(api API, exodus ExodusConstraints) (err error) {
+	config := exodus.Config
+	if err = checkMerkleProofLevels("MerkleProofsAccountAsset", exodus.MerkleProofsAccountAsset, config.AssetMerkleLevels); err != nil {
+		return err
+	}
+	if err = checkMerkleProofLevels("MerkleProofsAccount", exodus.MerkleProofsAccount, config.AccountMerkleLevels); err != nil {
+		return err
+	}
+	hFunc, err := types.NewHash(api, exodus.HashType)
+	if err != nil {
+		return err
+	}
+	verifyExodusStateRoot(api, hFunc, exodus.StateRoot, exodus.AccountRoot, exodus.LiquidityRoot, exodus.NftRoot, exodus.CollectionRoot)
+
+	// asset leaf
+	api.AssertIsLessOrEqual(exodus.AssetId, config.LastAccountAssetId())
+	assetMerkleHelper := AssetIdToMerkleHelper(api, exodus.AssetId, config)
+	hFunc.Reset()
+	hFunc.Write(
+		exodus.Balance,
+		exodus.OfferCanceledOrFinalized,
+	)
+	assetNodeHash := hFunc.Sum()
+	hFunc.Reset()
+	types.VerifyMerkleProof(api, 1, hFunc, exodus.AssetRoot, assetNodeHash, exodus.MerkleProofsAccountAsset, assetMerkleHelper)
+
+	// account leaf
+	verifyExodusAccount(
+		api, hFunc, config, exodus.AccountRoot, exodus.AccountIndex, exodus.AccountNameHash, exodus.AccountPk,
+		exodus.Nonce, exodus.CollectionNonce, exodus.AssetRoot, exodus.MerkleProofsAccount,
+	)
+	return nil
+}
+
+/*
+	ExodusNftConstraints: the nft is a leaf of the state root and is owned by the account
+*/
+type ExodusNftConstraints struct {
+	StateRoot       Variable             `gnark:",public"`
+	AccountIndex    Variable             `gnark:",public"`
+	AccountNameHash Variable             `gnark:",public"`
+	Nft             types.NftConstraints `gnark:",public"`
+
+	AccountRoot         Variable
+	LiquidityRoot       Variable
+	NftRoot             Variable
+	CollectionRoot      Variable
+	AccountPk           eddsa.PublicKey
+	Nonce               Variable
+	// frozen collection nonce, part of the account leaf
+	CollectionNonce     Variable
+	AssetRoot           Variable
+	MerkleProofsAccount []Variable
+	MerkleProofsNft     []Variable
+	// hash and depth of the state trees
+	HashType types.HashType
+	Config   CircuitConfig
+}
+
+/*
+	NewExodusNftConstraints: construct an empty exodus nft circuit for the given trees
+*/
+func NewExodusNftConstraints(config CircuitConfig, hashType types.HashType) ExodusNftConstraints {
+	return ExodusNftConstraints{
+		MerkleProofsAccount: zeroMerkleProof(config.AccountMerkleLevels),
+		MerkleProofsNft:     zeroMerkleProof(config.NftMerkleLevels),
+		HashType:            hashType,
+		Config:              config,
+	}
+}
+
+func (circuit ExodusNftConstraints) Define(api API) error {
+	return VerifyExodusNft(api, circuit)
+}
+
+func VerifyExodusNft(api API, exodus ExodusNftConstraints) (err error) {
+	config := exodus.Config
+	if err = checkMerkleProofLevels("MerkleProofsAccount", exodus.MerkleProofsAccount, config.AccountMerkleLevels); err != nil {
+		return err
+	}
+	if err = checkMerkleProofLevels("MerkleProofsNft", exodus.MerkleProofsNft, config.NftMerkleLevels); err != nil {
+		return err
+	}
+	hFunc, err := types.NewHash(api, exodus.HashType)
+	if err != nil {
+		return err
+	}
+	verifyExodusStateRoot(api, hFunc, exodus.StateRoot, exodus.AccountRoot, exodus.LiquidityRoot, exodus.NftRoot, exodus.CollectionRoot)
+
+	// nft leaf
+	api.AssertIsEqual(exodus.Nft.OwnerAccountIndex, exodus.AccountIndex)
+	api.AssertIsLessOrEqual(exodus.Nft.NftIndex, config.LastNftIndex())
+	nftIndexMerkleHelper := NftIndexToMerkleHelper(api, exodus.Nft.NftIndex, config)
+	hFunc.Reset()
+	hFunc.Write(
+		exodus.Nft.CreatorAccountIndex,
+		exodus.Nft.OwnerAccountIndex,
+		exodus.Nft.NftContentHash,
+		exodus.Nft.NftL1Address,
+		exodus.Nft.NftL1TokenId,
+		exodus.Nft.CreatorTreasuryRate,
+		exodus.Nft.CollectionId,
+	)
+	nftNodeHash := hFunc.Sum()
+	hFunc.Reset()
+	types.VerifyMerkleProof(api, 1, hFunc, exodus.NftRoot, nftNodeHash, exodus.MerkleProofsNft, nftIndexMerkleHelper)
+
+	// owner account leaf
+	verifyExodusAccount(
+		api, hFunc, config, exodus.AccountRoot, exodus.AccountIndex, exodus.AccountNameHash, exodus.AccountPk,
+		exodus.Nonce, exodus.CollectionNonce, exodus.AssetRoot, exodus.MerkleProofsAccount,
+	)
+	return nil
+}
+
+func verifyExodusStateRoot(api API, hFunc types.Hash, stateRoot, accountRoot, liquidityRoot, nftRoot, collectionRoot Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		accountRoot,
+		liquidityRoot,
+		nftRoot,
+		collectionRoot,
+	)
+	api.AssertIsEqual(hFunc.Sum(), stateRoot)
+}
+
+func verifyExodusAccount(
+	api API, hFunc types.Hash, config CircuitConfig, accountRoot Variable,
+	accountIndex, accountNameHash Variable, accountPk eddsa.PublicKey, nonce, collectionNonce, assetRoot Variable,
+	merkleProofsAccount []Variable,
+) {
+	// empty accounts have no owner
+	api.AssertIsDifferent(accountNameHash, types.ZeroInt)
+	api.AssertIsLessOrEqual(accountIndex, config.LastAccountIndex())
+	accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, accountIndex, config)
+	hFunc.Reset()
+	hFunc.Write(
+		accountNameHash,
+		accountPk.A.X,
+		accountPk.A.Y,
+		nonce,
+		collectionNonce,
+		assetRoot,
+	)
+	accountNodeHash := hFunc.Sum()
+	hFunc.Reset()
+	types.VerifyMerkleProof(api, 1, hFunc, accountRoot, accountNodeHash, merkleProofsAccount, accountIndexMerkleHelper)
+}
+
+func SetExodusWitness(oExodus *Exodus, config CircuitConfig) (witness ExodusConstraints, err error) {
+	if oExodus == nil || oExodus.AccountInfo == nil || oExodus.Asset == nil {
+		log.Println("[SetExodusWitness] invalid params")
+		return witness, errors.New("[SetExodusWitness] invalid params")
+	}
+	account := oExodus.AccountInfo
+	witness = ExodusConstraints{
+		StateRoot:                oExodus.StateRoot,
+		AccountIndex:             account.AccountIndex,
+		AccountNameHash:          account.AccountNameHash,
+		AssetId:                  oExodus.Asset.AssetId,
+		Balance:                  oExodus.Asset.Balance,
+		AccountRoot:              oExodus.AccountRoot,
+		LiquidityRoot:            oExodus.LiquidityRoot,
+		NftRoot:                  oExodus.NftRoot,
+		CollectionRoot:           oExodus.CollectionRoot,
+		AccountPk:                types.SetPubKeyWitness(account.AccountPk),
+		Nonce:                    account.Nonce,
+		CollectionNonce:          account.CollectionNonce,
+		AssetRoot:                account.AssetRoot,
+		OfferCanceledOrFinalized: oExodus.Asset.OfferCanceledOrFinalized,
+		Config:                   config,
+	}
+	witness.MerkleProofsAccountAsset, err = SetMerkleProofWitness(oExodus.MerkleProofsAccountAsset, config.AssetMerkleLevels)
+	if err != nil {
+		return witness, err
+	}
+	witness.MerkleProofsAccount, err = SetMerkleProofWitness(oExodus.MerkleProofsAccount, config.AccountMerkleLevels)
+	if err != nil {
+		return witness, err
+	}
+	return witness, nil
+}
+
+func SetExodusNftWitness(oExodus *ExodusNft, config CircuitConfig) (witness ExodusNftConstraints, err error) {
+	if oExodus == nil || oExodus.AccountInfo == nil || oExodus.Nft == nil {
+		log.Println("[SetExodusNftWitness] invalid params")
+		return witness, errors.New("[SetExodusNftWitness] invalid params")
+	}
+	account := oExodus.AccountInfo
+	witness = ExodusNftConstraints{
+		StateRoot:       oExodus.StateRoot,
+		AccountIndex:    account.AccountIndex,
+		AccountNameHash: account.AccountNameHash,
+		AccountRoot:     oExodus.AccountRoot,
+		LiquidityRoot:   oExodus.LiquidityRoot,
+		NftRoot:         oExodus.NftRoot,
+		CollectionRoot:  oExodus.CollectionRoot,
+		AccountPk:       types.SetPubKeyWitness(account.AccountPk),
+		Nonce:           account.Nonce,
+		CollectionNonce: account.CollectionNonce,
+		AssetRoot:       account.AssetRoot,
+		Config:          config,
+	}
+	witness.Nft, err = types.SetNftWitness(oExodus.Nft)
+	if err != nil {
+		return witness, err
+	}
+	witness.MerkleProofsAccount, err = SetMerkleProofWitness(oExodus.MerkleProofsAccount, config.AccountMerkleLevels)
+	if err != nil {
+		return witness, err
+	}
+	witness.MerkleProofsNft, err = SetMerkleProofWitness(oExodus.MerkleProofsNft, config.NftMerkleLevels)
+	if err != nil {
+		return witness, err
+	}
+	return witness, nil
+}
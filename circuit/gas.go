@@ -24,6 +24,6 @@ import (
 type Gas struct {
 	GasAssetCount                   int
 	AccountInfoBefore               *types.GasAccount
-	MerkleProofsAccountBefore       [AccountMerkleLevels][]byte
-	MerkleProofsAccountAssetsBefore [][AssetMerkleLevels][]byte
+	MerkleProofsAccountBefore       [][]byte
+	MerkleProofsAccountAssetsBefore [][][]byte
 }
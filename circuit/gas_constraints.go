@@ -39,17 +39,26 @@ type GasAccountConstraints struct {
 type GasConstraints struct {
 	GasAssetCount                   int
 	AccountInfoBefore               GasAccountConstraints
-	MerkleProofsAccountBefore       [AccountMerkleLevels]Variable
-	MerkleProofsAccountAssetsBefore [][AssetMerkleLevels]Variable
+	MerkleProofsAccountBefore       []Variable
+	MerkleProofsAccountAssetsBefore [][]Variable
 }
 
 func VerifyGas(
 	api API,
 	gas GasConstraints,
+	config CircuitConfig,
 	needGas Variable,
 	gasAssetDeltas []Variable,
-	hFunc MiMC,
+	hFunc types.Hash,
 	accountRoot Variable) (newAccountRoot Variable, err error) {
+	if err = checkMerkleProofLevels("gas MerkleProofsAccountBefore", gas.MerkleProofsAccountBefore, config.AccountMerkleLevels); err != nil {
+		return nil, err
+	}
+	for i := range gas.MerkleProofsAccountAssetsBefore {
+		if err = checkMerkleProofLevels("gas MerkleProofsAccountAssetsBefore", gas.MerkleProofsAccountAssetsBefore[i], config.AssetMerkleLevels); err != nil {
+			return nil, err
+		}
+	}
 	newAccountRoot = accountRoot
 	newAccountAssetsRoot := gas.AccountInfoBefore.AssetRoot
 
@@ -58,7 +67,7 @@ func VerifyGas(
 
 	gasAssetCount := len(gasAssetDeltas)
 	for i := 0; i < gasAssetCount; i++ {
-		assetMerkleHelper := AssetIdToMerkleHelper(api, gas.AccountInfoBefore.AssetsInfo[i].AssetId)
+		assetMerkleHelper := AssetIdToMerkleHelper(api, gas.AccountInfoBefore.AssetsInfo[i].AssetId, config)
 		hFunc.Reset()
 		hFunc.Write(
 			gas.AccountInfoBefore.AssetsInfo[i].Balance,
@@ -72,7 +81,7 @@ func VerifyGas(
 			hFunc,
 			newAccountAssetsRoot,
 			assetNodeHash,
-			gas.MerkleProofsAccountAssetsBefore[i][:],
+			gas.MerkleProofsAccountAssetsBefore[i],
 			assetMerkleHelper,
 		)
 		hFunc.Reset()
@@ -83,10 +92,10 @@ func VerifyGas(
 		assetNodeHash = hFunc.Sum()
 		hFunc.Reset()
 		newAccountAssetsRoot = types.UpdateMerkleProof(
-			api, hFunc, assetNodeHash, gas.MerkleProofsAccountAssetsBefore[i][:], assetMerkleHelper)
+			api, hFunc, assetNodeHash, gas.MerkleProofsAccountAssetsBefore[i], assetMerkleHelper)
 	}
 	// verify account node hash
-	accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, gas.AccountInfoBefore.AccountIndex)
+	accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, gas.AccountInfoBefore.AccountIndex, config)
 	hFunc.Reset()
 	hFunc.Write(
 		gas.AccountInfoBefore.AccountNameHash,
@@ -105,7 +114,7 @@ func VerifyGas(
 		hFunc,
 		newAccountRoot,
 		accountNodeHash,
-		gas.MerkleProofsAccountBefore[:],
+		gas.MerkleProofsAccountBefore,
 		accountIndexMerkleHelper,
 	)
 	hFunc.Reset()
@@ -120,11 +129,11 @@ func VerifyGas(
 	accountNodeHash = hFunc.Sum()
 	hFunc.Reset()
 	// update merkle proof
-	newAccountRoot = types.UpdateMerkleProof(api, hFunc, accountNodeHash, gas.MerkleProofsAccountBefore[:], accountIndexMerkleHelper)
+	newAccountRoot = types.UpdateMerkleProof(api, hFunc, accountNodeHash, gas.MerkleProofsAccountBefore, accountIndexMerkleHelper)
 	return newAccountRoot, err
 }
 
-func GetZeroGasConstraints(gasAssets []int64) GasConstraints {
+func GetZeroGasConstraints(gasAssets []int64, config CircuitConfig) GasConstraints {
 	gasAssetCount := len(gasAssets)
 	var zeroGasConstraint GasConstraints
 	zeroGasConstraint.GasAssetCount = gasAssetCount
@@ -149,17 +158,13 @@ func GetZeroGasConstraints(gasAssets []int64) GasConstraints {
 	}
 	// accounts info before
 	zeroGasConstraint.AccountInfoBefore = zeroAccountConstraint
-	zeroGasConstraint.MerkleProofsAccountAssetsBefore = make([][AssetMerkleLevels]Variable, gasAssetCount)
+	zeroGasConstraint.MerkleProofsAccountAssetsBefore = make([][]Variable, gasAssetCount)
 	for j := 0; j < gasAssetCount; j++ {
-		for k := 0; k < AssetMerkleLevels; k++ {
-			// account assets before
-			zeroGasConstraint.MerkleProofsAccountAssetsBefore[j][k] = 0
-		}
-	}
-	for j := 0; j < AccountMerkleLevels; j++ {
-		// account before
-		zeroGasConstraint.MerkleProofsAccountBefore[j] = 0
+		// account assets before
+		zeroGasConstraint.MerkleProofsAccountAssetsBefore[j] = zeroMerkleProof(config.AssetMerkleLevels)
 	}
+	// account before
+	zeroGasConstraint.MerkleProofsAccountBefore = zeroMerkleProof(config.AccountMerkleLevels)
 
 	return zeroGasConstraint
 }
@@ -190,23 +195,26 @@ func SetGasAccountWitness(account *types.GasAccount, assetCount int) (witness Ga
 	return witness, nil
 }
 
-func SetGasWitness(oGas *Gas) (witness GasConstraints, err error) {
+func SetGasWitness(oGas *Gas, config CircuitConfig) (witness GasConstraints, err error) {
 	witness.GasAssetCount = oGas.GasAssetCount
 	witness.AccountInfoBefore, err = SetGasAccountWitness(oGas.AccountInfoBefore, oGas.GasAssetCount)
 	if err != nil {
 		log.Println("fail to set gas witness, err:", err.Error())
 		return witness, err
 	}
-	for i := 0; i < AccountMerkleLevels; i++ {
-		// account before
-		witness.MerkleProofsAccountBefore[i] = oGas.MerkleProofsAccountBefore[i]
+	// account before
+	witness.MerkleProofsAccountBefore, err = SetMerkleProofWitness(oGas.MerkleProofsAccountBefore, config.AccountMerkleLevels)
+	if err != nil {
+		log.Println("fail to set gas witness, err:", err.Error())
+		return witness, err
 	}
-	witness.MerkleProofsAccountAssetsBefore = make([][AssetMerkleLevels]Variable, 0)
+	witness.MerkleProofsAccountAssetsBefore = make([][]Variable, 0)
 	for i := 0; i < oGas.GasAssetCount; i++ {
-		merkleProofsAccountAssets := [AssetMerkleLevels]Variable{}
-		for j := 0; j < AssetMerkleLevels; j++ {
-			// account assets before
-			merkleProofsAccountAssets[j] = oGas.MerkleProofsAccountAssetsBefore[i][j]
+		// account assets before
+		merkleProofsAccountAssets, err := SetMerkleProofWitness(oGas.MerkleProofsAccountAssetsBefore[i], config.AssetMerkleLevels)
+		if err != nil {
+			log.Println("fail to set gas witness, err:", err.Error())
+			return witness, err
 		}
 		witness.MerkleProofsAccountAssetsBefore = append(witness.MerkleProofsAccountAssetsBefore, merkleProofsAccountAssets)
 	}
@@ -0,0 +1,318 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	HashElements: native hash of field elements, each element is written as 32 bytes
+*/
+func HashElements(hFunc hash.Hash, elements ...*big.Int) *big.Int {
+	hFunc.Reset()
+	for _, element := range elements {
+		hFunc.Write(new(big.Int).Mod(element, fr.Modulus()).FillBytes(make([]byte, 32)))
+	}
+	return new(big.Int).SetBytes(hFunc.Sum(nil))
+}
+
+/*
+	ComputeAssetLeafHash: hash of an asset leaf, the asset id is its index in the asset tree
+*/
+func ComputeAssetLeafHash(hFunc hash.Hash, balance, offerCanceledOrFinalized *big.Int) *big.Int {
+	return HashElements(hFunc, balance, offerCanceledOrFinalized)
+}
+
+/*
+	ComputeAccountLeafHash: hash of an account leaf, pkX and pkY are the coordinates of the layer 2 key
+*/
+func ComputeAccountLeafHash(hFunc hash.Hash, accountNameHash, pkX, pkY, nonce, collectionNonce, assetRoot *big.Int) *big.Int {
+	return HashElements(hFunc, accountNameHash, pkX, pkY, nonce, collectionNonce, assetRoot)
+}
+
+/*
+	ComputeLiquidityLeafHash: hash of a pair leaf
+*/
+func ComputeLiquidityLeafHash(
+	hFunc hash.Hash,
+	assetAId, assetA, assetBId, assetB, lpAssetId, lpAmount, feeRate, treasuryAccountIndex, treasuryRate *big.Int,
+) *big.Int {
+	return HashElements(hFunc, assetAId, assetA, assetBId, assetB, lpAssetId, lpAmount, feeRate, treasuryAccountIndex, treasuryRate)
+}
+
+/*
+	ComputeNftLeafHash: hash of an nft leaf
+*/
+func ComputeNftLeafHash(
+	hFunc hash.Hash,
+	creatorAccountIndex, ownerAccountIndex, nftContentHash, nftL1Address, nftL1TokenId, creatorTreasuryRate, collectionId *big.Int,
+) *big.Int {
+	return HashElements(hFunc, creatorAccountIndex, ownerAccountIndex, nftContentHash, nftL1Address, nftL1TokenId, creatorTreasuryRate, collectionId)
+}
+
+/*
+	ComputeCollectionLeafHash: hash of a collection leaf
+*/
+func ComputeCollectionLeafHash(hFunc hash.Hash, ownerAccountIndex, metadataHash, creatorTreasuryRate *big.Int) *big.Int {
+	return HashElements(hFunc, ownerAccountIndex, metadataHash, creatorTreasuryRate)
+}
+
+/*
+	ComputeTxHash: native counterpart of the types.ComputeHashFrom*Tx functions, the message
+	VerifyTransaction checks the signature of a layer 2 tx against
+*/
+func ComputeTxHash(oTx *Tx, chainId int64) (hashVal *big.Int, err error) {
+	if oTx == nil {
+		log.Println("[ComputeTxHash] invalid tx")
+		return nil, errors.New("[ComputeTxHash] invalid tx")
+	}
+	var (
+		r        witnessReader
+		missing  bool
+		elements []*big.Int
+	)
+	header := func(accountIndex, gasAccountIndex, gasFeeAssetId, gasFeeAssetAmount Variable) []*big.Int {
+		return []*big.Int{
+			r.pack(chainId, accountIndex, oTx.Nonce, oTx.ExpiredAt),
+			r.pack(gasAccountIndex, gasFeeAssetId, gasFeeAssetAmount),
+		}
+	}
+	switch oTx.TxType {
+	case types.TxTypeTransfer:
+		if missing = oTx.TransferTxInfo == nil; !missing {
+			txInfo := types.SetTransferTxWitness(oTx.TransferTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.ToAccountIndex, txInfo.AssetId, txInfo.AssetAmount),
+				r.value(txInfo.ToAccountNameHash),
+				r.value(txInfo.CallDataHash),
+			)
+		}
+	case types.TxTypeWithdraw:
+		if missing = oTx.WithdrawTxInfo == nil; !missing {
+			txInfo := types.SetWithdrawTxWitness(oTx.WithdrawTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.AssetId),
+				r.value(txInfo.AssetAmount),
+				r.value(txInfo.ToAddress),
+			)
+		}
+	case types.TxTypeCreateCollection:
+		if missing = oTx.CreateCollectionTxInfo == nil; !missing {
+			txInfo := types.SetCreateCollectionTxWitness(oTx.CreateCollectionTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.CreatorTreasuryRate),
+				r.value(txInfo.MetadataHash),
+			)
+		}
+	case types.TxTypeMintNft:
+		if missing = oTx.MintNftTxInfo == nil; !missing {
+			txInfo := types.SetMintNftTxWitness(oTx.MintNftTxInfo)
+			elements = append(header(txInfo.CreatorAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.ToAccountIndex, txInfo.CreatorTreasuryRate, txInfo.CollectionId),
+				r.value(txInfo.ToAccountNameHash),
+				r.value(txInfo.NftContentHash),
+			)
+		}
+	case types.TxTypeTransferNft:
+		if missing = oTx.TransferNftTxInfo == nil; !missing {
+			txInfo := types.SetTransferNftTxWitness(oTx.TransferNftTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.ToAccountIndex, txInfo.NftIndex),
+				r.value(txInfo.ToAccountNameHash),
+				r.value(txInfo.CallDataHash),
+			)
+		}
+	case types.TxTypeAtomicMatch:
+		if missing = oTx.AtomicMatchTxInfo == nil || oTx.AtomicMatchTxInfo.BuyOffer == nil || oTx.AtomicMatchTxInfo.SellOffer == nil; !missing {
+			txInfo := types.SetAtomicMatchTxWitness(oTx.AtomicMatchTxInfo)
+			elements = header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+			for _, offer := range []types.OfferTxConstraints{txInfo.BuyOffer, txInfo.SellOffer} {
+				elements = append(elements,
+					r.pack(offer.Type, offer.OfferId, offer.AccountIndex, offer.NftIndex),
+					r.pack(offer.AssetId, offer.AssetAmount, offer.ListedAt, offer.ExpiredAt),
+					r.value(offer.Sig.R.X),
+					r.value(offer.Sig.R.Y),
+					r.value(offer.Sig.S),
+				)
+			}
+		}
+	case types.TxTypeCancelOffer:
+		if missing = oTx.CancelOfferTxInfo == nil; !missing {
+			txInfo := types.SetCancelOfferTxWitness(oTx.CancelOfferTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.OfferId),
+			)
+		}
+	case types.TxTypeWithdrawNft:
+		if missing = oTx.WithdrawNftTxInfo == nil; !missing {
+			txInfo := types.SetWithdrawNftTxWitness(oTx.WithdrawNftTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.NftIndex),
+				r.value(txInfo.ToAddress),
+			)
+		}
+	case types.TxTypeChangePubKey:
+		if missing = oTx.ChangePubKeyTxInfo == nil || oTx.ChangePubKeyTxInfo.PubKey == nil; !missing {
+			txInfo := types.SetChangePubKeyTxWitness(oTx.ChangePubKeyTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.PubKey.A.X),
+				r.value(txInfo.PubKey.A.Y),
+			)
+		}
+	case types.TxTypeBatchTransfer:
+		if missing = oTx.BatchTransferTxInfo == nil; !missing {
+			txInfo := types.SetBatchTransferTxWitness(oTx.BatchTransferTxInfo)
+			elements = header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+			for i := range txInfo.ToAccountIndexes {
+				elements = append(elements,
+					r.pack(txInfo.ToAccountIndexes[i], txInfo.AssetId, txInfo.AssetAmounts[i]),
+					r.value(txInfo.ToAccountNameHashes[i]),
+				)
+			}
+			elements = append(elements, r.value(txInfo.CallDataHash))
+		}
+	case types.TxTypeSwap:
+		if missing = oTx.SwapTxInfo == nil; !missing {
+			txInfo := types.SetSwapTxWitness(oTx.SwapTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId),
+				r.value(txInfo.AssetAAmount),
+				r.value(txInfo.AssetBMinAmount),
+			)
+		}
+	case types.TxTypeAddLiquidity:
+		if missing = oTx.AddLiquidityTxInfo == nil; !missing {
+			txInfo := types.SetAddLiquidityTxWitness(oTx.AddLiquidityTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId),
+				r.value(txInfo.AssetAAmount),
+				r.value(txInfo.AssetBAmount),
+				r.value(txInfo.LpMinAmount),
+			)
+		}
+	case types.TxTypeRemoveLiquidity:
+		if missing = oTx.RemoveLiquidityTxInfo == nil; !missing {
+			txInfo := types.SetRemoveLiquidityTxWitness(oTx.RemoveLiquidityTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId),
+				r.value(txInfo.LpAmount),
+				r.value(txInfo.AssetAMinAmount),
+				r.value(txInfo.AssetBMinAmount),
+			)
+		}
+	case types.TxTypeBurnNft:
+		if missing = oTx.BurnNftTxInfo == nil; !missing {
+			txInfo := types.SetBurnNftTxWitness(oTx.BurnNftTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.value(txInfo.NftIndex),
+			)
+		}
+	case types.TxTypeUpdateCollection:
+		if missing = oTx.UpdateCollectionTxInfo == nil; !missing {
+			txInfo := types.SetUpdateCollectionTxWitness(oTx.UpdateCollectionTxInfo)
+			elements = append(header(txInfo.AccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.CollectionId, txInfo.CreatorTreasuryRate),
+				r.value(txInfo.MetadataHash),
+			)
+		}
+	case types.TxTypeTransferCollection:
+		if missing = oTx.TransferCollectionTxInfo == nil; !missing {
+			txInfo := types.SetTransferCollectionTxWitness(oTx.TransferCollectionTxInfo)
+			elements = append(header(txInfo.FromAccountIndex, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount),
+				r.pack(txInfo.ToAccountIndex, txInfo.CollectionId),
+				r.value(txInfo.ToAccountNameHash),
+			)
+		}
+	default:
+		log.Println("[ComputeTxHash] tx type is not signed:", oTx.TxType)
+		return nil, fmt.Errorf("[ComputeTxHash] tx type %d is not signed", oTx.TxType)
+	}
+	if missing {
+		log.Println("[ComputeTxHash] tx info is missing")
+		return nil, errors.New("[ComputeTxHash] tx info is missing")
+	}
+	if r.err != nil {
+		log.Println("[ComputeTxHash] invalid tx info:", r.err)
+		return nil, r.err
+	}
+	return HashElements(mimc.NewMiMC(), elements...), nil
+}
+
+/*
+	ComputeOfferHash: native counterpart of types.ComputeHashFromOfferTx, offers are signed for the chain of the block
+*/
+func ComputeOfferHash(offer *types.OfferTx, chainId int64) (hashVal *big.Int, err error) {
+	if offer == nil {
+		log.Println("[ComputeOfferHash] invalid offer")
+		return nil, errors.New("[ComputeOfferHash] invalid offer")
+	}
+	var r witnessReader
+	witness := types.SetOfferTxWitness(offer)
+	elements := []*big.Int{
+		r.pack(witness.Type, witness.OfferId, witness.AccountIndex, witness.NftIndex),
+		r.pack(witness.AssetId, witness.AssetAmount, witness.ListedAt, witness.ExpiredAt),
+		r.pack(witness.TreasuryRate, chainId),
+	}
+	if r.err != nil {
+		log.Println("[ComputeOfferHash] invalid offer:", r.err)
+		return nil, r.err
+	}
+	return HashElements(mimc.NewMiMC(), elements...), nil
+}
+
+/*
+	witnessReader: reads witness values as field elements, the first invalid value is kept
+	and the following reads return 0
+*/
+type witnessReader struct {
+	err error
+}
+
+func (r *witnessReader) value(x Variable) *big.Int {
+	if r.err != nil {
+		return big.NewInt(0)
+	}
+	v, err := types.ToFieldElement(x)
+	if err != nil {
+		r.err = err
+		return big.NewInt(0)
+	}
+	return v
+}
+
+/*
+	pack: same as types.PackInt64Variables
+*/
+func (r *witnessReader) pack(inputs ...Variable) *big.Int {
+	res := r.value(inputs[0])
+	for _, input := range inputs[1:] {
+		res.Lsh(res, 64)
+		res.Add(res, r.value(input))
+	}
+	return res
+}
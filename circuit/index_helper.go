@@ -17,17 +17,74 @@
 
 package circuit
 
-func AccountIndexToMerkleHelper(api API, accountIndex Variable) (merkleHelpers []Variable) {
-	merkleHelpers = api.ToBinary(accountIndex, AccountMerkleLevels)
+import (
+	"fmt"
+	"log"
+)
+
+func AccountIndexToMerkleHelper(api API, accountIndex Variable, config CircuitConfig) (merkleHelpers []Variable) {
+	merkleHelpers = api.ToBinary(accountIndex, config.AccountMerkleLevels)
 	return merkleHelpers
 }
 
-func AssetIdToMerkleHelper(api API, assetId Variable) (merkleHelpers []Variable) {
-	merkleHelpers = api.ToBinary(assetId, AssetMerkleLevels)
+func AssetIdToMerkleHelper(api API, assetId Variable, config CircuitConfig) (merkleHelpers []Variable) {
+	merkleHelpers = api.ToBinary(assetId, config.AssetMerkleLevels)
 	return merkleHelpers
 }
 
-func NftIndexToMerkleHelper(api API, nftIndex Variable) (merkleHelpers []Variable) {
-	merkleHelpers = api.ToBinary(nftIndex, NftMerkleLevels)
+func NftIndexToMerkleHelper(api API, nftIndex Variable, config CircuitConfig) (merkleHelpers []Variable) {
+	merkleHelpers = api.ToBinary(nftIndex, config.NftMerkleLevels)
 	return merkleHelpers
 }
+
+func PairIndexToMerkleHelper(api API, pairIndex Variable, config CircuitConfig) (merkleHelpers []Variable) {
+	merkleHelpers = api.ToBinary(pairIndex, config.LiquidityMerkleLevels)
+	return merkleHelpers
+}
+
+func CollectionIdToMerkleHelper(api API, collectionId Variable, config CircuitConfig) (merkleHelpers []Variable) {
+	merkleHelpers = api.ToBinary(collectionId, config.CollectionMerkleLevels)
+	return merkleHelpers
+}
+
+/*
+	SetMerkleProofWitness: witness of a merkle proof, the proof should have a node per level of the tree
+*/
+func SetMerkleProofWitness(proof [][]byte, levels int) (witness []Variable, err error) {
+	if len(proof) != levels {
+		log.Println("[SetMerkleProofWitness] invalid merkle proof size")
+		return nil, fmt.Errorf("[SetMerkleProofWitness] merkle proof has %d nodes, expected %d", len(proof), levels)
+	}
+	witness = make([]Variable, levels)
+	for i := 0; i < levels; i++ {
+		witness[i] = proof[i]
+	}
+	return witness, nil
+}
+
+func zeroMerkleProof(levels int) (proof []Variable) {
+	proof = make([]Variable, levels)
+	for i := 0; i < levels; i++ {
+		proof[i] = 0
+	}
+	return proof
+}
+
+func emptyMerkleProof(levels int) (proof [][]byte) {
+	proof = make([][]byte, levels)
+	for i := 0; i < levels; i++ {
+		proof[i] = make([]byte, 32)
+	}
+	return proof
+}
+
+/*
+	checkMerkleProofLevels: the proofs of a circuit are slices, they should be sized by its config
+*/
+func checkMerkleProofLevels(name string, proof []Variable, levels int) error {
+	if len(proof) != levels {
+		log.Println("[checkMerkleProofLevels] invalid merkle proof size of", name)
+		return fmt.Errorf("[checkMerkleProofLevels] %s has %d nodes, expected %d", name, len(proof), levels)
+	}
+	return nil
+}
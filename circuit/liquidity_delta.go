@@ -0,0 +1,239 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package circuit
+
+import (
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	LiquidityDeltaConstraints: the pair leaf after the tx, the pair index never changes
+*/
+type LiquidityDeltaConstraints struct {
+	AssetAId             Variable
+	AssetA               Variable
+	AssetBId             Variable
+	AssetB               Variable
+	LpAssetId            Variable
+	LpAmount             Variable
+	FeeRate              Variable
+	TreasuryAccountIndex Variable
+	TreasuryRate         Variable
+}
+
+func EmptyLiquidityDeltaConstraints(liquidity LiquidityConstraints) LiquidityDeltaConstraints {
+	return LiquidityDeltaConstraints{
+		AssetAId:             liquidity.AssetAId,
+		AssetA:               liquidity.AssetA,
+		AssetBId:             liquidity.AssetBId,
+		AssetB:               liquidity.AssetB,
+		LpAssetId:            liquidity.LpAssetId,
+		LpAmount:             liquidity.LpAmount,
+		FeeRate:              liquidity.FeeRate,
+		TreasuryAccountIndex: liquidity.TreasuryAccountIndex,
+		TreasuryRate:         liquidity.TreasuryRate,
+	}
+}
+
+func UpdateLiquidity(
+	liquidity LiquidityConstraints,
+	liquidityDelta LiquidityDeltaConstraints,
+) (liquidityAfter LiquidityConstraints) {
+	liquidityAfter = liquidity
+	liquidityAfter.AssetAId = liquidityDelta.AssetAId
+	liquidityAfter.AssetA = liquidityDelta.AssetA
+	liquidityAfter.AssetBId = liquidityDelta.AssetBId
+	liquidityAfter.AssetB = liquidityDelta.AssetB
+	liquidityAfter.LpAssetId = liquidityDelta.LpAssetId
+	liquidityAfter.LpAmount = liquidityDelta.LpAmount
+	liquidityAfter.FeeRate = liquidityDelta.FeeRate
+	liquidityAfter.TreasuryAccountIndex = liquidityDelta.TreasuryAccountIndex
+	liquidityAfter.TreasuryRate = liquidityDelta.TreasuryRate
+	return liquidityAfter
+}
+
+func GetLiquidityDeltaFromCreatePair(
+	txInfo CreatePairTxConstraints,
+) (liquidityDelta LiquidityDeltaConstraints) {
+	liquidityDelta = LiquidityDeltaConstraints{
+		AssetAId:             txInfo.AssetAId,
+		AssetA:               types.ZeroInt,
+		AssetBId:             txInfo.AssetBId,
+		AssetB:               types.ZeroInt,
+		LpAssetId:            txInfo.LpAssetId,
+		LpAmount:             types.ZeroInt,
+		FeeRate:              txInfo.FeeRate,
+		TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
+		TreasuryRate:         txInfo.TreasuryRate,
+	}
+	return liquidityDelta
+}
+
+/*
+	GetAssetDeltasAndLiquidityDeltaFromSwap: the asset sold goes to the pool
+	less the treasury amount, the treasury account takes the second slot
+*/
+func GetAssetDeltasAndLiquidityDeltaFromSwap(
+	api API,
+	txInfo SwapTxConstraints,
+	liquidityBefore LiquidityConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	liquidityDelta LiquidityDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetAAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		// asset B
+		{
+			BalanceDelta:             txInfo.AssetBAmountDelta,
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+	// treasury account
+	deltas[1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             txInfo.TreasuryAmount,
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	deltas[2] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// gas
+	deltas[3] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		EmptyAccountAssetDeltaConstraints(),
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+
+	isAToB := api.IsZero(api.Sub(txInfo.AssetAId, liquidityBefore.AssetAId))
+	poolDeltaIn := api.Sub(txInfo.AssetAAmount, txInfo.TreasuryAmount)
+	poolDeltaA := api.Select(isAToB, poolDeltaIn, api.Neg(txInfo.AssetBAmountDelta))
+	poolDeltaB := api.Select(isAToB, api.Neg(txInfo.AssetBAmountDelta), poolDeltaIn)
+	liquidityDelta = EmptyLiquidityDeltaConstraints(liquidityBefore)
+	liquidityDelta.AssetA = api.Add(liquidityBefore.AssetA, poolDeltaA)
+	liquidityDelta.AssetB = api.Add(liquidityBefore.AssetB, poolDeltaB)
+
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, liquidityDelta, gasDeltas
+}
+
+func GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(
+	api API,
+	txInfo AddLiquidityTxConstraints,
+	liquidityBefore LiquidityConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	liquidityDelta LiquidityDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetAAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		// asset B
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetBAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+	for i := 1; i < NbAccountsPerTx-1; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	// lp shares and gas
+	deltas[3] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             txInfo.LpAmount,
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+
+	liquidityDelta = EmptyLiquidityDeltaConstraints(liquidityBefore)
+	liquidityDelta.AssetA = api.Add(liquidityBefore.AssetA, txInfo.AssetAAmount)
+	liquidityDelta.AssetB = api.Add(liquidityBefore.AssetB, txInfo.AssetBAmount)
+	liquidityDelta.LpAmount = api.Add(liquidityBefore.LpAmount, txInfo.LpAmount)
+	// the first deposit locks MinimumLiquidity lp shares in the pair
+	lockedAmount := api.Select(api.IsZero(liquidityBefore.LpAmount), types.MinimumLiquidity, 0)
+	liquidityDelta.LpAmount = api.Add(liquidityDelta.LpAmount, lockedAmount)
+
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, liquidityDelta, gasDeltas
+}
+
+func GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(
+	api API,
+	txInfo RemoveLiquidityTxConstraints,
+	liquidityBefore LiquidityConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	liquidityDelta LiquidityDeltaConstraints,
+	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             txInfo.AssetAAmountDelta,
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		// asset B
+		{
+			BalanceDelta:             txInfo.AssetBAmountDelta,
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+	for i := 1; i < NbAccountsPerTx-1; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	// lp shares and gas
+	deltas[3] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.LpAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			OfferCanceledOrFinalized: types.ZeroInt,
+		},
+	}
+
+	liquidityDelta = EmptyLiquidityDeltaConstraints(liquidityBefore)
+	liquidityDelta.AssetA = api.Sub(liquidityBefore.AssetA, txInfo.AssetAAmountDelta)
+	liquidityDelta.AssetB = api.Sub(liquidityBefore.AssetB, txInfo.AssetBAmountDelta)
+	liquidityDelta.LpAmount = api.Sub(liquidityBefore.LpAmount, txInfo.LpAmount)
+
+	gasDeltas = GetGasDeltas(txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount)
+	return deltas, liquidityDelta, gasDeltas
+}
@@ -19,45 +19,40 @@ package solidity
 
 import (
 	"fmt"
-	"os"
 	"testing"
 
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
-
-	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/prover"
 )
 
 func TestCompileCircuit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compilation of the block circuit in short mode")
+	}
 	differentBlockSizes := []int{1, 10}
 	gasAssetIds := []int64{0, 1}
 	gasAccountIndex := int64(1)
 	for i := 0; i < len(differentBlockSizes); i++ {
-		var blockConstraints circuit.BlockConstraints
-		blockConstraints.TxsCount = differentBlockSizes[i]
-		blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
-		for i := 0; i < blockConstraints.TxsCount; i++ {
-			blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
-		}
-		blockConstraints.GasAssetIds = gasAssetIds
-		blockConstraints.GasAccountIndex = gasAccountIndex
-		blockConstraints.Gas = circuit.GetZeroGasConstraints(gasAssetIds)
-		oR1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
+		blockCircuit, err := prover.CompileBlockCircuit(differentBlockSizes[i], gasAssetIds, gasAccountIndex, types.MiMCHashType, types.MainnetConfig)
 		if err != nil {
 			panic(err)
 		}
-		fmt.Printf("Number of constraints: %d\n", oR1cs.GetNbConstraints())
+		fmt.Printf("Number of constraints: %d\n", blockCircuit.Ccs.GetNbConstraints())
 	}
 }
 
 func TestExportSol(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compilation of the block circuit in short mode")
+	}
 	differentBlockSizes := []int{1, 10}
 	exportSol(differentBlockSizes)
 }
 
 func TestExportSolSmall(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compilation of the block circuit in short mode")
+	}
 	differentBlockSizes := []int{1}
 	exportSol(differentBlockSizes)
 }
@@ -66,54 +61,56 @@ func exportSol(differentBlockSizes []int) {
 	gasAssetIds := []int64{0, 1}
 	gasAccountIndex := int64(1)
 	for i := 0; i < len(differentBlockSizes); i++ {
-		var blockConstraints circuit.BlockConstraints
-		blockConstraints.TxsCount = differentBlockSizes[i]
-		blockConstraints.Txs = make([]circuit.TxConstraints, blockConstraints.TxsCount)
-		for i := 0; i < blockConstraints.TxsCount; i++ {
-			blockConstraints.Txs[i] = circuit.GetZeroTxConstraint()
+		blockCircuit, err := prover.CompileBlockCircuit(differentBlockSizes[i], gasAssetIds, gasAccountIndex, types.MiMCHashType, types.MainnetConfig)
+		if err != nil {
+			panic(err)
 		}
-		blockConstraints.GasAssetIds = gasAssetIds
-		blockConstraints.GasAccountIndex = gasAccountIndex
-		blockConstraints.Gas = circuit.GetZeroGasConstraints(gasAssetIds)
-		oR1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
+		pk, vk, err := prover.Setup(blockCircuit)
 		if err != nil {
 			panic(err)
 		}
+		err = prover.SaveKeys(pk, vk,
+			"zkbnb"+fmt.Sprint(differentBlockSizes[i])+".pk",
+			"zkbnb"+fmt.Sprint(differentBlockSizes[i])+".vk")
+		if err != nil {
+			panic(err)
+		}
+		err = prover.ExportSolidity(vk, "ZkBNBVerifier"+fmt.Sprint(differentBlockSizes[i])+".sol")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
 
-		pk, vk, err := groth16.Setup(oR1cs)
+func TestExportPlonkKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compilation of the block circuit in short mode")
+	}
+	differentBlockSizes := []int{1}
+	exportPlonkKeys(differentBlockSizes)
+}
+
+func exportPlonkKeys(differentBlockSizes []int) {
+	gasAssetIds := []int64{0, 1}
+	gasAccountIndex := int64(1)
+	for i := 0; i < len(differentBlockSizes); i++ {
+		blockCircuit, err := prover.CompileBlockCircuitPlonk(differentBlockSizes[i], gasAssetIds, gasAccountIndex, types.MiMCHashType, types.MainnetConfig)
 		if err != nil {
 			panic(err)
 		}
-		{
-			f, err := os.Create("zkbnb" + fmt.Sprint(differentBlockSizes[i]) + ".vk")
-			if err != nil {
-				panic(err)
-			}
-			_, err = vk.WriteRawTo(f)
-			if err != nil {
-				panic(err)
-			}
+		srs, err := prover.NewKZGSRS(blockCircuit)
+		if err != nil {
+			panic(err)
 		}
-		{
-			f, err := os.Create("zkbnb" + fmt.Sprint(differentBlockSizes[i]) + ".pk")
-			if err != nil {
-				panic(err)
-			}
-			_, err = pk.WriteRawTo(f)
-			if err != nil {
-				panic(err)
-			}
+		_, vk, err := prover.SetupPlonk(blockCircuit, srs)
+		if err != nil {
+			panic(err)
 		}
-
-		{
-			f, err := os.Create("ZkBNBVerifier" + fmt.Sprint(differentBlockSizes[i]) + ".sol")
-			if err != nil {
-				panic(err)
-			}
-			err = vk.ExportSolidity(f)
-			if err != nil {
-				panic(err)
-			}
+		err = prover.SavePlonkKeys(vk, srs,
+			"zkbnb"+fmt.Sprint(differentBlockSizes[i])+".vk_plonk",
+			"zkbnb"+fmt.Sprint(differentBlockSizes[i])+".srs_plonk")
+		if err != nil {
+			panic(err)
 		}
 	}
 }
@@ -25,19 +25,28 @@ type Tx struct {
 	// tx type
 	TxType uint8
 	// different transactions
-	RegisterZnsTxInfo      *RegisterZnsTx
-	DepositTxInfo          *DepositTx
-	DepositNftTxInfo       *DepositNftTx
-	TransferTxInfo         *TransferTx
-	CreateCollectionTxInfo *CreateCollectionTx
-	MintNftTxInfo          *MintNftTx
-	TransferNftTxInfo      *TransferNftTx
-	AtomicMatchTxInfo      *AtomicMatchTx
-	CancelOfferTxInfo      *CancelOfferTx
-	WithdrawTxInfo         *WithdrawTx
-	WithdrawNftTxInfo      *WithdrawNftTx
-	FullExitTxInfo         *FullExitTx
-	FullExitNftTxInfo      *FullExitNftTx
+	RegisterZnsTxInfo        *RegisterZnsTx
+	DepositTxInfo            *DepositTx
+	DepositNftTxInfo         *DepositNftTx
+	TransferTxInfo           *TransferTx
+	CreateCollectionTxInfo   *CreateCollectionTx
+	MintNftTxInfo            *MintNftTx
+	TransferNftTxInfo        *TransferNftTx
+	AtomicMatchTxInfo        *AtomicMatchTx
+	CancelOfferTxInfo        *CancelOfferTx
+	WithdrawTxInfo           *WithdrawTx
+	WithdrawNftTxInfo        *WithdrawNftTx
+	FullExitTxInfo           *FullExitTx
+	FullExitNftTxInfo        *FullExitNftTx
+	ChangePubKeyTxInfo       *ChangePubKeyTx
+	BatchTransferTxInfo      *BatchTransferTx
+	CreatePairTxInfo         *CreatePairTx
+	SwapTxInfo               *SwapTx
+	AddLiquidityTxInfo       *AddLiquidityTx
+	RemoveLiquidityTxInfo    *RemoveLiquidityTx
+	BurnNftTxInfo            *BurnNftTx
+	UpdateCollectionTxInfo   *UpdateCollectionTx
+	TransferCollectionTxInfo *TransferCollectionTx
 	// nonce
 	Nonce int64
 	// expired at
@@ -46,20 +55,32 @@ type Tx struct {
 	Signature *Signature
 	// account root before
 	AccountRootBefore []byte
-	// account before info
-	AccountsInfoBefore [NbAccountsPerTx]*types.Account
+	// account before info, NbAccountsPerTx accounts of the config
+	AccountsInfoBefore []*types.Account
+	// liquidity root before
+	LiquidityRootBefore []byte
+	// liquidity before
+	LiquidityBefore *types.Liquidity
 	// nft root before
 	NftRootBefore []byte
 	// nft before
 	NftBefore *types.Nft
+	// collection root before
+	CollectionRootBefore []byte
+	// collection before
+	CollectionBefore *types.Collection
 	// state root before
 	StateRootBefore []byte
 	// before account asset merkle proof
-	MerkleProofsAccountAssetsBefore [NbAccountsPerTx][NbAccountAssetsPerAccount][AssetMerkleLevels][]byte
+	MerkleProofsAccountAssetsBefore [][][][]byte
 	// before account merkle proof
-	MerkleProofsAccountBefore [NbAccountsPerTx][AccountMerkleLevels][]byte
+	MerkleProofsAccountBefore [][][]byte
+	// before liquidity tree merkle proof
+	MerkleProofsLiquidityBefore [][]byte
 	// before nft tree merkle proof
-	MerkleProofsNftBefore [NftMerkleLevels][]byte
+	MerkleProofsNftBefore [][]byte
+	// before collection tree merkle proof
+	MerkleProofsCollectionBefore [][]byte
 	// state root after
 	StateRootAfter []byte
 }
@@ -19,6 +19,7 @@ package circuit
 
 import (
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/consensys/gnark/std/hash/mimc"
@@ -30,19 +31,28 @@ type TxConstraints struct {
 	// tx type
 	TxType Variable
 	// different transactions
-	RegisterZnsTxInfo      RegisterZnsTxConstraints
-	DepositTxInfo          DepositTxConstraints
-	DepositNftTxInfo       DepositNftTxConstraints
-	TransferTxInfo         TransferTxConstraints
-	CreateCollectionTxInfo CreateCollectionTxConstraints
-	MintNftTxInfo          MintNftTxConstraints
-	TransferNftTxInfo      TransferNftTxConstraints
-	AtomicMatchTxInfo      AtomicMatchTxConstraints
-	CancelOfferTxInfo      CancelOfferTxConstraints
-	WithdrawTxInfo         WithdrawTxConstraints
-	WithdrawNftTxInfo      WithdrawNftTxConstraints
-	FullExitTxInfo         FullExitTxConstraints
-	FullExitNftTxInfo      FullExitNftTxConstraints
+	RegisterZnsTxInfo        RegisterZnsTxConstraints
+	DepositTxInfo            DepositTxConstraints
+	DepositNftTxInfo         DepositNftTxConstraints
+	TransferTxInfo           TransferTxConstraints
+	CreateCollectionTxInfo   CreateCollectionTxConstraints
+	MintNftTxInfo            MintNftTxConstraints
+	TransferNftTxInfo        TransferNftTxConstraints
+	AtomicMatchTxInfo        AtomicMatchTxConstraints
+	CancelOfferTxInfo        CancelOfferTxConstraints
+	WithdrawTxInfo           WithdrawTxConstraints
+	WithdrawNftTxInfo        WithdrawNftTxConstraints
+	FullExitTxInfo           FullExitTxConstraints
+	FullExitNftTxInfo        FullExitNftTxConstraints
+	ChangePubKeyTxInfo       ChangePubKeyTxConstraints
+	BatchTransferTxInfo      BatchTransferTxConstraints
+	CreatePairTxInfo         CreatePairTxConstraints
+	SwapTxInfo               SwapTxConstraints
+	AddLiquidityTxInfo       AddLiquidityTxConstraints
+	RemoveLiquidityTxInfo    RemoveLiquidityTxConstraints
+	BurnNftTxInfo            BurnNftTxConstraints
+	UpdateCollectionTxInfo   UpdateCollectionTxConstraints
+	TransferCollectionTxInfo TransferCollectionTxConstraints
 	// nonce
 	Nonce Variable
 	// expired at
@@ -51,22 +61,39 @@ type TxConstraints struct {
 	Signature SignatureConstraints
 	// account root before
 	AccountRootBefore Variable
-	// account before info, size is 5
-	AccountsInfoBefore [NbAccountsPerTx]types.AccountConstraints
+	// account before info, NbAccountsPerTx accounts of the config
+	AccountsInfoBefore []types.AccountConstraints
+	// liquidity root before
+	LiquidityRootBefore Variable
+	// liquidity before
+	LiquidityBefore types.LiquidityConstraints
 	// nft root before
 	NftRootBefore Variable
 	// nft before
 	NftBefore types.NftConstraints
+	// collection root before
+	CollectionRootBefore Variable
+	// collection before
+	CollectionBefore types.CollectionConstraints
 	// state root before
 	StateRootBefore Variable
 	// before account asset merkle proof
-	MerkleProofsAccountAssetsBefore [NbAccountsPerTx][NbAccountAssetsPerAccount][AssetMerkleLevels]Variable
+	MerkleProofsAccountAssetsBefore [][][]Variable
+	// before liquidity tree merkle proof
+	MerkleProofsLiquidityBefore []Variable
 	// before nft tree merkle proof
-	MerkleProofsNftBefore [NftMerkleLevels]Variable
+	MerkleProofsNftBefore []Variable
+	// before collection tree merkle proof
+	MerkleProofsCollectionBefore []Variable
 	// before account merkle proof
-	MerkleProofsAccountBefore [NbAccountsPerTx][AccountMerkleLevels]Variable
+	MerkleProofsAccountBefore [][]Variable
 	// state root after
 	StateRootAfter Variable
+	// depth of the trees and layout of the tx the slots are sized for
+	Config CircuitConfig
+	// chain id and creation time of the block, only read by Define when the tx is verified alone
+	ChainId        int64
+	BlockCreatedAt int64
 }
 
 func (circuit TxConstraints) Define(api API) error {
@@ -76,7 +103,7 @@ func (circuit TxConstraints) Define(api API) error {
 		return err
 	}
 
-	_, _, _, _, err = VerifyTransaction(api, circuit, hFunc, 1633400952228, []int64{0})
+	_, _, _, _, err = VerifyTransaction(api, circuit, hFunc, types.MiMCHashType, circuit.Config, circuit.ChainId, circuit.BlockCreatedAt, []int64{0})
 	if err != nil {
 		return err
 	}
@@ -87,10 +114,29 @@ func VerifyTransaction(
 	api API,
 	tx TxConstraints,
 	hFunc MiMC,
+	hashType types.HashType,
+	config CircuitConfig,
+	chainId Variable,
 	blockCreatedAt Variable,
 	gasAssetIds []int64,
-) (isOnChainOp Variable, pubData [types.PubDataSizePerTx]Variable, roots [types.NbRoots]Variable,
-	gasDeltas [NbGasAssetsPerTx]GasDeltaConstraints, err error) {
+) (isOnChainOp Variable, pubData []Variable, roots [types.NbRoots]Variable,
+	gasDeltas []GasDeltaConstraints, err error) {
+	if err = checkTxLayout(tx, config); err != nil {
+		return nil, pubData, roots, gasDeltas, err
+	}
+	if err = checkTxMerkleProofLevels(tx, config); err != nil {
+		return nil, pubData, roots, gasDeltas, err
+	}
+	// hash of the state trees
+	treeHFunc, err := types.NewHash(api, hashType)
+	if err != nil {
+		return nil, pubData, roots, gasDeltas, err
+	}
+	emptyAssetRoot, err := types.EmptyAssetRootOf(hashType, config.AssetMerkleLevels)
+	if err != nil {
+		return nil, pubData, roots, gasDeltas, err
+	}
+
 	// compute tx type
 	isEmptyTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeEmptyTx))
 	isRegisterZnsTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeRegisterZns))
@@ -106,6 +152,18 @@ func VerifyTransaction(
 	isWithdrawNftTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeWithdrawNft))
 	isFullExitTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeFullExit))
 	isFullExitNftTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeFullExitNft))
+	isChangePubKeyTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeChangePubKey))
+	// change pub key is a layer 2 tx unless it is sent on layer 1 as a priority op
+	isChangePubKeyPriorityOp := api.Mul(isChangePubKeyTx, tx.ChangePubKeyTxInfo.IsPriorityOp)
+	isChangePubKeyLayer2Tx := api.Sub(isChangePubKeyTx, isChangePubKeyPriorityOp)
+	isBatchTransferTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeBatchTransfer))
+	isCreatePairTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeCreatePair))
+	isSwapTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeSwap))
+	isAddLiquidityTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeAddLiquidity))
+	isRemoveLiquidityTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeRemoveLiquidity))
+	isBurnNftTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeBurnNft))
+	isUpdateCollectionTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeUpdateCollection))
+	isTransferCollectionTx := api.IsZero(api.Sub(tx.TxType, types.TxTypeTransferCollection))
 
 	// verify nonce
 	isLayer2Tx := api.Add(
@@ -117,6 +175,14 @@ func VerifyTransaction(
 		isAtomicMatchTx,
 		isCancelOfferTx,
 		isWithdrawNftTx,
+		isChangePubKeyLayer2Tx,
+		isBatchTransferTx,
+		isSwapTx,
+		isAddLiquidityTx,
+		isRemoveLiquidityTx,
+		isBurnNftTx,
+		isUpdateCollectionTx,
+		isTransferCollectionTx,
 	)
 
 	isOnChainOp = api.Add(
@@ -127,32 +193,58 @@ func VerifyTransaction(
 		isWithdrawNftTx,
 		isFullExitTx,
 		isFullExitNftTx,
+		isChangePubKeyPriorityOp,
+		isCreatePairTx,
 	)
 
 	// get hash value from tx based on tx type
 	// transfer tx
-	hashVal := types.ComputeHashFromTransferTx(api, tx.TransferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal := types.ComputeHashFromTransferTx(api, tx.TransferTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	// withdraw tx
-	hashValCheck := types.ComputeHashFromWithdrawTx(api, tx.WithdrawTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck := types.ComputeHashFromWithdrawTx(api, tx.WithdrawTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isWithdrawTx, hashValCheck, hashVal)
 	// createCollection tx
-	hashValCheck = types.ComputeHashFromCreateCollectionTx(api, tx.CreateCollectionTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromCreateCollectionTx(api, tx.CreateCollectionTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isCreateCollectionTx, hashValCheck, hashVal)
 	// mint nft tx
-	hashValCheck = types.ComputeHashFromMintNftTx(api, tx.MintNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromMintNftTx(api, tx.MintNftTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isMintNftTx, hashValCheck, hashVal)
 	// transfer nft tx
-	hashValCheck = types.ComputeHashFromTransferNftTx(api, tx.TransferNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromTransferNftTx(api, tx.TransferNftTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isTransferNftTx, hashValCheck, hashVal)
 	// set nft price tx
-	hashValCheck = types.ComputeHashFromAtomicMatchTx(api, tx.AtomicMatchTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromAtomicMatchTx(api, tx.AtomicMatchTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isAtomicMatchTx, hashValCheck, hashVal)
 	// buy nft tx
-	hashValCheck = types.ComputeHashFromCancelOfferTx(api, tx.CancelOfferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromCancelOfferTx(api, tx.CancelOfferTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isCancelOfferTx, hashValCheck, hashVal)
 	// withdraw nft tx
-	hashValCheck = types.ComputeHashFromWithdrawNftTx(api, tx.WithdrawNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashValCheck = types.ComputeHashFromWithdrawNftTx(api, tx.WithdrawNftTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
 	hashVal = api.Select(isWithdrawNftTx, hashValCheck, hashVal)
+	// change pub key tx
+	hashValCheck = types.ComputeHashFromChangePubKeyTx(api, tx.ChangePubKeyTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isChangePubKeyTx, hashValCheck, hashVal)
+	// batch transfer tx
+	hashValCheck = types.ComputeHashFromBatchTransferTx(api, tx.BatchTransferTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isBatchTransferTx, hashValCheck, hashVal)
+	// swap tx
+	hashValCheck = types.ComputeHashFromSwapTx(api, tx.SwapTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isSwapTx, hashValCheck, hashVal)
+	// add liquidity tx
+	hashValCheck = types.ComputeHashFromAddLiquidityTx(api, tx.AddLiquidityTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isAddLiquidityTx, hashValCheck, hashVal)
+	// remove liquidity tx
+	hashValCheck = types.ComputeHashFromRemoveLiquidityTx(api, tx.RemoveLiquidityTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isRemoveLiquidityTx, hashValCheck, hashVal)
+	// burn nft tx
+	hashValCheck = types.ComputeHashFromBurnNftTx(api, tx.BurnNftTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isBurnNftTx, hashValCheck, hashVal)
+	// update collection tx
+	hashValCheck = types.ComputeHashFromUpdateCollectionTx(api, tx.UpdateCollectionTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isUpdateCollectionTx, hashValCheck, hashVal)
+	// transfer collection tx
+	hashValCheck = types.ComputeHashFromTransferCollectionTx(api, tx.TransferCollectionTxInfo, chainId, tx.Nonce, tx.ExpiredAt, hFunc)
+	hashVal = api.Select(isTransferCollectionTx, hashValCheck, hashVal)
 	hFunc.Reset()
 
 	types.IsVariableEqual(api, isLayer2Tx, tx.AccountsInfoBefore[0].Nonce, tx.Nonce)
@@ -171,28 +263,29 @@ func VerifyTransaction(
 	}
 
 	// verify transactions
-	for i := 0; i < types.PubDataSizePerTx; i++ {
+	pubData = make([]Variable, config.PubDataSizePerTx)
+	for i := 0; i < config.PubDataSizePerTx; i++ {
 		pubData[i] = 0
 	}
-	pubDataCheck := types.VerifyRegisterZNSTx(api, isRegisterZnsTx, tx.RegisterZnsTxInfo, tx.AccountsInfoBefore)
+	pubDataCheck := types.VerifyRegisterZNSTx(api, isRegisterZnsTx, tx.RegisterZnsTxInfo, tx.AccountsInfoBefore, emptyAssetRoot)
 	pubData = SelectPubData(api, isRegisterZnsTx, pubDataCheck, pubData)
-	pubDataCheck = types.VerifyDepositTx(api, isDepositTx, tx.DepositTxInfo, tx.AccountsInfoBefore)
+	pubDataCheck = types.VerifyDepositTx(api, isDepositTx, tx.DepositTxInfo, tx.AccountsInfoBefore, config.FirstLpAssetId())
 	pubData = SelectPubData(api, isDepositTx, pubDataCheck, pubData)
 	pubDataCheck = types.VerifyDepositNftTx(api, isDepositNftTx, tx.DepositNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
 	pubData = SelectPubData(api, isDepositNftTx, pubDataCheck, pubData)
 	pubDataCheck = types.VerifyTransferTx(api, isTransferTx, &tx.TransferTxInfo, tx.AccountsInfoBefore)
 	pubData = SelectPubData(api, isTransferTx, pubDataCheck, pubData)
-	pubDataCheck = types.VerifyCreateCollectionTx(api, isCreateCollectionTx, &tx.CreateCollectionTxInfo, tx.AccountsInfoBefore)
+	pubDataCheck = types.VerifyCreateCollectionTx(api, isCreateCollectionTx, &tx.CreateCollectionTxInfo, tx.AccountsInfoBefore, tx.CollectionBefore, config.LastCollectionId())
 	pubData = SelectPubData(api, isCreateCollectionTx, pubDataCheck, pubData)
-	pubDataCheck = types.VerifyWithdrawTx(api, isWithdrawTx, &tx.WithdrawTxInfo, tx.AccountsInfoBefore)
+	pubDataCheck = types.VerifyWithdrawTx(api, isWithdrawTx, &tx.WithdrawTxInfo, tx.AccountsInfoBefore, config.FirstLpAssetId())
 	pubData = SelectPubData(api, isWithdrawTx, pubDataCheck, pubData)
-	pubDataCheck = types.VerifyMintNftTx(api, isMintNftTx, &tx.MintNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+	pubDataCheck = types.VerifyMintNftTx(api, isMintNftTx, &tx.MintNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore, tx.CollectionBefore)
 	pubData = SelectPubData(api, isMintNftTx, pubDataCheck, pubData)
 	pubDataCheck = types.VerifyTransferNftTx(api, isTransferNftTx, &tx.TransferNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
 	pubData = SelectPubData(api, isTransferNftTx, pubDataCheck, pubData)
 	hFunc.Reset()
 	pubDataCheck, err = types.VerifyAtomicMatchTx(
-		api, isAtomicMatchTx, &tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore, blockCreatedAt,
+		api, isAtomicMatchTx, &tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore, chainId, blockCreatedAt,
 		hFunc,
 	)
 	if err != nil {
@@ -207,19 +300,39 @@ func VerifyTransaction(
 	pubData = SelectPubData(api, isFullExitTx, pubDataCheck, pubData)
 	pubDataCheck = types.VerifyFullExitNftTx(api, isFullExitNftTx, tx.FullExitNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
 	pubData = SelectPubData(api, isFullExitNftTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyChangePubKeyTx(api, isChangePubKeyTx, &tx.ChangePubKeyTxInfo, tx.AccountsInfoBefore)
+	pubData = SelectPubData(api, isChangePubKeyTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyBatchTransferTx(api, isBatchTransferTx, &tx.BatchTransferTxInfo, tx.AccountsInfoBefore)
+	pubData = SelectPubData(api, isBatchTransferTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyCreatePairTx(api, isCreatePairTx, tx.CreatePairTxInfo, tx.LiquidityBefore, config.FirstLpAssetId())
+	pubData = SelectPubData(api, isCreatePairTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifySwapTx(api, isSwapTx, &tx.SwapTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+	pubData = SelectPubData(api, isSwapTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyAddLiquidityTx(api, isAddLiquidityTx, &tx.AddLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+	pubData = SelectPubData(api, isAddLiquidityTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyRemoveLiquidityTx(api, isRemoveLiquidityTx, &tx.RemoveLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+	pubData = SelectPubData(api, isRemoveLiquidityTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyBurnNftTx(api, isBurnNftTx, &tx.BurnNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+	pubData = SelectPubData(api, isBurnNftTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyUpdateCollectionTx(api, isUpdateCollectionTx, &tx.UpdateCollectionTxInfo, tx.AccountsInfoBefore, tx.CollectionBefore)
+	pubData = SelectPubData(api, isUpdateCollectionTx, pubDataCheck, pubData)
+	pubDataCheck = types.VerifyTransferCollectionTx(api, isTransferCollectionTx, &tx.TransferCollectionTxInfo, tx.AccountsInfoBefore, tx.CollectionBefore)
+	pubData = SelectPubData(api, isTransferCollectionTx, pubDataCheck, pubData)
 
 	// verify timestamp
 	types.IsVariableLessOrEqual(api, isLayer2Tx, blockCreatedAt, tx.ExpiredAt)
 
 	// empty delta
 	var (
-		assetDeltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints
-		nftDelta    NftDeltaConstraints
+		assetDeltas     = make([][]AccountAssetDeltaConstraints, config.NbAccountsPerTx)
+		nftDelta        NftDeltaConstraints
+		liquidityDelta  LiquidityDeltaConstraints
+		collectionDelta CollectionDeltaConstraints
 	)
-	for i := 0; i < NbAccountsPerTx; i++ {
-		assetDeltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
-			EmptyAccountAssetDeltaConstraints(),
-			EmptyAccountAssetDeltaConstraints(),
+	for i := 0; i < config.NbAccountsPerTx; i++ {
+		assetDeltas[i] = make([]AccountAssetDeltaConstraints, config.NbAccountAssetsPerAccount)
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			assetDeltas[i][j] = EmptyAccountAssetDeltaConstraints()
 		}
 	}
 	nftDelta = NftDeltaConstraints{
@@ -231,7 +344,10 @@ func VerifyTransaction(
 		CreatorTreasuryRate: tx.NftBefore.CreatorTreasuryRate,
 		CollectionId:        tx.NftBefore.CollectionId,
 	}
-	for i := 0; i < NbGasAssetsPerTx; i++ {
+	liquidityDelta = EmptyLiquidityDeltaConstraints(tx.LiquidityBefore)
+	collectionDelta = EmptyCollectionDeltaConstraints(tx.CollectionBefore)
+	gasDeltas = make([]GasDeltaConstraints, config.NbGasAssetsPerTx)
+	for i := 0; i < config.NbGasAssetsPerTx; i++ {
 		gasDeltas[i] = EmptyGasDeltaConstraints(gasAssetIds[0])
 	}
 
@@ -255,6 +371,8 @@ func VerifyTransaction(
 	assetDeltasCheck, gasDeltasCheck = GetAssetDeltasFromCreateCollection(api, tx.CreateCollectionTxInfo)
 	assetDeltas = SelectAssetDeltas(api, isCreateCollectionTx, assetDeltasCheck, assetDeltas)
 	gasDeltas = SelectGasDeltas(api, isCreateCollectionTx, gasDeltasCheck, gasDeltas)
+	collectionDeltaCheck := GetCollectionDeltaFromCreateCollection(tx.CreateCollectionTxInfo)
+	collectionDelta = SelectCollectionDeltas(api, isCreateCollectionTx, collectionDeltaCheck, collectionDelta)
 	// mint nft
 	assetDeltasCheck, nftDeltaCheck, gasDeltasCheck = GetAssetDeltasAndNftDeltaFromMintNft(api, tx.MintNftTxInfo)
 	assetDeltas = SelectAssetDeltas(api, isMintNftTx, assetDeltasCheck, assetDeltas)
@@ -285,69 +403,117 @@ func VerifyTransaction(
 	// full exit nft
 	nftDeltaCheck = GetNftDeltaFromFullExitNft()
 	nftDelta = SelectNftDeltas(api, isFullExitNftTx, nftDeltaCheck, nftDelta)
+	// change pub key, priority ops don't pay gas
+	assetDeltasCheck, gasDeltasCheck = GetAssetDeltasFromChangePubKey(api, tx.ChangePubKeyTxInfo)
+	assetDeltas = SelectAssetDeltas(api, isChangePubKeyTx, assetDeltasCheck, assetDeltas)
+	gasDeltas = SelectGasDeltas(api, isChangePubKeyLayer2Tx, gasDeltasCheck, gasDeltas)
+	// batch transfer
+	assetDeltasCheck, gasDeltasCheck = GetAssetDeltasFromBatchTransfer(api, tx.BatchTransferTxInfo)
+	assetDeltas = SelectAssetDeltas(api, isBatchTransferTx, assetDeltasCheck, assetDeltas)
+	gasDeltas = SelectGasDeltas(api, isBatchTransferTx, gasDeltasCheck, gasDeltas)
+	// create pair
+	liquidityDeltaCheck := GetLiquidityDeltaFromCreatePair(tx.CreatePairTxInfo)
+	liquidityDelta = SelectLiquidityDeltas(api, isCreatePairTx, liquidityDeltaCheck, liquidityDelta)
+	// swap
+	assetDeltasCheck, liquidityDeltaCheck, gasDeltasCheck = GetAssetDeltasAndLiquidityDeltaFromSwap(api, tx.SwapTxInfo, tx.LiquidityBefore)
+	assetDeltas = SelectAssetDeltas(api, isSwapTx, assetDeltasCheck, assetDeltas)
+	liquidityDelta = SelectLiquidityDeltas(api, isSwapTx, liquidityDeltaCheck, liquidityDelta)
+	gasDeltas = SelectGasDeltas(api, isSwapTx, gasDeltasCheck, gasDeltas)
+	// add liquidity
+	assetDeltasCheck, liquidityDeltaCheck, gasDeltasCheck = GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(api, tx.AddLiquidityTxInfo, tx.LiquidityBefore)
+	assetDeltas = SelectAssetDeltas(api, isAddLiquidityTx, assetDeltasCheck, assetDeltas)
+	liquidityDelta = SelectLiquidityDeltas(api, isAddLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	gasDeltas = SelectGasDeltas(api, isAddLiquidityTx, gasDeltasCheck, gasDeltas)
+	// remove liquidity
+	assetDeltasCheck, liquidityDeltaCheck, gasDeltasCheck = GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(api, tx.RemoveLiquidityTxInfo, tx.LiquidityBefore)
+	assetDeltas = SelectAssetDeltas(api, isRemoveLiquidityTx, assetDeltasCheck, assetDeltas)
+	liquidityDelta = SelectLiquidityDeltas(api, isRemoveLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	gasDeltas = SelectGasDeltas(api, isRemoveLiquidityTx, gasDeltasCheck, gasDeltas)
+	// burn nft
+	assetDeltasCheck, nftDeltaCheck, gasDeltasCheck = GetAssetDeltasAndNftDeltaFromBurnNft(api, tx.BurnNftTxInfo)
+	assetDeltas = SelectAssetDeltas(api, isBurnNftTx, assetDeltasCheck, assetDeltas)
+	nftDelta = SelectNftDeltas(api, isBurnNftTx, nftDeltaCheck, nftDelta)
+	gasDeltas = SelectGasDeltas(api, isBurnNftTx, gasDeltasCheck, gasDeltas)
+	// update collection
+	assetDeltasCheck, collectionDeltaCheck, gasDeltasCheck = GetAssetDeltasAndCollectionDeltaFromUpdateCollection(api, tx.UpdateCollectionTxInfo, tx.CollectionBefore)
+	assetDeltas = SelectAssetDeltas(api, isUpdateCollectionTx, assetDeltasCheck, assetDeltas)
+	collectionDelta = SelectCollectionDeltas(api, isUpdateCollectionTx, collectionDeltaCheck, collectionDelta)
+	gasDeltas = SelectGasDeltas(api, isUpdateCollectionTx, gasDeltasCheck, gasDeltas)
+	// transfer collection
+	assetDeltasCheck, collectionDeltaCheck, gasDeltasCheck = GetAssetDeltasAndCollectionDeltaFromTransferCollection(api, tx.TransferCollectionTxInfo, tx.CollectionBefore)
+	assetDeltas = SelectAssetDeltas(api, isTransferCollectionTx, assetDeltasCheck, assetDeltas)
+	collectionDelta = SelectCollectionDeltas(api, isTransferCollectionTx, collectionDeltaCheck, collectionDelta)
+	gasDeltas = SelectGasDeltas(api, isTransferCollectionTx, gasDeltasCheck, gasDeltas)
 	// update accounts
 	AccountsInfoAfter := UpdateAccounts(api, tx.AccountsInfoBefore, assetDeltas)
 	AccountsInfoAfter[0].AccountNameHash = api.Select(isRegisterZnsTx, accountDelta.AccountNameHash, AccountsInfoAfter[0].AccountNameHash)
 	AccountsInfoAfter[0].AccountPk.A.X = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
 	AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
+	AccountsInfoAfter[0].AccountPk.A.X = api.Select(isChangePubKeyTx, tx.ChangePubKeyTxInfo.PubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
+	AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isChangePubKeyTx, tx.ChangePubKeyTxInfo.PubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
 	// update nonce
 	AccountsInfoAfter[0].Nonce = api.Add(AccountsInfoAfter[0].Nonce, isLayer2Tx)
-	AccountsInfoAfter[0].CollectionNonce = api.Add(AccountsInfoAfter[0].CollectionNonce, isCreateCollectionTx)
 	// update nft
 	NftAfter := UpdateNft(tx.NftBefore, nftDelta)
+	// update liquidity
+	LiquidityAfter := UpdateLiquidity(tx.LiquidityBefore, liquidityDelta)
+	// update collection
+	CollectionAfter := UpdateCollection(tx.CollectionBefore, collectionDelta)
 
 	// check old state root
-	hFunc.Reset()
-	hFunc.Write(
+	treeHFunc.Reset()
+	treeHFunc.Write(
 		tx.AccountRootBefore,
+		tx.LiquidityRootBefore,
 		tx.NftRootBefore,
+		tx.CollectionRootBefore,
 	)
-	oldStateRoot := hFunc.Sum()
+	oldStateRoot := treeHFunc.Sum()
 	notEmptyTx := api.IsZero(isEmptyTx)
 	types.IsVariableEqual(api, notEmptyTx, oldStateRoot, tx.StateRootBefore)
 
 	newAccountRoot := tx.AccountRootBefore
-	for i := 0; i < NbAccountsPerTx; i++ {
+	for i := 0; i < config.NbAccountsPerTx; i++ {
 		var (
 			NewAccountAssetsRoot = tx.AccountsInfoBefore[i].AssetRoot
 		)
 		// verify account asset node hash
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
-			api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, LastAccountAssetId)
-			assetMerkleHelper := AssetIdToMerkleHelper(api, tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId)
-			hFunc.Reset()
-			hFunc.Write(
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, config.LastAccountAssetId())
+			assetMerkleHelper := AssetIdToMerkleHelper(api, tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, config)
+			treeHFunc.Reset()
+			treeHFunc.Write(
 				tx.AccountsInfoBefore[i].AssetsInfo[j].Balance,
 				tx.AccountsInfoBefore[i].AssetsInfo[j].OfferCanceledOrFinalized,
 			)
-			assetNodeHash := hFunc.Sum()
+			assetNodeHash := treeHFunc.Sum()
 			// verify account asset merkle proof
-			hFunc.Reset()
+			treeHFunc.Reset()
 			types.VerifyMerkleProof(
 				api,
 				notEmptyTx,
-				hFunc,
+				treeHFunc,
 				NewAccountAssetsRoot,
 				assetNodeHash,
-				tx.MerkleProofsAccountAssetsBefore[i][j][:],
+				tx.MerkleProofsAccountAssetsBefore[i][j],
 				assetMerkleHelper,
 			)
-			hFunc.Reset()
-			hFunc.Write(
+			treeHFunc.Reset()
+			treeHFunc.Write(
 				AccountsInfoAfter[i].AssetsInfo[j].Balance,
 				AccountsInfoAfter[i].AssetsInfo[j].OfferCanceledOrFinalized,
 			)
-			assetNodeHash = hFunc.Sum()
-			hFunc.Reset()
+			assetNodeHash = treeHFunc.Sum()
+			treeHFunc.Reset()
 			// update merkle proof
 			NewAccountAssetsRoot = types.UpdateMerkleProof(
-				api, hFunc, assetNodeHash, tx.MerkleProofsAccountAssetsBefore[i][j][:], assetMerkleHelper)
+				api, treeHFunc, assetNodeHash, tx.MerkleProofsAccountAssetsBefore[i][j], assetMerkleHelper)
 		}
 		// verify account node hash
-		api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AccountIndex, LastAccountIndex)
-		accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, tx.AccountsInfoBefore[i].AccountIndex)
-		hFunc.Reset()
-		hFunc.Write(
+		api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AccountIndex, config.LastAccountIndex())
+		accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, tx.AccountsInfoBefore[i].AccountIndex, config)
+		treeHFunc.Reset()
+		treeHFunc.Write(
 			tx.AccountsInfoBefore[i].AccountNameHash,
 			tx.AccountsInfoBefore[i].AccountPk.A.X,
 			tx.AccountsInfoBefore[i].AccountPk.A.Y,
@@ -355,20 +521,20 @@ func VerifyTransaction(
 			tx.AccountsInfoBefore[i].CollectionNonce,
 			tx.AccountsInfoBefore[i].AssetRoot,
 		)
-		accountNodeHash := hFunc.Sum()
+		accountNodeHash := treeHFunc.Sum()
 		// verify account merkle proof
-		hFunc.Reset()
+		treeHFunc.Reset()
 		types.VerifyMerkleProof(
 			api,
 			notEmptyTx,
-			hFunc,
+			treeHFunc,
 			newAccountRoot,
 			accountNodeHash,
-			tx.MerkleProofsAccountBefore[i][:],
+			tx.MerkleProofsAccountBefore[i],
 			accountIndexMerkleHelper,
 		)
-		hFunc.Reset()
-		hFunc.Write(
+		treeHFunc.Reset()
+		treeHFunc.Write(
 			AccountsInfoAfter[i].AccountNameHash,
 			AccountsInfoAfter[i].AccountPk.A.X,
 			AccountsInfoAfter[i].AccountPk.A.Y,
@@ -376,18 +542,63 @@ func VerifyTransaction(
 			AccountsInfoAfter[i].CollectionNonce,
 			NewAccountAssetsRoot,
 		)
-		accountNodeHash = hFunc.Sum()
-		hFunc.Reset()
+		accountNodeHash = treeHFunc.Sum()
+		treeHFunc.Reset()
 		// update merkle proof
-		newAccountRoot = types.UpdateMerkleProof(api, hFunc, accountNodeHash, tx.MerkleProofsAccountBefore[i][:], accountIndexMerkleHelper)
+		newAccountRoot = types.UpdateMerkleProof(api, treeHFunc, accountNodeHash, tx.MerkleProofsAccountBefore[i], accountIndexMerkleHelper)
 	}
 
+	//// liquidity tree
+	newLiquidityRoot := tx.LiquidityRootBefore
+	api.AssertIsLessOrEqual(tx.LiquidityBefore.PairIndex, config.LastPairIndex())
+	pairIndexMerkleHelper := PairIndexToMerkleHelper(api, tx.LiquidityBefore.PairIndex, config)
+	treeHFunc.Reset()
+	treeHFunc.Write(
+		tx.LiquidityBefore.AssetAId,
+		tx.LiquidityBefore.AssetA,
+		tx.LiquidityBefore.AssetBId,
+		tx.LiquidityBefore.AssetB,
+		tx.LiquidityBefore.LpAssetId,
+		tx.LiquidityBefore.LpAmount,
+		tx.LiquidityBefore.FeeRate,
+		tx.LiquidityBefore.TreasuryAccountIndex,
+		tx.LiquidityBefore.TreasuryRate,
+	)
+	liquidityNodeHash := treeHFunc.Sum()
+	// verify liquidity merkle proof
+	treeHFunc.Reset()
+	types.VerifyMerkleProof(
+		api,
+		notEmptyTx,
+		treeHFunc,
+		newLiquidityRoot,
+		liquidityNodeHash,
+		tx.MerkleProofsLiquidityBefore,
+		pairIndexMerkleHelper,
+	)
+	treeHFunc.Reset()
+	treeHFunc.Write(
+		LiquidityAfter.AssetAId,
+		LiquidityAfter.AssetA,
+		LiquidityAfter.AssetBId,
+		LiquidityAfter.AssetB,
+		LiquidityAfter.LpAssetId,
+		LiquidityAfter.LpAmount,
+		LiquidityAfter.FeeRate,
+		LiquidityAfter.TreasuryAccountIndex,
+		LiquidityAfter.TreasuryRate,
+	)
+	liquidityNodeHash = treeHFunc.Sum()
+	treeHFunc.Reset()
+	// update merkle proof
+	newLiquidityRoot = types.UpdateMerkleProof(api, treeHFunc, liquidityNodeHash, tx.MerkleProofsLiquidityBefore, pairIndexMerkleHelper)
+
 	//// nft tree
 	newNftRoot := tx.NftRootBefore
-	api.AssertIsLessOrEqual(tx.NftBefore.NftIndex, LastNftIndex)
-	nftIndexMerkleHelper := NftIndexToMerkleHelper(api, tx.NftBefore.NftIndex)
-	hFunc.Reset()
-	hFunc.Write(
+	api.AssertIsLessOrEqual(tx.NftBefore.NftIndex, config.LastNftIndex())
+	nftIndexMerkleHelper := NftIndexToMerkleHelper(api, tx.NftBefore.NftIndex, config)
+	treeHFunc.Reset()
+	treeHFunc.Write(
 		tx.NftBefore.CreatorAccountIndex,
 		tx.NftBefore.OwnerAccountIndex,
 		tx.NftBefore.NftContentHash,
@@ -396,20 +607,20 @@ func VerifyTransaction(
 		tx.NftBefore.CreatorTreasuryRate,
 		tx.NftBefore.CollectionId,
 	)
-	nftNodeHash := hFunc.Sum()
+	nftNodeHash := treeHFunc.Sum()
 	// verify account merkle proof
-	hFunc.Reset()
+	treeHFunc.Reset()
 	types.VerifyMerkleProof(
 		api,
 		notEmptyTx,
-		hFunc,
+		treeHFunc,
 		newNftRoot,
 		nftNodeHash,
-		tx.MerkleProofsNftBefore[:],
+		tx.MerkleProofsNftBefore,
 		nftIndexMerkleHelper,
 	)
-	hFunc.Reset()
-	hFunc.Write(
+	treeHFunc.Reset()
+	treeHFunc.Write(
 		NftAfter.CreatorAccountIndex,
 		NftAfter.OwnerAccountIndex,
 		NftAfter.NftContentHash,
@@ -418,63 +629,98 @@ func VerifyTransaction(
 		NftAfter.CreatorTreasuryRate,
 		NftAfter.CollectionId,
 	)
-	nftNodeHash = hFunc.Sum()
-	hFunc.Reset()
+	nftNodeHash = treeHFunc.Sum()
+	treeHFunc.Reset()
+	// update merkle proof
+	newNftRoot = types.UpdateMerkleProof(api, treeHFunc, nftNodeHash, tx.MerkleProofsNftBefore, nftIndexMerkleHelper)
+
+	//// collection tree
+	newCollectionRoot := tx.CollectionRootBefore
+	api.AssertIsLessOrEqual(tx.CollectionBefore.CollectionId, config.LastCollectionId())
+	collectionIdMerkleHelper := CollectionIdToMerkleHelper(api, tx.CollectionBefore.CollectionId, config)
+	treeHFunc.Reset()
+	treeHFunc.Write(
+		tx.CollectionBefore.OwnerAccountIndex,
+		tx.CollectionBefore.MetadataHash,
+		tx.CollectionBefore.CreatorTreasuryRate,
+	)
+	collectionNodeHash := treeHFunc.Sum()
+	// verify collection merkle proof
+	treeHFunc.Reset()
+	types.VerifyMerkleProof(
+		api,
+		notEmptyTx,
+		treeHFunc,
+		newCollectionRoot,
+		collectionNodeHash,
+		tx.MerkleProofsCollectionBefore,
+		collectionIdMerkleHelper,
+	)
+	treeHFunc.Reset()
+	treeHFunc.Write(
+		CollectionAfter.OwnerAccountIndex,
+		CollectionAfter.MetadataHash,
+		CollectionAfter.CreatorTreasuryRate,
+	)
+	collectionNodeHash = treeHFunc.Sum()
+	treeHFunc.Reset()
 	// update merkle proof
-	newNftRoot = types.UpdateMerkleProof(api, hFunc, nftNodeHash, tx.MerkleProofsNftBefore[:], nftIndexMerkleHelper)
+	newCollectionRoot = types.UpdateMerkleProof(api, treeHFunc, collectionNodeHash, tx.MerkleProofsCollectionBefore, collectionIdMerkleHelper)
 
 	// check state root
-	hFunc.Reset()
-	hFunc.Write(
+	treeHFunc.Reset()
+	treeHFunc.Write(
 		newAccountRoot,
+		newLiquidityRoot,
 		newNftRoot,
+		newCollectionRoot,
 	)
-	newStateRoot := hFunc.Sum()
+	newStateRoot := treeHFunc.Sum()
 	types.IsVariableEqual(api, notEmptyTx, newStateRoot, tx.StateRootAfter)
+	// empty txs don't change the state
+	types.IsVariableEqual(api, isEmptyTx, tx.StateRootBefore, tx.StateRootAfter)
 
 	roots[0] = newAccountRoot
-	roots[1] = newNftRoot
+	roots[1] = newLiquidityRoot
+	roots[2] = newNftRoot
+	roots[3] = newCollectionRoot
 	return isOnChainOp, pubData, roots, gasDeltas, nil
 }
 
-func EmptyTx(stateRoot []byte) (oTx *Tx) {
+func EmptyTx(stateRoot []byte, config CircuitConfig) (oTx *Tx) {
 	oTx = &Tx{
-		TxType:            types.TxTypeEmptyTx,
-		Nonce:             0,
-		ExpiredAt:         0,
-		Signature:         types.EmptySignature(),
-		AccountRootBefore: make([]byte, 32),
-		AccountsInfoBefore: [NbAccountsPerTx]*types.Account{
-			types.EmptyAccount(0, make([]byte, 32)),
-			types.EmptyAccount(0, make([]byte, 32)),
-			types.EmptyAccount(0, make([]byte, 32)),
-			types.EmptyAccount(0, make([]byte, 32)),
-		},
+		TxType:                          types.TxTypeEmptyTx,
+		Nonce:                           0,
+		ExpiredAt:                       0,
+		Signature:                       types.EmptySignature(),
+		AccountRootBefore:               make([]byte, 32),
+		AccountsInfoBefore:              make([]*types.Account, config.NbAccountsPerTx),
+		LiquidityRootBefore:             make([]byte, 32),
+		LiquidityBefore:                 types.EmptyLiquidity(0),
 		NftRootBefore:                   make([]byte, 32),
 		NftBefore:                       types.EmptyNft(0),
+		CollectionRootBefore:            make([]byte, 32),
+		CollectionBefore:                types.EmptyCollection(0),
 		StateRootBefore:                 stateRoot,
-		MerkleProofsAccountAssetsBefore: [NbAccountsPerTx][NbAccountAssetsPerAccount][AssetMerkleLevels][]byte{},
-		MerkleProofsAccountBefore:       [NbAccountsPerTx][AccountMerkleLevels][]byte{},
-		MerkleProofsNftBefore:           [NftMerkleLevels][]byte{},
+		MerkleProofsLiquidityBefore:     emptyMerkleProof(config.LiquidityMerkleLevels),
+		MerkleProofsNftBefore:           emptyMerkleProof(config.NftMerkleLevels),
+		MerkleProofsCollectionBefore:    emptyMerkleProof(config.CollectionMerkleLevels),
 		StateRootAfter:                  stateRoot,
+		MerkleProofsAccountAssetsBefore: make([][][][]byte, config.NbAccountsPerTx),
+		MerkleProofsAccountBefore:       make([][][]byte, config.NbAccountsPerTx),
 	}
-	for i := 0; i < NbAccountsPerTx; i++ {
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
-			for k := 0; k < AssetMerkleLevels; k++ {
-				oTx.MerkleProofsAccountAssetsBefore[i][j][k] = make([]byte, 32)
-			}
+	for i := 0; i < config.NbAccountsPerTx; i++ {
+		oTx.AccountsInfoBefore[i] = types.EmptyAccount(0, make([]byte, 32), config)
+		oTx.MerkleProofsAccountAssetsBefore[i] = make([][][]byte, config.NbAccountAssetsPerAccount)
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			oTx.MerkleProofsAccountAssetsBefore[i][j] = emptyMerkleProof(config.AssetMerkleLevels)
 		}
-		for j := 0; j < AccountMerkleLevels; j++ {
-			oTx.MerkleProofsAccountBefore[i][j] = make([]byte, 32)
-		}
-	}
-	for i := 0; i < NftMerkleLevels; i++ {
-		oTx.MerkleProofsNftBefore[i] = make([]byte, 32)
+		oTx.MerkleProofsAccountBefore[i] = emptyMerkleProof(config.AccountMerkleLevels)
 	}
 	return oTx
 }
 
-func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
+func SetTxWitness(oTx *Tx, config CircuitConfig) (witness TxConstraints, err error) {
 	witness.TxType = int64(oTx.TxType)
 	witness.RegisterZnsTxInfo = types.EmptyRegisterZnsTxWitness()
 	witness.DepositTxInfo = types.EmptyDepositTxWitness()
@@ -489,9 +735,19 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 	witness.WithdrawNftTxInfo = types.EmptyWithdrawNftTxWitness()
 	witness.FullExitTxInfo = types.EmptyFullExitTxWitness()
 	witness.FullExitNftTxInfo = types.EmptyFullExitNftTxWitness()
+	witness.ChangePubKeyTxInfo = types.EmptyChangePubKeyTxWitness()
+	witness.BatchTransferTxInfo = types.EmptyBatchTransferTxWitness()
+	witness.CreatePairTxInfo = types.EmptyCreatePairTxWitness()
+	witness.SwapTxInfo = types.EmptySwapTxWitness()
+	witness.AddLiquidityTxInfo = types.EmptyAddLiquidityTxWitness()
+	witness.RemoveLiquidityTxInfo = types.EmptyRemoveLiquidityTxWitness()
+	witness.BurnNftTxInfo = types.EmptyBurnNftTxWitness()
+	witness.UpdateCollectionTxInfo = types.EmptyUpdateCollectionTxWitness()
+	witness.TransferCollectionTxInfo = types.EmptyTransferCollectionTxWitness()
 	witness.Signature = EmptySignatureWitness()
 	witness.Nonce = oTx.Nonce
 	witness.ExpiredAt = oTx.ExpiredAt
+	witness.Config = config
 	switch oTx.TxType {
 	case types.TxTypeEmptyTx:
 		break
@@ -558,6 +814,59 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 	case types.TxTypeFullExitNft:
 		witness.FullExitNftTxInfo = types.SetFullExitNftTxWitness(oTx.FullExitNftTxInfo)
 		break
+	case types.TxTypeChangePubKey:
+		witness.ChangePubKeyTxInfo = types.SetChangePubKeyTxWitness(oTx.ChangePubKeyTxInfo)
+		if oTx.ChangePubKeyTxInfo.IsPriorityOp == 0 {
+			witness.Signature.R.X = oTx.Signature.R.X
+			witness.Signature.R.Y = oTx.Signature.R.Y
+			witness.Signature.S = oTx.Signature.S[:]
+		}
+		break
+	case types.TxTypeBatchTransfer:
+		witness.BatchTransferTxInfo = types.SetBatchTransferTxWitness(oTx.BatchTransferTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeCreatePair:
+		witness.CreatePairTxInfo = types.SetCreatePairTxWitness(oTx.CreatePairTxInfo)
+		break
+	case types.TxTypeSwap:
+		witness.SwapTxInfo = types.SetSwapTxWitness(oTx.SwapTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeAddLiquidity:
+		witness.AddLiquidityTxInfo = types.SetAddLiquidityTxWitness(oTx.AddLiquidityTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeRemoveLiquidity:
+		witness.RemoveLiquidityTxInfo = types.SetRemoveLiquidityTxWitness(oTx.RemoveLiquidityTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeBurnNft:
+		witness.BurnNftTxInfo = types.SetBurnNftTxWitness(oTx.BurnNftTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeUpdateCollection:
+		witness.UpdateCollectionTxInfo = types.SetUpdateCollectionTxWitness(oTx.UpdateCollectionTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case types.TxTypeTransferCollection:
+		witness.TransferCollectionTxInfo = types.SetTransferCollectionTxWitness(oTx.TransferCollectionTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
 	default:
 		log.Println("[SetTxWitness] invalid oTx type")
 		return witness, errors.New("[SetTxWitness] invalid oTx type")
@@ -565,7 +874,9 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 	// set common account & merkle parts
 	// account root before
 	witness.AccountRootBefore = oTx.AccountRootBefore
+	witness.LiquidityRootBefore = oTx.LiquidityRootBefore
 	witness.NftRootBefore = oTx.NftRootBefore
+	witness.CollectionRootBefore = oTx.CollectionRootBefore
 	witness.StateRootBefore = oTx.StateRootBefore
 	witness.StateRootAfter = oTx.StateRootAfter
 
@@ -575,29 +886,125 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 		log.Println("[SetTxWitness] unable to set nft witness:", err.Error())
 		return witness, err
 	}
+	witness.LiquidityBefore, err = types.SetLiquidityWitness(oTx.LiquidityBefore)
+	if err != nil {
+		log.Println("[SetTxWitness] unable to set liquidity witness:", err.Error())
+		return witness, err
+	}
+	witness.CollectionBefore, err = types.SetCollectionWitness(oTx.CollectionBefore)
+	if err != nil {
+		log.Println("[SetTxWitness] unable to set collection witness:", err.Error())
+		return witness, err
+	}
 
-	// account before info, size is 4
-	for i := 0; i < NbAccountsPerTx; i++ {
+	// account before info, NbAccountsPerTx accounts of NbAccountAssetsPerAccount assets
+	if err = checkTxSlots(oTx, config); err != nil {
+		return witness, err
+	}
+	witness.AccountsInfoBefore = make([]types.AccountConstraints, config.NbAccountsPerTx)
+	witness.MerkleProofsAccountAssetsBefore = make([][][]Variable, config.NbAccountsPerTx)
+	witness.MerkleProofsAccountBefore = make([][]Variable, config.NbAccountsPerTx)
+	for i := 0; i < config.NbAccountsPerTx; i++ {
 		// accounts info before
 		witness.AccountsInfoBefore[i], err = types.SetAccountWitness(oTx.AccountsInfoBefore[i])
 		if err != nil {
 			log.Println("[SetTxWitness] err info:", err)
 			return witness, err
 		}
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
-			for k := 0; k < AssetMerkleLevels; k++ {
-				// account assets before
-				witness.MerkleProofsAccountAssetsBefore[i][j][k] = oTx.MerkleProofsAccountAssetsBefore[i][j][k]
+		witness.MerkleProofsAccountAssetsBefore[i] = make([][]Variable, config.NbAccountAssetsPerAccount)
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			// account assets before
+			witness.MerkleProofsAccountAssetsBefore[i][j], err = SetMerkleProofWitness(oTx.MerkleProofsAccountAssetsBefore[i][j], config.AssetMerkleLevels)
+			if err != nil {
+				log.Println("[SetTxWitness] err info:", err)
+				return witness, err
 			}
 		}
-		for j := 0; j < AccountMerkleLevels; j++ {
-			// account before
-			witness.MerkleProofsAccountBefore[i][j] = oTx.MerkleProofsAccountBefore[i][j]
+		// account before
+		witness.MerkleProofsAccountBefore[i], err = SetMerkleProofWitness(oTx.MerkleProofsAccountBefore[i], config.AccountMerkleLevels)
+		if err != nil {
+			log.Println("[SetTxWitness] err info:", err)
+			return witness, err
 		}
 	}
-	for i := 0; i < NftMerkleLevels; i++ {
-		// nft assets before
-		witness.MerkleProofsNftBefore[i] = oTx.MerkleProofsNftBefore[i]
+	// liquidity before
+	witness.MerkleProofsLiquidityBefore, err = SetMerkleProofWitness(oTx.MerkleProofsLiquidityBefore, config.LiquidityMerkleLevels)
+	if err != nil {
+		log.Println("[SetTxWitness] err info:", err)
+		return witness, err
+	}
+	// nft assets before
+	witness.MerkleProofsNftBefore, err = SetMerkleProofWitness(oTx.MerkleProofsNftBefore, config.NftMerkleLevels)
+	if err != nil {
+		log.Println("[SetTxWitness] err info:", err)
+		return witness, err
+	}
+	// collection before
+	witness.MerkleProofsCollectionBefore, err = SetMerkleProofWitness(oTx.MerkleProofsCollectionBefore, config.CollectionMerkleLevels)
+	if err != nil {
+		log.Println("[SetTxWitness] err info:", err)
+		return witness, err
 	}
 	return witness, nil
 }
+
+/*
+	checkTxSlots: the tx has the account and asset slots of the config
+*/
+func checkTxSlots(oTx *Tx, config CircuitConfig) error {
+	if len(oTx.AccountsInfoBefore) != config.NbAccountsPerTx ||
+		len(oTx.MerkleProofsAccountAssetsBefore) != config.NbAccountsPerTx ||
+		len(oTx.MerkleProofsAccountBefore) != config.NbAccountsPerTx {
+		log.Println("[SetTxWitness] invalid number of account slots")
+		return fmt.Errorf("[SetTxWitness] tx should have %d account slots", config.NbAccountsPerTx)
+	}
+	for i := 0; i < config.NbAccountsPerTx; i++ {
+		if oTx.AccountsInfoBefore[i] == nil ||
+			len(oTx.AccountsInfoBefore[i].AssetsInfo) != config.NbAccountAssetsPerAccount ||
+			len(oTx.MerkleProofsAccountAssetsBefore[i]) != config.NbAccountAssetsPerAccount {
+			log.Println("[SetTxWitness] invalid number of asset slots")
+			return fmt.Errorf("[SetTxWitness] account slot %d should have %d asset slots", i, config.NbAccountAssetsPerAccount)
+		}
+	}
+	return nil
+}
+
+/*
+	checkTxLayout: the circuit has the account and asset slots of the config
+*/
+func checkTxLayout(tx TxConstraints, config CircuitConfig) error {
+	if len(tx.AccountsInfoBefore) != config.NbAccountsPerTx ||
+		len(tx.MerkleProofsAccountAssetsBefore) != config.NbAccountsPerTx ||
+		len(tx.MerkleProofsAccountBefore) != config.NbAccountsPerTx {
+		log.Println("[VerifyTransaction] invalid number of account slots")
+		return fmt.Errorf("[VerifyTransaction] tx should have %d account slots", config.NbAccountsPerTx)
+	}
+	for i := 0; i < config.NbAccountsPerTx; i++ {
+		if len(tx.AccountsInfoBefore[i].AssetsInfo) != config.NbAccountAssetsPerAccount ||
+			len(tx.MerkleProofsAccountAssetsBefore[i]) != config.NbAccountAssetsPerAccount {
+			log.Println("[VerifyTransaction] invalid number of asset slots")
+			return fmt.Errorf("[VerifyTransaction] account slot %d should have %d asset slots", i, config.NbAccountAssetsPerAccount)
+		}
+	}
+	return nil
+}
+
+func checkTxMerkleProofLevels(tx TxConstraints, config CircuitConfig) (err error) {
+	for i := 0; i < config.NbAccountsPerTx; i++ {
+		for j := 0; j < config.NbAccountAssetsPerAccount; j++ {
+			if err = checkMerkleProofLevels("MerkleProofsAccountAssetsBefore", tx.MerkleProofsAccountAssetsBefore[i][j], config.AssetMerkleLevels); err != nil {
+				return err
+			}
+		}
+		if err = checkMerkleProofLevels("MerkleProofsAccountBefore", tx.MerkleProofsAccountBefore[i], config.AccountMerkleLevels); err != nil {
+			return err
+		}
+	}
+	if err = checkMerkleProofLevels("MerkleProofsLiquidityBefore", tx.MerkleProofsLiquidityBefore, config.LiquidityMerkleLevels); err != nil {
+		return err
+	}
+	if err = checkMerkleProofLevels("MerkleProofsNftBefore", tx.MerkleProofsNftBefore, config.NftMerkleLevels); err != nil {
+		return err
+	}
+	return checkMerkleProofLevels("MerkleProofsCollectionBefore", tx.MerkleProofsCollectionBefore, config.CollectionMerkleLevels)
+}
@@ -34,44 +34,68 @@ type (
 	API                  = frontend.API
 	MiMC                 = mimc.MiMC
 
-	RegisterZnsTx      = types.RegisterZnsTx
-	DepositTx          = types.DepositTx
-	DepositNftTx       = types.DepositNftTx
-	TransferTx         = types.TransferTx
-	CreateCollectionTx = types.CreateCollectionTx
-	MintNftTx          = types.MintNftTx
-	TransferNftTx      = types.TransferNftTx
-	AtomicMatchTx      = types.AtomicMatchTx
-	CancelOfferTx      = types.CancelOfferTx
-	WithdrawTx         = types.WithdrawTx
-	WithdrawNftTx      = types.WithdrawNftTx
-	FullExitTx         = types.FullExitTx
-	FullExitNftTx      = types.FullExitNftTx
+	RegisterZnsTx        = types.RegisterZnsTx
+	DepositTx            = types.DepositTx
+	DepositNftTx         = types.DepositNftTx
+	TransferTx           = types.TransferTx
+	CreateCollectionTx   = types.CreateCollectionTx
+	MintNftTx            = types.MintNftTx
+	TransferNftTx        = types.TransferNftTx
+	AtomicMatchTx        = types.AtomicMatchTx
+	CancelOfferTx        = types.CancelOfferTx
+	WithdrawTx           = types.WithdrawTx
+	WithdrawNftTx        = types.WithdrawNftTx
+	FullExitTx           = types.FullExitTx
+	FullExitNftTx        = types.FullExitNftTx
+	ChangePubKeyTx       = types.ChangePubKeyTx
+	BatchTransferTx      = types.BatchTransferTx
+	CreatePairTx         = types.CreatePairTx
+	SwapTx               = types.SwapTx
+	AddLiquidityTx       = types.AddLiquidityTx
+	RemoveLiquidityTx    = types.RemoveLiquidityTx
+	BurnNftTx            = types.BurnNftTx
+	UpdateCollectionTx   = types.UpdateCollectionTx
+	TransferCollectionTx = types.TransferCollectionTx
 
-	RegisterZnsTxConstraints      = types.RegisterZnsTxConstraints
-	DepositTxConstraints          = types.DepositTxConstraints
-	DepositNftTxConstraints       = types.DepositNftTxConstraints
-	TransferTxConstraints         = types.TransferTxConstraints
-	CreateCollectionTxConstraints = types.CreateCollectionTxConstraints
-	MintNftTxConstraints          = types.MintNftTxConstraints
-	TransferNftTxConstraints      = types.TransferNftTxConstraints
-	AtomicMatchTxConstraints      = types.AtomicMatchTxConstraints
-	CancelOfferTxConstraints      = types.CancelOfferTxConstraints
-	WithdrawTxConstraints         = types.WithdrawTxConstraints
-	WithdrawNftTxConstraints      = types.WithdrawNftTxConstraints
-	FullExitTxConstraints         = types.FullExitTxConstraints
-	FullExitNftTxConstraints      = types.FullExitNftTxConstraints
+	RegisterZnsTxConstraints        = types.RegisterZnsTxConstraints
+	DepositTxConstraints            = types.DepositTxConstraints
+	DepositNftTxConstraints         = types.DepositNftTxConstraints
+	TransferTxConstraints           = types.TransferTxConstraints
+	CreateCollectionTxConstraints   = types.CreateCollectionTxConstraints
+	MintNftTxConstraints            = types.MintNftTxConstraints
+	TransferNftTxConstraints        = types.TransferNftTxConstraints
+	AtomicMatchTxConstraints        = types.AtomicMatchTxConstraints
+	CancelOfferTxConstraints        = types.CancelOfferTxConstraints
+	WithdrawTxConstraints           = types.WithdrawTxConstraints
+	WithdrawNftTxConstraints        = types.WithdrawNftTxConstraints
+	FullExitTxConstraints           = types.FullExitTxConstraints
+	FullExitNftTxConstraints        = types.FullExitNftTxConstraints
+	ChangePubKeyTxConstraints       = types.ChangePubKeyTxConstraints
+	BatchTransferTxConstraints      = types.BatchTransferTxConstraints
+	CreatePairTxConstraints         = types.CreatePairTxConstraints
+	SwapTxConstraints               = types.SwapTxConstraints
+	AddLiquidityTxConstraints       = types.AddLiquidityTxConstraints
+	RemoveLiquidityTxConstraints    = types.RemoveLiquidityTxConstraints
+	BurnNftTxConstraints            = types.BurnNftTxConstraints
+	UpdateCollectionTxConstraints   = types.UpdateCollectionTxConstraints
+	TransferCollectionTxConstraints = types.TransferCollectionTxConstraints
 
-	NftConstraints = types.NftConstraints
+	NftConstraints        = types.NftConstraints
+	LiquidityConstraints  = types.LiquidityConstraints
+	CollectionConstraints = types.CollectionConstraints
+
+	CircuitConfig = types.CircuitConfig
 )
 
 const (
 	NbAccountAssetsPerAccount = types.NbAccountAssetsPerAccount
 	NbAccountsPerTx           = types.NbAccountsPerTx
 	NbGasAssetsPerTx          = types.NbGasAssetsPerTx
-	AssetMerkleLevels         = 16
-	NftMerkleLevels           = 40
-	AccountMerkleLevels       = 32
+	AssetMerkleLevels         = types.AssetMerkleLevels
+	NftMerkleLevels           = types.NftMerkleLevels
+	AccountMerkleLevels       = types.AccountMerkleLevels
+	LiquidityMerkleLevels     = types.LiquidityMerkleLevels
+	CollectionMerkleLevels    = types.CollectionMerkleLevels
 	RateBase                  = types.RateBase
 	OfferSizePerAsset         = 128
 
@@ -31,27 +31,34 @@ type Account struct {
 	AccountNameHash []byte
 	AccountPk       *eddsa.PublicKey
 	Nonce           int64
+	// frozen since collection ids are global, it keeps its last value in the leaf hash
 	CollectionNonce int64
 	AssetRoot       []byte
-	AssetsInfo      [NbAccountAssetsPerAccount]*AccountAsset
+	// NbAccountAssetsPerAccount assets of the config
+	AssetsInfo []*AccountAsset
 }
 
-func EmptyAccount(accountIndex int64, assetRoot []byte) *Account {
-	return &Account{
+func EmptyAccount(accountIndex int64, assetRoot []byte, config CircuitConfig) *Account {
+	account := &Account{
 		AccountIndex:    accountIndex,
 		AccountNameHash: []byte{},
-		AccountPk: &eddsa.PublicKey{
-			A: curve.Point{
-				X: fr.NewElement(0),
-				Y: fr.NewElement(0),
-			},
-		},
+		AccountPk:       EmptyPubKey(),
 		Nonce:           0,
 		CollectionNonce: 0,
 		AssetRoot:       assetRoot,
-		AssetsInfo: [NbAccountAssetsPerAccount]*AccountAsset{
-			EmptyAccountAsset(0),
-			EmptyAccountAsset(0),
+		AssetsInfo:      make([]*AccountAsset, config.NbAccountAssetsPerAccount),
+	}
+	for i := 0; i < config.NbAccountAssetsPerAccount; i++ {
+		account.AssetsInfo[i] = EmptyAccountAsset(0)
+	}
+	return account
+}
+
+func EmptyPubKey() *eddsa.PublicKey {
+	return &eddsa.PublicKey{
+		A: curve.Point{
+			X: fr.NewElement(0),
+			Y: fr.NewElement(0),
 		},
 	}
 }
@@ -29,20 +29,21 @@ type AccountConstraints struct {
 	AccountNameHash Variable
 	AccountPk       eddsa.PublicKey
 	Nonce           Variable
+	// frozen, no tx updates it, it is only hashed into the account leaf
 	CollectionNonce Variable
 	AssetRoot       Variable
-	// at most 4 assets changed in one transaction
-	AssetsInfo [NbAccountAssetsPerAccount]AccountAssetConstraints
+	// NbAccountAssetsPerAccount assets of the config
+	AssetsInfo []AccountAssetConstraints
 }
 
-func CheckEmptyAccountNode(api API, flag Variable, account AccountConstraints) {
+func CheckEmptyAccountNode(api API, flag Variable, account AccountConstraints, emptyAssetRoot Variable) {
 	IsVariableEqual(api, flag, account.AccountNameHash, ZeroInt)
 	IsVariableEqual(api, flag, account.AccountPk.A.X, ZeroInt)
 	IsVariableEqual(api, flag, account.AccountPk.A.Y, ZeroInt)
 	IsVariableEqual(api, flag, account.Nonce, ZeroInt)
 	IsVariableEqual(api, flag, account.CollectionNonce, ZeroInt)
 	// empty asset
-	IsVariableEqual(api, flag, account.AssetRoot, EmptyAssetRoot)
+	IsVariableEqual(api, flag, account.AssetRoot, emptyAssetRoot)
 }
 
 func CheckNonEmptyAccountNode(api API, flag Variable, account AccountConstraints) {
@@ -86,7 +87,8 @@ func SetAccountWitness(account *Account) (witness AccountConstraints, err error)
 		AssetRoot:       account.AssetRoot,
 	}
 	// set assets witness
-	for i := 0; i < NbAccountAssetsPerAccount; i++ {
+	witness.AssetsInfo = make([]AccountAssetConstraints, len(account.AssetsInfo))
+	for i := 0; i < len(account.AssetsInfo); i++ {
 		witness.AssetsInfo[i], err = SetAccountAssetWitness(account.AssetsInfo[i])
 		if err != nil {
 			return witness, err
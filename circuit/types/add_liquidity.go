@@ -0,0 +1,161 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+)
+
+/*
+	AddLiquidityTx: deposit AssetAAmount and AssetBAmount to a pair for LpAmount
+	lp shares, at least LpMinAmount. The amounts are raw uint112 amounts.
+*/
+type AddLiquidityTx struct {
+	FromAccountIndex  int64
+	PairIndex         int64
+	AssetAId          int64
+	AssetAAmount      *big.Int
+	AssetBId          int64
+	AssetBAmount      *big.Int
+	LpMinAmount       *big.Int
+	LpAmount          *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type AddLiquidityTxConstraints struct {
+	FromAccountIndex  Variable
+	PairIndex         Variable
+	AssetAId          Variable
+	AssetAAmount      Variable
+	AssetBId          Variable
+	AssetBAmount      Variable
+	LpMinAmount       Variable
+	LpAmount          Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyAddLiquidityTxWitness() (witness AddLiquidityTxConstraints) {
+	return AddLiquidityTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		PairIndex:         ZeroInt,
+		AssetAId:          ZeroInt,
+		AssetAAmount:      ZeroInt,
+		AssetBId:          ZeroInt,
+		AssetBAmount:      ZeroInt,
+		LpMinAmount:       ZeroInt,
+		LpAmount:          ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetAddLiquidityTxWitness(tx *AddLiquidityTx) (witness AddLiquidityTxConstraints) {
+	witness = AddLiquidityTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		PairIndex:         tx.PairIndex,
+		AssetAId:          tx.AssetAId,
+		AssetAAmount:      tx.AssetAAmount,
+		AssetBId:          tx.AssetBId,
+		AssetBAmount:      tx.AssetBAmount,
+		LpMinAmount:       tx.LpMinAmount,
+		LpAmount:          tx.LpAmount,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromAddLiquidityTx(api API, tx AddLiquidityTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		PackInt64Variables(api, tx.PairIndex, tx.AssetAId, tx.AssetBId),
+		tx.AssetAAmount,
+		tx.AssetBAmount,
+		tx.LpMinAmount,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	VerifyAddLiquidityTx: the first deposit of a pair mints floor(sqrt(a * b)) lp
+	shares, MinimumLiquidity of them are locked in the pair and the depositor gets
+	the rest. Later ones mint min(floor(a * L / A), floor(b * L / B)) for reserves
+	A, B and L lp shares, the whole amounts are deposited
+*/
+func VerifyAddLiquidityTx(
+	api API, flag Variable,
+	tx *AddLiquidityTxConstraints,
+	accountsBefore []AccountConstraints,
+	liquidityBefore LiquidityConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	lpAccount := 3
+	pubData = CollectPubDataFromAddLiquidity(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AssetAId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.AssetBId, accountsBefore[fromAccount].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[lpAccount].AccountIndex)
+	IsVariableEqual(api, flag, liquidityBefore.LpAssetId, accountsBefore[lpAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[lpAccount].AssetsInfo[1].AssetId)
+	// the pair should exist
+	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
+	IsVariableDifferent(api, flag, liquidityBefore.AssetAId, liquidityBefore.AssetBId)
+	IsVariableEqual(api, flag, tx.AssetAId, liquidityBefore.AssetAId)
+	IsVariableEqual(api, flag, tx.AssetBId, liquidityBefore.AssetBId)
+	IsVariableDifferent(api, flag, tx.AssetAAmount, 0)
+	IsVariableDifferent(api, flag, tx.AssetBAmount, 0)
+	// lp amount
+	isFirstDeposit := api.And(flag, api.IsZero(liquidityBefore.LpAmount))
+	isNextDeposit := api.Sub(flag, isFirstDeposit)
+	product := api.Mul(tx.AssetAAmount, tx.AssetBAmount)
+	mintedAmount := api.Add(tx.LpAmount, MinimumLiquidity)
+	IsVariableLessOrEqual(api, isFirstDeposit, api.Mul(mintedAmount, mintedAmount), product)
+	mintedAmountPlusOne := api.Add(mintedAmount, 1)
+	IsVariableLess(api, isFirstDeposit, product, api.Mul(mintedAmountPlusOne, mintedAmountPlusOne))
+	lpAmountPlusOne := api.Add(tx.LpAmount, 1)
+	shareA := api.Mul(tx.AssetAAmount, liquidityBefore.LpAmount)
+	shareB := api.Mul(tx.AssetBAmount, liquidityBefore.LpAmount)
+	IsVariableLessOrEqual(api, isNextDeposit, api.Mul(tx.LpAmount, liquidityBefore.AssetA), shareA)
+	IsVariableLessOrEqual(api, isNextDeposit, api.Mul(tx.LpAmount, liquidityBefore.AssetB), shareB)
+	// the lp amount is the smallest of the two shares
+	isShareA := api.IsZero(api.Add(api.Cmp(shareA, api.Mul(lpAmountPlusOne, liquidityBefore.AssetA)), 1))
+	isShareB := api.IsZero(api.Add(api.Cmp(shareB, api.Mul(lpAmountPlusOne, liquidityBefore.AssetB)), 1))
+	IsVariableEqual(api, isNextDeposit, api.Or(isShareA, isShareB), 1)
+	IsVariableDifferent(api, flag, tx.LpAmount, 0)
+	IsVariableLessOrEqual(api, flag, tx.LpMinAmount, tx.LpAmount)
+	// the reserves and lp shares should stay uint112
+	CheckLiquidityAmount(api, flag, api.Add(liquidityBefore.AssetA, tx.AssetAAmount))
+	CheckLiquidityAmount(api, flag, api.Add(liquidityBefore.AssetB, tx.AssetBAmount))
+	CheckLiquidityAmount(api, flag, api.Add(api.Add(liquidityBefore.LpAmount, tx.LpAmount), api.Select(isFirstDeposit, MinimumLiquidity, 0)))
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.AssetAAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.AssetBAmount, accountsBefore[fromAccount].AssetsInfo[1].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[lpAccount].AssetsInfo[1].Balance)
+	return pubData
+}
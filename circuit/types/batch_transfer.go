@@ -0,0 +1,132 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	BatchTransferTx: transfer one asset from the first account slot to the accounts
+	of the other slots. Unused recipients have a zero name hash and amount and are
+	sent to the sender itself.
+*/
+type BatchTransferTx struct {
+	FromAccountIndex    int64
+	AssetId             int64
+	ToAccountIndexes    [NbBatchTransferRecipients]int64
+	ToAccountNameHashes [NbBatchTransferRecipients][]byte
+	AssetAmounts        [NbBatchTransferRecipients]int64
+	GasAccountIndex     int64
+	GasFeeAssetId       int64
+	GasFeeAssetAmount   int64
+	CallDataHash        []byte
+}
+
+type BatchTransferTxConstraints struct {
+	FromAccountIndex    Variable
+	AssetId             Variable
+	ToAccountIndexes    [NbBatchTransferRecipients]Variable
+	ToAccountNameHashes [NbBatchTransferRecipients]Variable
+	AssetAmounts        [NbBatchTransferRecipients]Variable
+	GasAccountIndex     Variable
+	GasFeeAssetId       Variable
+	GasFeeAssetAmount   Variable
+	CallDataHash        Variable
+}
+
+func EmptyBatchTransferTxWitness() (witness BatchTransferTxConstraints) {
+	witness = BatchTransferTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		AssetId:           ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+		CallDataHash:      ZeroInt,
+	}
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		witness.ToAccountIndexes[i] = ZeroInt
+		witness.ToAccountNameHashes[i] = ZeroInt
+		witness.AssetAmounts[i] = ZeroInt
+	}
+	return witness
+}
+
+func SetBatchTransferTxWitness(tx *BatchTransferTx) (witness BatchTransferTxConstraints) {
+	witness = BatchTransferTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		AssetId:           tx.AssetId,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+		CallDataHash:      tx.CallDataHash,
+	}
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		witness.ToAccountIndexes[i] = tx.ToAccountIndexes[i]
+		witness.ToAccountNameHashes[i] = tx.ToAccountNameHashes[i]
+		witness.AssetAmounts[i] = tx.AssetAmounts[i]
+	}
+	return witness
+}
+
+func ComputeHashFromBatchTransferTx(api API, tx BatchTransferTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+	)
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		hFunc.Write(
+			PackInt64Variables(api, tx.ToAccountIndexes[i], tx.AssetId, tx.AssetAmounts[i]),
+			tx.ToAccountNameHashes[i],
+		)
+	}
+	hFunc.Write(tx.CallDataHash)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyBatchTransferTx(
+	api API, flag Variable,
+	tx *BatchTransferTxConstraints,
+	accountsBefore []AccountConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+
+	// collect pubdata
+	pubData = CollectPubDataFromBatchTransfer(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[1].AssetId)
+	totalAmount := Variable(0)
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		toAccount := i + 1
+		isUnused := api.And(flag, api.IsZero(tx.ToAccountNameHashes[i]))
+		isUsed := api.Sub(flag, isUnused)
+		IsVariableEqual(api, flag, tx.ToAccountIndexes[i], accountsBefore[toAccount].AccountIndex)
+		IsVariableEqual(api, flag, tx.AssetId, accountsBefore[toAccount].AssetsInfo[0].AssetId)
+		IsVariableEqual(api, isUsed, tx.ToAccountNameHashes[i], accountsBefore[toAccount].AccountNameHash)
+		// unused recipients don't receive anything
+		IsVariableEqual(api, isUnused, tx.ToAccountIndexes[i], tx.FromAccountIndex)
+		IsVariableEqual(api, isUnused, tx.AssetAmounts[i], 0)
+		tx.AssetAmounts[i] = UnpackAmount(api, tx.AssetAmounts[i])
+		totalAmount = api.Add(totalAmount, tx.AssetAmounts[i])
+	}
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, totalAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[1].Balance)
+	return pubData
+}
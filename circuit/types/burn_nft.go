@@ -0,0 +1,97 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	BurnNftTx: the owner of an nft destroys it on layer 2, the nft leaf is reset
+	to an empty leaf and the owner pays the gas fee
+*/
+type BurnNftTx struct {
+	AccountIndex      int64
+	NftIndex          int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type BurnNftTxConstraints struct {
+	AccountIndex      Variable
+	NftIndex          Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyBurnNftTxWitness() (witness BurnNftTxConstraints) {
+	return BurnNftTxConstraints{
+		AccountIndex:      ZeroInt,
+		NftIndex:          ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetBurnNftTxWitness(tx *BurnNftTx) (witness BurnNftTxConstraints) {
+	witness = BurnNftTxConstraints{
+		AccountIndex:      tx.AccountIndex,
+		NftIndex:          tx.NftIndex,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromBurnNftTx(api API, tx BurnNftTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.AccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		tx.NftIndex,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyBurnNftTx(
+	api API,
+	flag Variable,
+	tx *BurnNftTxConstraints,
+	accountsBefore []AccountConstraints,
+	nftBefore NftConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	pubData = CollectPubDataFromBurnNft(api, *tx)
+	// verify params
+	// account index
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[fromAccount].AccountIndex)
+	// asset id
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	// nft info, the nft should exist and not be deposited from layer 1: burning isn't an
+	// on chain op, the layer 1 token would stay locked in the contract
+	IsVariableEqual(api, flag, tx.NftIndex, nftBefore.NftIndex)
+	IsVariableEqual(api, flag, tx.AccountIndex, nftBefore.OwnerAccountIndex)
+	isEmptyContentHash := api.IsZero(nftBefore.NftContentHash)
+	IsVariableEqual(api, flag, isEmptyContentHash, 0)
+	IsVariableEqual(api, flag, nftBefore.NftL1Address, 0)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	return pubData
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type burnNftCircuit struct {
+	Tx             BurnNftTxConstraints
+	AccountsBefore []AccountConstraints
+	NftBefore      NftConstraints
+}
+
+func (circuit burnNftCircuit) Define(api API) error {
+	VerifyBurnNftTx(api, 1, &circuit.Tx, circuit.AccountsBefore, circuit.NftBefore)
+	return nil
+}
+
+func TestBurnNftOwner(t *testing.T) {
+	const (
+		alice    = 2
+		nftIndex = 7
+	)
+	burnNftWitness := func(accountIndex int64, nft *Nft) burnNftCircuit {
+		witness := burnNftCircuit{
+			Tx: SetBurnNftTxWitness(&BurnNftTx{
+				AccountIndex:      accountIndex,
+				NftIndex:          nft.NftIndex,
+				GasFeeAssetAmount: 0,
+			}),
+			AccountsBefore: make([]AccountConstraints, NbAccountsPerTx),
+		}
+		var err error
+		for i := range witness.AccountsBefore {
+			witness.AccountsBefore[i], err = SetAccountWitness(EmptyAccount(accountIndex, make([]byte, 32), TestConfig))
+			require.NoError(t, err)
+		}
+		witness.NftBefore, err = SetNftWitness(nft)
+		require.NoError(t, err)
+		return witness
+	}
+	// nft minted on layer 2 by alice
+	nft := &Nft{
+		NftIndex: nftIndex, NftContentHash: []byte{1}, CreatorAccountIndex: alice, OwnerAccountIndex: alice,
+		NftL1Address: big.NewInt(0), NftL1TokenId: big.NewInt(0), CreatorTreasuryRate: 100,
+	}
+
+	circuit := burnNftWitness(alice, nft)
+	witness := burnNftWitness(alice, nft)
+	assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// an empty leaf is owned by account 0, which can't burn it
+	witness = burnNftWitness(0, EmptyNft(nftIndex))
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// an nft deposited from layer 1 is withdrawn, not burnt
+	l1Nft := *nft
+	l1Nft.NftL1Address = big.NewInt(0x1234)
+	l1Nft.NftL1TokenId = big.NewInt(1)
+	witness = burnNftWitness(alice, &l1Nft)
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
@@ -54,10 +54,10 @@ func SetCancelOfferTxWitness(tx *CancelOfferTx) (witness CancelOfferTxConstraint
 	return witness
 }
 
-func ComputeHashFromCancelOfferTx(api API, tx CancelOfferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+func ComputeHashFromCancelOfferTx(api API, tx CancelOfferTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
 	hFunc.Reset()
 	hFunc.Write(
-		PackInt64Variables(api, ChainId, tx.AccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, chainId, tx.AccountIndex, nonce, expiredAt),
 		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
 		tx.OfferId,
 	)
@@ -68,7 +68,7 @@ func ComputeHashFromCancelOfferTx(api API, tx CancelOfferTxConstraints, nonce Va
 func VerifyCancelOfferTx(
 	api API, flag Variable,
 	tx *CancelOfferTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	fromAccount := 0
 	pubData = CollectPubDataFromCancelOffer(api, *tx)
@@ -0,0 +1,114 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+)
+
+/*
+	ChangePubKeyTx: replace the layer 2 key of an account. The tx is either signed
+	by the current key of the account and pays a gas fee like other layer 2 txs,
+	or it is a priority op sent on layer 1 (IsPriorityOp = 1) which is neither
+	signed nor charged.
+*/
+type ChangePubKeyTx struct {
+	AccountIndex      int64
+	AccountNameHash   []byte
+	PubKey            *eddsa.PublicKey
+	IsPriorityOp      int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type ChangePubKeyTxConstraints struct {
+	AccountIndex      Variable
+	AccountNameHash   Variable
+	PubKey            PublicKeyConstraints
+	IsPriorityOp      Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyChangePubKeyTxWitness() (witness ChangePubKeyTxConstraints) {
+	return ChangePubKeyTxConstraints{
+		AccountIndex:      ZeroInt,
+		AccountNameHash:   ZeroInt,
+		PubKey:            EmptyPublicKeyWitness(),
+		IsPriorityOp:      ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetChangePubKeyTxWitness(tx *ChangePubKeyTx) (witness ChangePubKeyTxConstraints) {
+	witness = ChangePubKeyTxConstraints{
+		AccountIndex:      tx.AccountIndex,
+		AccountNameHash:   tx.AccountNameHash,
+		PubKey:            SetPubKeyWitness(tx.PubKey),
+		IsPriorityOp:      tx.IsPriorityOp,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+/*
+	ComputeHashFromChangePubKeyTx: message signed by the current key of the account, priority ops are not signed
+*/
+func ComputeHashFromChangePubKeyTx(api API, tx ChangePubKeyTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.AccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		tx.PubKey.A.X,
+		tx.PubKey.A.Y,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyChangePubKeyTx(
+	api API, flag Variable,
+	tx *ChangePubKeyTxConstraints,
+	accountsBefore []AccountConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	pubData = CollectPubDataFromChangePubKey(api, *tx)
+	api.AssertIsBoolean(tx.IsPriorityOp)
+	isPriorityOp := api.And(flag, tx.IsPriorityOp)
+	isLayer2 := api.And(flag, api.Sub(1, tx.IsPriorityOp))
+	// verify params
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AccountNameHash, accountsBefore[fromAccount].AccountNameHash)
+	// the account should exist
+	IsVariableDifferent(api, flag, tx.AccountNameHash, 0)
+	// priority ops don't pay gas
+	IsVariableEqual(api, isPriorityOp, tx.GasAccountIndex, 0)
+	IsVariableEqual(api, isPriorityOp, tx.GasFeeAssetId, 0)
+	IsVariableEqual(api, isPriorityOp, tx.GasFeeAssetAmount, 0)
+	IsVariableEqual(api, isLayer2, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, isLayer2, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	return pubData
+}
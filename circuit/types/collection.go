@@ -0,0 +1,40 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	Collection: leaf of the collection tree. Collection ids are global, only
+	the owner can mint into a collection, update its metadata and royalty
+	default or hand it to another account. A collection exists once its
+	metadata hash isn't zero, like the content hash of an nft.
+*/
+type Collection struct {
+	CollectionId        int64
+	OwnerAccountIndex   int64
+	MetadataHash        []byte
+	CreatorTreasuryRate int64
+}
+
+func EmptyCollection(collectionId int64) *Collection {
+	return &Collection{
+		CollectionId:        collectionId,
+		OwnerAccountIndex:   0,
+		MetadataHash:        []byte{0},
+		CreatorTreasuryRate: 0,
+	}
+}
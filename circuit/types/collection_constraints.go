@@ -0,0 +1,62 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"log"
+)
+
+type CollectionConstraints struct {
+	CollectionId        Variable
+	OwnerAccountIndex   Variable
+	MetadataHash        Variable
+	CreatorTreasuryRate Variable
+}
+
+func CheckEmptyCollectionNode(api API, flag Variable, collection CollectionConstraints) {
+	IsVariableEqual(api, flag, collection.OwnerAccountIndex, ZeroInt)
+	IsVariableEqual(api, flag, collection.MetadataHash, ZeroInt)
+	IsVariableEqual(api, flag, collection.CreatorTreasuryRate, ZeroInt)
+}
+
+/*
+	CheckCollectionOwner: the collection exists and is owned by the account
+*/
+func CheckCollectionOwner(api API, flag Variable, collection CollectionConstraints, accountIndex Variable) {
+	isZero := api.IsZero(collection.MetadataHash)
+	IsVariableEqual(api, flag, isZero, 0)
+	IsVariableEqual(api, flag, collection.OwnerAccountIndex, accountIndex)
+}
+
+/*
+	SetCollectionWitness: set collection witness
+*/
+func SetCollectionWitness(collection *Collection) (witness CollectionConstraints, err error) {
+	if collection == nil {
+		log.Println("[SetCollectionWitness] invalid params")
+		return witness, errors.New("[SetCollectionWitness] invalid params")
+	}
+	witness = CollectionConstraints{
+		CollectionId:        collection.CollectionId,
+		OwnerAccountIndex:   collection.OwnerAccountIndex,
+		MetadataHash:        collection.MetadataHash,
+		CreatorTreasuryRate: collection.CreatorTreasuryRate,
+	}
+	return witness, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"fmt"
+	"log"
+)
+
+/*
+	CircuitConfig: depth of the state trees and layout of a tx the circuits are built for.
+	The tx types address the first NbAccountsPerTx account slots, NbAccountAssetsPerAccount
+	assets, NbGasAssetsPerTx gas deltas and PubDataSizePerTx pub data words, a layout
+	can only be larger: extra slots are left unchanged and extra pub data words are zero.
+*/
+type CircuitConfig struct {
+	AccountMerkleLevels    int
+	AssetMerkleLevels      int
+	NftMerkleLevels        int
+	LiquidityMerkleLevels  int
+	CollectionMerkleLevels int
+
+	NbAccountsPerTx           int
+	NbAccountAssetsPerAccount int
+	NbGasAssetsPerTx          int
+	PubDataSizePerTx          int
+}
+
+var (
+	MainnetConfig = CircuitConfig{
+		AccountMerkleLevels:    AccountMerkleLevels,
+		AssetMerkleLevels:      AssetMerkleLevels,
+		NftMerkleLevels:        NftMerkleLevels,
+		LiquidityMerkleLevels:  LiquidityMerkleLevels,
+		CollectionMerkleLevels: CollectionMerkleLevels,
+
+		NbAccountsPerTx:           NbAccountsPerTx,
+		NbAccountAssetsPerAccount: NbAccountAssetsPerAccount,
+		NbGasAssetsPerTx:          NbGasAssetsPerTx,
+		PubDataSizePerTx:          PubDataSizePerTx,
+	}
+	// small trees for test networks and tests, 256 accounts, assets, nfts, pairs and collections,
+	// the layout of a tx is the smallest one the tx types fit in
+	TestConfig = CircuitConfig{
+		AccountMerkleLevels:    8,
+		AssetMerkleLevels:      8,
+		NftMerkleLevels:        8,
+		LiquidityMerkleLevels:  8,
+		CollectionMerkleLevels: 8,
+
+		NbAccountsPerTx:           NbAccountsPerTx,
+		NbAccountAssetsPerAccount: NbAccountAssetsPerAccount,
+		NbGasAssetsPerTx:          NbGasAssetsPerTx,
+		PubDataSizePerTx:          PubDataSizePerTx,
+	}
+)
+
+/*
+	Validate: the trees can't be deeper than the mainnet ones, account indexes,
+	asset ids, nft, pair and collection indexes wouldn't fit in the pub data otherwise.
+	The layout of a tx can't be smaller than the slots the tx types use.
+*/
+func (c CircuitConfig) Validate() error {
+	if c.AccountMerkleLevels <= 0 || c.AccountMerkleLevels > AccountMerkleLevels {
+		log.Println("[Validate] invalid account merkle levels")
+		return fmt.Errorf("[Validate] account merkle levels should be in [1, %d]", AccountMerkleLevels)
+	}
+	if c.AssetMerkleLevels <= 0 || c.AssetMerkleLevels > AssetMerkleLevels {
+		log.Println("[Validate] invalid asset merkle levels")
+		return fmt.Errorf("[Validate] asset merkle levels should be in [1, %d]", AssetMerkleLevels)
+	}
+	if c.NftMerkleLevels <= 0 || c.NftMerkleLevels > NftMerkleLevels {
+		log.Println("[Validate] invalid nft merkle levels")
+		return fmt.Errorf("[Validate] nft merkle levels should be in [1, %d]", NftMerkleLevels)
+	}
+	if c.LiquidityMerkleLevels <= 0 || c.LiquidityMerkleLevels > LiquidityMerkleLevels {
+		log.Println("[Validate] invalid liquidity merkle levels")
+		return fmt.Errorf("[Validate] liquidity merkle levels should be in [1, %d]", LiquidityMerkleLevels)
+	}
+	if c.CollectionMerkleLevels <= 0 || c.CollectionMerkleLevels > CollectionMerkleLevels {
+		log.Println("[Validate] invalid collection merkle levels")
+		return fmt.Errorf("[Validate] collection merkle levels should be in [1, %d]", CollectionMerkleLevels)
+	}
+	if c.NbAccountsPerTx < NbAccountsPerTx {
+		log.Println("[Validate] invalid accounts per tx")
+		return fmt.Errorf("[Validate] accounts per tx should be at least %d", NbAccountsPerTx)
+	}
+	if c.NbAccountAssetsPerAccount < NbAccountAssetsPerAccount {
+		log.Println("[Validate] invalid assets per account")
+		return fmt.Errorf("[Validate] assets per account should be at least %d", NbAccountAssetsPerAccount)
+	}
+	if c.NbGasAssetsPerTx < NbGasAssetsPerTx {
+		log.Println("[Validate] invalid gas assets per tx")
+		return fmt.Errorf("[Validate] gas assets per tx should be at least %d", NbGasAssetsPerTx)
+	}
+	if c.PubDataSizePerTx < PubDataSizePerTx {
+		log.Println("[Validate] invalid pub data size per tx")
+		return fmt.Errorf("[Validate] pub data size per tx should be at least %d", PubDataSizePerTx)
+	}
+	return nil
+}
+
+func (c CircuitConfig) LastAccountIndex() int64 {
+	return 1<<c.AccountMerkleLevels - 1
+}
+
+func (c CircuitConfig) LastAccountAssetId() int64 {
+	return 1<<c.AssetMerkleLevels - 1
+}
+
+/*
+	FirstLpAssetId: the upper half of the asset ids holds the lp shares of the
+	pairs, the lp asset id of a pair is FirstLpAssetId + PairIndex
+*/
+func (c CircuitConfig) FirstLpAssetId() int64 {
+	return 1 << (c.AssetMerkleLevels - 1)
+}
+
+func (c CircuitConfig) LastNftIndex() int64 {
+	return 1<<c.NftMerkleLevels - 1
+}
+
+func (c CircuitConfig) LastPairIndex() int64 {
+	return 1<<c.LiquidityMerkleLevels - 1
+}
+
+func (c CircuitConfig) LastCollectionId() int64 {
+	return 1<<c.CollectionMerkleLevels - 1
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigLayout(t *testing.T) {
+	assert.NoError(t, TestConfig.Validate())
+	config := TestConfig
+	config.NbAccountsPerTx = NbAccountsPerTx + 1
+	config.PubDataSizePerTx = PubDataSizePerTx + 2
+	assert.NoError(t, config.Validate())
+	// the tx types don't fit in a smaller layout
+	config.NbAccountAssetsPerAccount = NbAccountAssetsPerAccount - 1
+	assert.Error(t, config.Validate())
+}
@@ -38,17 +38,26 @@ const (
 	ZeroInt    = uint64(0)
 	DefaultInt = int64(-1)
 
+	// slots and pub data words addressed by the tx types, the smallest layout of a CircuitConfig
 	NbAccountAssetsPerAccount = 2
 	NbAccountsPerTx           = 4
 	NbGasAssetsPerTx          = 2 // at most two assets transferred to gas account
 
-	NbRoots = 2 // account root, nft root
+	NbBatchTransferRecipients = NbAccountsPerTx - 1 // the other account slots receive the transfers
+
+	NbRoots = 4 // account root, liquidity root, nft root, collection root
 
 	PubDataSizePerTx = 6
 
+	AssetMerkleLevels      = 16
+	NftMerkleLevels        = 40
+	AccountMerkleLevels    = 32
+	LiquidityMerkleLevels  = 16
+	CollectionMerkleLevels = 16
+
 	OfferSizePerAsset = 128
 
-	ChainId = 1
+	ChainIdBits = 32 // chain ids are uint32 on layer 1
 )
 
 const (
@@ -66,10 +75,25 @@ const (
 	TxTypeWithdrawNft
 	TxTypeFullExit
 	TxTypeFullExitNft
+	_ // offers of wasm/txtypes, they are never a tx of a block
+	TxTypeChangePubKey
+	TxTypeBatchTransfer
+	TxTypeCreatePair
+	TxTypeSwap
+	TxTypeAddLiquidity
+	TxTypeRemoveLiquidity
+	TxTypeBurnNft
+	TxTypeUpdateCollection
+	TxTypeTransferCollection
 )
 
 const (
 	RateBase = 10000
+	// lp shares locked for good by the first deposit of a pair, the share price
+	// can't be inflated by the first depositor
+	MinimumLiquidity = 1000
+	// nfts minted without a collection, the id is never created and anyone can mint into it
+	NoCollectionId = 0
 )
 
 var (
@@ -17,56 +17,70 @@
 
 package types
 
+/*
+	CreateCollectionTx: the account becomes the owner of the collection leaf
+	CollectionId, the id is picked by the sequencer among the empty leaves
+*/
 type CreateCollectionTx struct {
-	AccountIndex      int64
-	CollectionId      int64
-	GasAccountIndex   int64
-	GasFeeAssetId     int64
-	GasFeeAssetAmount int64
-	ExpiredAt         int64
-	Nonce             int64
+	AccountIndex        int64
+	CollectionId        int64
+	MetadataHash        []byte
+	CreatorTreasuryRate int64
+	GasAccountIndex     int64
+	GasFeeAssetId       int64
+	GasFeeAssetAmount   int64
+	ExpiredAt           int64
+	Nonce               int64
 }
 
 type CreateCollectionTxConstraints struct {
-	AccountIndex      Variable
-	CollectionId      Variable
-	GasAccountIndex   Variable
-	GasFeeAssetId     Variable
-	GasFeeAssetAmount Variable
-	ExpiredAt         Variable
-	Nonce             Variable
+	AccountIndex        Variable
+	CollectionId        Variable
+	MetadataHash        Variable
+	CreatorTreasuryRate Variable
+	GasAccountIndex     Variable
+	GasFeeAssetId       Variable
+	GasFeeAssetAmount   Variable
+	ExpiredAt           Variable
+	Nonce               Variable
 }
 
 func EmptyCreateCollectionTxWitness() (witness CreateCollectionTxConstraints) {
 	return CreateCollectionTxConstraints{
-		AccountIndex:      ZeroInt,
-		CollectionId:      ZeroInt,
-		GasAccountIndex:   ZeroInt,
-		GasFeeAssetId:     ZeroInt,
-		GasFeeAssetAmount: ZeroInt,
-		ExpiredAt:         ZeroInt,
-		Nonce:             ZeroInt,
+		AccountIndex:        ZeroInt,
+		CollectionId:        ZeroInt,
+		MetadataHash:        ZeroInt,
+		CreatorTreasuryRate: ZeroInt,
+		GasAccountIndex:     ZeroInt,
+		GasFeeAssetId:       ZeroInt,
+		GasFeeAssetAmount:   ZeroInt,
+		ExpiredAt:           ZeroInt,
+		Nonce:               ZeroInt,
 	}
 }
 
 func SetCreateCollectionTxWitness(tx *CreateCollectionTx) (witness CreateCollectionTxConstraints) {
 	witness = CreateCollectionTxConstraints{
-		AccountIndex:      tx.AccountIndex,
-		CollectionId:      tx.CollectionId,
-		GasAccountIndex:   tx.GasAccountIndex,
-		GasFeeAssetId:     tx.GasFeeAssetId,
-		GasFeeAssetAmount: tx.GasFeeAssetAmount,
-		ExpiredAt:         tx.ExpiredAt,
-		Nonce:             tx.Nonce,
+		AccountIndex:        tx.AccountIndex,
+		CollectionId:        tx.CollectionId,
+		MetadataHash:        tx.MetadataHash,
+		CreatorTreasuryRate: tx.CreatorTreasuryRate,
+		GasAccountIndex:     tx.GasAccountIndex,
+		GasFeeAssetId:       tx.GasFeeAssetId,
+		GasFeeAssetAmount:   tx.GasFeeAssetAmount,
+		ExpiredAt:           tx.ExpiredAt,
+		Nonce:               tx.Nonce,
 	}
 	return witness
 }
 
-func ComputeHashFromCreateCollectionTx(api API, tx CreateCollectionTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+func ComputeHashFromCreateCollectionTx(api API, tx CreateCollectionTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
 	hFunc.Reset()
 	hFunc.Write(
-		PackInt64Variables(api, ChainId, tx.AccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, chainId, tx.AccountIndex, nonce, expiredAt),
 		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		tx.CreatorTreasuryRate,
+		tx.MetadataHash,
 	)
 	hashVal = hFunc.Sum()
 	return hashVal
@@ -75,18 +89,26 @@ func ComputeHashFromCreateCollectionTx(api API, tx CreateCollectionTxConstraints
 func VerifyCreateCollectionTx(
 	api API, flag Variable,
 	tx *CreateCollectionTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
+	collectionBefore CollectionConstraints,
+	lastCollectionId int64,
 ) (pubData [PubDataSizePerTx]Variable) {
 	fromAccount := 0
 	pubData = CollectPubDataFromCreateCollection(api, *tx)
 	// verify params
-	IsVariableLessOrEqual(api, flag, tx.CollectionId, 65535)
+	IsVariableDifferent(api, flag, tx.CollectionId, NoCollectionId)
+	IsVariableLessOrEqual(api, flag, tx.CollectionId, lastCollectionId)
+	IsVariableLessOrEqual(api, flag, tx.CreatorTreasuryRate, RateBase)
+	// metadata hash
+	isZero := api.IsZero(tx.MetadataHash)
+	IsVariableEqual(api, flag, isZero, 0)
 	// account index
 	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[fromAccount].AccountIndex)
 	// asset id
 	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
-	// collection id
-	IsVariableEqual(api, flag, tx.CollectionId, accountsBefore[fromAccount].CollectionNonce)
+	// collection id, the collection shouldn't exist yet
+	IsVariableEqual(api, flag, tx.CollectionId, collectionBefore.CollectionId)
+	CheckEmptyCollectionNode(api, flag, collectionBefore)
 	// should have enough assets
 	tx.GasFeeAssetAmount = UnpackAmount(api, tx.GasFeeAssetAmount)
 	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
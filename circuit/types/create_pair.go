@@ -0,0 +1,90 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	CreatePairTx: priority op creating the pair of two assets in the liquidity
+	tree, its lp shares are the account asset LpAssetId, FirstLpAssetId + PairIndex
+	of the config. Swaps pay FeeRate of
+	the input, TreasuryRate of it goes to the treasury account and the rest
+	stays in the pool.
+*/
+type CreatePairTx struct {
+	PairIndex            int64
+	AssetAId             int64
+	AssetBId             int64
+	LpAssetId            int64
+	FeeRate              int64
+	TreasuryAccountIndex int64
+	TreasuryRate         int64
+}
+
+type CreatePairTxConstraints struct {
+	PairIndex            Variable
+	AssetAId             Variable
+	AssetBId             Variable
+	LpAssetId            Variable
+	FeeRate              Variable
+	TreasuryAccountIndex Variable
+	TreasuryRate         Variable
+}
+
+func EmptyCreatePairTxWitness() (witness CreatePairTxConstraints) {
+	return CreatePairTxConstraints{
+		PairIndex:            ZeroInt,
+		AssetAId:             ZeroInt,
+		AssetBId:             ZeroInt,
+		LpAssetId:            ZeroInt,
+		FeeRate:              ZeroInt,
+		TreasuryAccountIndex: ZeroInt,
+		TreasuryRate:         ZeroInt,
+	}
+}
+
+func SetCreatePairTxWitness(tx *CreatePairTx) (witness CreatePairTxConstraints) {
+	witness = CreatePairTxConstraints{
+		PairIndex:            tx.PairIndex,
+		AssetAId:             tx.AssetAId,
+		AssetBId:             tx.AssetBId,
+		LpAssetId:            tx.LpAssetId,
+		FeeRate:              tx.FeeRate,
+		TreasuryAccountIndex: tx.TreasuryAccountIndex,
+		TreasuryRate:         tx.TreasuryRate,
+	}
+	return witness
+}
+
+func VerifyCreatePairTx(
+	api API, flag Variable,
+	tx CreatePairTxConstraints,
+	liquidityBefore LiquidityConstraints,
+	firstLpAssetId int64,
+) (pubData [PubDataSizePerTx]Variable) {
+	pubData = CollectPubDataFromCreatePair(api, tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
+	CheckEmptyLiquidityNode(api, flag, liquidityBefore)
+	IsVariableDifferent(api, flag, tx.AssetAId, tx.AssetBId)
+	IsVariableDifferent(api, flag, tx.LpAssetId, tx.AssetAId)
+	IsVariableDifferent(api, flag, tx.LpAssetId, tx.AssetBId)
+	// every pair has its own lp asset id, out of the asset ids Deposit credits
+	IsVariableEqual(api, flag, tx.LpAssetId, api.Add(tx.PairIndex, firstLpAssetId))
+	IsVariableLessOrEqual(api, flag, tx.TreasuryRate, tx.FeeRate)
+	IsVariableLessOrEqual(api, flag, tx.FeeRate, RateBase)
+	return pubData
+}
@@ -57,10 +57,13 @@ func SetDepositTxWitness(tx *DepositTx) (witness DepositTxConstraints) {
 func VerifyDepositTx(
 	api API, flag Variable,
 	tx DepositTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
+	firstLpAssetId int64,
 ) (pubData [PubDataSizePerTx]Variable) {
 	pubData = CollectPubDataFromDeposit(api, tx)
 	// verify params
+	// lp shares are only minted by AddLiquidity
+	IsVariableLess(api, flag, tx.AssetId, firstLpAssetId)
 	IsVariableEqual(api, flag, tx.AccountNameHash, accountsBefore[0].AccountNameHash)
 	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
 	IsVariableEqual(api, flag, tx.AssetId, accountsBefore[0].AssetsInfo[0].AssetId)
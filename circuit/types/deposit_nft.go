@@ -78,7 +78,7 @@ func VerifyDepositNftTx(
 	api API,
 	flag Variable,
 	tx DepositNftTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
 	nftBefore NftConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	pubData = CollectPubDataFromDepositNft(api, tx)
@@ -57,7 +57,7 @@ func SetFullExitTxWitness(tx *FullExitTx) (witness FullExitTxConstraints) {
 func VerifyFullExitTx(
 	api API, flag Variable,
 	tx FullExitTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	pubData = CollectPubDataFromFullExit(api, tx)
 	// verify params
@@ -81,7 +81,7 @@ func SetFullExitNftTxWitness(tx *FullExitNftTx) (witness FullExitNftTxConstraint
 func VerifyFullExitNftTx(
 	api API, flag Variable,
 	tx FullExitNftTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints, nftBefore NftConstraints,
+	accountsBefore []AccountConstraints, nftBefore NftConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	pubData = CollectPubDataFromFullExitNft(api, tx)
 	// verify params
@@ -18,10 +18,7 @@
 package types
 
 import (
-	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
-
-	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
 )
 
 type GasAccount struct {
@@ -38,12 +35,7 @@ func EmptyGasAccount(accountIndex int64, assetRoot []byte) *GasAccount {
 	return &GasAccount{
 		AccountIndex:    accountIndex,
 		AccountNameHash: []byte{},
-		AccountPk: &eddsa.PublicKey{
-			A: curve.Point{
-				X: fr.NewElement(0),
-				Y: fr.NewElement(0),
-			},
-		},
+		AccountPk:       EmptyPubKey(),
 		Nonce:           0,
 		CollectionNonce: 0,
 		AssetRoot:       assetRoot,
@@ -0,0 +1,122 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"hash"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	gmimc "github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/bnb-chain/zkbnb-crypto/hash/poseidon"
+)
+
+/*
+	Hash: in circuit hash of the merkle trees and of their leaves
+*/
+type Hash interface {
+	Write(data ...Variable)
+	Sum() Variable
+	Reset()
+}
+
+/*
+	HashType: hash of the state trees, the tx hashes signed by the users are always MiMC
+*/
+type HashType uint8
+
+const (
+	MiMCHashType HashType = iota
+	PoseidonHashType
+)
+
+func NewHash(api API, hashType HashType) (Hash, error) {
+	switch hashType {
+	case MiMCHashType:
+		h, err := gmimc.NewMiMC(api)
+		if err != nil {
+			return nil, err
+		}
+		return &h, nil
+	case PoseidonHashType:
+		h := NewPoseidon(api)
+		return &h, nil
+	default:
+		log.Println("[NewHash] unknown hash type")
+		return nil, errors.New("[NewHash] unknown hash type")
+	}
+}
+
+/*
+	NewNativeHash: hash.Hash matching NewHash, elements are written as 32 bytes
+*/
+func NewNativeHash(hashType HashType) (hash.Hash, error) {
+	switch hashType {
+	case MiMCHashType:
+		return mimc.NewMiMC(), nil
+	case PoseidonHashType:
+		return poseidon.NewPoseidon(), nil
+	default:
+		log.Println("[NewNativeHash] unknown hash type")
+		return nil, errors.New("[NewNativeHash] unknown hash type")
+	}
+}
+
+type emptyAssetRootKey struct {
+	hashType          HashType
+	assetMerkleLevels int
+}
+
+var (
+	emptyAssetRootsLock sync.Mutex
+	emptyAssetRoots     = make(map[emptyAssetRootKey]*big.Int)
+)
+
+/*
+	EmptyAssetRootOf: root of an empty asset tree of the given depth, EmptyAssetRoot for MiMC on mainnet
+*/
+func EmptyAssetRootOf(hashType HashType, assetMerkleLevels int) (*big.Int, error) {
+	key := emptyAssetRootKey{hashType: hashType, assetMerkleLevels: assetMerkleLevels}
+	emptyAssetRootsLock.Lock()
+	defer emptyAssetRootsLock.Unlock()
+	if root, exist := emptyAssetRoots[key]; exist {
+		return root, nil
+	}
+	hFunc, err := NewNativeHash(hashType)
+	if err != nil {
+		return nil, err
+	}
+	// an empty asset has a zero balance and no offer
+	node := make([]byte, 32)
+	hFunc.Write(node)
+	hFunc.Write(node)
+	node = hFunc.Sum(nil)
+	for i := 0; i < assetMerkleLevels; i++ {
+		hFunc.Reset()
+		hFunc.Write(node)
+		hFunc.Write(node)
+		node = hFunc.Sum(nil)
+	}
+	root := new(big.Int).SetBytes(node)
+	emptyAssetRoots[key] = root
+	return root, nil
+}
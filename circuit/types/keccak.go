@@ -0,0 +1,316 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+const (
+	keccakRounds = 24
+	// bytes absorbed by every permutation of keccak-256
+	keccakRate = 136
+)
+
+var keccakRoundConstants = [keccakRounds]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotation offsets of the rho step, indexed by [x][y]
+var keccakRotations = [5][5]int{
+	{0, 36, 3, 41, 18},
+	{1, 44, 10, 45, 2},
+	{62, 6, 43, 15, 61},
+	{28, 55, 25, 21, 56},
+	{27, 20, 39, 8, 14},
+}
+
+/*
+	keccakBit: bit of the keccak state, the bit is not(v) when neg is set so
+	that the negations of chi and iota don't cost any constraint. v is always
+	a constant or a single variable, Xor only supports those.
+*/
+type keccakBit struct {
+	v   Variable
+	neg bool
+}
+
+/*
+	keccakState: 25 lanes of 64 bits, lane x + 5y, bits of a lane are little endian
+*/
+type keccakState [25][64]keccakBit
+
+func newKeccakState() (state *keccakState) {
+	state = new(keccakState)
+	for i := 0; i < 25; i++ {
+		for j := 0; j < 64; j++ {
+			state[i][j] = keccakBit{v: 0}
+		}
+	}
+	return state
+}
+
+/*
+	Keccak256Words: in-circuit counterpart of the Keccak256 hint, the words are
+	hashed as 32 bytes big endian integers and the digest is returned as a field element
+*/
+func Keccak256Words(api API, words []Variable) Variable {
+	msgBits := make([]Variable, 0, 256*len(words))
+	for _, word := range words {
+		// 254 bits, the two most significant bits of the word are zero
+		wordBits := append(fieldToBits(api, word), 0, 0)
+		for i := 31; i >= 0; i-- {
+			msgBits = append(msgBits, wordBits[8*i:8*i+8]...)
+		}
+	}
+	digestBits := keccak256Bits(api, msgBits)
+	return digestToField(api, digestBits[:])
+}
+
+/*
+	Keccak256Bytes: keccak-256 of the first length bytes of data, the bytes past
+	length are ignored so that messages of different lengths share one circuit.
+	length must be at most len(data), every byte before it must fit in 8 bits.
+*/
+func Keccak256Bytes(api API, data []Variable, length Variable) (digest [32]Variable) {
+	nbBlocks := len(data)/keccakRate + 1
+	// the padding starts at length and ends with the last byte of its block
+	var (
+		isPadStart = make([]Variable, nbBlocks*keccakRate)
+		isLast     = make([]Variable, nbBlocks)
+		isData     = Variable(1)
+		padStarts  = Variable(0)
+	)
+	for i := 0; i < nbBlocks*keccakRate; i++ {
+		if i <= len(data) {
+			isPadStart[i] = api.IsZero(api.Sub(length, i))
+			padStarts = api.Add(padStarts, isPadStart[i])
+		} else {
+			isPadStart[i] = 0
+		}
+	}
+	api.AssertIsEqual(padStarts, 1)
+	for i := 0; i < nbBlocks; i++ {
+		isLast[i] = 0
+		for j := i * keccakRate; j < (i+1)*keccakRate; j++ {
+			isLast[i] = api.Add(isLast[i], isPadStart[j])
+		}
+	}
+
+	state := newKeccakState()
+	var digests [][256]Variable
+	for i := 0; i < nbBlocks; i++ {
+		blockBits := make([]Variable, 0, 8*keccakRate)
+		for j := i * keccakRate; j < (i+1)*keccakRate; j++ {
+			isData = api.Sub(isData, isPadStart[j])
+			value := Variable(0)
+			if j < len(data) {
+				value = api.Select(isData, data[j], 0)
+			}
+			value = api.Add(value, isPadStart[j])
+			if j == (i+1)*keccakRate-1 {
+				value = api.Add(value, api.Mul(isLast[i], 0x80))
+			}
+			blockBits = append(blockBits, api.ToBinary(value, 8)...)
+		}
+		keccakAbsorb(api, state, blockBits)
+		digests = append(digests, keccakSqueeze(api, state))
+	}
+
+	for i := 0; i < 32; i++ {
+		digest[i] = 0
+		for j := 0; j < nbBlocks; j++ {
+			digest[i] = api.Add(digest[i], api.Mul(isLast[j], api.FromBinary(digests[j][8*i:8*i+8]...)))
+		}
+	}
+	return digest
+}
+
+/*
+	keccak256Bits: keccak-256 of a message of whole bytes, bits little endian in every byte
+*/
+func keccak256Bits(api API, msgBits []Variable) [256]Variable {
+	// pad10*1 with the keccak domain, 0x01 first and 0x80 last
+	msgBits = append(msgBits, 1)
+	for len(msgBits)%(8*keccakRate) != 8*keccakRate-1 {
+		msgBits = append(msgBits, 0)
+	}
+	msgBits = append(msgBits, 1)
+
+	state := newKeccakState()
+	for i := 0; i < len(msgBits); i += 8 * keccakRate {
+		keccakAbsorb(api, state, msgBits[i:i+8*keccakRate])
+	}
+	return keccakSqueeze(api, state)
+}
+
+func keccakAbsorb(api API, state *keccakState, blockBits []Variable) {
+	for i := 0; i < len(blockBits); i++ {
+		state[i/64][i%64] = xorBit(api, state[i/64][i%64], keccakBit{v: blockBits[i]})
+	}
+	keccakF(api, state)
+}
+
+/*
+	keccakSqueeze: the first 32 bytes of the state
+*/
+func keccakSqueeze(api API, state *keccakState) (digestBits [256]Variable) {
+	for i := 0; i < 256; i++ {
+		digestBits[i] = state[i/64][i%64].value(api)
+	}
+	return digestBits
+}
+
+/*
+	keccakF: keccak-f[1600] permutation
+*/
+func keccakF(api API, a *keccakState) {
+	var (
+		c [5][64]keccakBit
+		d [5][64]keccakBit
+		b keccakState
+	)
+	for round := 0; round < keccakRounds; round++ {
+		// theta
+		for x := 0; x < 5; x++ {
+			for z := 0; z < 64; z++ {
+				c[x][z] = a[x][z]
+				for y := 1; y < 5; y++ {
+					c[x][z] = xorBit(api, c[x][z], a[x+5*y][z])
+				}
+			}
+		}
+		for x := 0; x < 5; x++ {
+			for z := 0; z < 64; z++ {
+				d[x][z] = xorBit(api, c[(x+4)%5][z], c[(x+1)%5][(z+63)%64])
+			}
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				for z := 0; z < 64; z++ {
+					a[x+5*y][z] = xorBit(api, a[x+5*y][z], d[x][z])
+				}
+			}
+		}
+		// rho and pi
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				for z := 0; z < 64; z++ {
+					b[y+5*((2*x+3*y)%5)][z] = a[x+5*y][(z+64-keccakRotations[x][y])%64]
+				}
+			}
+		}
+		// chi
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				for z := 0; z < 64; z++ {
+					a[x+5*y][z] = chiBit(api, b[x+5*y][z], b[(x+1)%5+5*y][z], b[(x+2)%5+5*y][z])
+				}
+			}
+		}
+		// iota
+		for z := 0; z < 64; z++ {
+			if keccakRoundConstants[round]>>z&1 == 1 {
+				a[0][z].neg = !a[0][z].neg
+			}
+		}
+	}
+}
+
+func (bit keccakBit) value(api API) Variable {
+	if !bit.neg {
+		return bit.v
+	}
+	res := api.Sub(1, bit.v)
+	api.Compiler().MarkBoolean(res)
+	return res
+}
+
+/*
+	xorBit: xor of two bits, constant bits are folded away, the padding and
+	the empty state of the first permutation are mostly constants
+*/
+func xorBit(api API, a, b keccakBit) keccakBit {
+	neg := a.neg != b.neg
+	if v, ok := api.Compiler().ConstantValue(a.v); ok {
+		return keccakBit{v: b.v, neg: neg != (v.Sign() != 0)}
+	}
+	if v, ok := api.Compiler().ConstantValue(b.v); ok {
+		return keccakBit{v: a.v, neg: neg != (v.Sign() != 0)}
+	}
+	return keccakBit{v: api.Xor(a.v, b.v), neg: neg}
+}
+
+/*
+	chiBit: a xor (not(b) and c)
+*/
+func chiBit(api API, a, b, c keccakBit) keccakBit {
+	if v, ok := api.Compiler().ConstantValue(b.v); ok {
+		if (v.Sign() != 0) != b.neg {
+			return a
+		}
+		return xorBit(api, a, c)
+	}
+	if v, ok := api.Compiler().ConstantValue(c.v); ok {
+		if (v.Sign() != 0) == c.neg {
+			return a
+		}
+		return xorBit(api, a, keccakBit{v: b.v, neg: !b.neg})
+	}
+	notB := keccakBit{v: b.v, neg: !b.neg}
+	t := api.Mul(notB.value(api), c.value(api))
+	api.Compiler().MarkBoolean(t)
+	return xorBit(api, a, keccakBit{v: t})
+}
+
+/*
+	fieldToBits: little endian bits of a field element, constrained to be
+	its canonical decomposition so that v + r can't be hashed in place of v
+*/
+func fieldToBits(api API, v Variable) []Variable {
+	bits := api.ToBinary(v, fr.Bits)
+	bound := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	// equal is 1 while the bits above i are the ones of the bound
+	equal := Variable(1)
+	for i := fr.Bits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			equal = api.Mul(equal, bits[i])
+		} else {
+			api.AssertIsEqual(api.Mul(equal, bits[i]), 0)
+		}
+	}
+	return bits
+}
+
+/*
+	digestToField: the digest read as a 32 bytes big endian integer, reduced in the field
+*/
+func digestToField(api API, digestBits []Variable) Variable {
+	bits := make([]Variable, 0, 256)
+	for i := 31; i >= 0; i-- {
+		bits = append(bits, digestBits[8*i:8*i+8]...)
+	}
+	return api.FromBinary(bits...)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type KeccakWordsConstraints struct {
+	Words  []Variable
+	Digest Variable
+}
+
+func (circuit KeccakWordsConstraints) Define(api API) error {
+	api.AssertIsEqual(Keccak256Words(api, circuit.Words), circuit.Digest)
+	return nil
+}
+
+type KeccakBytesConstraints struct {
+	Data   []Variable
+	Length Variable
+	Digest [32]Variable
+}
+
+func (circuit KeccakBytesConstraints) Define(api API) error {
+	digest := Keccak256Bytes(api, circuit.Data, circuit.Length)
+	for i := 0; i < 32; i++ {
+		api.AssertIsEqual(digest[i], circuit.Digest[i])
+	}
+	return nil
+}
+
+func TestKeccak256Words(t *testing.T) {
+	// 1 to 5 words cover one and two permutations
+	for nbWords := 1; nbWords <= 5; nbWords++ {
+		circuit := KeccakWordsConstraints{Words: make([]Variable, nbWords)}
+		witness := KeccakWordsConstraints{Words: make([]Variable, nbWords)}
+		inputs := make([]*big.Int, nbWords)
+		for i := 0; i < nbWords; i++ {
+			inputs[i] = new(big.Int).Sub(fr.Modulus(), big.NewInt(int64(i+1)))
+			witness.Words[i] = inputs[i]
+		}
+		outputs := []*big.Int{new(big.Int)}
+		assert.NoError(t, Keccak256(ecc.BN254, inputs, outputs))
+		witness.Digest = outputs[0]
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "%d words", nbWords)
+		if nbWords == 2 {
+			// the test engine folds every bit as a constant, the compiled circuit doesn't
+			test.NewAssert(t).SolvingSucceeded(&circuit, &witness, test.WithBackends(backend.GROTH16, backend.PLONK), test.WithCurves(ecc.BN254))
+		}
+
+		witness.Digest = new(big.Int).Add(outputs[0], big.NewInt(1))
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "%d words", nbWords)
+	}
+}
+
+func TestKeccak256Bytes(t *testing.T) {
+	maxLength := 2*keccakRate + 1
+	data := make([]byte, maxLength)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	circuit := KeccakBytesConstraints{Data: make([]Variable, maxLength)}
+	// lengths around the rate, where the padding moves to the next block
+	for _, length := range []int{0, 1, keccakRate - 1, keccakRate, keccakRate + 1, 2 * keccakRate, maxLength} {
+		witness := KeccakBytesConstraints{Data: make([]Variable, maxLength), Length: length}
+		for i := 0; i < maxLength; i++ {
+			if i < length {
+				witness.Data[i] = data[i]
+			} else {
+				// ignored past the length, even when it isn't a byte
+				witness.Data[i] = 256
+			}
+		}
+		digest := crypto.Keccak256(data[:length])
+		for i := 0; i < 32; i++ {
+			witness.Digest[i] = digest[i]
+		}
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "length %d", length)
+		if length == keccakRate {
+			test.NewAssert(t).SolvingSucceeded(&circuit, &witness, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+		}
+
+		if length > 0 {
+			witness.Length = length - 1
+			assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "length %d", length)
+		}
+	}
+
+	witness := KeccakBytesConstraints{Data: make([]Variable, maxLength), Length: maxLength + 1}
+	for i := 0; i < maxLength; i++ {
+		witness.Data[i] = data[i]
+	}
+	for i := 0; i < 32; i++ {
+		witness.Digest[i] = 0
+	}
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+)
+
+/*
+	Liquidity: leaf of the liquidity tree, the reserves of a pair and its lp
+	shares. The lp shares are held as the account asset LpAssetId, a pair is
+	created once its asset ids differ.
+*/
+type Liquidity struct {
+	PairIndex            int64
+	AssetAId             int64
+	AssetA               *big.Int
+	AssetBId             int64
+	AssetB               *big.Int
+	LpAssetId            int64
+	LpAmount             *big.Int
+	FeeRate              int64
+	TreasuryAccountIndex int64
+	TreasuryRate         int64
+}
+
+func EmptyLiquidity(pairIndex int64) *Liquidity {
+	return &Liquidity{
+		PairIndex:            pairIndex,
+		AssetAId:             0,
+		AssetA:               big.NewInt(0),
+		AssetBId:             0,
+		AssetB:               big.NewInt(0),
+		LpAssetId:            0,
+		LpAmount:             big.NewInt(0),
+		FeeRate:              0,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         0,
+	}
+}
@@ -78,10 +78,10 @@ func SetMintNftTxWitness(tx *MintNftTx) (witness MintNftTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
 	hFunc.Reset()
 	hFunc.Write(
-		PackInt64Variables(api, ChainId, tx.CreatorAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, chainId, tx.CreatorAccountIndex, nonce, expiredAt),
 		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
 		PackInt64Variables(api, tx.ToAccountIndex, tx.CreatorTreasuryRate, tx.CollectionId),
 		tx.ToAccountNameHash,
@@ -94,7 +94,8 @@ func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, nonce Variable,
 func VerifyMintNftTx(
 	api API, flag Variable,
 	tx *MintNftTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints, nftBefore NftConstraints,
+	accountsBefore []AccountConstraints, nftBefore NftConstraints,
+	collectionBefore CollectionConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	fromAccount := 0
 	toAccount := 1
@@ -116,7 +117,12 @@ func VerifyMintNftTx(
 	// should have enough balance
 	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
 	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
-	// collection id should be less than creator's collection nonce
-	IsVariableLess(api, flag, tx.CollectionId, accountsBefore[fromAccount].CollectionNonce)
+	// anyone can mint without a collection, only the owner of a collection can mint into it
+	// and the nft takes the creator treasury rate of the collection
+	IsVariableEqual(api, flag, tx.CollectionId, collectionBefore.CollectionId)
+	isNoCollection := api.IsZero(api.Sub(tx.CollectionId, NoCollectionId))
+	collectionFlag := api.Select(isNoCollection, 0, flag)
+	CheckCollectionOwner(api, collectionFlag, collectionBefore, tx.CreatorAccountIndex)
+	IsVariableEqual(api, collectionFlag, tx.CreatorTreasuryRate, collectionBefore.CreatorTreasuryRate)
 	return pubData
 }
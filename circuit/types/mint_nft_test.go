@@ -0,0 +1,94 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mintNftCircuit struct {
+	Tx               MintNftTxConstraints
+	AccountsBefore   []AccountConstraints
+	NftBefore        NftConstraints
+	CollectionBefore CollectionConstraints
+}
+
+func (circuit mintNftCircuit) Define(api API) error {
+	VerifyMintNftTx(api, 1, &circuit.Tx, circuit.AccountsBefore, circuit.NftBefore, circuit.CollectionBefore)
+	return nil
+}
+
+func TestMintNftCollectionOwner(t *testing.T) {
+	const (
+		alice        = 2
+		bob          = 3
+		collectionId = 5
+	)
+	mintNftWitness := func(creatorAccountIndex int64, collection *Collection) mintNftCircuit {
+		witness := mintNftCircuit{
+			Tx: SetMintNftTxWitness(&MintNftTx{
+				CreatorAccountIndex: creatorAccountIndex,
+				ToAccountIndex:      creatorAccountIndex,
+				ToAccountNameHash:   []byte{},
+				NftContentHash:      []byte{1},
+				CreatorTreasuryRate: 100,
+				GasFeeAssetAmount:   0,
+				CollectionId:        collection.CollectionId,
+			}),
+			AccountsBefore: make([]AccountConstraints, NbAccountsPerTx),
+		}
+		var err error
+		for i := range witness.AccountsBefore {
+			witness.AccountsBefore[i], err = SetAccountWitness(EmptyAccount(creatorAccountIndex, make([]byte, 32), TestConfig))
+			require.NoError(t, err)
+		}
+		witness.NftBefore, err = SetNftWitness(EmptyNft(0))
+		require.NoError(t, err)
+		witness.CollectionBefore, err = SetCollectionWitness(collection)
+		require.NoError(t, err)
+		return witness
+	}
+	// collection created by alice
+	collection := &Collection{
+		CollectionId: collectionId, OwnerAccountIndex: alice, MetadataHash: []byte{1}, CreatorTreasuryRate: 100,
+	}
+
+	circuit := mintNftWitness(alice, collection)
+	witness := mintNftWitness(alice, collection)
+	assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// bob can't mint into alice's collection
+	witness = mintNftWitness(bob, collection)
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// nor into a collection that doesn't exist
+	witness = mintNftWitness(bob, EmptyCollection(collectionId))
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// the nft takes the creator treasury rate of the collection
+	otherRate := *collection
+	otherRate.CreatorTreasuryRate = 250
+	witness = mintNftWitness(alice, &otherRate)
+	assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+	// anyone can mint without a collection
+	witness = mintNftWitness(bob, EmptyCollection(NoCollectionId))
+	assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16))
+}
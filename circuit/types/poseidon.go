@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/bnb-chain/zkbnb-crypto/hash/poseidon"
+)
+
+/*
+	Poseidon: in circuit poseidon hash, it hashes the written elements like poseidon.NewPoseidon
+	hashes the same elements written as 32 bytes
+*/
+type Poseidon struct {
+	api  API
+	data []Variable
+}
+
+func NewPoseidon(api API) Poseidon {
+	return Poseidon{api: api}
+}
+
+func (h *Poseidon) Write(data ...Variable) {
+	h.data = append(h.data, data...)
+}
+
+func (h *Poseidon) Reset() {
+	h.data = nil
+}
+
+func (h *Poseidon) Sum() Variable {
+	return PoseidonHash(h.api, h.data...)
+}
+
+/*
+	PoseidonHash: same as poseidon.Hash
+*/
+func PoseidonHash(api API, inputs ...Variable) Variable {
+	if len(inputs) == 0 {
+		inputs = []Variable{0}
+	}
+	n := len(inputs)
+	if n > poseidon.MaxInputs {
+		n = poseidon.MaxInputs
+	}
+	state := make([]Variable, n+1)
+	state[0] = 0
+	copy(state[1:], inputs[:n])
+	poseidonPermute(api, state)
+	h := state[0]
+	for inputs = inputs[n:]; len(inputs) > 0; inputs = inputs[n:] {
+		n = len(inputs)
+		if n > poseidon.MaxInputs-1 {
+			n = poseidon.MaxInputs - 1
+		}
+		state = make([]Variable, n+2)
+		state[0] = 0
+		state[1] = h
+		copy(state[2:], inputs[:n])
+		poseidonPermute(api, state)
+		h = state[0]
+	}
+	return h
+}
+
+func poseidonPermute(api API, state []Variable) {
+	p := poseidon.GetParams(len(state))
+	t := p.T
+	mds := make([][]*big.Int, t)
+	for i := range mds {
+		mds[i] = make([]*big.Int, t)
+		for j := range mds[i] {
+			mds[i][j] = p.MDS[i][j].ToBigIntRegular(new(big.Int))
+		}
+	}
+	nbRounds := p.NbFullRounds + p.NbPartialRounds
+	for r := 0; r < nbRounds; r++ {
+		for i := range state {
+			state[i] = api.Add(state[i], p.RoundConstants[r*t+i].ToBigIntRegular(new(big.Int)))
+		}
+		if r < p.NbFullRounds/2 || r >= p.NbFullRounds/2+p.NbPartialRounds {
+			for i := range state {
+				state[i] = poseidonSbox(api, state[i])
+			}
+		} else {
+			state[0] = poseidonSbox(api, state[0])
+		}
+		mixed := make([]Variable, t)
+		for i := range mixed {
+			mixed[i] = api.Mul(mds[i][0], state[0])
+			for j := 1; j < t; j++ {
+				mixed[i] = api.Add(mixed[i], api.Mul(mds[i][j], state[j]))
+			}
+		}
+		copy(state, mixed)
+	}
+}
+
+func poseidonSbox(api API, x Variable) Variable {
+	x2 := api.Mul(x, x)
+	x4 := api.Mul(x2, x2)
+	return api.Mul(x4, x)
+}
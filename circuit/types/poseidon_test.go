@@ -0,0 +1,149 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/hash/poseidon"
+	"github.com/bnb-chain/zkbnb-crypto/merkleTree"
+)
+
+type HashConstraints struct {
+	HashType HashType
+	Inputs   []Variable
+	Digest   Variable
+}
+
+func (circuit HashConstraints) Define(api API) error {
+	h, err := NewHash(api, circuit.HashType)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.Inputs...)
+	api.AssertIsEqual(h.Sum(), circuit.Digest)
+	return nil
+}
+
+func TestPoseidonHash(t *testing.T) {
+	// a single permutation and chained permutations
+	for _, nbInputs := range []int{1, 2, 6, 20} {
+		circuit := HashConstraints{HashType: PoseidonHashType, Inputs: make([]Variable, nbInputs)}
+		witness := HashConstraints{HashType: PoseidonHashType, Inputs: make([]Variable, nbInputs)}
+		hFunc := poseidon.NewPoseidon()
+		for i := 0; i < nbInputs; i++ {
+			input := new(big.Int).Sub(fr.Modulus(), big.NewInt(int64(i+1)))
+			witness.Inputs[i] = input
+			hFunc.Write(input.FillBytes(make([]byte, 32)))
+		}
+		digest := hFunc.Sum(nil)
+		witness.Digest = digest
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "%d inputs", nbInputs)
+
+		witness.Digest = new(big.Int).Add(new(big.Int).SetBytes(digest), big.NewInt(1))
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "%d inputs", nbInputs)
+	}
+}
+
+func TestHashConstraintsCount(t *testing.T) {
+	nbConstraints := make(map[HashType]int)
+	for _, hashType := range []HashType{MiMCHashType, PoseidonHashType} {
+		circuit := HashConstraints{HashType: hashType, Inputs: make([]Variable, 2)}
+		ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+		assert.NoError(t, err)
+		nbConstraints[hashType] = ccs.GetNbConstraints()
+	}
+	t.Logf("constraints of a merkle node: mimc %d, poseidon %d", nbConstraints[MiMCHashType], nbConstraints[PoseidonHashType])
+	assert.Less(t, nbConstraints[PoseidonHashType], nbConstraints[MiMCHashType])
+
+	_, err := NewNativeHash(HashType(2))
+	assert.Error(t, err)
+}
+
+type MerkleProofConstraints struct {
+	HashType HashType
+	Root     Variable
+	Leaf     Variable
+	Proof    []Variable
+	Helper   []Variable
+}
+
+func (circuit MerkleProofConstraints) Define(api API) error {
+	h, err := NewHash(api, circuit.HashType)
+	if err != nil {
+		return err
+	}
+	VerifyMerkleProof(api, 1, h, circuit.Root, circuit.Leaf, circuit.Proof, circuit.Helper)
+	return nil
+}
+
+func TestVerifyMerkleProofHashes(t *testing.T) {
+	const height = 8
+	for _, hashType := range []HashType{MiMCHashType, PoseidonHashType} {
+		hFunc, err := NewNativeHash(hashType)
+		assert.NoError(t, err)
+		tree, err := merkleTree.NewEmptyTree(height, make([]byte, 32), hFunc)
+		assert.NoError(t, err)
+		leaves := make([][]byte, 4)
+		for i := range leaves {
+			leaves[i] = big.NewInt(int64(i + 1)).FillBytes(make([]byte, 32))
+		}
+		for i, leaf := range leaves {
+			assert.NoError(t, tree.Update(int64(i*37), leaf))
+		}
+		proof, helper, err := tree.BuildMerkleProofs(37)
+		assert.NoError(t, err)
+
+		circuit := MerkleProofConstraints{HashType: hashType, Proof: make([]Variable, height), Helper: make([]Variable, height)}
+		witness := MerkleProofConstraints{
+			HashType: hashType,
+			Root:     tree.RootNode.Value,
+			Leaf:     leaves[1],
+			Proof:    make([]Variable, height),
+			Helper:   make([]Variable, height),
+		}
+		for i := 0; i < height; i++ {
+			witness.Proof[i] = proof[i]
+			witness.Helper[i] = helper[i]
+		}
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "hash %d", hashType)
+		witness.Leaf = leaves[0]
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "hash %d", hashType)
+	}
+}
+
+func TestEmptyAssetRootOf(t *testing.T) {
+	root, err := EmptyAssetRootOf(MiMCHashType, AssetMerkleLevels)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, root.Cmp(EmptyAssetRoot))
+	root, err = EmptyAssetRootOf(PoseidonHashType, AssetMerkleLevels)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, root.Cmp(EmptyAssetRoot))
+	root, err = EmptyAssetRootOf(MiMCHashType, TestConfig.AssetMerkleLevels)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, root.Cmp(EmptyAssetRoot))
+}
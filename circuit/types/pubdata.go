@@ -0,0 +1,491 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+/*
+	pubDataWriter: native counterpart of CollectPubDataFrom*, values written to
+	a chunk are packed from the most significant bits down, like the bits
+	those helpers append in front of each other
+*/
+type pubDataWriter struct {
+	pubData   [PubDataSizePerTx]*big.Int
+	count     int
+	chunk     *big.Int
+	chunkSize int
+	err       error
+}
+
+func newPubDataWriter() *pubDataWriter {
+	return &pubDataWriter{chunk: big.NewInt(0)}
+}
+
+/*
+	write: append a value of bitsSize bits to the current chunk, the circuit
+	can't decompose values that don't fit so they are rejected here as well
+*/
+func (w *pubDataWriter) write(x interface{}, bitsSize int) {
+	if w.err != nil {
+		return
+	}
+	value, err := ToFieldElement(x)
+	if err != nil {
+		w.err = err
+		return
+	}
+	if value.BitLen() > bitsSize {
+		log.Println("[pubDataWriter] value doesn't fit in", bitsSize, "bits")
+		w.err = fmt.Errorf("[pubDataWriter] %s doesn't fit in %d bits", value.String(), bitsSize)
+		return
+	}
+	w.chunk.Lsh(w.chunk, uint(bitsSize))
+	w.chunk.Or(w.chunk, value)
+	w.chunkSize += bitsSize
+}
+
+func (w *pubDataWriter) pad(bitsSize int) {
+	w.write(0, bitsSize)
+}
+
+/*
+	next: close the current chunk, it takes the next pub data word
+*/
+func (w *pubDataWriter) next() {
+	if w.err != nil || w.chunkSize == 0 {
+		return
+	}
+	// FromBinary reduces the chunk into the field
+	value := new(fr.Element).SetBigInt(w.chunk)
+	w.append(value.ToBigIntRegular(new(big.Int)))
+	w.chunk = big.NewInt(0)
+	w.chunkSize = 0
+}
+
+/*
+	word: close the current chunk and take a whole word for a field element
+*/
+func (w *pubDataWriter) word(x interface{}) {
+	w.next()
+	if w.err != nil {
+		return
+	}
+	value, err := ToFieldElement(x)
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.append(value)
+}
+
+func (w *pubDataWriter) append(value *big.Int) {
+	if w.count == PubDataSizePerTx {
+		log.Println("[pubDataWriter] pub data is full")
+		w.err = errors.New("[pubDataWriter] pub data is full")
+		return
+	}
+	w.pubData[w.count] = value
+	w.count++
+}
+
+/*
+	result: the pub data words, unused words are zero
+*/
+func (w *pubDataWriter) result() (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w.next()
+	if w.err != nil {
+		return pubData, w.err
+	}
+	for i := 0; i < PubDataSizePerTx; i++ {
+		if w.pubData[i] == nil {
+			w.pubData[i] = big.NewInt(0)
+		}
+	}
+	return w.pubData, nil
+}
+
+/*
+	ToFieldElement: the field element a witness value is assigned to, in regular form
+*/
+func ToFieldElement(x interface{}) (*big.Int, error) {
+	var e fr.Element
+	if _, err := e.SetInterface(x); err != nil {
+		log.Println("[ToFieldElement] invalid value:", err)
+		return nil, err
+	}
+	return e.ToBigIntRegular(new(big.Int)), nil
+}
+
+func ComputePubDataFromRegisterZNS(tx *RegisterZnsTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	if tx.PubKey == nil {
+		log.Println("[ComputePubDataFromRegisterZNS] invalid public key")
+		return pubData, errors.New("[ComputePubDataFromRegisterZNS] invalid public key")
+	}
+	w := newPubDataWriter()
+	w.write(TxTypeRegisterZns, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.pad(216)
+	w.word(tx.AccountName)
+	w.word(tx.AccountNameHash)
+	w.word(&tx.PubKey.A.X)
+	w.word(&tx.PubKey.A.Y)
+	return w.result()
+}
+
+func ComputePubDataFromDeposit(tx *DepositTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeDeposit, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.AssetId, AssetIdBitsSize)
+	w.write(tx.AssetAmount, StateAmountBitsSize)
+	w.pad(72)
+	w.word(tx.AccountNameHash)
+	return w.result()
+}
+
+func ComputePubDataFromDepositNft(tx *DepositNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeDepositNft, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.NftL1Address, AddressBitsSize)
+	w.pad(16)
+	w.next()
+	w.write(tx.CreatorAccountIndex, AccountIndexBitsSize)
+	w.write(tx.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.word(tx.NftContentHash)
+	w.word(tx.NftL1TokenId)
+	w.word(tx.AccountNameHash)
+	return w.result()
+}
+
+func ComputePubDataFromTransfer(tx *TransferTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeTransfer, TxTypeBitsSize)
+	w.write(tx.FromAccountIndex, AccountIndexBitsSize)
+	w.write(tx.ToAccountIndex, AccountIndexBitsSize)
+	w.write(tx.AssetId, AssetIdBitsSize)
+	w.write(tx.AssetAmount, PackedAmountBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(64)
+	w.word(tx.CallDataHash)
+	return w.result()
+}
+
+func ComputePubDataFromWithdraw(tx *WithdrawTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeWithdraw, TxTypeBitsSize)
+	w.write(tx.FromAccountIndex, AccountIndexBitsSize)
+	w.write(tx.ToAddress, AddressBitsSize)
+	w.write(tx.AssetId, AssetIdBitsSize)
+	w.pad(40)
+	w.next()
+	w.write(tx.AssetAmount, StateAmountBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	return w.result()
+}
+
+func ComputePubDataFromCreateCollection(tx *CreateCollectionTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeCreateCollection, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.write(tx.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
+	w.pad(120)
+	w.word(tx.MetadataHash)
+	return w.result()
+}
+
+func ComputePubDataFromMintNft(tx *MintNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeMintNft, TxTypeBitsSize)
+	w.write(tx.CreatorAccountIndex, AccountIndexBitsSize)
+	w.write(tx.ToAccountIndex, AccountIndexBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.write(tx.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.pad(48)
+	w.word(tx.NftContentHash)
+	return w.result()
+}
+
+func ComputePubDataFromTransferNft(tx *TransferNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeTransferNft, TxTypeBitsSize)
+	w.write(tx.FromAccountIndex, AccountIndexBitsSize)
+	w.write(tx.ToAccountIndex, AccountIndexBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(80)
+	w.word(tx.CallDataHash)
+	return w.result()
+}
+
+func ComputePubDataFromAtomicMatch(tx *AtomicMatchTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	if tx.BuyOffer == nil || tx.SellOffer == nil {
+		log.Println("[ComputePubDataFromAtomicMatch] invalid offers")
+		return pubData, errors.New("[ComputePubDataFromAtomicMatch] invalid offers")
+	}
+	w := newPubDataWriter()
+	w.write(TxTypeAtomicMatch, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.BuyOffer.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.BuyOffer.OfferId, OfferIdBitsSize)
+	w.write(tx.SellOffer.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.SellOffer.OfferId, OfferIdBitsSize)
+	w.write(tx.BuyOffer.NftIndex, NftIndexBitsSize)
+	w.write(tx.SellOffer.AssetId, AssetIdBitsSize)
+	w.pad(48)
+	w.next()
+	w.write(tx.SellOffer.AssetAmount, PackedAmountBitsSize)
+	w.write(tx.CreatorAmount, PackedAmountBitsSize)
+	w.write(tx.TreasuryAmount, PackedAmountBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	return w.result()
+}
+
+func ComputePubDataFromCancelOffer(tx *CancelOfferTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeCancelOffer, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.OfferId, OfferIdBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(128)
+	return w.result()
+}
+
+func ComputePubDataFromWithdrawNft(tx *WithdrawNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeWithdrawNft, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.CreatorAccountIndex, AccountIndexBitsSize)
+	w.write(tx.CreatorTreasuryRate, FeeRateBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.pad(112)
+	w.word(tx.NftL1Address)
+	w.write(tx.ToAddress, AddressBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.word(tx.NftContentHash)
+	w.word(tx.NftL1TokenId)
+	w.word(tx.CreatorAccountNameHash)
+	return w.result()
+}
+
+func ComputePubDataFromFullExit(tx *FullExitTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeFullExit, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.AssetId, AssetIdBitsSize)
+	w.write(tx.AssetAmount, StateAmountBitsSize)
+	w.pad(72)
+	w.word(tx.AccountNameHash)
+	return w.result()
+}
+
+func ComputePubDataFromFullExitNft(tx *FullExitNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeFullExitNft, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.CreatorAccountIndex, AccountIndexBitsSize)
+	w.write(tx.CreatorTreasuryRate, FeeRateBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.pad(112)
+	w.word(tx.NftL1Address)
+	w.word(tx.AccountNameHash)
+	w.word(tx.CreatorAccountNameHash)
+	w.word(tx.NftContentHash)
+	w.word(tx.NftL1TokenId)
+	return w.result()
+}
+
+func ComputePubDataFromChangePubKey(tx *ChangePubKeyTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	if tx.PubKey == nil {
+		log.Println("[ComputePubDataFromChangePubKey] invalid public key")
+		return pubData, errors.New("[ComputePubDataFromChangePubKey] invalid public key")
+	}
+	w := newPubDataWriter()
+	w.write(TxTypeChangePubKey, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.IsPriorityOp, PriorityOpFlagBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(144)
+	w.word(tx.AccountNameHash)
+	w.word(&tx.PubKey.A.X)
+	w.word(&tx.PubKey.A.Y)
+	return w.result()
+}
+
+func ComputePubDataFromBatchTransfer(tx *BatchTransferTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeBatchTransfer, TxTypeBitsSize)
+	w.write(tx.FromAccountIndex, AccountIndexBitsSize)
+	w.write(tx.AssetId, AssetIdBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(136)
+	w.next()
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		w.write(tx.ToAccountIndexes[i], AccountIndexBitsSize)
+		w.write(tx.AssetAmounts[i], PackedAmountBitsSize)
+	}
+	w.pad(40)
+	w.word(tx.CallDataHash)
+	return w.result()
+}
+
+func ComputePubDataFromCreatePair(tx *CreatePairTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeCreatePair, TxTypeBitsSize)
+	w.write(tx.PairIndex, PairIndexBitsSize)
+	w.write(tx.AssetAId, AssetIdBitsSize)
+	w.write(tx.AssetBId, AssetIdBitsSize)
+	w.write(tx.LpAssetId, AssetIdBitsSize)
+	w.write(tx.FeeRate, FeeRateBitsSize)
+	w.write(tx.TreasuryAccountIndex, AccountIndexBitsSize)
+	w.write(tx.TreasuryRate, FeeRateBitsSize)
+	w.pad(120)
+	return w.result()
+}
+
+func ComputePubDataFromSwap(tx *SwapTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.writeLiquidityTxHeader(
+		TxTypeSwap, tx.FromAccountIndex, tx.PairIndex, tx.AssetAId, tx.AssetBId,
+		tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount,
+	)
+	w.writeLiquidityAmounts(tx.AssetAAmount, tx.AssetBAmountDelta, tx.TreasuryAmount)
+	return w.result()
+}
+
+func ComputePubDataFromAddLiquidity(tx *AddLiquidityTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.writeLiquidityTxHeader(
+		TxTypeAddLiquidity, tx.FromAccountIndex, tx.PairIndex, tx.AssetAId, tx.AssetBId,
+		tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount,
+	)
+	w.writeLiquidityAmounts(tx.AssetAAmount, tx.AssetBAmount, tx.LpAmount)
+	return w.result()
+}
+
+func ComputePubDataFromRemoveLiquidity(tx *RemoveLiquidityTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.writeLiquidityTxHeader(
+		TxTypeRemoveLiquidity, tx.FromAccountIndex, tx.PairIndex, tx.AssetAId, tx.AssetBId,
+		tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount,
+	)
+	w.writeLiquidityAmounts(tx.AssetAAmountDelta, tx.AssetBAmountDelta, tx.LpAmount)
+	return w.result()
+}
+
+/*
+	writeLiquidityTxHeader: first word of swap and liquidity txs, see collectPubDataFromLiquidityTx
+*/
+func (w *pubDataWriter) writeLiquidityTxHeader(
+	txType int, fromAccountIndex, pairIndex, assetAId, assetBId int64,
+	gasAccountIndex, gasFeeAssetId, gasFeeAssetAmount int64,
+) {
+	w.write(txType, TxTypeBitsSize)
+	w.write(fromAccountIndex, AccountIndexBitsSize)
+	w.write(pairIndex, PairIndexBitsSize)
+	w.write(assetAId, AssetIdBitsSize)
+	w.write(assetBId, AssetIdBitsSize)
+	w.write(gasAccountIndex, AccountIndexBitsSize)
+	w.write(gasFeeAssetId, AssetIdBitsSize)
+	w.write(gasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(104)
+	w.next()
+}
+
+func (w *pubDataWriter) writeLiquidityAmounts(amountA, amountB, amountC *big.Int) {
+	w.write(amountA, LiquidityAmountBitsSize)
+	w.write(amountB, LiquidityAmountBitsSize)
+	w.pad(32)
+	w.next()
+	w.write(amountC, LiquidityAmountBitsSize)
+	w.pad(144)
+}
+
+func ComputePubDataFromBurnNft(tx *BurnNftTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeBurnNft, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.NftIndex, NftIndexBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(112)
+	return w.result()
+}
+
+func ComputePubDataFromUpdateCollection(tx *UpdateCollectionTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeUpdateCollection, TxTypeBitsSize)
+	w.write(tx.AccountIndex, AccountIndexBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.write(tx.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
+	w.pad(120)
+	w.word(tx.MetadataHash)
+	return w.result()
+}
+
+func ComputePubDataFromTransferCollection(tx *TransferCollectionTx) (pubData [PubDataSizePerTx]*big.Int, err error) {
+	w := newPubDataWriter()
+	w.write(TxTypeTransferCollection, TxTypeBitsSize)
+	w.write(tx.FromAccountIndex, AccountIndexBitsSize)
+	w.write(tx.ToAccountIndex, AccountIndexBitsSize)
+	w.write(tx.CollectionId, CollectionIdBitsSize)
+	w.write(tx.GasAccountIndex, AccountIndexBitsSize)
+	w.write(tx.GasFeeAssetId, AssetIdBitsSize)
+	w.write(tx.GasFeeAssetAmount, PackedFeeBitsSize)
+	w.pad(104)
+	return w.result()
+}
@@ -151,18 +151,21 @@ func CollectPubDataFromCreateCollection(api API, txInfo CreateCollectionTxConstr
 	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
 	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
 	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	creatorTreasuryRateBits := api.ToBinary(txInfo.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
 	ABits := append(accountIndexBits, txTypeBits...)
 	ABits = append(collectionIdBits, ABits...)
 	ABits = append(gasAccountIndexBits, ABits...)
 	ABits = append(gasFeeAssetIdBits, ABits...)
 	ABits = append(gasFeeAssetAmountBits, ABits...)
-	var paddingSize [136]Variable
-	for i := 0; i < 136; i++ {
+	ABits = append(creatorTreasuryRateBits, ABits...)
+	var paddingSize [120]Variable
+	for i := 0; i < 120; i++ {
 		paddingSize[i] = 0
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
+	pubData[1] = txInfo.MetadataHash
+	for i := 2; i < PubDataSizePerTx; i++ {
 		pubData[i] = 0
 	}
 	return pubData
@@ -369,3 +372,252 @@ func CollectPubDataFromFullExitNft(api API, txInfo FullExitNftTxConstraints) (pu
 	pubData[5] = txInfo.NftL1TokenId
 	return pubData
 }
+
+func CollectPubDataFromChangePubKey(api API, txInfo ChangePubKeyTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeChangePubKey, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	isPriorityOpBits := api.ToBinary(txInfo.IsPriorityOp, PriorityOpFlagBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	ABits = append(isPriorityOpBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [144]Variable
+	for i := 0; i < 144; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = txInfo.AccountNameHash
+	pubData[2] = txInfo.PubKey.A.X
+	pubData[3] = txInfo.PubKey.A.Y
+	for i := 4; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromBatchTransfer(api API, txInfo BatchTransferTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeBatchTransfer, TxTypeBitsSize)
+	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
+	assetIdBits := api.ToBinary(txInfo.AssetId, AssetIdBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(fromAccountIndexBits, txTypeBits...)
+	ABits = append(assetIdBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [136]Variable
+	for i := 0; i < 136; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	// recipients
+	var BBits []Variable
+	for i := 0; i < NbBatchTransferRecipients; i++ {
+		toAccountIndexBits := api.ToBinary(txInfo.ToAccountIndexes[i], AccountIndexBitsSize)
+		assetAmountBits := api.ToBinary(txInfo.AssetAmounts[i], PackedAmountBitsSize)
+		BBits = append(toAccountIndexBits, BBits...)
+		BBits = append(assetAmountBits, BBits...)
+	}
+	var recipientsPaddingSize [40]Variable
+	for i := 0; i < 40; i++ {
+		recipientsPaddingSize[i] = 0
+	}
+	BBits = append(recipientsPaddingSize[:], BBits...)
+	pubData[1] = api.FromBinary(BBits...)
+	pubData[2] = txInfo.CallDataHash
+	for i := 3; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromCreatePair(api API, txInfo CreatePairTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeCreatePair, TxTypeBitsSize)
+	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
+	assetAIdBits := api.ToBinary(txInfo.AssetAId, AssetIdBitsSize)
+	assetBIdBits := api.ToBinary(txInfo.AssetBId, AssetIdBitsSize)
+	lpAssetIdBits := api.ToBinary(txInfo.LpAssetId, AssetIdBitsSize)
+	feeRateBits := api.ToBinary(txInfo.FeeRate, FeeRateBitsSize)
+	treasuryAccountIndexBits := api.ToBinary(txInfo.TreasuryAccountIndex, AccountIndexBitsSize)
+	treasuryRateBits := api.ToBinary(txInfo.TreasuryRate, FeeRateBitsSize)
+	ABits := append(pairIndexBits, txTypeBits...)
+	ABits = append(assetAIdBits, ABits...)
+	ABits = append(assetBIdBits, ABits...)
+	ABits = append(lpAssetIdBits, ABits...)
+	ABits = append(feeRateBits, ABits...)
+	ABits = append(treasuryAccountIndexBits, ABits...)
+	ABits = append(treasuryRateBits, ABits...)
+	var paddingSize [120]Variable
+	for i := 0; i < 120; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	for i := 1; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromSwap(api API, txInfo SwapTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	return collectPubDataFromLiquidityTx(
+		api, TxTypeSwap, txInfo.FromAccountIndex, txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId,
+		txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount,
+		txInfo.AssetAAmount, txInfo.AssetBAmountDelta, txInfo.TreasuryAmount,
+	)
+}
+
+func CollectPubDataFromAddLiquidity(api API, txInfo AddLiquidityTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	return collectPubDataFromLiquidityTx(
+		api, TxTypeAddLiquidity, txInfo.FromAccountIndex, txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId,
+		txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount,
+		txInfo.AssetAAmount, txInfo.AssetBAmount, txInfo.LpAmount,
+	)
+}
+
+func CollectPubDataFromRemoveLiquidity(api API, txInfo RemoveLiquidityTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	return collectPubDataFromLiquidityTx(
+		api, TxTypeRemoveLiquidity, txInfo.FromAccountIndex, txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId,
+		txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount,
+		txInfo.AssetAAmountDelta, txInfo.AssetBAmountDelta, txInfo.LpAmount,
+	)
+}
+
+/*
+	collectPubDataFromLiquidityTx: swap and liquidity txs share their layout, the
+	header then two uint112 amounts and the third one in the next word. The
+	decomposition of the amounts range checks them.
+*/
+func collectPubDataFromLiquidityTx(
+	api API, txType int,
+	fromAccountIndex, pairIndex, assetAId, assetBId Variable,
+	gasAccountIndex, gasFeeAssetId, gasFeeAssetAmount Variable,
+	amountA, amountB, amountC Variable,
+) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(txType, TxTypeBitsSize)
+	fromAccountIndexBits := api.ToBinary(fromAccountIndex, AccountIndexBitsSize)
+	pairIndexBits := api.ToBinary(pairIndex, PairIndexBitsSize)
+	assetAIdBits := api.ToBinary(assetAId, AssetIdBitsSize)
+	assetBIdBits := api.ToBinary(assetBId, AssetIdBitsSize)
+	gasAccountIndexBits := api.ToBinary(gasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(gasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(gasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(fromAccountIndexBits, txTypeBits...)
+	ABits = append(pairIndexBits, ABits...)
+	ABits = append(assetAIdBits, ABits...)
+	ABits = append(assetBIdBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [104]Variable
+	for i := 0; i < 104; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	amountABits := api.ToBinary(amountA, LiquidityAmountBitsSize)
+	amountBBits := api.ToBinary(amountB, LiquidityAmountBitsSize)
+	amountCBits := api.ToBinary(amountC, LiquidityAmountBitsSize)
+	BBits := append(amountBBits, amountABits...)
+	var amountsPaddingSize [32]Variable
+	for i := 0; i < 32; i++ {
+		amountsPaddingSize[i] = 0
+	}
+	BBits = append(amountsPaddingSize[:], BBits...)
+	pubData[1] = api.FromBinary(BBits...)
+	var amountPaddingSize [144]Variable
+	for i := 0; i < 144; i++ {
+		amountPaddingSize[i] = 0
+	}
+	CBits := append(amountPaddingSize[:], amountCBits...)
+	pubData[2] = api.FromBinary(CBits...)
+	for i := 3; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromBurnNft(api API, txInfo BurnNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeBurnNft, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	nftIndexBits := api.ToBinary(txInfo.NftIndex, NftIndexBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	ABits = append(nftIndexBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [112]Variable
+	for i := 0; i < 112; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	for i := 1; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromUpdateCollection(api API, txInfo UpdateCollectionTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeUpdateCollection, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	collectionIdBits := api.ToBinary(txInfo.CollectionId, CollectionIdBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	creatorTreasuryRateBits := api.ToBinary(txInfo.CreatorTreasuryRate, CreatorTreasuryRateBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	ABits = append(collectionIdBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	ABits = append(creatorTreasuryRateBits, ABits...)
+	var paddingSize [120]Variable
+	for i := 0; i < 120; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = txInfo.MetadataHash
+	for i := 2; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
+
+func CollectPubDataFromTransferCollection(api API, txInfo TransferCollectionTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+	txTypeBits := api.ToBinary(TxTypeTransferCollection, TxTypeBitsSize)
+	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
+	toAccountIndexBits := api.ToBinary(txInfo.ToAccountIndex, AccountIndexBitsSize)
+	collectionIdBits := api.ToBinary(txInfo.CollectionId, CollectionIdBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(fromAccountIndexBits, txTypeBits...)
+	ABits = append(toAccountIndexBits, ABits...)
+	ABits = append(collectionIdBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [104]Variable
+	for i := 0; i < 104; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	for i := 1; i < PubDataSizePerTx; i++ {
+		pubData[i] = 0
+	}
+	return pubData
+}
@@ -0,0 +1,359 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PubDataConstraints struct {
+	TxType                   int
+	RegisterZnsTxInfo        RegisterZnsTxConstraints
+	DepositTxInfo            DepositTxConstraints
+	DepositNftTxInfo         DepositNftTxConstraints
+	TransferTxInfo           TransferTxConstraints
+	WithdrawTxInfo           WithdrawTxConstraints
+	CreateCollectionTxInfo   CreateCollectionTxConstraints
+	MintNftTxInfo            MintNftTxConstraints
+	TransferNftTxInfo        TransferNftTxConstraints
+	AtomicMatchTxInfo        AtomicMatchTxConstraints
+	CancelOfferTxInfo        CancelOfferTxConstraints
+	WithdrawNftTxInfo        WithdrawNftTxConstraints
+	FullExitTxInfo           FullExitTxConstraints
+	FullExitNftTxInfo        FullExitNftTxConstraints
+	ChangePubKeyTxInfo       ChangePubKeyTxConstraints
+	BatchTransferTxInfo      BatchTransferTxConstraints
+	CreatePairTxInfo         CreatePairTxConstraints
+	SwapTxInfo               SwapTxConstraints
+	AddLiquidityTxInfo       AddLiquidityTxConstraints
+	RemoveLiquidityTxInfo    RemoveLiquidityTxConstraints
+	BurnNftTxInfo            BurnNftTxConstraints
+	UpdateCollectionTxInfo   UpdateCollectionTxConstraints
+	TransferCollectionTxInfo TransferCollectionTxConstraints
+	PubData                  [PubDataSizePerTx]Variable
+}
+
+func (circuit PubDataConstraints) Define(api API) error {
+	var pubData [PubDataSizePerTx]Variable
+	switch circuit.TxType {
+	case TxTypeRegisterZns:
+		pubData = CollectPubDataFromRegisterZNS(api, circuit.RegisterZnsTxInfo)
+	case TxTypeDeposit:
+		pubData = CollectPubDataFromDeposit(api, circuit.DepositTxInfo)
+	case TxTypeDepositNft:
+		pubData = CollectPubDataFromDepositNft(api, circuit.DepositNftTxInfo)
+	case TxTypeTransfer:
+		pubData = CollectPubDataFromTransfer(api, circuit.TransferTxInfo)
+	case TxTypeWithdraw:
+		pubData = CollectPubDataFromWithdraw(api, circuit.WithdrawTxInfo)
+	case TxTypeCreateCollection:
+		pubData = CollectPubDataFromCreateCollection(api, circuit.CreateCollectionTxInfo)
+	case TxTypeMintNft:
+		pubData = CollectPubDataFromMintNft(api, circuit.MintNftTxInfo)
+	case TxTypeTransferNft:
+		pubData = CollectPubDataFromTransferNft(api, circuit.TransferNftTxInfo)
+	case TxTypeAtomicMatch:
+		pubData = CollectPubDataFromAtomicMatch(api, circuit.AtomicMatchTxInfo)
+	case TxTypeCancelOffer:
+		pubData = CollectPubDataFromCancelOffer(api, circuit.CancelOfferTxInfo)
+	case TxTypeWithdrawNft:
+		pubData = CollectPubDataFromWithdrawNft(api, circuit.WithdrawNftTxInfo)
+	case TxTypeFullExit:
+		pubData = CollectPubDataFromFullExit(api, circuit.FullExitTxInfo)
+	case TxTypeFullExitNft:
+		pubData = CollectPubDataFromFullExitNft(api, circuit.FullExitNftTxInfo)
+	case TxTypeChangePubKey:
+		pubData = CollectPubDataFromChangePubKey(api, circuit.ChangePubKeyTxInfo)
+	case TxTypeBatchTransfer:
+		pubData = CollectPubDataFromBatchTransfer(api, circuit.BatchTransferTxInfo)
+	case TxTypeCreatePair:
+		pubData = CollectPubDataFromCreatePair(api, circuit.CreatePairTxInfo)
+	case TxTypeSwap:
+		pubData = CollectPubDataFromSwap(api, circuit.SwapTxInfo)
+	case TxTypeAddLiquidity:
+		pubData = CollectPubDataFromAddLiquidity(api, circuit.AddLiquidityTxInfo)
+	case TxTypeRemoveLiquidity:
+		pubData = CollectPubDataFromRemoveLiquidity(api, circuit.RemoveLiquidityTxInfo)
+	case TxTypeBurnNft:
+		pubData = CollectPubDataFromBurnNft(api, circuit.BurnNftTxInfo)
+	case TxTypeUpdateCollection:
+		pubData = CollectPubDataFromUpdateCollection(api, circuit.UpdateCollectionTxInfo)
+	case TxTypeTransferCollection:
+		pubData = CollectPubDataFromTransferCollection(api, circuit.TransferCollectionTxInfo)
+	}
+	for i := 0; i < PubDataSizePerTx; i++ {
+		api.AssertIsEqual(pubData[i], circuit.PubData[i])
+	}
+	return nil
+}
+
+func emptyPubDataWitness(txType int) PubDataConstraints {
+	return PubDataConstraints{
+		TxType:                   txType,
+		RegisterZnsTxInfo:        EmptyRegisterZnsTxWitness(),
+		DepositTxInfo:            EmptyDepositTxWitness(),
+		DepositNftTxInfo:         EmptyDepositNftTxWitness(),
+		TransferTxInfo:           EmptyTransferTxWitness(),
+		WithdrawTxInfo:           EmptyWithdrawTxWitness(),
+		CreateCollectionTxInfo:   EmptyCreateCollectionTxWitness(),
+		MintNftTxInfo:            EmptyMintNftTxWitness(),
+		TransferNftTxInfo:        EmptyTransferNftTxWitness(),
+		AtomicMatchTxInfo:        EmptyAtomicMatchTxWitness(),
+		CancelOfferTxInfo:        EmptyCancelOfferTxWitness(),
+		WithdrawNftTxInfo:        EmptyWithdrawNftTxWitness(),
+		FullExitTxInfo:           EmptyFullExitTxWitness(),
+		FullExitNftTxInfo:        EmptyFullExitNftTxWitness(),
+		ChangePubKeyTxInfo:       EmptyChangePubKeyTxWitness(),
+		BatchTransferTxInfo:      EmptyBatchTransferTxWitness(),
+		CreatePairTxInfo:         EmptyCreatePairTxWitness(),
+		SwapTxInfo:               EmptySwapTxWitness(),
+		AddLiquidityTxInfo:       EmptyAddLiquidityTxWitness(),
+		RemoveLiquidityTxInfo:    EmptyRemoveLiquidityTxWitness(),
+		BurnNftTxInfo:            EmptyBurnNftTxWitness(),
+		UpdateCollectionTxInfo:   EmptyUpdateCollectionTxWitness(),
+		TransferCollectionTxInfo: EmptyTransferCollectionTxWitness(),
+	}
+}
+
+// values close to the bit size of their fields so that a misplaced field shows up
+func testBytes(seed byte) []byte {
+	b := make([]byte, 31)
+	for i := range b {
+		b[i] = seed + byte(i)
+	}
+	return b
+}
+
+func testPubKey() *eddsa.PublicKey {
+	pk := new(eddsa.PublicKey)
+	pk.A.X.SetUint64(7)
+	pk.A.Y.SetUint64(11)
+	return pk
+}
+
+func testOffer(offerType int64, offerId int64, accountIndex int64) *OfferTx {
+	return &OfferTx{
+		Type:         offerType,
+		OfferId:      offerId,
+		AccountIndex: accountIndex,
+		NftIndex:     1<<40 - 3,
+		AssetId:      1<<16 - 2,
+		AssetAmount:  1<<40 - 5,
+		ListedAt:     1654656781000,
+		ExpiredAt:    1654656791000,
+		TreasuryRate: 200,
+		Sig:          EmptySignature(),
+	}
+}
+
+func TestComputePubData(t *testing.T) {
+	l1Address := "0xffeeddccbbaa99887766554433221100ffeeddcc"
+	toAddress, _ := new(big.Int).SetString("ccddeeff00112233445566778899aabbccddeeff", 16)
+	stateAmount := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), StateAmountBitsSize), big.NewInt(1))
+	liquidityAmount := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), LiquidityAmountBitsSize), big.NewInt(1))
+
+	registerZns := &RegisterZnsTx{AccountIndex: 1<<32 - 1, AccountName: testBytes(1), AccountNameHash: testBytes(2), PubKey: testPubKey()}
+	deposit := &DepositTx{AccountIndex: 1<<32 - 2, AccountNameHash: testBytes(3), AssetId: 1<<16 - 1, AssetAmount: stateAmount}
+	depositNft := &DepositNftTx{
+		AccountIndex: 5, NftIndex: 1<<40 - 1, NftL1Address: l1Address, AccountNameHash: testBytes(4),
+		NftContentHash: testBytes(5), NftL1TokenId: big.NewInt(12345), CreatorAccountIndex: 1<<32 - 3,
+		CreatorTreasuryRate: 1<<16 - 1, CollectionId: 1<<16 - 2,
+	}
+	transfer := &TransferTx{
+		FromAccountIndex: 2, ToAccountIndex: 1<<32 - 4, ToAccountNameHash: testBytes(6), AssetId: 3,
+		AssetAmount: 1<<40 - 1, GasAccountIndex: 1, GasFeeAssetId: 1<<16 - 3, GasFeeAssetAmount: 1<<16 - 1,
+		CallDataHash: testBytes(7),
+	}
+	withdraw := &WithdrawTx{
+		FromAccountIndex: 1<<32 - 5, AssetId: 4, AssetAmount: stateAmount, GasAccountIndex: 1,
+		GasFeeAssetId: 1<<16 - 4, GasFeeAssetAmount: 1<<16 - 2, ToAddress: toAddress,
+	}
+	createCollection := &CreateCollectionTx{
+		AccountIndex: 1<<32 - 6, CollectionId: 1<<16 - 1, MetadataHash: testBytes(23), CreatorTreasuryRate: 1<<16 - 32,
+		GasAccountIndex: 1, GasFeeAssetId: 2, GasFeeAssetAmount: 1<<16 - 3,
+	}
+	mintNft := &MintNftTx{
+		CreatorAccountIndex: 1<<32 - 7, ToAccountIndex: 8, ToAccountNameHash: testBytes(8), NftIndex: 1<<40 - 2,
+		NftContentHash: testBytes(9), CreatorTreasuryRate: 1<<16 - 5, GasAccountIndex: 1, GasFeeAssetId: 3,
+		GasFeeAssetAmount: 1<<16 - 4, CollectionId: 1<<16 - 6,
+	}
+	transferNft := &TransferNftTx{
+		FromAccountIndex: 9, ToAccountIndex: 1<<32 - 8, ToAccountNameHash: testBytes(10), NftIndex: 1<<40 - 4,
+		GasAccountIndex: 1, GasFeeAssetId: 1<<16 - 7, GasFeeAssetAmount: 1<<16 - 5, CallDataHash: testBytes(11),
+	}
+	atomicMatch := &AtomicMatchTx{
+		AccountIndex: 1<<32 - 9, BuyOffer: testOffer(0, 1<<24-1, 10), SellOffer: testOffer(1, 1<<24-2, 1<<32-10),
+		CreatorAmount: 1<<40 - 6, TreasuryAmount: 1<<40 - 7, GasAccountIndex: 1, GasFeeAssetId: 1<<16 - 8,
+		GasFeeAssetAmount: 1<<16 - 6,
+	}
+	cancelOffer := &CancelOfferTx{
+		AccountIndex: 1<<32 - 11, OfferId: 1<<24 - 3, GasAccountIndex: 1, GasFeeAssetId: 1<<16 - 9,
+		GasFeeAssetAmount: 1<<16 - 7,
+	}
+	withdrawNft := &WithdrawNftTx{
+		AccountIndex: 1<<32 - 12, CreatorAccountIndex: 13, CreatorAccountNameHash: testBytes(12),
+		CreatorTreasuryRate: 1<<16 - 10, NftIndex: 1<<40 - 5, NftContentHash: testBytes(13), NftL1Address: l1Address,
+		NftL1TokenId: big.NewInt(54321), ToAddress: "0x" + toAddress.Text(16), GasAccountIndex: 1,
+		GasFeeAssetId: 1<<16 - 11, GasFeeAssetAmount: 1<<16 - 8, CollectionId: 1<<16 - 12,
+	}
+	fullExit := &FullExitTx{AccountIndex: 1<<32 - 13, AccountNameHash: testBytes(14), AssetId: 1<<16 - 13, AssetAmount: stateAmount}
+	fullExitNft := &FullExitNftTx{
+		AccountIndex: 1<<32 - 14, AccountNameHash: testBytes(15), CreatorAccountIndex: 1<<32 - 15,
+		CreatorAccountNameHash: testBytes(16), CreatorTreasuryRate: 1<<16 - 14, NftIndex: 1<<40 - 6,
+		CollectionId: 1<<16 - 15, NftContentHash: testBytes(17), NftL1Address: l1Address, NftL1TokenId: big.NewInt(99),
+	}
+	changePubKey := &ChangePubKeyTx{
+		AccountIndex: 1<<32 - 16, AccountNameHash: testBytes(18), PubKey: testPubKey(), IsPriorityOp: 1,
+		GasAccountIndex: 1<<32 - 17, GasFeeAssetId: 1<<16 - 16, GasFeeAssetAmount: 1<<16 - 9,
+	}
+	batchTransfer := &BatchTransferTx{
+		FromAccountIndex: 1<<32 - 18, AssetId: 1<<16 - 17,
+		ToAccountIndexes:    [NbBatchTransferRecipients]int64{1<<32 - 19, 20, 1<<32 - 21},
+		ToAccountNameHashes: [NbBatchTransferRecipients][]byte{testBytes(19), testBytes(20), testBytes(21)},
+		AssetAmounts:        [NbBatchTransferRecipients]int64{1<<40 - 8, 1<<40 - 9, 1<<40 - 10},
+		GasAccountIndex:     1, GasFeeAssetId: 1<<16 - 18, GasFeeAssetAmount: 1<<16 - 10, CallDataHash: testBytes(22),
+	}
+	createPair := &CreatePairTx{
+		PairIndex: 1<<16 - 1, AssetAId: 1<<16 - 19, AssetBId: 1<<16 - 20, LpAssetId: 1<<16 - 21,
+		FeeRate: 1<<16 - 3, TreasuryAccountIndex: 1<<32 - 22, TreasuryRate: 1<<16 - 4,
+	}
+	swap := &SwapTx{
+		FromAccountIndex: 1<<32 - 23, PairIndex: 1<<16 - 5, AssetAId: 1<<16 - 22, AssetAAmount: liquidityAmount,
+		AssetBId: 1<<16 - 23, AssetBMinAmount: big.NewInt(1), AssetBAmountDelta: big.NewInt(1<<62 - 1), TreasuryAmount: liquidityAmount,
+		GasAccountIndex: 1<<32 - 24, GasFeeAssetId: 1<<16 - 24, GasFeeAssetAmount: 1<<16 - 11,
+	}
+	addLiquidity := &AddLiquidityTx{
+		FromAccountIndex: 1<<32 - 25, PairIndex: 1<<16 - 6, AssetAId: 1<<16 - 25, AssetAAmount: big.NewInt(1<<62 - 2),
+		AssetBId: 1<<16 - 26, AssetBAmount: liquidityAmount, LpMinAmount: big.NewInt(1), LpAmount: liquidityAmount,
+		GasAccountIndex: 1<<32 - 26, GasFeeAssetId: 1<<16 - 27, GasFeeAssetAmount: 1<<16 - 12,
+	}
+	removeLiquidity := &RemoveLiquidityTx{
+		FromAccountIndex: 1<<32 - 27, PairIndex: 1<<16 - 7, AssetAId: 1<<16 - 28, AssetAMinAmount: big.NewInt(1),
+		AssetAAmountDelta: liquidityAmount, AssetBId: 1<<16 - 29, AssetBMinAmount: big.NewInt(1), AssetBAmountDelta: liquidityAmount,
+		LpAmount: big.NewInt(1<<62 - 3), GasAccountIndex: 1<<32 - 28, GasFeeAssetId: 1<<16 - 30, GasFeeAssetAmount: 1<<16 - 13,
+	}
+	burnNft := &BurnNftTx{
+		AccountIndex: 1<<32 - 29, NftIndex: 1<<40 - 11,
+		GasAccountIndex: 1<<32 - 30, GasFeeAssetId: 1<<16 - 31, GasFeeAssetAmount: 1<<16 - 14,
+	}
+	updateCollection := &UpdateCollectionTx{
+		AccountIndex: 1<<32 - 31, CollectionId: 1<<16 - 33, MetadataHash: testBytes(24), CreatorTreasuryRate: 1<<16 - 34,
+		GasAccountIndex: 1<<32 - 32, GasFeeAssetId: 1<<16 - 35, GasFeeAssetAmount: 1<<16 - 15,
+	}
+	transferCollection := &TransferCollectionTx{
+		FromAccountIndex: 1<<32 - 33, ToAccountIndex: 1<<32 - 34, ToAccountNameHash: testBytes(25), CollectionId: 1<<16 - 36,
+		GasAccountIndex: 1<<32 - 35, GasFeeAssetId: 1<<16 - 37, GasFeeAssetAmount: 1<<16 - 16,
+	}
+
+	testCases := []struct {
+		txType  int
+		compute func() ([PubDataSizePerTx]*big.Int, error)
+		set     func(witness *PubDataConstraints)
+	}{
+		{TxTypeRegisterZns, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromRegisterZNS(registerZns) },
+			func(witness *PubDataConstraints) { witness.RegisterZnsTxInfo = SetRegisterZnsTxWitness(registerZns) }},
+		{TxTypeDeposit, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromDeposit(deposit) },
+			func(witness *PubDataConstraints) { witness.DepositTxInfo = SetDepositTxWitness(deposit) }},
+		{TxTypeDepositNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromDepositNft(depositNft) },
+			func(witness *PubDataConstraints) { witness.DepositNftTxInfo = SetDepositNftTxWitness(depositNft) }},
+		{TxTypeTransfer, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromTransfer(transfer) },
+			func(witness *PubDataConstraints) { witness.TransferTxInfo = SetTransferTxWitness(transfer) }},
+		{TxTypeWithdraw, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromWithdraw(withdraw) },
+			func(witness *PubDataConstraints) { witness.WithdrawTxInfo = SetWithdrawTxWitness(withdraw) }},
+		{TxTypeCreateCollection, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromCreateCollection(createCollection) },
+			func(witness *PubDataConstraints) {
+				witness.CreateCollectionTxInfo = SetCreateCollectionTxWitness(createCollection)
+			}},
+		{TxTypeMintNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromMintNft(mintNft) },
+			func(witness *PubDataConstraints) { witness.MintNftTxInfo = SetMintNftTxWitness(mintNft) }},
+		{TxTypeTransferNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromTransferNft(transferNft) },
+			func(witness *PubDataConstraints) { witness.TransferNftTxInfo = SetTransferNftTxWitness(transferNft) }},
+		{TxTypeAtomicMatch, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromAtomicMatch(atomicMatch) },
+			func(witness *PubDataConstraints) { witness.AtomicMatchTxInfo = SetAtomicMatchTxWitness(atomicMatch) }},
+		{TxTypeCancelOffer, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromCancelOffer(cancelOffer) },
+			func(witness *PubDataConstraints) { witness.CancelOfferTxInfo = SetCancelOfferTxWitness(cancelOffer) }},
+		{TxTypeWithdrawNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromWithdrawNft(withdrawNft) },
+			func(witness *PubDataConstraints) { witness.WithdrawNftTxInfo = SetWithdrawNftTxWitness(withdrawNft) }},
+		{TxTypeFullExit, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromFullExit(fullExit) },
+			func(witness *PubDataConstraints) { witness.FullExitTxInfo = SetFullExitTxWitness(fullExit) }},
+		{TxTypeFullExitNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromFullExitNft(fullExitNft) },
+			func(witness *PubDataConstraints) { witness.FullExitNftTxInfo = SetFullExitNftTxWitness(fullExitNft) }},
+		{TxTypeChangePubKey, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromChangePubKey(changePubKey) },
+			func(witness *PubDataConstraints) { witness.ChangePubKeyTxInfo = SetChangePubKeyTxWitness(changePubKey) }},
+		{TxTypeBatchTransfer, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromBatchTransfer(batchTransfer) },
+			func(witness *PubDataConstraints) {
+				witness.BatchTransferTxInfo = SetBatchTransferTxWitness(batchTransfer)
+			}},
+		{TxTypeCreatePair, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromCreatePair(createPair) },
+			func(witness *PubDataConstraints) { witness.CreatePairTxInfo = SetCreatePairTxWitness(createPair) }},
+		{TxTypeSwap, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromSwap(swap) },
+			func(witness *PubDataConstraints) { witness.SwapTxInfo = SetSwapTxWitness(swap) }},
+		{TxTypeAddLiquidity, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromAddLiquidity(addLiquidity) },
+			func(witness *PubDataConstraints) { witness.AddLiquidityTxInfo = SetAddLiquidityTxWitness(addLiquidity) }},
+		{TxTypeRemoveLiquidity, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromRemoveLiquidity(removeLiquidity) },
+			func(witness *PubDataConstraints) {
+				witness.RemoveLiquidityTxInfo = SetRemoveLiquidityTxWitness(removeLiquidity)
+			}},
+		{TxTypeBurnNft, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromBurnNft(burnNft) },
+			func(witness *PubDataConstraints) { witness.BurnNftTxInfo = SetBurnNftTxWitness(burnNft) }},
+		{TxTypeUpdateCollection, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromUpdateCollection(updateCollection) },
+			func(witness *PubDataConstraints) {
+				witness.UpdateCollectionTxInfo = SetUpdateCollectionTxWitness(updateCollection)
+			}},
+		{TxTypeTransferCollection, func() ([PubDataSizePerTx]*big.Int, error) { return ComputePubDataFromTransferCollection(transferCollection) },
+			func(witness *PubDataConstraints) {
+				witness.TransferCollectionTxInfo = SetTransferCollectionTxWitness(transferCollection)
+			}},
+	}
+	for _, testCase := range testCases {
+		pubData, err := testCase.compute()
+		require.NoError(t, err, "tx type %d", testCase.txType)
+		circuit := PubDataConstraints{TxType: testCase.txType}
+		witness := emptyPubDataWitness(testCase.txType)
+		testCase.set(&witness)
+		for i := 0; i < PubDataSizePerTx; i++ {
+			witness.PubData[i] = pubData[i]
+		}
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "tx type %d", testCase.txType)
+
+		// a single changed word must be caught
+		witness.PubData[0] = new(big.Int).Add(pubData[0], big.NewInt(1))
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16), "tx type %d", testCase.txType)
+	}
+}
+
+func TestComputePubDataOutOfRange(t *testing.T) {
+	_, err := ComputePubDataFromTransfer(&TransferTx{AssetAmount: 1 << PackedAmountBitsSize, CallDataHash: testBytes(1)})
+	assert.Error(t, err)
+	_, err = ComputePubDataFromDeposit(&DepositTx{AccountIndex: -1, AccountNameHash: testBytes(1), AssetAmount: big.NewInt(1)})
+	assert.Error(t, err)
+	_, err = ComputePubDataFromFullExit(&FullExitTx{AccountNameHash: testBytes(1)})
+	assert.Error(t, err)
+	_, err = ComputePubDataFromSwap(&SwapTx{
+		AssetAAmount: new(big.Int).Lsh(big.NewInt(1), LiquidityAmountBitsSize), AssetBAmountDelta: big.NewInt(1), TreasuryAmount: big.NewInt(0),
+	})
+	assert.Error(t, err)
+}
@@ -57,9 +57,10 @@ func SetRegisterZnsTxWitness(tx *RegisterZnsTx) (witness RegisterZnsTxConstraint
 func VerifyRegisterZNSTx(
 	api API, flag Variable,
 	tx RegisterZnsTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
+	emptyAssetRoot Variable,
 ) (pubData [PubDataSizePerTx]Variable) {
 	pubData = CollectPubDataFromRegisterZNS(api, tx)
-	CheckEmptyAccountNode(api, flag, accountsBefore[0])
+	CheckEmptyAccountNode(api, flag, accountsBefore[0], emptyAssetRoot)
 	return pubData
 }
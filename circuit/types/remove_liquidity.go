@@ -0,0 +1,144 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+)
+
+/*
+	RemoveLiquidityTx: burn LpAmount lp shares of a pair for their part of the
+	reserves, AssetAAmountDelta and AssetBAmountDelta, at least AssetAMinAmount
+	and AssetBMinAmount. The amounts are raw uint112 amounts.
+*/
+type RemoveLiquidityTx struct {
+	FromAccountIndex  int64
+	PairIndex         int64
+	AssetAId          int64
+	AssetAMinAmount   *big.Int
+	AssetAAmountDelta *big.Int
+	AssetBId          int64
+	AssetBMinAmount   *big.Int
+	AssetBAmountDelta *big.Int
+	LpAmount          *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type RemoveLiquidityTxConstraints struct {
+	FromAccountIndex  Variable
+	PairIndex         Variable
+	AssetAId          Variable
+	AssetAMinAmount   Variable
+	AssetAAmountDelta Variable
+	AssetBId          Variable
+	AssetBMinAmount   Variable
+	AssetBAmountDelta Variable
+	LpAmount          Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyRemoveLiquidityTxWitness() (witness RemoveLiquidityTxConstraints) {
+	return RemoveLiquidityTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		PairIndex:         ZeroInt,
+		AssetAId:          ZeroInt,
+		AssetAMinAmount:   ZeroInt,
+		AssetAAmountDelta: ZeroInt,
+		AssetBId:          ZeroInt,
+		AssetBMinAmount:   ZeroInt,
+		AssetBAmountDelta: ZeroInt,
+		LpAmount:          ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetRemoveLiquidityTxWitness(tx *RemoveLiquidityTx) (witness RemoveLiquidityTxConstraints) {
+	witness = RemoveLiquidityTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		PairIndex:         tx.PairIndex,
+		AssetAId:          tx.AssetAId,
+		AssetAMinAmount:   tx.AssetAMinAmount,
+		AssetAAmountDelta: tx.AssetAAmountDelta,
+		AssetBId:          tx.AssetBId,
+		AssetBMinAmount:   tx.AssetBMinAmount,
+		AssetBAmountDelta: tx.AssetBAmountDelta,
+		LpAmount:          tx.LpAmount,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromRemoveLiquidityTx(api API, tx RemoveLiquidityTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		PackInt64Variables(api, tx.PairIndex, tx.AssetAId, tx.AssetBId),
+		tx.LpAmount,
+		tx.AssetAMinAmount,
+		tx.AssetBMinAmount,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	VerifyRemoveLiquidityTx: lp shares are worth floor(lp * A / L) and
+	floor(lp * B / L) for reserves A, B and L lp shares
+*/
+func VerifyRemoveLiquidityTx(
+	api API, flag Variable,
+	tx *RemoveLiquidityTxConstraints,
+	accountsBefore []AccountConstraints,
+	liquidityBefore LiquidityConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	lpAccount := 3
+	pubData = CollectPubDataFromRemoveLiquidity(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AssetAId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.AssetBId, accountsBefore[fromAccount].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[lpAccount].AccountIndex)
+	IsVariableEqual(api, flag, liquidityBefore.LpAssetId, accountsBefore[lpAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[lpAccount].AssetsInfo[1].AssetId)
+	// the pair should exist
+	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
+	IsVariableDifferent(api, flag, liquidityBefore.AssetAId, liquidityBefore.AssetBId)
+	IsVariableEqual(api, flag, tx.AssetAId, liquidityBefore.AssetAId)
+	IsVariableEqual(api, flag, tx.AssetBId, liquidityBefore.AssetBId)
+	// amounts
+	IsVariableLessOrEqual(api, flag, tx.LpAmount, liquidityBefore.LpAmount)
+	IsFloorDiv(api, flag, tx.AssetAAmountDelta, api.Mul(tx.LpAmount, liquidityBefore.AssetA), liquidityBefore.LpAmount)
+	IsFloorDiv(api, flag, tx.AssetBAmountDelta, api.Mul(tx.LpAmount, liquidityBefore.AssetB), liquidityBefore.LpAmount)
+	IsVariableLessOrEqual(api, flag, tx.AssetAMinAmount, tx.AssetAAmountDelta)
+	IsVariableLessOrEqual(api, flag, tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.LpAmount, accountsBefore[lpAccount].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[lpAccount].AssetsInfo[1].Balance)
+	return pubData
+}
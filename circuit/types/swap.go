@@ -0,0 +1,151 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+import (
+	"math/big"
+)
+
+/*
+	SwapTx: sell AssetAAmount of asset A to a pair for AssetBAmountDelta of asset B,
+	at least AssetBMinAmount. The amounts are raw uint112 amounts, the fee is
+	paid from the amount sold and TreasuryAmount of it goes to the treasury account
+	of the pair.
+*/
+type SwapTx struct {
+	FromAccountIndex  int64
+	PairIndex         int64
+	AssetAId          int64
+	AssetAAmount      *big.Int
+	AssetBId          int64
+	AssetBMinAmount   *big.Int
+	AssetBAmountDelta *big.Int
+	TreasuryAmount    *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type SwapTxConstraints struct {
+	FromAccountIndex  Variable
+	PairIndex         Variable
+	AssetAId          Variable
+	AssetAAmount      Variable
+	AssetBId          Variable
+	AssetBMinAmount   Variable
+	AssetBAmountDelta Variable
+	TreasuryAmount    Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptySwapTxWitness() (witness SwapTxConstraints) {
+	return SwapTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		PairIndex:         ZeroInt,
+		AssetAId:          ZeroInt,
+		AssetAAmount:      ZeroInt,
+		AssetBId:          ZeroInt,
+		AssetBMinAmount:   ZeroInt,
+		AssetBAmountDelta: ZeroInt,
+		TreasuryAmount:    ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetSwapTxWitness(tx *SwapTx) (witness SwapTxConstraints) {
+	witness = SwapTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		PairIndex:         tx.PairIndex,
+		AssetAId:          tx.AssetAId,
+		AssetAAmount:      tx.AssetAAmount,
+		AssetBId:          tx.AssetBId,
+		AssetBMinAmount:   tx.AssetBMinAmount,
+		AssetBAmountDelta: tx.AssetBAmountDelta,
+		TreasuryAmount:    tx.TreasuryAmount,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromSwapTx(api API, tx SwapTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		PackInt64Variables(api, tx.PairIndex, tx.AssetAId, tx.AssetBId),
+		tx.AssetAAmount,
+		tx.AssetBMinAmount,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	VerifySwapTx: constant product pricing, with x = amountIn * (RateBase - FeeRate)
+	amountOut = floor(x * reserveOut / (reserveIn * RateBase + x))
+*/
+func VerifySwapTx(
+	api API, flag Variable,
+	tx *SwapTxConstraints,
+	accountsBefore []AccountConstraints,
+	liquidityBefore LiquidityConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	treasuryAccount := 1
+	fromGasAccount := 3
+	pubData = CollectPubDataFromSwap(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AssetAId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.AssetBId, accountsBefore[fromAccount].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, liquidityBefore.TreasuryAccountIndex, accountsBefore[treasuryAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.AssetAId, accountsBefore[treasuryAccount].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromGasAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromGasAccount].AssetsInfo[1].AssetId)
+	// the pair should exist and trade the assets of the tx in either direction
+	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
+	IsVariableDifferent(api, flag, liquidityBefore.AssetAId, liquidityBefore.AssetBId)
+	isAToB := api.IsZero(api.Sub(tx.AssetAId, liquidityBefore.AssetAId))
+	IsVariableEqual(api, flag, tx.AssetAId, api.Select(isAToB, liquidityBefore.AssetAId, liquidityBefore.AssetBId))
+	IsVariableEqual(api, flag, tx.AssetBId, api.Select(isAToB, liquidityBefore.AssetBId, liquidityBefore.AssetAId))
+	reserveIn := api.Select(isAToB, liquidityBefore.AssetA, liquidityBefore.AssetB)
+	reserveOut := api.Select(isAToB, liquidityBefore.AssetB, liquidityBefore.AssetA)
+	// treasury amount
+	IsFloorDiv(api, flag, tx.TreasuryAmount, api.Mul(tx.AssetAAmount, liquidityBefore.TreasuryRate), RateBase)
+	// amount out
+	amountInWithFee := api.Mul(tx.AssetAAmount, api.Sub(RateBase, liquidityBefore.FeeRate))
+	IsFloorDiv(
+		api, flag, tx.AssetBAmountDelta,
+		api.Mul(amountInWithFee, reserveOut),
+		api.Add(api.Mul(reserveIn, RateBase), amountInWithFee),
+	)
+	IsVariableLessOrEqual(api, flag, tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	// the reserve of the asset sold should stay uint112
+	CheckLiquidityAmount(api, flag, api.Sub(api.Add(reserveIn, tx.AssetAAmount), tx.TreasuryAmount))
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.AssetAAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromGasAccount].AssetsInfo[1].Balance)
+	return pubData
+}
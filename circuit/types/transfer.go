@@ -70,10 +70,10 @@ func SetTransferTxWitness(tx *TransferTx) (witness TransferTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromTransferTx(api API, tx TransferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+func ComputeHashFromTransferTx(api API, tx TransferTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
 	hFunc.Reset()
 	hFunc.Write(
-		PackInt64Variables(api, ChainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
 		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
 		PackInt64Variables(api, tx.ToAccountIndex, tx.AssetId, tx.AssetAmount),
 		tx.ToAccountNameHash,
@@ -86,7 +86,7 @@ func ComputeHashFromTransferTx(api API, tx TransferTxConstraints, nonce Variable
 func VerifyTransferTx(
 	api API, flag Variable,
 	tx *TransferTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
 ) (pubData [PubDataSizePerTx]Variable) {
 	fromAccount := 0
 	toAccount := 1
@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	TransferCollectionTx: the owner of a collection hands it to another
+	account, the new owner is the only one able to mint into it afterwards
+*/
+type TransferCollectionTx struct {
+	FromAccountIndex  int64
+	ToAccountIndex    int64
+	ToAccountNameHash []byte
+	CollectionId      int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type TransferCollectionTxConstraints struct {
+	FromAccountIndex  Variable
+	ToAccountIndex    Variable
+	ToAccountNameHash Variable
+	CollectionId      Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyTransferCollectionTxWitness() (witness TransferCollectionTxConstraints) {
+	return TransferCollectionTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		ToAccountIndex:    ZeroInt,
+		ToAccountNameHash: ZeroInt,
+		CollectionId:      ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetTransferCollectionTxWitness(tx *TransferCollectionTx) (witness TransferCollectionTxConstraints) {
+	witness = TransferCollectionTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		ToAccountIndex:    tx.ToAccountIndex,
+		ToAccountNameHash: tx.ToAccountNameHash,
+		CollectionId:      tx.CollectionId,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromTransferCollectionTx(api API, tx TransferCollectionTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		PackInt64Variables(api, tx.ToAccountIndex, tx.CollectionId),
+		tx.ToAccountNameHash,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyTransferCollectionTx(
+	api API,
+	flag Variable,
+	tx *TransferCollectionTxConstraints,
+	accountsBefore []AccountConstraints,
+	collectionBefore CollectionConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	toAccount := 1
+	pubData = CollectPubDataFromTransferCollection(api, *tx)
+	// verify params
+	// account index
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
+	IsVariableEqual(api, flag, tx.ToAccountIndex, accountsBefore[toAccount].AccountIndex)
+	// account name
+	IsVariableEqual(api, flag, tx.ToAccountNameHash, accountsBefore[toAccount].AccountNameHash)
+	// asset id
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	// collection info
+	IsVariableEqual(api, flag, tx.CollectionId, collectionBefore.CollectionId)
+	CheckCollectionOwner(api, flag, collectionBefore, tx.FromAccountIndex)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	return pubData
+}
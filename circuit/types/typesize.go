@@ -30,4 +30,7 @@ const (
 	PackedAmountBitsSize        = 40
 	PackedFeeBitsSize           = 16
 	AddressBitsSize             = 160
+	PriorityOpFlagBitsSize      = 8
+	PairIndexBitsSize           = 16
+	LiquidityAmountBitsSize     = 112
 )
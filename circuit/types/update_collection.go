@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package types
+
+/*
+	UpdateCollectionTx: the owner of a collection replaces its metadata hash
+	and the royalty default of the nfts minted into it
+*/
+type UpdateCollectionTx struct {
+	AccountIndex        int64
+	CollectionId        int64
+	MetadataHash        []byte
+	CreatorTreasuryRate int64
+	GasAccountIndex     int64
+	GasFeeAssetId       int64
+	GasFeeAssetAmount   int64
+}
+
+type UpdateCollectionTxConstraints struct {
+	AccountIndex        Variable
+	CollectionId        Variable
+	MetadataHash        Variable
+	CreatorTreasuryRate Variable
+	GasAccountIndex     Variable
+	GasFeeAssetId       Variable
+	GasFeeAssetAmount   Variable
+}
+
+func EmptyUpdateCollectionTxWitness() (witness UpdateCollectionTxConstraints) {
+	return UpdateCollectionTxConstraints{
+		AccountIndex:        ZeroInt,
+		CollectionId:        ZeroInt,
+		MetadataHash:        ZeroInt,
+		CreatorTreasuryRate: ZeroInt,
+		GasAccountIndex:     ZeroInt,
+		GasFeeAssetId:       ZeroInt,
+		GasFeeAssetAmount:   ZeroInt,
+	}
+}
+
+func SetUpdateCollectionTxWitness(tx *UpdateCollectionTx) (witness UpdateCollectionTxConstraints) {
+	witness = UpdateCollectionTxConstraints{
+		AccountIndex:        tx.AccountIndex,
+		CollectionId:        tx.CollectionId,
+		MetadataHash:        tx.MetadataHash,
+		CreatorTreasuryRate: tx.CreatorTreasuryRate,
+		GasAccountIndex:     tx.GasAccountIndex,
+		GasFeeAssetId:       tx.GasFeeAssetId,
+		GasFeeAssetAmount:   tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func ComputeHashFromUpdateCollectionTx(api API, tx UpdateCollectionTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		PackInt64Variables(api, chainId, tx.AccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
+		PackInt64Variables(api, tx.CollectionId, tx.CreatorTreasuryRate),
+		tx.MetadataHash,
+	)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyUpdateCollectionTx(
+	api API,
+	flag Variable,
+	tx *UpdateCollectionTxConstraints,
+	accountsBefore []AccountConstraints,
+	collectionBefore CollectionConstraints,
+) (pubData [PubDataSizePerTx]Variable) {
+	fromAccount := 0
+	pubData = CollectPubDataFromUpdateCollection(api, *tx)
+	// verify params
+	IsVariableLessOrEqual(api, flag, tx.CreatorTreasuryRate, RateBase)
+	// metadata hash
+	isZero := api.IsZero(tx.MetadataHash)
+	IsVariableEqual(api, flag, isZero, 0)
+	// account index
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[fromAccount].AccountIndex)
+	// asset id
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
+	// collection info
+	IsVariableEqual(api, flag, tx.CollectionId, collectionBefore.CollectionId)
+	CheckCollectionOwner(api, flag, collectionBefore, tx.AccountIndex)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[fromAccount].AssetsInfo[0].Balance)
+	return pubData
+}
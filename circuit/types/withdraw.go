@@ -66,10 +66,10 @@ func SetWithdrawTxWitness(tx *WithdrawTx) (witness WithdrawTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromWithdrawTx(api API, tx WithdrawTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+func ComputeHashFromWithdrawTx(api API, tx WithdrawTxConstraints, chainId Variable, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
 	hFunc.Reset()
 	hFunc.Write(
-		PackInt64Variables(api, ChainId, tx.FromAccountIndex, nonce, expiredAt),
+		PackInt64Variables(api, chainId, tx.FromAccountIndex, nonce, expiredAt),
 		PackInt64Variables(api, tx.GasAccountIndex, tx.GasFeeAssetId, tx.GasFeeAssetAmount),
 		tx.AssetId,
 		tx.AssetAmount,
@@ -82,14 +82,16 @@ func ComputeHashFromWithdrawTx(api API, tx WithdrawTxConstraints, nonce Variable
 func VerifyWithdrawTx(
 	api API, flag Variable,
 	tx *WithdrawTxConstraints,
-	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	accountsBefore []AccountConstraints,
+	firstLpAssetId int64,
 ) (pubData [PubDataSizePerTx]Variable) {
 	fromAccount := 0
 	pubData = CollectPubDataFromWithdraw(api, *tx)
 	// verify params
 	// account index
 	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[fromAccount].AccountIndex)
-	// asset id
+	// asset id, lp shares stay on layer 2
+	IsVariableLess(api, flag, tx.AssetId, firstLpAssetId)
 	IsVariableEqual(api, flag, tx.AssetId, accountsBefore[fromAccount].AssetsInfo[0].AssetId)
 	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[fromAccount].AssetsInfo[1].AssetId)
 	// should have enough assets
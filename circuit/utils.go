@@ -23,11 +23,21 @@ import (
 	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
 )
 
+/*
+	SelectAssetDeltas: deltas of a tx type if flag is set, the slots of the config
+	past the ones the tx types address keep their deltas
+*/
 func SelectAssetDeltas(
 	api API,
 	flag Variable,
-	deltas, deltasCheck [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
-) (deltasRes [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints) {
+	deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints,
+	deltasCheck [][]AccountAssetDeltaConstraints,
+) (deltasRes [][]AccountAssetDeltaConstraints) {
+	deltasRes = make([][]AccountAssetDeltaConstraints, len(deltasCheck))
+	for i := 0; i < len(deltasCheck); i++ {
+		deltasRes[i] = make([]AccountAssetDeltaConstraints, len(deltasCheck[i]))
+		copy(deltasRes[i], deltasCheck[i])
+	}
 	for i := 0; i < NbAccountsPerTx; i++ {
 		for j := 0; j < NbAccountAssetsPerAccount; j++ {
 			deltasRes[i][j].BalanceDelta =
@@ -42,8 +52,11 @@ func SelectAssetDeltas(
 func SelectGasDeltas(
 	api API,
 	flag Variable,
-	deltas, deltasCheck [NbGasAssetsPerTx]GasDeltaConstraints,
-) (deltasRes [NbGasAssetsPerTx]GasDeltaConstraints) {
+	deltas [NbGasAssetsPerTx]GasDeltaConstraints,
+	deltasCheck []GasDeltaConstraints,
+) (deltasRes []GasDeltaConstraints) {
+	deltasRes = make([]GasDeltaConstraints, len(deltasCheck))
+	copy(deltasRes, deltasCheck)
 	for i := 0; i < NbGasAssetsPerTx; i++ {
 		deltasRes[i].AssetId =
 			api.Select(flag, deltas[i].AssetId, deltasCheck[i].AssetId)
@@ -68,11 +81,46 @@ func SelectNftDeltas(
 	return deltaRes
 }
 
+func SelectLiquidityDeltas(
+	api API,
+	flag Variable,
+	delta, deltaCheck LiquidityDeltaConstraints,
+) (deltaRes LiquidityDeltaConstraints) {
+	deltaRes.AssetAId = api.Select(flag, delta.AssetAId, deltaCheck.AssetAId)
+	deltaRes.AssetA = api.Select(flag, delta.AssetA, deltaCheck.AssetA)
+	deltaRes.AssetBId = api.Select(flag, delta.AssetBId, deltaCheck.AssetBId)
+	deltaRes.AssetB = api.Select(flag, delta.AssetB, deltaCheck.AssetB)
+	deltaRes.LpAssetId = api.Select(flag, delta.LpAssetId, deltaCheck.LpAssetId)
+	deltaRes.LpAmount = api.Select(flag, delta.LpAmount, deltaCheck.LpAmount)
+	deltaRes.FeeRate = api.Select(flag, delta.FeeRate, deltaCheck.FeeRate)
+	deltaRes.TreasuryAccountIndex = api.Select(flag, delta.TreasuryAccountIndex, deltaCheck.TreasuryAccountIndex)
+	deltaRes.TreasuryRate = api.Select(flag, delta.TreasuryRate, deltaCheck.TreasuryRate)
+	return deltaRes
+}
+
+func SelectCollectionDeltas(
+	api API,
+	flag Variable,
+	delta, deltaCheck CollectionDeltaConstraints,
+) (deltaRes CollectionDeltaConstraints) {
+	deltaRes.OwnerAccountIndex = api.Select(flag, delta.OwnerAccountIndex, deltaCheck.OwnerAccountIndex)
+	deltaRes.MetadataHash = api.Select(flag, delta.MetadataHash, deltaCheck.MetadataHash)
+	deltaRes.CreatorTreasuryRate = api.Select(flag, delta.CreatorTreasuryRate, deltaCheck.CreatorTreasuryRate)
+	return deltaRes
+}
+
+/*
+	SelectPubData: pub data of a tx type if flag is set, the words of the config
+	past the ones the tx types write stay zero
+*/
 func SelectPubData(
 	api API,
 	flag Variable,
-	delta, deltaCheck [types.PubDataSizePerTx]Variable,
-) (deltaRes [types.PubDataSizePerTx]Variable) {
+	delta [types.PubDataSizePerTx]Variable,
+	deltaCheck []Variable,
+) (deltaRes []Variable) {
+	deltaRes = make([]Variable, len(deltaCheck))
+	copy(deltaRes, deltaCheck)
 	for i := 0; i < types.PubDataSizePerTx; i++ {
 		deltaRes[i] = api.Select(flag, delta[i], deltaCheck[i])
 	}
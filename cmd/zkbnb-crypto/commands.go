@@ -0,0 +1,368 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark/backend"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/prover"
+)
+
+func runSetup(args []string) error {
+	var f circuitFlags
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	f.registerShape(fs)
+	f.registerDir(fs)
+	_ = fs.Parse(args)
+
+	blockSizes, err := f.parseBlockSizes()
+	if err != nil {
+		return err
+	}
+	gasAssetIds, err := f.parseGasAssetIds()
+	if err != nil {
+		return err
+	}
+	config, err := f.parseConfig()
+	if err != nil {
+		return err
+	}
+	hashType, err := f.parseHashType()
+	if err != nil {
+		return err
+	}
+	backendID, err := f.parseBackend()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+	for _, blockSize := range blockSizes {
+		blockCircuit, err := compile(backendID, blockSize, gasAssetIds, f.gasAccountIndex, hashType, config)
+		if err != nil {
+			return err
+		}
+		files := newKeyFiles(f.dir, backendID, blockSize)
+		manifest := prover.NewManifest(blockCircuit)
+		manifest.VerifyingKey = filepath.Base(files.verifyingKey)
+		if backendID == backend.PLONK {
+			srs, err := prover.NewKZGSRS(blockCircuit)
+			if err != nil {
+				return err
+			}
+			_, vk, err := prover.SetupPlonk(blockCircuit, srs)
+			if err != nil {
+				return err
+			}
+			if err = prover.SavePlonkKeys(vk, srs, files.verifyingKey, files.srs); err != nil {
+				return err
+			}
+			manifest.SRS = filepath.Base(files.srs)
+		} else {
+			pk, vk, err := prover.Setup(blockCircuit)
+			if err != nil {
+				return err
+			}
+			if err = prover.SaveKeys(pk, vk, files.provingKey, files.verifyingKey); err != nil {
+				return err
+			}
+			manifest.ProvingKey = filepath.Base(files.provingKey)
+		}
+		if err = prover.SaveManifest(manifest, files.manifest); err != nil {
+			return err
+		}
+		fmt.Printf("block size %d: %d constraints, keys written to %s\n", blockSize, manifest.NbConstraints, files.manifest)
+	}
+	return nil
+}
+
+func runExportSol(args []string) error {
+	var f circuitFlags
+	fs := flag.NewFlagSet("export-sol", flag.ExitOnError)
+	fs.StringVar(&f.blockSizes, "block-sizes", "1,10", "comma separated list of block sizes (txs per block)")
+	f.registerBackend(fs)
+	f.registerDir(fs)
+	_ = fs.Parse(args)
+
+	blockSizes, err := f.parseBlockSizes()
+	if err != nil {
+		return err
+	}
+	backendID, err := f.parseBackend()
+	if err != nil {
+		return err
+	}
+	// gnark v0.7 has no solidity template for plonk verifying keys
+	if backendID == backend.PLONK {
+		return errors.New("solidity verifiers are only exported for the groth16 backend")
+	}
+	for _, blockSize := range blockSizes {
+		files := newKeyFiles(f.dir, backendID, blockSize)
+		manifest, err := prover.LoadManifest(files.manifest)
+		if err != nil {
+			return err
+		}
+		vk, err := prover.LoadVerifyingKey(filepath.Join(f.dir, manifest.VerifyingKey))
+		if err != nil {
+			return err
+		}
+		err = prover.ExportSolidity(vk, files.solidity)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("block size %d: verifier written to %s\n", blockSize, files.solidity)
+	}
+	return nil
+}
+
+func runProve(args []string) error {
+	var (
+		f         circuitFlags
+		blockSize int
+		blockPath string
+		proofPath string
+	)
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	fs.IntVar(&blockSize, "block-size", 1, "block size (txs per block) of the keys to use")
+	fs.StringVar(&blockPath, "block", "", "path of the json encoded circuit.Block")
+	fs.StringVar(&proofPath, "proof", "block.proof", "path of the generated proof")
+	f.registerBackend(fs)
+	f.registerDir(fs)
+	_ = fs.Parse(args)
+
+	if blockPath == "" {
+		return errors.New("-block is required")
+	}
+	backendID, err := f.parseBackend()
+	if err != nil {
+		return err
+	}
+	files := newKeyFiles(f.dir, backendID, blockSize)
+	manifest, err := prover.LoadManifest(files.manifest)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(blockPath)
+	if err != nil {
+		return err
+	}
+	oBlock := new(circuit.Block)
+	if err = json.Unmarshal(data, oBlock); err != nil {
+		return fmt.Errorf("invalid block: %v", err)
+	}
+
+	blockCircuit, err := compile(backendID, manifest.TxsCount, manifest.GasAssetIds, manifest.GasAccountIndex, manifest.HashType, manifest.Config)
+	if err != nil {
+		return err
+	}
+	if !manifest.Matches(blockCircuit) {
+		return errors.New("compiled circuit doesn't match the manifest, keys are outdated")
+	}
+	if backendID == backend.PLONK {
+		// the plonk proving key can't be stored with gnark v0.7, it is set up again from the srs
+		fmt.Println("rebuilding the plonk proving key from", manifest.SRS)
+		srs, err := prover.LoadPlonkSRS(filepath.Join(f.dir, manifest.SRS))
+		if err != nil {
+			return err
+		}
+		pk, _, err := prover.SetupPlonk(blockCircuit, srs)
+		if err != nil {
+			return err
+		}
+		proof, err := prover.ProveBlockPlonk(blockCircuit, pk, oBlock)
+		if err != nil {
+			return err
+		}
+		err = prover.SaveProof(proof, proofPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		pk, _, err := prover.LoadKeys(filepath.Join(f.dir, manifest.ProvingKey), filepath.Join(f.dir, manifest.VerifyingKey))
+		if err != nil {
+			return err
+		}
+		proof, err := prover.ProveBlock(blockCircuit, pk, oBlock)
+		if err != nil {
+			return err
+		}
+		err = prover.SaveProof(proof, proofPath)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("proof of block %d written to %s, commitment 0x%x\n", oBlock.BlockNumber, proofPath, oBlock.BlockCommitment)
+	return nil
+}
+
+func runVerify(args []string) error {
+	var (
+		f          circuitFlags
+		blockSize  int
+		proofPath  string
+		commitment string
+	)
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.IntVar(&blockSize, "block-size", 1, "block size (txs per block) of the keys to use")
+	fs.StringVar(&proofPath, "proof", "block.proof", "path of the proof")
+	fs.StringVar(&commitment, "commitment", "", "hex encoded block commitment")
+	f.registerBackend(fs)
+	f.registerDir(fs)
+	_ = fs.Parse(args)
+
+	blockCommitment, err := hex.DecodeString(strings.TrimPrefix(commitment, "0x"))
+	if err != nil || len(blockCommitment) == 0 {
+		return errors.New("-commitment should be a non empty hex string")
+	}
+	backendID, err := f.parseBackend()
+	if err != nil {
+		return err
+	}
+	files := newKeyFiles(f.dir, backendID, blockSize)
+	manifest, err := prover.LoadManifest(files.manifest)
+	if err != nil {
+		return err
+	}
+	if backendID == backend.PLONK {
+		srs, err := prover.LoadPlonkSRS(filepath.Join(f.dir, manifest.SRS))
+		if err != nil {
+			return err
+		}
+		vk, err := prover.LoadPlonkVerifyingKey(filepath.Join(f.dir, manifest.VerifyingKey), srs)
+		if err != nil {
+			return err
+		}
+		proof, err := prover.LoadPlonkProof(proofPath)
+		if err != nil {
+			return err
+		}
+		err = prover.VerifyBlockProofPlonk(proof, vk, blockCommitment)
+		if err != nil {
+			return err
+		}
+	} else {
+		vk, err := prover.LoadVerifyingKey(filepath.Join(f.dir, manifest.VerifyingKey))
+		if err != nil {
+			return err
+		}
+		proof, err := prover.LoadProof(proofPath)
+		if err != nil {
+			return err
+		}
+		err = prover.VerifyBlockProof(proof, vk, blockCommitment)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Println("proof is valid")
+	return nil
+}
+
+func runInfo(args []string) error {
+	var f circuitFlags
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	f.registerShape(fs)
+	_ = fs.Parse(args)
+
+	blockSizes, err := f.parseBlockSizes()
+	if err != nil {
+		return err
+	}
+	gasAssetIds, err := f.parseGasAssetIds()
+	if err != nil {
+		return err
+	}
+	config, err := f.parseConfig()
+	if err != nil {
+		return err
+	}
+	hashType, err := f.parseHashType()
+	if err != nil {
+		return err
+	}
+	backendID, err := f.parseBackend()
+	if err != nil {
+		return err
+	}
+	for _, blockSize := range blockSizes {
+		blockCircuit, err := compile(backendID, blockSize, gasAssetIds, f.gasAccountIndex, hashType, config)
+		if err != nil {
+			return err
+		}
+		nbInternal, nbSecret, nbPublic := blockCircuit.Ccs.GetNbVariables()
+		fmt.Printf("block size %d (%s): %d constraints, %d public, %d secret, %d internal variables\n",
+			blockSize, backendID, blockCircuit.Ccs.GetNbConstraints(), nbPublic, nbSecret, nbInternal)
+	}
+	return nil
+}
+
+func runExodusSetup(args []string) error {
+	var f circuitFlags
+	fs := flag.NewFlagSet("exodus-setup", flag.ExitOnError)
+	f.registerConfig(fs)
+	f.registerDir(fs)
+	_ = fs.Parse(args)
+
+	config, err := f.parseConfig()
+	if err != nil {
+		return err
+	}
+	hashType, err := f.parseHashType()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+	for _, nft := range []bool{false, true} {
+		var exodusCircuit *prover.ExodusCircuit
+		if nft {
+			exodusCircuit, err = prover.CompileExodusNftCircuit(hashType, config)
+		} else {
+			exodusCircuit, err = prover.CompileExodusCircuit(hashType, config)
+		}
+		if err != nil {
+			return err
+		}
+		files := newExodusKeyFiles(f.dir, nft)
+		pk, vk, err := prover.SetupExodus(exodusCircuit)
+		if err != nil {
+			return err
+		}
+		if err = prover.SaveKeys(pk, vk, files.provingKey, files.verifyingKey); err != nil {
+			return err
+		}
+		if err = prover.ExportSolidity(vk, files.solidity); err != nil {
+			return err
+		}
+		fmt.Printf("exodus circuit (nft %t): %d constraints, verifier written to %s\n",
+			nft, exodusCircuit.Ccs.GetNbConstraints(), files.solidity)
+	}
+	return nil
+}
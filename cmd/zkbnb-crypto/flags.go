@@ -0,0 +1,189 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/backend"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/prover"
+)
+
+type circuitFlags struct {
+	blockSizes      string
+	gasAssetIds     string
+	gasAccountIndex int64
+	config          string
+	hash            string
+	backend         string
+	dir             string
+}
+
+func (f *circuitFlags) registerShape(fs *flag.FlagSet) {
+	fs.StringVar(&f.blockSizes, "block-sizes", "1,10", "comma separated list of block sizes (txs per block)")
+	fs.StringVar(&f.gasAssetIds, "gas-asset-ids", "0,1", "comma separated list of gas asset ids")
+	fs.Int64Var(&f.gasAccountIndex, "gas-account-index", 1, "index of the gas account")
+	f.registerConfig(fs)
+	f.registerBackend(fs)
+}
+
+func (f *circuitFlags) registerConfig(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", "mainnet", "depth of the state trees, mainnet or test")
+	fs.StringVar(&f.hash, "hash", "mimc", "hash of the state trees, mimc or poseidon")
+}
+
+func (f *circuitFlags) registerBackend(fs *flag.FlagSet) {
+	fs.StringVar(&f.backend, "backend", "groth16", "proving backend, groth16 or plonk")
+}
+
+func (f *circuitFlags) registerDir(fs *flag.FlagSet) {
+	fs.StringVar(&f.dir, "dir", ".", "directory of the keys and manifests")
+}
+
+func (f *circuitFlags) parseBlockSizes() ([]int, error) {
+	values, err := parseInt64List(f.blockSizes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block sizes: %v", err)
+	}
+	blockSizes := make([]int, len(values))
+	for i := 0; i < len(values); i++ {
+		if values[i] <= 0 {
+			return nil, fmt.Errorf("invalid block size %d", values[i])
+		}
+		blockSizes[i] = int(values[i])
+	}
+	return blockSizes, nil
+}
+
+func (f *circuitFlags) parseGasAssetIds() ([]int64, error) {
+	gasAssetIds, err := parseInt64List(f.gasAssetIds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas asset ids: %v", err)
+	}
+	return gasAssetIds, nil
+}
+
+func (f *circuitFlags) parseConfig() (types.CircuitConfig, error) {
+	switch f.config {
+	case "mainnet":
+		return types.MainnetConfig, nil
+	case "test":
+		return types.TestConfig, nil
+	default:
+		return types.CircuitConfig{}, fmt.Errorf("unsupported config %q", f.config)
+	}
+}
+
+func (f *circuitFlags) parseHashType() (types.HashType, error) {
+	switch f.hash {
+	case "mimc":
+		return types.MiMCHashType, nil
+	case "poseidon":
+		return types.PoseidonHashType, nil
+	default:
+		return types.MiMCHashType, fmt.Errorf("unsupported hash %q", f.hash)
+	}
+}
+
+func (f *circuitFlags) parseBackend() (backend.ID, error) {
+	switch f.backend {
+	case "groth16":
+		return backend.GROTH16, nil
+	case "plonk":
+		return backend.PLONK, nil
+	default:
+		return backend.UNKNOWN, fmt.Errorf("unsupported backend %q", f.backend)
+	}
+}
+
+func parseInt64List(s string) ([]int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("empty list")
+	}
+	parts := strings.Split(s, ",")
+	values := make([]int64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func compile(
+	backendID backend.ID, txsCount int, gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig,
+) (*prover.BlockCircuit, error) {
+	if backendID == backend.PLONK {
+		return prover.CompileBlockCircuitPlonk(txsCount, gasAssetIds, gasAccountIndex, hashType, config)
+	}
+	return prover.CompileBlockCircuit(txsCount, gasAssetIds, gasAccountIndex, hashType, config)
+}
+
+/*
+	keyFiles: file names of the keys of a block size, groth16 files keep the
+	names of the former exportSol test and plonk files carry a _plonk suffix.
+	There is no plonk proving key file, it is rebuilt from the srs
+*/
+type keyFiles struct {
+	manifest     string
+	provingKey   string
+	verifyingKey string
+	srs          string
+	solidity     string
+}
+
+func newKeyFiles(dir string, backendID backend.ID, txsCount int) keyFiles {
+	name := "zkbnb" + strconv.Itoa(txsCount)
+	if backendID == backend.PLONK {
+		return keyFiles{
+			manifest:     filepath.Join(dir, name+".manifest_plonk"),
+			verifyingKey: filepath.Join(dir, name+".vk_plonk"),
+			srs:          filepath.Join(dir, name+".srs_plonk"),
+		}
+	}
+	return keyFiles{
+		manifest:     filepath.Join(dir, name+".manifest"),
+		provingKey:   filepath.Join(dir, name+".pk"),
+		verifyingKey: filepath.Join(dir, name+".vk"),
+		solidity:     filepath.Join(dir, "ZkBNBVerifier"+strconv.Itoa(txsCount)+".sol"),
+	}
+}
+
+func newExodusKeyFiles(dir string, nft bool) keyFiles {
+	if nft {
+		return keyFiles{
+			provingKey:   filepath.Join(dir, "exodus_nft.pk"),
+			verifyingKey: filepath.Join(dir, "exodus_nft.vk"),
+			solidity:     filepath.Join(dir, "ZkBNBExodusNftVerifier.sol"),
+		}
+	}
+	return keyFiles{
+		provingKey:   filepath.Join(dir, "exodus.pk"),
+		verifyingKey: filepath.Join(dir, "exodus.vk"),
+		solidity:     filepath.Join(dir, "ZkBNBExodusVerifier.sol"),
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+func TestCircuitFlags(t *testing.T) {
+	f := circuitFlags{blockSizes: "1, 10", gasAssetIds: "0,1", config: "test", hash: "poseidon", backend: "plonk"}
+	blockSizes, err := f.parseBlockSizes()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 10}, blockSizes)
+	gasAssetIds, err := f.parseGasAssetIds()
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{0, 1}, gasAssetIds)
+	config, err := f.parseConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, types.TestConfig, config)
+	hashType, err := f.parseHashType()
+	assert.Nil(t, err)
+	assert.Equal(t, types.PoseidonHashType, hashType)
+	backendID, err := f.parseBackend()
+	assert.Nil(t, err)
+	assert.Equal(t, backend.PLONK, backendID)
+
+	f = circuitFlags{blockSizes: "0", gasAssetIds: "a", config: "devnet", hash: "sha256", backend: "stark"}
+	_, err = f.parseBlockSizes()
+	assert.NotNil(t, err)
+	_, err = f.parseGasAssetIds()
+	assert.NotNil(t, err)
+	_, err = f.parseConfig()
+	assert.NotNil(t, err)
+	_, err = f.parseHashType()
+	assert.NotNil(t, err)
+	_, err = f.parseBackend()
+	assert.NotNil(t, err)
+}
+
+func TestKeyFiles(t *testing.T) {
+	files := newKeyFiles("keys", backend.GROTH16, 10)
+	assert.Equal(t, "keys/zkbnb10.pk", files.provingKey)
+	assert.Equal(t, "keys/ZkBNBVerifier10.sol", files.solidity)
+	assert.Equal(t, "", files.srs)
+	files = newKeyFiles("keys", backend.PLONK, 1)
+	assert.Equal(t, "keys/zkbnb1.srs_plonk", files.srs)
+	assert.Equal(t, "", files.solidity)
+	files = newExodusKeyFiles("keys", true)
+	assert.Equal(t, "keys/exodus_nft.vk", files.verifyingKey)
+	assert.Equal(t, "keys/ZkBNBExodusNftVerifier.sol", files.solidity)
+}
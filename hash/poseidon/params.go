@@ -0,0 +1,185 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package poseidon
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+/*
+	Params: parameters of the poseidon permutation of width T over the bn254 scalar field.
+	They are generated like the reference implementation and circomlib, the x^5 sbox
+	is used with 8 full rounds and the partial rounds of circomlib.
+*/
+type Params struct {
+	T               int
+	NbFullRounds    int
+	NbPartialRounds int
+	// (NbFullRounds + NbPartialRounds) * T constants, added at the start of every round
+	RoundConstants []fr.Element
+	MDS            [][]fr.Element
+}
+
+const (
+	MinWidth = 2
+	MaxWidth = 17
+	// max number of elements hashed at once, longer inputs are chained
+	MaxInputs = MaxWidth - 1
+
+	nbFullRounds = 8
+)
+
+// partial rounds by width, starting at MinWidth
+var nbPartialRounds = [...]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+var (
+	paramsLock sync.Mutex
+	params     = make(map[int]*Params)
+)
+
+/*
+	GetParams: parameters of the permutation of width t, generated once
+*/
+func GetParams(t int) *Params {
+	if t < MinWidth || t > MaxWidth {
+		panic("poseidon: unsupported width")
+	}
+	paramsLock.Lock()
+	defer paramsLock.Unlock()
+	p, exist := params[t]
+	if !exist {
+		p = generateParams(t)
+		params[t] = p
+	}
+	return p
+}
+
+/*
+	grain: the grain LFSR used to sample the parameters
+*/
+type grain struct {
+	state [80]bool
+}
+
+func newGrain(fieldSize int, t int, nbFullRounds int, nbPartialRounds int) *grain {
+	g := new(grain)
+	bits := g.state[:0]
+	appendBits := func(v int, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, (v>>uint(i))&1 == 1)
+		}
+	}
+	// prime field, x^alpha sbox
+	appendBits(1, 2)
+	appendBits(0, 4)
+	appendBits(fieldSize, 12)
+	appendBits(t, 12)
+	appendBits(nbFullRounds, 10)
+	appendBits(nbPartialRounds, 10)
+	appendBits(1<<30-1, 30)
+	for i := 0; i < 160; i++ {
+		g.next()
+	}
+	return g
+}
+
+func (g *grain) next() bool {
+	s := &g.state
+	bit := s[62] != s[51] != s[38] != s[23] != s[13] != s[0]
+	copy(s[:], s[1:])
+	s[79] = bit
+	return bit
+}
+
+// bits are sampled in pairs, the second one is kept when the first one is set
+func (g *grain) nextBit() bool {
+	for {
+		if g.next() {
+			return g.next()
+		}
+		g.next()
+	}
+}
+
+func (g *grain) nextInt(n int) *big.Int {
+	v := new(big.Int)
+	for i := 0; i < n; i++ {
+		v.Lsh(v, 1)
+		if g.nextBit() {
+			v.SetBit(v, 0, 1)
+		}
+	}
+	return v
+}
+
+func generateParams(t int) *Params {
+	modulus := fr.Modulus()
+	fieldSize := modulus.BitLen()
+	p := &Params{
+		T:               t,
+		NbFullRounds:    nbFullRounds,
+		NbPartialRounds: nbPartialRounds[t-MinWidth],
+	}
+	g := newGrain(fieldSize, t, p.NbFullRounds, p.NbPartialRounds)
+
+	p.RoundConstants = make([]fr.Element, (p.NbFullRounds+p.NbPartialRounds)*t)
+	for i := range p.RoundConstants {
+		v := g.nextInt(fieldSize)
+		for v.Cmp(modulus) >= 0 {
+			v = g.nextInt(fieldSize)
+		}
+		p.RoundConstants[i].SetBigInt(v)
+	}
+
+	// cauchy matrix 1 / (x_i + y_j)
+	for {
+		xs := make([]fr.Element, 2*t)
+		for i := range xs {
+			xs[i].SetBigInt(g.nextInt(fieldSize))
+		}
+		if p.MDS = cauchyMatrix(xs[:t], xs[t:]); p.MDS != nil {
+			return p
+		}
+	}
+}
+
+func cauchyMatrix(xs []fr.Element, ys []fr.Element) [][]fr.Element {
+	all := append(append([]fr.Element{}, xs...), ys...)
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			if all[i].Equal(&all[j]) {
+				return nil
+			}
+		}
+	}
+	m := make([][]fr.Element, len(xs))
+	for i := range xs {
+		m[i] = make([]fr.Element, len(ys))
+		for j := range ys {
+			m[i][j].Add(&xs[i], &ys[j])
+			if m[i][j].IsZero() {
+				return nil
+			}
+			m[i][j].Inverse(&m[i][j])
+		}
+	}
+	return m
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package poseidon
+
+import (
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+const BlockSize = fr.Bytes
+
+/*
+	Permute: the poseidon permutation of the state, its width selects the parameters
+*/
+func Permute(state []fr.Element) {
+	p := GetParams(len(state))
+	t := p.T
+	nbRounds := p.NbFullRounds + p.NbPartialRounds
+	tmp := make([]fr.Element, t)
+	for r := 0; r < nbRounds; r++ {
+		for i := range state {
+			state[i].Add(&state[i], &p.RoundConstants[r*t+i])
+		}
+		if r < p.NbFullRounds/2 || r >= p.NbFullRounds/2+p.NbPartialRounds {
+			for i := range state {
+				sbox(&state[i])
+			}
+		} else {
+			sbox(&state[0])
+		}
+		for i := range tmp {
+			tmp[i].SetZero()
+			for j := range state {
+				var v fr.Element
+				v.Mul(&p.MDS[i][j], &state[j])
+				tmp[i].Add(&tmp[i], &v)
+			}
+		}
+		copy(state, tmp)
+	}
+}
+
+func sbox(x *fr.Element) {
+	var x2 fr.Element
+	x2.Square(x)
+	x2.Square(&x2)
+	x.Mul(x, &x2)
+}
+
+/*
+	Hash: poseidon hash of the inputs compatible with circomlib, the inputs
+	following the first MaxInputs are chained as Hash(h, next inputs...)
+*/
+func Hash(inputs ...fr.Element) fr.Element {
+	if len(inputs) == 0 {
+		inputs = make([]fr.Element, 1)
+	}
+	n := len(inputs)
+	if n > MaxInputs {
+		n = MaxInputs
+	}
+	state := make([]fr.Element, n+1)
+	copy(state[1:], inputs[:n])
+	Permute(state)
+	h := state[0]
+	for inputs = inputs[n:]; len(inputs) > 0; inputs = inputs[n:] {
+		n = len(inputs)
+		if n > MaxInputs-1 {
+			n = MaxInputs - 1
+		}
+		state = make([]fr.Element, n+2)
+		state[1] = h
+		copy(state[2:], inputs[:n])
+		Permute(state)
+		h = state[0]
+	}
+	return h
+}
+
+/*
+	digest: hash.Hash of the written data split in 32 bytes big endian field elements,
+	the last block is left padded with zeros like the mimc digest
+*/
+type digest struct {
+	data []byte
+}
+
+func NewPoseidon() hash.Hash {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+func (d *digest) Reset() {
+	d.data = nil
+}
+
+func (d *digest) Write(p []byte) (n int, err error) {
+	d.data = append(d.data, p...)
+	return len(p), nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	nbBlocks := (len(d.data) + BlockSize - 1) / BlockSize
+	inputs := make([]fr.Element, nbBlocks)
+	for i := range inputs {
+		var block [BlockSize]byte
+		if r := len(d.data) % BlockSize; i == nbBlocks-1 && r != 0 {
+			copy(block[BlockSize-r:], d.data[i*BlockSize:])
+		} else {
+			copy(block[:], d.data[i*BlockSize:(i+1)*BlockSize])
+		}
+		inputs[i].SetBytes(block[:])
+	}
+	h := Hash(inputs...)
+	res := h.Bytes()
+	return append(b, res[:]...)
+}
+
+func (d *digest) Size() int {
+	return BlockSize
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func elements(values ...uint64) []fr.Element {
+	res := make([]fr.Element, len(values))
+	for i, v := range values {
+		res[i].SetUint64(v)
+	}
+	return res
+}
+
+func TestHash(t *testing.T) {
+	// circomlib test vectors
+	vectors := []struct {
+		inputs   []fr.Element
+		expected string
+	}{
+		{elements(1), "18586133768512220936620570745912940619677854269274689475585506675881198879027"},
+		{elements(1, 2), "7853200120776062878684798364095072458815029376092732009249414926327459813530"},
+		{elements(1, 2, 3, 4), "18821383157269793795438455681495246036402687001665670618754263018637548127333"},
+	}
+	for _, v := range vectors {
+		h := Hash(v.inputs...)
+		assert.Equal(t, v.expected, h.String())
+	}
+
+	// longer inputs are chained
+	inputs := elements(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18)
+	h := Hash(inputs[:MaxInputs]...)
+	expected := Hash(append([]fr.Element{h}, inputs[MaxInputs:]...)...)
+	assert.Equal(t, expected, Hash(inputs...))
+}
+
+func TestDigest(t *testing.T) {
+	hFunc := NewPoseidon()
+	assert.Equal(t, BlockSize, hFunc.Size())
+	inputs := elements(1, 2, 3)
+	for i := range inputs {
+		b := inputs[i].Bytes()
+		hFunc.Write(b[:])
+	}
+	expected := Hash(inputs...)
+	assert.Equal(t, expected.Bytes(), toArray(hFunc.Sum(nil)))
+	// Sum doesn't change the state
+	assert.Equal(t, expected.Bytes(), toArray(hFunc.Sum(nil)))
+
+	// a short block is left padded
+	hFunc.Reset()
+	hFunc.Write([]byte{1, 2})
+	expected = Hash(elements(0x0102)...)
+	assert.Equal(t, expected.Bytes(), toArray(hFunc.Sum(nil)))
+
+	// nothing written hashes a zero element
+	hFunc.Reset()
+	expected = Hash(elements(0)...)
+	assert.Equal(t, expected.Bytes(), toArray(hFunc.Sum(nil)))
+}
+
+func TestParams(t *testing.T) {
+	for width := MinWidth; width <= MaxWidth; width++ {
+		p := GetParams(width)
+		assert.Equal(t, width, p.T)
+		assert.Len(t, p.RoundConstants, (p.NbFullRounds+p.NbPartialRounds)*width)
+		assert.Len(t, p.MDS, width)
+	}
+	// first round constant of circomlib for 2 inputs
+	c := GetParams(3).RoundConstants[0]
+	expected, _ := new(big.Int).SetString("6745197990210204598374042828761989596302876299545964402857411729872131034734", 10)
+	assert.Equal(t, expected, c.ToBigIntRegular(new(big.Int)))
+	assert.Panics(t, func() { GetParams(MaxWidth + 1) })
+}
+
+func toArray(b []byte) (res [BlockSize]byte) {
+	copy(res[:], b)
+	return res
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+/*
+	Package aggregation is an experiment folding groth16 block proofs over BLS12-377 into one
+	BW6-761 proof. The block circuit is BN254 only, so it can't aggregate a real block yet,
+	see docs/block-proof-aggregation.md
+*/
+package aggregation
+
+import (
+	"errors"
+	"log"
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsFr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fr/mimc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+type (
+	Variable = frontend.Variable
+	API      = frontend.API
+
+	ConstraintSystem = frontend.CompiledConstraintSystem
+	ProvingKey       = groth16.ProvingKey
+	VerifyingKey     = groth16.VerifyingKey
+	Proof            = groth16.Proof
+)
+
+/*
+	NbAggregatedBlockPublicInputs: a block proof folded by the aggregation circuit
+	has the public inputs OldStateRoot, NewStateRoot and BlockCommitment, in that order
+*/
+const NbAggregatedBlockPublicInputs = 3
+
+/*
+	AggregatedBlock: public inputs of a block proof over BLS12-377, they are
+	elements of the BLS12-377 scalar field
+*/
+type AggregatedBlock struct {
+	OldStateRoot    []byte
+	NewStateRoot    []byte
+	BlockCommitment []byte
+}
+
+/*
+	BlockVerifyingKey: groth16 verifying key of the block proofs over BLS12-377,
+	K has one point for the constant wire and one for each public input
+*/
+type BlockVerifyingKey struct {
+	Alpha              bls12377.G1Affine
+	Beta, Gamma, Delta bls12377.G2Affine
+	K                  []bls12377.G1Affine
+}
+
+/*
+	BlockProof: groth16 proof of a block over BLS12-377
+*/
+type BlockProof struct {
+	Ar, Krs bls12377.G1Affine
+	Bs      bls12377.G2Affine
+}
+
+/*
+	ComputeAggregatedCommitment: MiMC over the BW6-761 scalar field of the block
+	commitments, in block order. It is the public input of the aggregated proof
+	next to the old state root of the first block and the new state root of the last one
+*/
+func ComputeAggregatedCommitment(blocks []*AggregatedBlock) (commitment []byte, err error) {
+	if len(blocks) == 0 {
+		log.Println("[ComputeAggregatedCommitment] no block to aggregate")
+		return nil, errors.New("[ComputeAggregatedCommitment] no block to aggregate")
+	}
+	hFunc := mimc.NewMiMC()
+	for _, block := range blocks {
+		if block == nil || !isBlockScalar(block.BlockCommitment) {
+			log.Println("[ComputeAggregatedCommitment] invalid block commitment")
+			return nil, errors.New("[ComputeAggregatedCommitment] invalid block commitment")
+		}
+		hFunc.Write(new(big.Int).SetBytes(block.BlockCommitment).FillBytes(make([]byte, mimc.BlockSize)))
+	}
+	return hFunc.Sum(nil), nil
+}
+
+/*
+	isBlockScalar: the value is an element of the BLS12-377 scalar field, the field
+	of the public inputs of the block proofs
+*/
+func isBlockScalar(value []byte) bool {
+	return value != nil && new(big.Int).SetBytes(value).Cmp(blsFr.Modulus()) < 0
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package aggregation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsFp "github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	blsFr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/std/algebra/fields_bls12377"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+	"github.com/consensys/gnark/std/groth16_bls12377"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+/*
+	AggregatedBlockConstraints: public inputs and proof of a block folded by the aggregation circuit
+*/
+type AggregatedBlockConstraints struct {
+	OldStateRoot    Variable
+	NewStateRoot    Variable
+	BlockCommitment Variable
+	Proof           groth16_bls12377.Proof
+}
+
+/*
+	AggregationConstraints: verifies the BLS12-377 proofs of consecutive blocks in
+	a BW6-761 circuit. The state roots of the blocks are chained from OldStateRoot
+	to NewStateRoot and their commitments are hashed into AggregatedCommitment.
+	The verifying key of the block proofs is compiled into the circuit
+*/
+type AggregationConstraints struct {
+	OldStateRoot         Variable `gnark:",public"`
+	NewStateRoot         Variable `gnark:",public"`
+	AggregatedCommitment Variable `gnark:",public"`
+	Blocks               []AggregatedBlockConstraints
+	BlockVk              groth16_bls12377.VerifyingKey `gnark:"-"`
+}
+
+/*
+	NewAggregationConstraints: construct an empty aggregation circuit of blocksCount blocks
+*/
+func NewAggregationConstraints(blocksCount int, blockVk *BlockVerifyingKey) (circuit AggregationConstraints, err error) {
+	if blocksCount <= 0 {
+		log.Println("[NewAggregationConstraints] invalid blocks count:", blocksCount)
+		return circuit, fmt.Errorf("[NewAggregationConstraints] invalid blocks count: %d", blocksCount)
+	}
+	circuit.BlockVk, err = setBlockVerifyingKeyConstants(blockVk)
+	if err != nil {
+		return circuit, err
+	}
+	circuit.Blocks = make([]AggregatedBlockConstraints, blocksCount)
+	return circuit, nil
+}
+
+func (circuit AggregationConstraints) Define(api API) error {
+	return VerifyAggregation(api, circuit)
+}
+
+func VerifyAggregation(api API, aggregation AggregationConstraints) (err error) {
+	if len(aggregation.Blocks) == 0 {
+		return errors.New("[VerifyAggregation] no block to aggregate")
+	}
+	if len(aggregation.BlockVk.G1) != NbAggregatedBlockPublicInputs+1 {
+		return errors.New("[VerifyAggregation] the block verifying key is not set")
+	}
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	// the public inputs of the block proofs are BLS12-377 scalars, they are bounded so that
+	// a root of the chain can't be replaced with another representative of the same scalar
+	lastBlockScalar := new(big.Int).Sub(blsFr.Modulus(), big.NewInt(1))
+	api.AssertIsEqual(aggregation.Blocks[0].OldStateRoot, aggregation.OldStateRoot)
+	for i, block := range aggregation.Blocks {
+		if i != 0 {
+			api.AssertIsEqual(block.OldStateRoot, aggregation.Blocks[i-1].NewStateRoot)
+		}
+		publicInputs := []Variable{block.OldStateRoot, block.NewStateRoot, block.BlockCommitment}
+		for _, publicInput := range publicInputs {
+			api.AssertIsLessOrEqual(publicInput, lastBlockScalar)
+		}
+		groth16_bls12377.Verify(api, aggregation.BlockVk, block.Proof, publicInputs)
+		hFunc.Write(block.BlockCommitment)
+	}
+	api.AssertIsEqual(aggregation.Blocks[len(aggregation.Blocks)-1].NewStateRoot, aggregation.NewStateRoot)
+	api.AssertIsEqual(hFunc.Sum(), aggregation.AggregatedCommitment)
+	return nil
+}
+
+/*
+	SetAggregationWitness: witness of the aggregation circuit for consecutive blocks and their proofs
+*/
+func SetAggregationWitness(blocks []*AggregatedBlock, proofs []*BlockProof, blockVk *BlockVerifyingKey) (witness AggregationConstraints, err error) {
+	if len(blocks) == 0 || len(blocks) != len(proofs) {
+		log.Println("[SetAggregationWitness] invalid blocks or proofs")
+		return witness, errors.New("[SetAggregationWitness] invalid blocks or proofs")
+	}
+	aggregatedCommitment, err := ComputeAggregatedCommitment(blocks)
+	if err != nil {
+		return witness, err
+	}
+	witness, err = NewAggregationConstraints(len(blocks), blockVk)
+	if err != nil {
+		return witness, err
+	}
+	witness.OldStateRoot = blocks[0].OldStateRoot
+	witness.NewStateRoot = blocks[len(blocks)-1].NewStateRoot
+	witness.AggregatedCommitment = aggregatedCommitment
+	for i, block := range blocks {
+		if proofs[i] == nil || !isBlockScalar(block.OldStateRoot) || !isBlockScalar(block.NewStateRoot) {
+			log.Println("[SetAggregationWitness] invalid block:", i)
+			return witness, fmt.Errorf("[SetAggregationWitness] invalid block: %d", i)
+		}
+		witness.Blocks[i] = AggregatedBlockConstraints{
+			OldStateRoot:    block.OldStateRoot,
+			NewStateRoot:    block.NewStateRoot,
+			BlockCommitment: block.BlockCommitment,
+			Proof: groth16_bls12377.Proof{
+				Ar:  setG1Affine(&proofs[i].Ar),
+				Krs: setG1Affine(&proofs[i].Krs),
+				Bs:  setG2Affine(&proofs[i].Bs),
+			},
+		}
+	}
+	return witness, nil
+}
+
+/*
+	setBlockVerifyingKeyConstants: the verifying key in the form groth16_bls12377.Verify
+	takes, e(alpha, beta) is precomputed and gamma and delta are negated
+*/
+func setBlockVerifyingKeyConstants(blockVk *BlockVerifyingKey) (vk groth16_bls12377.VerifyingKey, err error) {
+	if blockVk == nil || len(blockVk.K) != NbAggregatedBlockPublicInputs+1 {
+		log.Println("[setBlockVerifyingKeyConstants] the block verifying key should have", NbAggregatedBlockPublicInputs, "public inputs")
+		return vk, fmt.Errorf("[setBlockVerifyingKeyConstants] the block verifying key should have %d public inputs", NbAggregatedBlockPublicInputs)
+	}
+	e, err := bls12377.Pair([]bls12377.G1Affine{blockVk.Alpha}, []bls12377.G2Affine{blockVk.Beta})
+	if err != nil {
+		log.Println("[setBlockVerifyingKeyConstants] unable to compute e(alpha, beta):", err)
+		return vk, err
+	}
+	vk.E = fields_bls12377.E12{
+		C0: setE6(&e.C0),
+		C1: setE6(&e.C1),
+	}
+	var gammaNeg, deltaNeg bls12377.G2Affine
+	gammaNeg.Neg(&blockVk.Gamma)
+	deltaNeg.Neg(&blockVk.Delta)
+	vk.G2.GammaNeg = setG2Affine(&gammaNeg)
+	vk.G2.DeltaNeg = setG2Affine(&deltaNeg)
+	vk.G1 = make([]sw_bls12377.G1Affine, len(blockVk.K))
+	for i := range blockVk.K {
+		vk.G1[i] = setG1Affine(&blockVk.K[i])
+	}
+	return vk, nil
+}
+
+func setG1Affine(p *bls12377.G1Affine) sw_bls12377.G1Affine {
+	return sw_bls12377.G1Affine{X: setFp(&p.X), Y: setFp(&p.Y)}
+}
+
+func setG2Affine(p *bls12377.G2Affine) sw_bls12377.G2Affine {
+	return sw_bls12377.G2Affine{X: setE2(&p.X), Y: setE2(&p.Y)}
+}
+
+func setE6(e *bls12377.E6) fields_bls12377.E6 {
+	return fields_bls12377.E6{B0: setE2(&e.B0), B1: setE2(&e.B1), B2: setE2(&e.B2)}
+}
+
+func setE2(e *bls12377.E2) fields_bls12377.E2 {
+	return fields_bls12377.E2{A0: setFp(&e.A0), A1: setFp(&e.A1)}
+}
+
+func setFp(e *blsFp.Element) *big.Int {
+	return e.ToBigIntRegular(new(big.Int))
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package aggregation
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	mimcConstraints "github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+	testBlockConstraints: stand-in for a block circuit over BLS12-377 with the public inputs
+	of AggregatedBlock, the new state root and the commitment are hashes of the tx
+*/
+type testBlockConstraints struct {
+	OldStateRoot    Variable `gnark:",public"`
+	NewStateRoot    Variable `gnark:",public"`
+	BlockCommitment Variable `gnark:",public"`
+	Tx              Variable
+}
+
+func (c *testBlockConstraints) Define(api frontend.API) error {
+	hFunc, err := mimcConstraints.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	hFunc.Write(c.OldStateRoot, c.Tx)
+	api.AssertIsEqual(hFunc.Sum(), c.NewStateRoot)
+	hFunc.Reset()
+	hFunc.Write(c.OldStateRoot, c.NewStateRoot, c.Tx)
+	api.AssertIsEqual(hFunc.Sum(), c.BlockCommitment)
+	return nil
+}
+
+func testBlockHash(values ...[]byte) []byte {
+	hFunc := mimc.NewMiMC()
+	for _, value := range values {
+		hFunc.Write(new(big.Int).SetBytes(value).FillBytes(make([]byte, mimc.BlockSize)))
+	}
+	return hFunc.Sum(nil)
+}
+
+/*
+	proveTestBlocks: prove blocksCount chained stand-in blocks over BLS12-377
+*/
+func proveTestBlocks(t *testing.T, blocksCount int) (vk VerifyingKey, blocks []*AggregatedBlock, proofs []Proof) {
+	var blockConstraints testBlockConstraints
+	ccs, err := frontend.Compile(ecc.BLS12_377, r1cs.NewBuilder, &blockConstraints)
+	require.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	require.NoError(t, err)
+	stateRoot := big.NewInt(17).Bytes()
+	for i := 0; i < blocksCount; i++ {
+		tx := big.NewInt(int64(100 + i)).Bytes()
+		block := &AggregatedBlock{OldStateRoot: stateRoot, NewStateRoot: testBlockHash(stateRoot, tx)}
+		block.BlockCommitment = testBlockHash(block.OldStateRoot, block.NewStateRoot, tx)
+		witness, err := frontend.NewWitness(&testBlockConstraints{
+			OldStateRoot:    block.OldStateRoot,
+			NewStateRoot:    block.NewStateRoot,
+			BlockCommitment: block.BlockCommitment,
+			Tx:              tx,
+		}, ecc.BLS12_377)
+		require.NoError(t, err)
+		proof, err := groth16.Prove(ccs, pk, witness)
+		require.NoError(t, err)
+		blocks = append(blocks, block)
+		proofs = append(proofs, proof)
+		stateRoot = block.NewStateRoot
+	}
+	return vk, blocks, proofs
+}
+
+func TestAggregationConstraints(t *testing.T) {
+	vk, blocks, proofs := proveTestBlocks(t, 2)
+	blockVk, err := toBlockVerifyingKey(vk)
+	require.NoError(t, err)
+	blockProofs := make([]*BlockProof, len(proofs))
+	for i := range proofs {
+		blockProofs[i], err = toBlockProof(proofs[i])
+		require.NoError(t, err)
+	}
+	aggregationConstraints, err := NewAggregationConstraints(len(blocks), blockVk)
+	require.NoError(t, err)
+	witness, err := SetAggregationWitness(blocks, blockProofs, blockVk)
+	require.NoError(t, err)
+	assert.NoError(t, test.IsSolved(&aggregationConstraints, &witness, ecc.BW6_761, backend.GROTH16))
+
+	// the blocks should be chained
+	unchained := []*AggregatedBlock{blocks[0], blocks[0]}
+	witness, err = SetAggregationWitness(unchained, []*BlockProof{blockProofs[0], blockProofs[0]}, blockVk)
+	require.NoError(t, err)
+	assert.Error(t, test.IsSolved(&aggregationConstraints, &witness, ecc.BW6_761, backend.GROTH16))
+
+	// the public inputs should match the block proofs
+	witness, err = SetAggregationWitness(blocks, []*BlockProof{blockProofs[1], blockProofs[0]}, blockVk)
+	require.NoError(t, err)
+	assert.Error(t, test.IsSolved(&aggregationConstraints, &witness, ecc.BW6_761, backend.GROTH16))
+
+	_, err = NewAggregationConstraints(0, blockVk)
+	assert.Error(t, err)
+	_, err = CompileAggregationCircuit(1, nil)
+	assert.Error(t, err)
+}
+
+func TestProveAggregation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the BW6-761 setup in short mode")
+	}
+	vk, blocks, proofs := proveTestBlocks(t, 2)
+	aggregationCircuit, err := CompileAggregationCircuit(len(blocks), vk)
+	require.NoError(t, err)
+	pk, aggregationVk, err := SetupAggregation(aggregationCircuit)
+	require.NoError(t, err)
+	proof, err := ProveAggregation(aggregationCircuit, pk, blocks, proofs)
+	require.NoError(t, err)
+	commitment, err := ComputeAggregatedCommitment(blocks)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyAggregationProof(proof, aggregationVk, blocks[0].OldStateRoot, blocks[1].NewStateRoot, commitment))
+	assert.Error(t, VerifyAggregationProof(proof, aggregationVk, blocks[1].OldStateRoot, blocks[1].NewStateRoot, commitment))
+	_, err = ProveAggregation(aggregationCircuit, pk, blocks[:1], proofs[:1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package aggregation
+
+import (
+	"errors"
+	"log"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+/*
+	AggregationCircuit: compiled BW6-761 circuit folding BlocksCount block proofs
+	over BLS12-377 made with BlockVk
+*/
+type AggregationCircuit struct {
+	BlocksCount int
+	BlockVk     *BlockVerifyingKey
+	Ccs         ConstraintSystem
+}
+
+/*
+	CompileAggregationCircuit: compile AggregationConstraints for block proofs verified by blockVk,
+	a groth16 verifying key over BLS12-377 with the public inputs of AggregatedBlock
+*/
+func CompileAggregationCircuit(blocksCount int, blockVk VerifyingKey) (aggregationCircuit *AggregationCircuit, err error) {
+	oBlockVk, err := toBlockVerifyingKey(blockVk)
+	if err != nil {
+		return nil, err
+	}
+	aggregationConstraints, err := NewAggregationConstraints(blocksCount, oBlockVk)
+	if err != nil {
+		return nil, err
+	}
+	oCcs, err := frontend.Compile(ecc.BW6_761, r1cs.NewBuilder, &aggregationConstraints)
+	if err != nil {
+		log.Println("[CompileAggregationCircuit] unable to compile aggregation circuit:", err)
+		return nil, err
+	}
+	return &AggregationCircuit{BlocksCount: blocksCount, BlockVk: oBlockVk, Ccs: oCcs}, nil
+}
+
+/*
+	SetupAggregation: run groth16 setup for the compiled aggregation circuit
+	NOTICE: the keys generated here rely on local randomness and are for test purpose only
+*/
+func SetupAggregation(aggregationCircuit *AggregationCircuit) (pk ProvingKey, vk VerifyingKey, err error) {
+	if aggregationCircuit == nil || aggregationCircuit.Ccs == nil {
+		log.Println("[SetupAggregation] invalid aggregation circuit")
+		return nil, nil, errors.New("[SetupAggregation] invalid aggregation circuit")
+	}
+	pk, vk, err = groth16.Setup(aggregationCircuit.Ccs)
+	if err != nil {
+		log.Println("[SetupAggregation] unable to setup aggregation circuit:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+/*
+	ProveAggregation: fold the proofs of consecutive blocks into one proof, proofs[i] is the
+	BLS12-377 proof of blocks[i]
+*/
+func ProveAggregation(aggregationCircuit *AggregationCircuit, pk ProvingKey, blocks []*AggregatedBlock, proofs []Proof) (proof Proof, err error) {
+	if aggregationCircuit == nil || aggregationCircuit.Ccs == nil || pk == nil ||
+		len(blocks) != aggregationCircuit.BlocksCount || len(proofs) != aggregationCircuit.BlocksCount {
+		log.Println("[ProveAggregation] invalid params")
+		return nil, errors.New("[ProveAggregation] invalid params")
+	}
+	blockProofs := make([]*BlockProof, len(proofs))
+	for i := range proofs {
+		blockProofs[i], err = toBlockProof(proofs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	aggregationWitness, err := SetAggregationWitness(blocks, blockProofs, aggregationCircuit.BlockVk)
+	if err != nil {
+		return nil, err
+	}
+	fullWitness, err := frontend.NewWitness(&aggregationWitness, ecc.BW6_761)
+	if err != nil {
+		log.Println("[ProveAggregation] unable to generate witness:", err)
+		return nil, err
+	}
+	proof, err = groth16.Prove(aggregationCircuit.Ccs, pk, fullWitness)
+	if err != nil {
+		log.Println("[ProveAggregation] unable to generate proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	VerifyAggregationProof: verify an aggregated proof against the old state root of the first
+	block, the new state root of the last one and ComputeAggregatedCommitment of the blocks
+*/
+func VerifyAggregationProof(proof Proof, vk VerifyingKey, oldStateRoot, newStateRoot, aggregatedCommitment []byte) (err error) {
+	if proof == nil || vk == nil {
+		log.Println("[VerifyAggregationProof] invalid params")
+		return errors.New("[VerifyAggregationProof] invalid params")
+	}
+	aggregationWitness := AggregationConstraints{
+		OldStateRoot:         oldStateRoot,
+		NewStateRoot:         newStateRoot,
+		AggregatedCommitment: aggregatedCommitment,
+	}
+	publicWitness, err := frontend.NewWitness(&aggregationWitness, ecc.BW6_761, frontend.PublicOnly())
+	if err != nil {
+		log.Println("[VerifyAggregationProof] unable to generate public witness:", err)
+		return err
+	}
+	err = groth16.Verify(proof, vk, publicWitness)
+	if err != nil {
+		log.Println("[VerifyAggregationProof] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	toBlockVerifyingKey: the points of a BLS12-377 groth16 verifying key, gnark v0.7 keeps
+	its concrete type internal so they are read by field name
+*/
+func toBlockVerifyingKey(vk VerifyingKey) (blockVk *BlockVerifyingKey, err error) {
+	if vk == nil || vk.CurveID() != ecc.BLS12_377 {
+		log.Println("[toBlockVerifyingKey] the block verifying key should be a BLS12-377 key")
+		return nil, errors.New("[toBlockVerifyingKey] the block verifying key should be a BLS12-377 key")
+	}
+	v := reflect.Indirect(reflect.ValueOf(vk))
+	blockVk = &BlockVerifyingKey{}
+	ok := readField(v, &blockVk.Alpha, "G1", "Alpha") &&
+		readField(v, &blockVk.K, "G1", "K") &&
+		readField(v, &blockVk.Beta, "G2", "Beta") &&
+		readField(v, &blockVk.Gamma, "G2", "Gamma") &&
+		readField(v, &blockVk.Delta, "G2", "Delta")
+	if !ok {
+		log.Println("[toBlockVerifyingKey] unexpected verifying key layout")
+		return nil, errors.New("[toBlockVerifyingKey] unexpected verifying key layout")
+	}
+	return blockVk, nil
+}
+
+/*
+	toBlockProof: the points of a BLS12-377 groth16 proof
+*/
+func toBlockProof(proof Proof) (blockProof *BlockProof, err error) {
+	if proof == nil || proof.CurveID() != ecc.BLS12_377 {
+		log.Println("[toBlockProof] the block proof should be a BLS12-377 proof")
+		return nil, errors.New("[toBlockProof] the block proof should be a BLS12-377 proof")
+	}
+	v := reflect.Indirect(reflect.ValueOf(proof))
+	blockProof = &BlockProof{}
+	ok := readField(v, &blockProof.Ar, "Ar") &&
+		readField(v, &blockProof.Krs, "Krs") &&
+		readField(v, &blockProof.Bs, "Bs")
+	if !ok {
+		log.Println("[toBlockProof] unexpected proof layout")
+		return nil, errors.New("[toBlockProof] unexpected proof layout")
+	}
+	return blockProof, nil
+}
+
+/*
+	readField: copy the field at path of the struct v into dst if it has the type of dst
+*/
+func readField(v reflect.Value, dst interface{}, path ...string) bool {
+	for _, name := range path {
+		if v.Kind() != reflect.Struct {
+			return false
+		}
+		v = v.FieldByName(name)
+	}
+	out := reflect.ValueOf(dst).Elem()
+	if !v.IsValid() || !v.CanInterface() || v.Type() != out.Type() {
+		return false
+	}
+	out.Set(v)
+	return true
+}
+
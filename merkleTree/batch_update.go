@@ -0,0 +1,143 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+/*
+	BatchUpdate: set several leaves at once and return the new root, every
+	ancestor of the leaves is hashed once. Subtrees holding updated leaves on
+	both sides are rehashed in parallel when NewHashFunc is set.
+*/
+func (t *Tree) BatchUpdate(leaves map[int64][]byte) (root []byte, err error) {
+	indexes, err := t.setLeaves(leaves)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		return t.RootNode.Value, nil
+	}
+	// forks down to about two goroutines per cpu
+	parallelDepth := 0
+	if t.NewHashFunc != nil {
+		for 1<<parallelDepth < 2*runtime.NumCPU() {
+			parallelDepth++
+		}
+	}
+	t.rehash(t.RootNode, indexes, t.HashFunc, parallelDepth)
+	return t.RootNode.Value, nil
+}
+
+/*
+	BatchUpdateWithProofs: BatchUpdate returning the proofs of the updated leaves
+	against the new root, see BuildMerkleProofs for the layout of the proofs
+*/
+func (t *Tree) BatchUpdateWithProofs(leaves map[int64][]byte) (root []byte, proofs map[int64][][]byte, err error) {
+	root, err = t.BatchUpdate(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofs = make(map[int64][][]byte, len(leaves))
+	for index := range leaves {
+		proofs[index], _, err = t.BuildMerkleProofs(index)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return root, proofs, nil
+}
+
+/*
+	setLeaves: create the missing nodes of the paths of the leaves and set
+	their values, returns the sorted indexes of the leaves
+*/
+func (t *Tree) setLeaves(leaves map[int64][]byte) (indexes []int64, err error) {
+	indexes = make([]int64, 0, len(leaves))
+	for index := range leaves {
+		if index < 0 || index >= 1<<t.MaxHeight {
+			errInfo := fmt.Sprintf("[BatchUpdate] index error, index: %v is out of tree capacity: %v.", index, int64(1)<<t.MaxHeight)
+			log.Println(errInfo)
+			return nil, errors.New(errInfo)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	for _, index := range indexes {
+		node := t.RootNode
+		for height := t.MaxHeight; height > 0; height-- {
+			child := &node.Left
+			if (index>>uint(height-1))&1 == 1 {
+				child = &node.Right
+			}
+			if *child == nil {
+				*child = &Node{
+					Value:  t.NilHashValueConst[height-1],
+					Parent: node,
+					Height: height - 1,
+				}
+			}
+			node = *child
+		}
+		node.Value = leaves[index]
+		t.Leaves[index] = node
+	}
+	return indexes, nil
+}
+
+/*
+	rehash: hash the nodes of node holding the sorted indexes, bottom up
+*/
+func (t *Tree) rehash(node *Node, indexes []int64, hFunc hash.Hash, parallelDepth int) {
+	if node.Height == 0 {
+		return
+	}
+	bit := uint(node.Height - 1)
+	split := sort.Search(len(indexes), func(i int) bool {
+		return (indexes[i]>>bit)&1 == 1
+	})
+	left, right := indexes[:split], indexes[split:]
+	if len(left) != 0 && len(right) != 0 && parallelDepth > 0 {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.rehash(node.Left, left, t.NewHashFunc(), parallelDepth-1)
+		}()
+		t.rehash(node.Right, right, hFunc, parallelDepth-1)
+		wg.Wait()
+	} else {
+		if len(left) != 0 {
+			t.rehash(node.Left, left, hFunc, parallelDepth)
+		}
+		if len(right) != 0 {
+			t.rehash(node.Right, right, hFunc, parallelDepth)
+		}
+	}
+	hFunc.Reset()
+	hFunc.Write(t.childValue(node.Left, node.Height-1))
+	hFunc.Write(t.childValue(node.Right, node.Height-1))
+	node.Value = hFunc.Sum([]byte{})
+}
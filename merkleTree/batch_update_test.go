@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"hash"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchUpdate(t *testing.T) {
+	for _, newHashFunc := range []func() hash.Hash{nil, mimc.NewMiMC} {
+		tree, err := NewEmptyTree(32, NilHash, mimc.NewMiMC())
+		assert.NoError(t, err)
+		tree.NewHashFunc = newHashFunc
+		expected, err := NewEmptyTree(32, NilHash, mimc.NewMiMC())
+		assert.NoError(t, err)
+
+		r := rand.New(rand.NewSource(1))
+		for round := 0; round < 3; round++ {
+			leaves := make(map[int64][]byte)
+			values := MockState(200)
+			for i := range values {
+				// neighbours and far away leaves
+				index := r.Int63n(1 << 32)
+				if i%4 == 0 {
+					index = int64(i)
+				}
+				leaves[index] = values[i]
+			}
+			for index, leaf := range leaves {
+				assert.NoError(t, expected.Update(index, leaf))
+			}
+			root, proofs, err := tree.BatchUpdateWithProofs(leaves)
+			assert.NoError(t, err)
+			assert.Equal(t, expected.RootNode.Value, root)
+			assert.Len(t, proofs, len(leaves))
+			for index, proof := range proofs {
+				expectedProof, _, err := expected.BuildMerkleProofs(index)
+				assert.NoError(t, err)
+				assert.Equal(t, expectedProof, proof)
+			}
+		}
+
+		// an invalid index leaves the tree untouched
+		root := tree.RootNode.Value
+		_, err = tree.BatchUpdate(map[int64][]byte{0: NilHash, 1 << 32: NilHash})
+		assert.Error(t, err)
+		assert.Equal(t, root, tree.RootNode.Value)
+		root, err = tree.BatchUpdate(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.RootNode.Value, root)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+	ConcurrentTree: sparse merkle tree safe for concurrent use. Nodes are never
+	modified, an update copies the path of the leaf and publishes the new root
+	atomically, so readers don't lock and a Snapshot stays consistent while
+	updates are applied. Updates are serialized.
+*/
+type ConcurrentTree struct {
+	maxHeight int
+	// root of empty subtrees by height, nilHashes[0] is the empty leaf
+	nilHashes [][]byte
+	// guards hashFunc and the publication of new roots
+	mu       sync.Mutex
+	hashFunc hash.Hash
+	// *TreeSnapshot
+	snapshot atomic.Value
+}
+
+/*
+	treeNode: immutable node, a nil child is an empty subtree
+*/
+type treeNode struct {
+	value []byte
+	left  *treeNode
+	right *treeNode
+}
+
+/*
+	TreeSnapshot: the tree at some point, it isn't affected by later updates
+*/
+type TreeSnapshot struct {
+	maxHeight int
+	nilHashes [][]byte
+	root      *treeNode
+}
+
+func NewConcurrentTree(maxHeight int, nilHash []byte, hFunc hash.Hash) (*ConcurrentTree, error) {
+	if maxHeight <= 0 || maxHeight > 62 {
+		log.Println("[NewConcurrentTree] invalid max height")
+		return nil, errors.New("[NewConcurrentTree] invalid max height")
+	}
+	t := &ConcurrentTree{
+		maxHeight: maxHeight,
+		nilHashes: make([][]byte, maxHeight+1),
+		hashFunc:  hFunc,
+	}
+	t.nilHashes[0] = copyBytes(nilHash)
+	for i := 1; i <= maxHeight; i++ {
+		t.nilHashes[i] = t.hashSubTrees(t.nilHashes[i-1], t.nilHashes[i-1])
+	}
+	t.snapshot.Store(&TreeSnapshot{maxHeight: maxHeight, nilHashes: t.nilHashes})
+	return t, nil
+}
+
+/*
+	Snapshot: current state of the tree, it never blocks
+*/
+func (t *ConcurrentTree) Snapshot() *TreeSnapshot {
+	return t.snapshot.Load().(*TreeSnapshot)
+}
+
+func (t *ConcurrentTree) Root() []byte {
+	return t.Snapshot().Root()
+}
+
+func (t *ConcurrentTree) Update(index int64, leaf []byte) (err error) {
+	_, err = t.BatchUpdate(map[int64][]byte{index: leaf})
+	return err
+}
+
+/*
+	BatchUpdate: set several leaves, readers see either none or all of them
+*/
+func (t *ConcurrentTree) BatchUpdate(leaves map[int64][]byte) (root []byte, err error) {
+	indexes := make([]int64, 0, len(leaves))
+	for index := range leaves {
+		if index < 0 || index >= 1<<t.maxHeight {
+			log.Println("[BatchUpdate] invalid index")
+			return nil, fmt.Errorf("[BatchUpdate] index %d out of the tree capacity %d", index, int64(1)<<t.maxHeight)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := t.Snapshot()
+	if len(indexes) == 0 {
+		return snapshot.Root(), nil
+	}
+	newSnapshot := &TreeSnapshot{
+		maxHeight: t.maxHeight,
+		nilHashes: t.nilHashes,
+		root:      t.update(snapshot.root, t.maxHeight, indexes, leaves),
+	}
+	t.snapshot.Store(newSnapshot)
+	return newSnapshot.Root(), nil
+}
+
+/*
+	update: copy of node of the given height with the leaves at the sorted indexes set
+*/
+func (t *ConcurrentTree) update(node *treeNode, height int, indexes []int64, leaves map[int64][]byte) *treeNode {
+	if height == 0 {
+		return &treeNode{value: copyBytes(leaves[indexes[0]])}
+	}
+	var left, right *treeNode
+	if node != nil {
+		left, right = node.left, node.right
+	}
+	bit := uint(height - 1)
+	split := sort.Search(len(indexes), func(i int) bool {
+		return (indexes[i]>>bit)&1 == 1
+	})
+	if split > 0 {
+		left = t.update(left, height-1, indexes[:split], leaves)
+	}
+	if split < len(indexes) {
+		right = t.update(right, height-1, indexes[split:], leaves)
+	}
+	return &treeNode{
+		value: t.hashSubTrees(nodeValue(left, t.nilHashes[height-1]), nodeValue(right, t.nilHashes[height-1])),
+		left:  left,
+		right: right,
+	}
+}
+
+func (t *ConcurrentTree) hashSubTrees(l []byte, r []byte) []byte {
+	t.hashFunc.Reset()
+	t.hashFunc.Write(l)
+	t.hashFunc.Write(r)
+	return t.hashFunc.Sum([]byte{})
+}
+
+func nodeValue(node *treeNode, nilHash []byte) []byte {
+	if node == nil {
+		return nilHash
+	}
+	return node.value
+}
+
+func (s *TreeSnapshot) Root() []byte {
+	return copyBytes(nodeValue(s.root, s.nilHashes[s.maxHeight]))
+}
+
+/*
+	Get: leaf at index, the empty leaf if it was never set
+*/
+func (s *TreeSnapshot) Get(index int64) ([]byte, error) {
+	if index < 0 || index >= 1<<s.maxHeight {
+		log.Println("[Get] invalid index")
+		return nil, fmt.Errorf("[Get] index %d out of the tree capacity %d", index, int64(1)<<s.maxHeight)
+	}
+	node := s.root
+	for height := s.maxHeight; height > 0 && node != nil; height-- {
+		if (index>>uint(height-1))&1 == 0 {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return copyBytes(nodeValue(node, s.nilHashes[0])), nil
+}
+
+/*
+	BuildMerkleProofs: same layout as Tree.BuildMerkleProofs
+*/
+func (s *TreeSnapshot) BuildMerkleProofs(index int64) (proof [][]byte, helper []int, err error) {
+	if index < 0 || index >= 1<<s.maxHeight {
+		log.Println("[BuildMerkleProofs] invalid index")
+		return nil, nil, fmt.Errorf("[BuildMerkleProofs] index %d out of the tree capacity %d", index, int64(1)<<s.maxHeight)
+	}
+	proof = make([][]byte, s.maxHeight)
+	helper = make([]int, s.maxHeight)
+	node := s.root
+	for height := s.maxHeight; height > 0; height-- {
+		var sibling *treeNode
+		if (index>>uint(height-1))&1 == 0 {
+			helper[height-1] = Left
+			if node != nil {
+				sibling, node = node.right, node.left
+			}
+		} else {
+			helper[height-1] = Right
+			if node != nil {
+				sibling, node = node.left, node.right
+			}
+		}
+		proof[height-1] = copyBytes(nodeValue(sibling, s.nilHashes[height-1]))
+	}
+	return proof, helper, nil
+}
+
+func (s *TreeSnapshot) BuildProof(index int64) (*Proof, error) {
+	siblings, _, err := s.BuildMerkleProofs(index)
+	if err != nil {
+		return nil, err
+	}
+	return NewProof(index, siblings), nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentTree(t *testing.T) {
+	tree, err := NewConcurrentTree(32, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	expected, err := NewEmptyTree(32, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, expected.RootNode.Value, tree.Root())
+
+	r := rand.New(rand.NewSource(1))
+	values := MockState(64)
+	for i := range values {
+		index := r.Int63n(1 << 32)
+		if i%2 == 0 {
+			assert.NoError(t, tree.Update(index, values[i]))
+		} else {
+			_, err = tree.BatchUpdate(map[int64][]byte{index: values[i], index ^ 1: values[i-1]})
+			assert.NoError(t, err)
+			assert.NoError(t, expected.Update(index^1, values[i-1]))
+		}
+		assert.NoError(t, expected.Update(index, values[i]))
+		assert.Equal(t, expected.RootNode.Value, tree.Root())
+
+		snapshot := tree.Snapshot()
+		leaf, err := snapshot.Get(index)
+		assert.NoError(t, err)
+		assert.Equal(t, values[i], leaf)
+		proof, helper, err := snapshot.BuildMerkleProofs(index)
+		assert.NoError(t, err)
+		expectedProof, expectedHelper, err := expected.BuildMerkleProofs(index)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedProof, proof)
+		assert.Equal(t, expectedHelper, helper)
+	}
+
+	// a snapshot isn't affected by later updates
+	snapshot := tree.Snapshot()
+	root := snapshot.Root()
+	assert.NoError(t, tree.Update(0, values[0]))
+	assert.Equal(t, root, snapshot.Root())
+	assert.NotEqual(t, root, tree.Root())
+
+	_, err = tree.BatchUpdate(map[int64][]byte{0: NilHash, 1 << 32: NilHash})
+	assert.Error(t, err)
+	_, err = snapshot.Get(-1)
+	assert.Error(t, err)
+	_, _, err = snapshot.BuildMerkleProofs(1 << 32)
+	assert.Error(t, err)
+}
+
+func TestConcurrentTreeReaders(t *testing.T) {
+	tree, err := NewConcurrentTree(20, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	values := MockState(32)
+	const nbReaders = 4
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for reader := 0; reader < nbReaders; reader++ {
+		wg.Add(1)
+		go func(reader int) {
+			defer wg.Done()
+			hFunc := mimc.NewMiMC()
+			index := int64(reader)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// the proof and the leaf must match the root of the same snapshot
+				snapshot := tree.Snapshot()
+				leaf, err := snapshot.Get(index)
+				if !assert.NoError(t, err) {
+					return
+				}
+				proof, err := snapshot.BuildProof(index)
+				if !assert.NoError(t, err) {
+					return
+				}
+				if !assert.NoError(t, proof.Verify(snapshot.Root(), leaf, hFunc)) {
+					return
+				}
+			}
+		}(reader)
+	}
+	for i := range values {
+		leaves := map[int64][]byte{int64(i % nbReaders): values[i], int64(i): values[i]}
+		_, err = tree.BatchUpdate(leaves)
+		assert.NoError(t, err)
+	}
+	close(done)
+	wg.Wait()
+}
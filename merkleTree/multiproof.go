@@ -0,0 +1,301 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"sort"
+)
+
+/*
+	MultiProof: proof of several leaves against a root. It holds the siblings
+	of the paths of the leaves that aren't on another path, from the leaves to
+	the root and by position at every height, nil for an empty subtree.
+*/
+type MultiProof struct {
+	MaxHeight int      `json:"max_height"`
+	Indexes   []int64  `json:"indexes"`
+	Siblings  [][]byte `json:"siblings"`
+}
+
+/*
+	BuildMultiProof: multiproof of the leaves at indexes, indexes may be in any order and repeated
+*/
+func (t *Tree) BuildMultiProof(indexes []int64) (*MultiProof, error) {
+	return buildMultiProof(t.MaxHeight, indexes, func(height int, position int64) ([]byte, error) {
+		node := t.RootNode
+		for h := t.MaxHeight; h > height && node != nil; h-- {
+			if (position>>uint(h-1-height))&1 == 0 {
+				node = node.Left
+			} else {
+				node = node.Right
+			}
+		}
+		value := t.childValue(node, height)
+		if bytes.Equal(value, t.NilHashValueConst[height]) {
+			return nil, nil
+		}
+		return value, nil
+	})
+}
+
+/*
+	GetMultiProof: multiproof of the leaves at indexes, including the updates not committed
+*/
+func (t *PersistentTree) GetMultiProof(indexes []int64) (*MultiProof, error) {
+	return buildMultiProof(t.maxHeight, indexes, func(height int, position int64) ([]byte, error) {
+		value, err := t.node(height, position)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(value, t.nilHashes[height]) {
+			return nil, nil
+		}
+		return value, nil
+	})
+}
+
+/*
+	buildMultiProof: node returns the value of a node by height and position, nil for an empty subtree
+*/
+func buildMultiProof(maxHeight int, indexes []int64, node func(height int, position int64) ([]byte, error)) (*MultiProof, error) {
+	positions, err := sortedIndexes(maxHeight, indexes)
+	if err != nil {
+		return nil, err
+	}
+	proof := &MultiProof{
+		MaxHeight: maxHeight,
+		Indexes:   append([]int64{}, positions...),
+	}
+	for height := 0; height < maxHeight; height++ {
+		var parents []int64
+		for i := 0; i < len(positions); i++ {
+			position := positions[i]
+			if position&1 == 0 && i+1 < len(positions) && positions[i+1] == position+1 {
+				// both children are on a path
+				i++
+			} else {
+				sibling, err := node(height, position^1)
+				if err != nil {
+					return nil, err
+				}
+				proof.Siblings = append(proof.Siblings, copyBytes(sibling))
+			}
+			parents = append(parents, position>>1)
+		}
+		positions = parents
+	}
+	return proof, nil
+}
+
+func sortedIndexes(maxHeight int, indexes []int64) ([]int64, error) {
+	if len(indexes) == 0 {
+		log.Println("[sortedIndexes] no index")
+		return nil, errors.New("[sortedIndexes] no index")
+	}
+	sorted := make([]int64, 0, len(indexes))
+	for _, index := range indexes {
+		if index < 0 || index >= 1<<maxHeight {
+			log.Println("[sortedIndexes] invalid index")
+			return nil, fmt.Errorf("[sortedIndexes] index %d out of the tree capacity %d", index, int64(1)<<maxHeight)
+		}
+		sorted = append(sorted, index)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	unique := sorted[:1]
+	for _, index := range sorted[1:] {
+		if index != unique[len(unique)-1] {
+			unique = append(unique, index)
+		}
+	}
+	return unique, nil
+}
+
+/*
+	VerifyMultiProof: check the leaves against root, leaves should hold exactly
+	the indexes of the proof. nilHash is the empty leaf of the tree.
+*/
+func VerifyMultiProof(root []byte, leaves map[int64][]byte, proof *MultiProof, nilHash []byte, hFunc hash.Hash) error {
+	if proof == nil || proof.MaxHeight <= 0 || proof.MaxHeight > 62 {
+		log.Println("[VerifyMultiProof] invalid proof")
+		return errors.New("[VerifyMultiProof] invalid proof")
+	}
+	positions, err := sortedIndexes(proof.MaxHeight, proof.Indexes)
+	if err != nil {
+		return err
+	}
+	if len(positions) != len(proof.Indexes) || len(leaves) != len(positions) {
+		log.Println("[VerifyMultiProof] leaves don't match the proof indexes")
+		return errors.New("[VerifyMultiProof] leaves don't match the proof indexes")
+	}
+	hashSubTrees := func(l, r []byte) []byte {
+		hFunc.Reset()
+		hFunc.Write(l)
+		hFunc.Write(r)
+		return hFunc.Sum([]byte{})
+	}
+	values := make([][]byte, len(positions))
+	for i, position := range positions {
+		leaf, exist := leaves[position]
+		if !exist {
+			log.Println("[VerifyMultiProof] leaves don't match the proof indexes")
+			return fmt.Errorf("[VerifyMultiProof] missing leaf %d", position)
+		}
+		values[i] = leaf
+	}
+	nilHashValue := nilHash
+	siblings := proof.Siblings
+	for height := 0; height < proof.MaxHeight; height++ {
+		var parents []int64
+		var parentValues [][]byte
+		for i := 0; i < len(positions); i++ {
+			position := positions[i]
+			var left, right []byte
+			if position&1 == 0 && i+1 < len(positions) && positions[i+1] == position+1 {
+				left, right = values[i], values[i+1]
+				i++
+			} else {
+				if len(siblings) == 0 {
+					log.Println("[VerifyMultiProof] missing siblings")
+					return errors.New("[VerifyMultiProof] missing siblings")
+				}
+				sibling := siblings[0]
+				siblings = siblings[1:]
+				if sibling == nil {
+					sibling = nilHashValue
+				}
+				if position&1 == 0 {
+					left, right = values[i], sibling
+				} else {
+					left, right = sibling, values[i]
+				}
+			}
+			parents = append(parents, position>>1)
+			parentValues = append(parentValues, hashSubTrees(left, right))
+		}
+		positions, values = parents, parentValues
+		nilHashValue = hashSubTrees(nilHashValue, nilHashValue)
+	}
+	if len(siblings) != 0 {
+		log.Println("[VerifyMultiProof] too many siblings")
+		return errors.New("[VerifyMultiProof] too many siblings")
+	}
+	if !bytes.Equal(values[0], root) {
+		log.Println("[VerifyMultiProof] root doesn't match")
+		return errors.New("[VerifyMultiProof] root doesn't match")
+	}
+	return nil
+}
+
+/*
+	MarshalBinary: maxHeight uint8 | hashSize uint8 | nbIndexes uint32 | indexes uint64 |
+	nbSiblings uint32 | bitmap of the empty siblings | non empty siblings
+*/
+func (p *MultiProof) MarshalBinary() ([]byte, error) {
+	if p.MaxHeight <= 0 || p.MaxHeight > 62 {
+		return nil, errors.New("[MarshalBinary] invalid max height")
+	}
+	hashSize := 0
+	for _, sibling := range p.Siblings {
+		if sibling == nil {
+			continue
+		}
+		if hashSize == 0 {
+			hashSize = len(sibling)
+		}
+		if len(sibling) != hashSize || hashSize > 255 {
+			return nil, errors.New("[MarshalBinary] siblings should have the same size")
+		}
+	}
+	buf := []byte{byte(p.MaxHeight), byte(hashSize)}
+	buf = appendUint32(buf, uint32(len(p.Indexes)))
+	for _, index := range p.Indexes {
+		buf = appendUint64(buf, uint64(index))
+	}
+	buf = appendUint32(buf, uint32(len(p.Siblings)))
+	bitmap := make([]byte, (len(p.Siblings)+7)/8)
+	for i, sibling := range p.Siblings {
+		if sibling == nil {
+			bitmap[i/8] |= 1 << uint(i%8)
+		}
+	}
+	buf = append(buf, bitmap...)
+	for _, sibling := range p.Siblings {
+		buf = append(buf, sibling...)
+	}
+	return buf, nil
+}
+
+func (p *MultiProof) UnmarshalBinary(buf []byte) error {
+	errInvalid := errors.New("[UnmarshalBinary] invalid multiproof")
+	if len(buf) < 6 {
+		return errInvalid
+	}
+	maxHeight, hashSize := int(buf[0]), int(buf[1])
+	nbIndexes := binary.BigEndian.Uint32(buf[2:])
+	buf = buf[6:]
+	if uint64(len(buf)) < uint64(nbIndexes)*8+4 {
+		return errInvalid
+	}
+	indexes := make([]int64, nbIndexes)
+	for i := range indexes {
+		indexes[i] = int64(binary.BigEndian.Uint64(buf))
+		buf = buf[8:]
+	}
+	nbSiblings := binary.BigEndian.Uint32(buf)
+	buf = buf[4:]
+	bitmapSize := (uint64(nbSiblings) + 7) / 8
+	if uint64(len(buf)) < bitmapSize {
+		return errInvalid
+	}
+	bitmap := buf[:bitmapSize]
+	buf = buf[bitmapSize:]
+	siblings := make([][]byte, nbSiblings)
+	for i := range siblings {
+		if bitmap[i/8]&(1<<uint(i%8)) != 0 {
+			continue
+		}
+		if len(buf) < hashSize || hashSize == 0 {
+			return errInvalid
+		}
+		siblings[i] = copyBytes(buf[:hashSize])
+		buf = buf[hashSize:]
+	}
+	if len(buf) != 0 {
+		return errInvalid
+	}
+	p.MaxHeight, p.Indexes, p.Siblings = maxHeight, indexes, siblings
+	return nil
+}
+
+func appendUint32(buf []byte, x uint32) []byte {
+	word := make([]byte, 4)
+	binary.BigEndian.PutUint32(word, x)
+	return append(buf, word...)
+}
+
+func appendUint64(buf []byte, x uint64) []byte {
+	word := make([]byte, 8)
+	binary.BigEndian.PutUint64(word, x)
+	return append(buf, word...)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiProof(t *testing.T) {
+	tree, err := NewEmptyTree(32, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	persistentTree, err := NewPersistentTree(NewMemoryStorage(), 32, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	values := MockState(8)
+	leafIndexes := []int64{0, 1, 2, 5, 1000, 1001, 1<<32 - 1, 70000}
+	for i, index := range leafIndexes {
+		assert.NoError(t, tree.Update(index, values[i]))
+		assert.NoError(t, persistentTree.Set(index, values[i]))
+	}
+
+	// set and empty leaves, repeated and unordered
+	indexes := []int64{1001, 0, 1, 5, 1000, 3, 1<<32 - 1, 0}
+	proof, err := tree.BuildMultiProof(indexes)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 3, 5, 1000, 1001, 1<<32 - 1}, proof.Indexes)
+	persistentProof, err := persistentTree.GetMultiProof(indexes)
+	assert.NoError(t, err)
+	assert.Equal(t, proof, persistentProof)
+
+	leaves := make(map[int64][]byte)
+	singleProofsSize := 0
+	for _, index := range proof.Indexes {
+		leaves[index], err = persistentTree.Get(index)
+		assert.NoError(t, err)
+		singleProofsSize += 32 * 32
+	}
+	root := tree.RootNode.Value
+	assert.NoError(t, VerifyMultiProof(root, leaves, proof, NilHash, mimc.NewMiMC()))
+
+	buf, err := proof.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Less(t, len(buf), singleProofsSize/4)
+	var decoded MultiProof
+	assert.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.Equal(t, *proof, decoded)
+	assert.Error(t, decoded.UnmarshalBinary(buf[:len(buf)-1]))
+
+	buf, err = json.Marshal(proof)
+	assert.NoError(t, err)
+	decoded = MultiProof{}
+	assert.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, *proof, decoded)
+
+	// wrong leaf
+	leaves[3] = values[0]
+	assert.Error(t, VerifyMultiProof(root, leaves, proof, NilHash, mimc.NewMiMC()))
+	delete(leaves, 3)
+	assert.Error(t, VerifyMultiProof(root, leaves, proof, NilHash, mimc.NewMiMC()))
+	leaves[3] = NilHash
+	assert.NoError(t, VerifyMultiProof(root, leaves, proof, NilHash, mimc.NewMiMC()))
+
+	// wrong siblings
+	tampered := *proof
+	tampered.Siblings = append([][]byte{}, proof.Siblings...)
+	for i, sibling := range tampered.Siblings {
+		if sibling == nil {
+			tampered.Siblings[i] = values[0]
+			break
+		}
+	}
+	assert.Error(t, VerifyMultiProof(root, leaves, &tampered, NilHash, mimc.NewMiMC()))
+	tampered.Siblings = proof.Siblings[1:]
+	assert.Error(t, VerifyMultiProof(root, leaves, &tampered, NilHash, mimc.NewMiMC()))
+	tampered.Siblings = append(append([][]byte{}, proof.Siblings...), nil)
+	assert.Error(t, VerifyMultiProof(root, leaves, &tampered, NilHash, mimc.NewMiMC()))
+
+	// a single leaf carries a full path
+	proof, err = tree.BuildMultiProof([]int64{70000})
+	assert.NoError(t, err)
+	assert.Len(t, proof.Siblings, 32)
+	singleProof, _, err := tree.BuildMerkleProofs(70000)
+	assert.NoError(t, err)
+	for i := range singleProof {
+		if proof.Siblings[i] == nil {
+			assert.Equal(t, tree.NilHashValueConst[i], singleProof[i])
+		} else {
+			assert.Equal(t, singleProof[i], proof.Siblings[i])
+		}
+	}
+	assert.NoError(t, VerifyMultiProof(root, map[int64][]byte{70000: values[7]}, proof, NilHash, mimc.NewMiMC()))
+
+	_, err = tree.BuildMultiProof(nil)
+	assert.Error(t, err)
+	_, err = tree.BuildMultiProof([]int64{1 << 32})
+	assert.Error(t, err)
+}
@@ -0,0 +1,258 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+)
+
+/*
+	PersistentTree: sparse merkle tree whose nodes are kept in a Storage. Only
+	the nodes that differ from an empty subtree are stored, updates are kept in
+	memory until they are committed as a new version or rolled back.
+	Proofs have the layout of types.VerifyMerkleProof, siblings from the leaf
+	to the root and the bits of the leaf index as helper.
+*/
+type PersistentTree struct {
+	storage   Storage
+	maxHeight int
+	// root of empty subtrees by height, nilHashes[0] is the empty leaf
+	nilHashes [][]byte
+	hashFunc  hash.Hash
+	root      []byte
+	// updated nodes not committed yet, nil for an empty subtree
+	dirty map[string][]byte
+	// committed versions
+	versions versionsInfo
+}
+
+func NewPersistentTree(storage Storage, maxHeight int, nilHash []byte, hFunc hash.Hash) (*PersistentTree, error) {
+	if maxHeight <= 0 || maxHeight > 62 {
+		log.Println("[NewPersistentTree] invalid max height")
+		return nil, errors.New("[NewPersistentTree] invalid max height")
+	}
+	t := &PersistentTree{
+		storage:   storage,
+		maxHeight: maxHeight,
+		nilHashes: make([][]byte, maxHeight+1),
+		hashFunc:  hFunc,
+		dirty:     make(map[string][]byte),
+	}
+	t.nilHashes[0] = nilHash
+	for i := 1; i <= maxHeight; i++ {
+		t.nilHashes[i] = t.hashSubTrees(t.nilHashes[i-1], t.nilHashes[i-1])
+	}
+	if err := t.loadVersions(); err != nil {
+		return nil, err
+	}
+	root, err := t.node(maxHeight, 0)
+	if err != nil {
+		return nil, err
+	}
+	t.root = root
+	return t, nil
+}
+
+func (t *PersistentTree) MaxHeight() int {
+	return t.maxHeight
+}
+
+func (t *PersistentTree) Root() []byte {
+	return copyBytes(t.root)
+}
+
+/*
+	Get: leaf at index, the empty leaf if it was never set
+*/
+func (t *PersistentTree) Get(index int64) ([]byte, error) {
+	if err := t.checkIndex(index); err != nil {
+		return nil, err
+	}
+	return t.node(0, index)
+}
+
+/*
+	Set: update a leaf and its ancestors, it costs maxHeight hashes
+*/
+func (t *PersistentTree) Set(index int64, leaf []byte) error {
+	if err := t.checkIndex(index); err != nil {
+		return err
+	}
+	node := copyBytes(leaf)
+	t.setNode(0, index, node)
+	for height := 0; height < t.maxHeight; height++ {
+		sibling, err := t.node(height, index^1)
+		if err != nil {
+			return err
+		}
+		if index&1 == 0 {
+			node = t.hashSubTrees(node, sibling)
+		} else {
+			node = t.hashSubTrees(sibling, node)
+		}
+		index >>= 1
+		t.setNode(height+1, index, node)
+	}
+	t.root = node
+	return nil
+}
+
+/*
+	GetProof: siblings of the path of a leaf from the leaf to the root, and
+	the side of the path at every height, Right when the path is the right child
+*/
+func (t *PersistentTree) GetProof(index int64) (proof [][]byte, helper []int, err error) {
+	return t.proof(t.dirty, index)
+}
+
+func (t *PersistentTree) proof(overlay map[string][]byte, index int64) (proof [][]byte, helper []int, err error) {
+	if err = t.checkIndex(index); err != nil {
+		return nil, nil, err
+	}
+	proof = make([][]byte, t.maxHeight)
+	helper = make([]int, t.maxHeight)
+	for height := 0; height < t.maxHeight; height++ {
+		proof[height], err = t.readNode(overlay, height, index^1)
+		if err != nil {
+			return nil, nil, err
+		}
+		helper[height] = int(index & 1)
+		index >>= 1
+	}
+	return proof, helper, nil
+}
+
+/*
+	Commit: write the updates to the storage in a single batch as a new version,
+	versions should be increasing, e.g. block heights
+*/
+func (t *PersistentTree) Commit(version uint64) error {
+	if t.versions.exist && version <= t.versions.latest {
+		log.Println("[Commit] version should be greater than the latest version")
+		return fmt.Errorf("[Commit] version %d should be greater than the latest version %d", version, t.versions.latest)
+	}
+	batch := make(map[string][]byte, len(t.dirty)+3)
+	undo := make(map[string][]byte, len(t.dirty))
+	for key, value := range t.dirty {
+		old, err := t.storage.Get([]byte(key))
+		if err != nil && err != ErrKeyNotFound {
+			log.Println("[Commit] unable to read node:", err)
+			return err
+		}
+		if bytes.Equal(old, value) {
+			continue
+		}
+		undo[key] = old
+		batch[key] = value
+	}
+	versions := t.versions
+	previous := versionLink{}
+	if versions.exist {
+		previous = versionLink{version: versions.latest, exist: true}
+	} else {
+		versions = versionsInfo{oldest: version, exist: true}
+	}
+	versions.latest = version
+	batch[string(undoKey(version))] = encodeUndo(undo)
+	batch[string(linkKey(version))] = previous.bytes()
+	batch[string(versionsKey)] = versions.bytes()
+	if err := t.storage.Write(batch); err != nil {
+		log.Println("[Commit] unable to write nodes:", err)
+		return err
+	}
+	t.versions = versions
+	t.dirty = make(map[string][]byte)
+	return nil
+}
+
+/*
+	Rollback: drop the updates since the last commit, see RollbackTo to drop committed versions
+*/
+func (t *PersistentTree) Rollback() error {
+	t.dirty = make(map[string][]byte)
+	root, err := t.node(t.maxHeight, 0)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+func (t *PersistentTree) checkIndex(index int64) error {
+	if index < 0 || index >= 1<<t.maxHeight {
+		log.Println("[checkIndex] invalid index")
+		return fmt.Errorf("[checkIndex] index %d out of the tree capacity %d", index, int64(1)<<t.maxHeight)
+	}
+	return nil
+}
+
+func (t *PersistentTree) node(height int, index int64) ([]byte, error) {
+	return t.readNode(t.dirty, height, index)
+}
+
+/*
+	readNode: node from the overlay if it holds it, from the storage otherwise,
+	a nil value is an empty subtree
+*/
+func (t *PersistentTree) readNode(overlay map[string][]byte, height int, index int64) ([]byte, error) {
+	key := nodeKey(height, index)
+	if value, exist := overlay[string(key)]; exist {
+		if value == nil {
+			return t.nilHashes[height], nil
+		}
+		return value, nil
+	}
+	value, err := t.storage.Get(key)
+	if err == ErrKeyNotFound {
+		return t.nilHashes[height], nil
+	}
+	if err != nil {
+		log.Println("[readNode] unable to read node:", err)
+		return nil, err
+	}
+	return value, nil
+}
+
+func (t *PersistentTree) setNode(height int, index int64, value []byte) {
+	if bytes.Equal(value, t.nilHashes[height]) {
+		value = nil
+	}
+	t.dirty[string(nodeKey(height, index))] = value
+}
+
+func (t *PersistentTree) hashSubTrees(l []byte, r []byte) []byte {
+	t.hashFunc.Reset()
+	t.hashFunc.Write(l)
+	t.hashFunc.Write(r)
+	return t.hashFunc.Sum([]byte{})
+}
+
+// node keys are the height of the node followed by its index at that height
+const nodeKeyLen = 9
+
+func nodeKey(height int, index int64) []byte {
+	key := make([]byte, nodeKeyLen)
+	key[0] = byte(height)
+	binary.BigEndian.PutUint64(key[1:], uint64(index))
+	return key
+}
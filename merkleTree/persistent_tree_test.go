@@ -0,0 +1,354 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	mimcGadget "github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+const testTreeHeight = 8
+
+type merkleProofConstraints struct {
+	Root   frontend.Variable `gnark:",public"`
+	Leaf   frontend.Variable
+	Proof  [testTreeHeight]frontend.Variable
+	Helper [testTreeHeight]frontend.Variable
+}
+
+func (circuit merkleProofConstraints) Define(api frontend.API) error {
+	h, err := mimcGadget.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	types.VerifyMerkleProof(api, 1, &h, circuit.Root, circuit.Leaf, circuit.Proof[:], circuit.Helper[:])
+	return nil
+}
+
+func assertProofVerified(t *testing.T, tree *PersistentTree, index int64) {
+	leaf, err := tree.Get(index)
+	assert.NoError(t, err)
+	proof, helper, err := tree.GetProof(index)
+	assert.NoError(t, err)
+	witness := merkleProofConstraints{Root: tree.Root(), Leaf: leaf}
+	for i := range proof {
+		witness.Proof[i] = proof[i]
+		witness.Helper[i] = helper[i]
+	}
+	assert.NoError(t, test.IsSolved(&merkleProofConstraints{}, &witness, ecc.BN254, backend.GROTH16))
+
+	witness.Leaf = MockState(6)[5]
+	assert.Error(t, test.IsSolved(&merkleProofConstraints{}, &witness, ecc.BN254, backend.GROTH16))
+}
+
+func storedNodes(tree *PersistentTree) (nbNodes int) {
+	for key := range tree.storage.(*MemoryStorage).values {
+		if key[0] != metaKeyPrefix {
+			nbNodes++
+		}
+	}
+	return nbNodes
+}
+
+func TestPersistentTree(t *testing.T) {
+	tree, err := NewPersistentTree(NewMemoryStorage(), testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	expected, err := NewEmptyTree(testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, expected.RootNode.Value, tree.Root())
+
+	leaves := MockState(5)
+	indexes := []int64{3, 0, 200, 255, 3}
+	for i, index := range indexes {
+		assert.NoError(t, tree.Set(index, leaves[i]))
+		assert.NoError(t, expected.Update(index, leaves[i]))
+		assert.Equal(t, expected.RootNode.Value, tree.Root())
+	}
+	leaf, err := tree.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, leaves[4], leaf)
+	leaf, err = tree.Get(100)
+	assert.NoError(t, err)
+	assert.Equal(t, NilHash, leaf)
+
+	for _, index := range []int64{0, 3, 100, 255} {
+		assertProofVerified(t, tree, index)
+	}
+
+	// setting a leaf back to the empty leaf empties its subtree
+	for _, index := range []int64{0, 3, 200, 255} {
+		assert.NoError(t, tree.Set(index, NilHash))
+	}
+	empty, err := NewEmptyTree(testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, empty.RootNode.Value, tree.Root())
+	assert.NoError(t, tree.Commit(1))
+	assert.Equal(t, 0, storedNodes(tree))
+
+	_, err = tree.Get(256)
+	assert.Error(t, err)
+	_, _, err = tree.GetProof(-1)
+	assert.Error(t, err)
+}
+
+func TestPersistentTreeCommitRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	storage, err := NewFileStorage(path)
+	assert.NoError(t, err)
+	tree, err := NewPersistentTree(storage, testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	emptyRoot := tree.Root()
+
+	leaves := MockState(3)
+	assert.NoError(t, tree.Set(1, leaves[0]))
+	assert.NoError(t, tree.Set(7, leaves[1]))
+	assert.NoError(t, tree.Rollback())
+	assert.Equal(t, emptyRoot, tree.Root())
+
+	assert.NoError(t, tree.Set(1, leaves[0]))
+	assert.NoError(t, tree.Set(7, leaves[1]))
+	assert.NoError(t, tree.Commit(1))
+	committedRoot := tree.Root()
+
+	assert.NoError(t, tree.Set(7, leaves[2]))
+	assert.NotEqual(t, committedRoot, tree.Root())
+	assert.NoError(t, tree.Rollback())
+	assert.Equal(t, committedRoot, tree.Root())
+	leaf, err := tree.Get(7)
+	assert.NoError(t, err)
+	assert.Equal(t, leaves[1], leaf)
+	assertProofVerified(t, tree, 7)
+	assert.NoError(t, storage.Close())
+
+	// an incomplete batch at the end of the file is dropped
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte{0, 0, 0, 2, 0, 0})
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	storage, err = NewFileStorage(path)
+	assert.NoError(t, err)
+	tree, err = NewPersistentTree(storage, testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, committedRoot, tree.Root())
+	assertProofVerified(t, tree, 1)
+
+	assert.NoError(t, tree.Set(7, NilHash))
+	assert.NoError(t, tree.Commit(2))
+	root := tree.Root()
+	assert.NoError(t, storage.Close())
+	storage, err = NewFileStorage(path)
+	assert.NoError(t, err)
+	defer storage.Close()
+	tree, err = NewPersistentTree(storage, testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, root, tree.Root())
+	leaf, err = tree.Get(7)
+	assert.NoError(t, err)
+	assert.Equal(t, NilHash, leaf)
+}
+
+func TestFileStorageCorruptLengths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	storage, err := NewFileStorage(path)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Write(map[string][]byte{"key": []byte("value")}))
+	assert.NoError(t, storage.Close())
+
+	// lengths beyond the end of the file are rejected before they are allocated
+	for _, tail := range [][]byte{
+		{0, 0, 0, 1, 0xff, 0xff, 0xff, 0xf0, 0, 0, 0, 1},
+		{0, 0, 0, 1, 0, 0, 0, 1, 0xff, 0xff, 0xff, 0xf0, 'k'},
+	} {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		assert.NoError(t, err)
+		_, err = file.Write(tail)
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+
+		storage, err = NewFileStorage(path)
+		assert.NoError(t, err)
+		value, err := storage.Get([]byte("key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value"), value)
+		_, err = storage.Get([]byte("k"))
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.NoError(t, storage.Close())
+	}
+}
+
+func TestFileStorageCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+	storage, err := NewFileStorage(path)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Write(map[string][]byte{"kept": []byte("value"), "deleted": []byte("value")}))
+	assert.NoError(t, storage.Write(map[string][]byte{"deleted": nil}))
+	value := make([]byte, 100)
+	for i := 0; i < 100; i++ {
+		value[0] = byte(i)
+		assert.NoError(t, storage.Write(map[string][]byte{"overwritten": value}))
+	}
+	// overwritten values are dropped once they take more bytes than the live entries
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(2*3*entrySize("overwritten", 100)))
+	assert.Equal(t, info.Size(), storage.size)
+	assert.NoError(t, storage.Close())
+
+	storage, err = NewFileStorage(path)
+	assert.NoError(t, err)
+	got, err := storage.Get([]byte("kept"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), got)
+	got, err = storage.Get([]byte("overwritten"))
+	assert.NoError(t, err)
+	assert.Equal(t, value, got)
+	_, err = storage.Get([]byte("deleted"))
+	assert.Equal(t, ErrKeyNotFound, err)
+	_, err = os.Stat(compactionPath(path))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, storage.Close())
+}
+
+func TestPersistentTreeLargeIndex(t *testing.T) {
+	tree, err := NewPersistentTree(NewMemoryStorage(), 40, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	leaf := MockState(1)[0]
+	index := int64(1)<<40 - 1
+	assert.NoError(t, tree.Set(index, leaf))
+	assert.NoError(t, tree.Commit(1))
+	// one node per height
+	assert.Equal(t, 41, storedNodes(tree))
+
+	proof, helper, err := tree.GetProof(index)
+	assert.NoError(t, err)
+	node := leaf
+	for i := range proof {
+		assert.Equal(t, Right, helper[i])
+		node = tree.hashSubTrees(proof[i], node)
+	}
+	assert.Equal(t, tree.Root(), node)
+}
+
+func TestPersistentTreeVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	storage, err := NewFileStorage(path)
+	assert.NoError(t, err)
+	tree, err := NewPersistentTree(storage, testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	_, ok := tree.LatestVersion()
+	assert.False(t, ok)
+	_, err = tree.RootAt(0)
+	assert.Error(t, err)
+
+	// version i sets leaf i and changes leaf 0
+	leaves := MockState(6)
+	roots := make(map[uint64][]byte)
+	for version := uint64(1); version <= 5; version++ {
+		assert.NoError(t, tree.Set(int64(version), leaves[version]))
+		assert.NoError(t, tree.Set(0, leaves[version-1]))
+		assert.NoError(t, tree.Commit(version))
+		roots[version] = tree.Root()
+	}
+	assert.Error(t, tree.Commit(5))
+
+	// uncommitted updates aren't visible at the latest version
+	assert.NoError(t, tree.Set(0, leaves[5]))
+	for version := uint64(1); version <= 5; version++ {
+		root, err := tree.RootAt(version)
+		assert.NoError(t, err)
+		assert.Equal(t, roots[version], root)
+		leaf, err := tree.GetAt(0, version)
+		assert.NoError(t, err)
+		assert.Equal(t, leaves[version-1], leaf)
+		leaf, err = tree.GetAt(int64(version)+1, version)
+		assert.NoError(t, err)
+		assert.Equal(t, NilHash, leaf)
+
+		proof, helper, err := tree.GetProofAt(int64(version), version)
+		assert.NoError(t, err)
+		node := leaves[version]
+		for i := range proof {
+			if helper[i] == Right {
+				node = tree.hashSubTrees(proof[i], node)
+			} else {
+				node = tree.hashSubTrees(node, proof[i])
+			}
+		}
+		assert.Equal(t, roots[version], node)
+	}
+
+	assert.NoError(t, tree.RollbackTo(3))
+	assert.Equal(t, roots[3], tree.Root())
+	latest, _ := tree.LatestVersion()
+	assert.Equal(t, uint64(3), latest)
+	_, err = tree.RootAt(4)
+	assert.Error(t, err)
+	leaf, err := tree.Get(4)
+	assert.NoError(t, err)
+	assert.Equal(t, NilHash, leaf)
+
+	// a rolled back version can be committed again
+	assert.NoError(t, tree.Set(4, leaves[5]))
+	assert.NoError(t, tree.Commit(4))
+	roots[4] = tree.Root()
+
+	assert.NoError(t, tree.Prune(2))
+	oldest, _ := tree.OldestVersion()
+	assert.Equal(t, uint64(2), oldest)
+	_, err = tree.RootAt(1)
+	assert.Error(t, err)
+	assert.Error(t, tree.RollbackTo(1))
+	assert.NoError(t, storage.Close())
+
+	storage, err = NewFileStorage(path)
+	assert.NoError(t, err)
+	defer storage.Close()
+	tree, err = NewPersistentTree(storage, testTreeHeight, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, roots[4], tree.Root())
+	for version := uint64(2); version <= 4; version++ {
+		root, err := tree.RootAt(version)
+		assert.NoError(t, err)
+		assert.Equal(t, roots[version], root)
+	}
+	assert.NoError(t, tree.RollbackTo(2))
+	assert.Equal(t, roots[2], tree.Root())
+	assertProofVerified(t, tree, 2)
+	// only version 2 is left
+	assert.NoError(t, tree.Prune(2))
+	keys := 0
+	for key := range storage.index {
+		if key[0] == metaKeyPrefix {
+			keys++
+		}
+	}
+	assert.Equal(t, 2, keys)
+}
@@ -0,0 +1,332 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+/*
+	Every committed version keeps the previous value of the nodes it changed,
+	so that the tree can be read at a past version and rolled back to it, and a
+	link to the previous version. Their keys start with metaKeyPrefix, which
+	can't be the height of a node.
+*/
+const metaKeyPrefix = 0xff
+
+var versionsKey = []byte{metaKeyPrefix, 'v'}
+
+type versionsInfo struct {
+	oldest uint64
+	latest uint64
+	exist  bool
+}
+
+func (v versionsInfo) bytes() []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], v.oldest)
+	binary.BigEndian.PutUint64(buf[8:], v.latest)
+	return buf
+}
+
+type versionLink struct {
+	version uint64
+	exist   bool
+}
+
+// the link of the oldest version is empty, a nil value would delete it
+func (l versionLink) bytes() []byte {
+	if !l.exist {
+		return []byte{}
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, l.version)
+	return buf
+}
+
+func undoKey(version uint64) []byte {
+	return versionKey('u', version)
+}
+
+func linkKey(version uint64) []byte {
+	return versionKey('l', version)
+}
+
+func versionKey(kind byte, version uint64) []byte {
+	key := make([]byte, 10)
+	key[0] = metaKeyPrefix
+	key[1] = kind
+	binary.BigEndian.PutUint64(key[2:], version)
+	return key
+}
+
+/*
+	encodeUndo: nbNodes uint32 | nodes, node: key | valueLen uint32 | value,
+	valueLen is deletedValue for an empty subtree
+*/
+func encodeUndo(undo map[string][]byte) []byte {
+	keys := make([]string, 0, len(undo))
+	size := 4
+	for key, value := range undo {
+		keys = append(keys, key)
+		size += nodeKeyLen + 4 + len(value)
+	}
+	sort.Strings(keys)
+	buf := make([]byte, 4, size)
+	binary.BigEndian.PutUint32(buf, uint32(len(keys)))
+	word := make([]byte, 4)
+	for _, key := range keys {
+		value := undo[key]
+		buf = append(buf, key...)
+		if value == nil {
+			binary.BigEndian.PutUint32(word, deletedValue)
+		} else {
+			binary.BigEndian.PutUint32(word, uint32(len(value)))
+		}
+		buf = append(buf, word...)
+		buf = append(buf, value...)
+	}
+	return buf
+}
+
+func decodeUndo(buf []byte) (undo map[string][]byte, err error) {
+	errInvalid := errors.New("[decodeUndo] invalid undo record")
+	if len(buf) < 4 {
+		return nil, errInvalid
+	}
+	nbNodes := binary.BigEndian.Uint32(buf)
+	buf = buf[4:]
+	undo = make(map[string][]byte, nbNodes)
+	for i := uint32(0); i < nbNodes; i++ {
+		if len(buf) < nodeKeyLen+4 {
+			return nil, errInvalid
+		}
+		key := string(buf[:nodeKeyLen])
+		valueLen := binary.BigEndian.Uint32(buf[nodeKeyLen:])
+		buf = buf[nodeKeyLen+4:]
+		if valueLen == deletedValue {
+			undo[key] = nil
+			continue
+		}
+		if uint32(len(buf)) < valueLen {
+			return nil, errInvalid
+		}
+		undo[key] = copyBytes(buf[:valueLen])
+		buf = buf[valueLen:]
+	}
+	if len(buf) != 0 {
+		return nil, errInvalid
+	}
+	return undo, nil
+}
+
+func (t *PersistentTree) loadVersions() error {
+	buf, err := t.storage.Get(versionsKey)
+	if err == ErrKeyNotFound {
+		t.versions = versionsInfo{}
+		return nil
+	}
+	if err != nil {
+		log.Println("[loadVersions] unable to read versions:", err)
+		return err
+	}
+	if len(buf) != 16 {
+		log.Println("[loadVersions] invalid versions")
+		return errors.New("[loadVersions] invalid versions")
+	}
+	t.versions = versionsInfo{
+		oldest: binary.BigEndian.Uint64(buf[:8]),
+		latest: binary.BigEndian.Uint64(buf[8:]),
+		exist:  true,
+	}
+	return nil
+}
+
+func (t *PersistentTree) link(version uint64) (link versionLink, err error) {
+	buf, err := t.storage.Get(linkKey(version))
+	if err != nil {
+		return link, err
+	}
+	switch len(buf) {
+	case 0:
+		return link, nil
+	case 8:
+		return versionLink{version: binary.BigEndian.Uint64(buf), exist: true}, nil
+	default:
+		return link, errors.New("[link] invalid version link")
+	}
+}
+
+/*
+	LatestVersion: last committed version, ok is false if nothing was committed
+*/
+func (t *PersistentTree) LatestVersion() (version uint64, ok bool) {
+	return t.versions.latest, t.versions.exist
+}
+
+/*
+	OldestVersion: oldest version the tree can be read at or rolled back to
+*/
+func (t *PersistentTree) OldestVersion() (version uint64, ok bool) {
+	return t.versions.oldest, t.versions.exist
+}
+
+func (t *PersistentTree) checkVersion(version uint64) error {
+	if !t.versions.exist || version < t.versions.oldest || version > t.versions.latest {
+		log.Println("[checkVersion] unknown version")
+		return fmt.Errorf("[checkVersion] unknown version %d", version)
+	}
+	if _, err := t.link(version); err != nil {
+		log.Println("[checkVersion] unknown version:", err)
+		return fmt.Errorf("[checkVersion] unknown version %d", version)
+	}
+	return nil
+}
+
+/*
+	versionOverlay: the nodes changed since version with their value at version,
+	and the versions committed after it
+*/
+func (t *PersistentTree) versionOverlay(version uint64) (overlay map[string][]byte, newerVersions []uint64, err error) {
+	if err = t.checkVersion(version); err != nil {
+		return nil, nil, err
+	}
+	overlay = make(map[string][]byte)
+	link := versionLink{version: t.versions.latest, exist: true}
+	for link.exist && link.version > version {
+		buf, err := t.storage.Get(undoKey(link.version))
+		if err != nil {
+			log.Println("[versionOverlay] unable to read undo record:", err)
+			return nil, nil, err
+		}
+		undo, err := decodeUndo(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		// older versions come last and override the newer ones
+		for key, value := range undo {
+			overlay[key] = value
+		}
+		newerVersions = append(newerVersions, link.version)
+		if link, err = t.link(link.version); err != nil {
+			log.Println("[versionOverlay] unable to read version link:", err)
+			return nil, nil, err
+		}
+	}
+	return overlay, newerVersions, nil
+}
+
+/*
+	RootAt: root of the tree at a committed version
+*/
+func (t *PersistentTree) RootAt(version uint64) ([]byte, error) {
+	overlay, _, err := t.versionOverlay(version)
+	if err != nil {
+		return nil, err
+	}
+	root, err := t.readNode(overlay, t.maxHeight, 0)
+	return copyBytes(root), err
+}
+
+/*
+	GetAt: leaf at index at a committed version
+*/
+func (t *PersistentTree) GetAt(index int64, version uint64) ([]byte, error) {
+	if err := t.checkIndex(index); err != nil {
+		return nil, err
+	}
+	overlay, _, err := t.versionOverlay(version)
+	if err != nil {
+		return nil, err
+	}
+	return t.readNode(overlay, 0, index)
+}
+
+/*
+	GetProofAt: proof of a leaf against the root of a committed version
+*/
+func (t *PersistentTree) GetProofAt(index int64, version uint64) (proof [][]byte, helper []int, err error) {
+	overlay, _, err := t.versionOverlay(version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.proof(overlay, index)
+}
+
+/*
+	RollbackTo: drop the versions committed after version and the updates not committed
+*/
+func (t *PersistentTree) RollbackTo(version uint64) error {
+	overlay, newerVersions, err := t.versionOverlay(version)
+	if err != nil {
+		return err
+	}
+	batch := overlay
+	for _, newerVersion := range newerVersions {
+		batch[string(undoKey(newerVersion))] = nil
+		batch[string(linkKey(newerVersion))] = nil
+	}
+	versions := t.versions
+	versions.latest = version
+	batch[string(versionsKey)] = versions.bytes()
+	if err = t.storage.Write(batch); err != nil {
+		log.Println("[RollbackTo] unable to write nodes:", err)
+		return err
+	}
+	t.versions = versions
+	return t.Rollback()
+}
+
+/*
+	Prune: drop the history before version, the tree can't be read at or rolled back to an older version anymore
+*/
+func (t *PersistentTree) Prune(version uint64) error {
+	if err := t.checkVersion(version); err != nil {
+		return err
+	}
+	link, err := t.link(version)
+	if err != nil {
+		return err
+	}
+	batch := map[string][]byte{
+		string(undoKey(version)): nil,
+		string(linkKey(version)): versionLink{}.bytes(),
+	}
+	for link.exist {
+		olderVersion := link.version
+		if link, err = t.link(olderVersion); err != nil {
+			log.Println("[Prune] unable to read version link:", err)
+			return err
+		}
+		batch[string(undoKey(olderVersion))] = nil
+		batch[string(linkKey(olderVersion))] = nil
+	}
+	versions := t.versions
+	versions.oldest = version
+	batch[string(versionsKey)] = versions.bytes()
+	if err = t.storage.Write(batch); err != nil {
+		log.Println("[Prune] unable to write versions:", err)
+		return err
+	}
+	t.versions = versions
+	return nil
+}
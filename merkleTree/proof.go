@@ -0,0 +1,176 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// encoding version of Proof
+const ProofVersion = 1
+
+/*
+	Proof: merkle proof of a leaf, the siblings of its path from the leaf to the root
+*/
+type Proof struct {
+	Version  uint8
+	Index    int64
+	Siblings [][]byte
+}
+
+func NewProof(index int64, siblings [][]byte) *Proof {
+	return &Proof{
+		Version:  ProofVersion,
+		Index:    index,
+		Siblings: siblings,
+	}
+}
+
+/*
+	BuildProof: proof of the leaf at index
+*/
+func (t *Tree) BuildProof(index int64) (*Proof, error) {
+	siblings, _, err := t.BuildMerkleProofs(index)
+	if err != nil {
+		return nil, err
+	}
+	return NewProof(index, siblings), nil
+}
+
+/*
+	VerifyProof: check a leaf against root, the side of the path at every
+	height is given by the bits of leafIndex
+*/
+func VerifyProof(root []byte, leafIndex int64, leaf []byte, proof [][]byte, hFunc hash.Hash) error {
+	if len(proof) == 0 || len(proof) > 62 || leafIndex < 0 || leafIndex >= 1<<len(proof) {
+		log.Println("[VerifyProof] invalid proof")
+		return fmt.Errorf("[VerifyProof] invalid proof of leaf %d", leafIndex)
+	}
+	node := leaf
+	for i, sibling := range proof {
+		hFunc.Reset()
+		if (leafIndex>>uint(i))&1 == 0 {
+			hFunc.Write(node)
+			hFunc.Write(sibling)
+		} else {
+			hFunc.Write(sibling)
+			hFunc.Write(node)
+		}
+		node = hFunc.Sum([]byte{})
+	}
+	if !bytes.Equal(node, root) {
+		log.Println("[VerifyProof] root doesn't match")
+		return fmt.Errorf("[VerifyProof] root doesn't match the proof of leaf %d", leafIndex)
+	}
+	return nil
+}
+
+func (p *Proof) Verify(root []byte, leaf []byte, hFunc hash.Hash) error {
+	return VerifyProof(root, p.Index, leaf, p.Siblings, hFunc)
+}
+
+type proofJSON struct {
+	Version  uint8           `json:"version"`
+	Index    int64           `json:"index"`
+	Siblings []hexutil.Bytes `json:"siblings"`
+}
+
+/*
+	MarshalJSON: siblings are 0x prefixed hex strings
+*/
+func (p Proof) MarshalJSON() ([]byte, error) {
+	siblings := make([]hexutil.Bytes, len(p.Siblings))
+	for i := range p.Siblings {
+		siblings[i] = p.Siblings[i]
+	}
+	return json.Marshal(proofJSON{
+		Version:  p.Version,
+		Index:    p.Index,
+		Siblings: siblings,
+	})
+}
+
+func (p *Proof) UnmarshalJSON(buf []byte) error {
+	var decoded proofJSON
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version != ProofVersion {
+		return fmt.Errorf("[UnmarshalJSON] unsupported proof version %d", decoded.Version)
+	}
+	p.Version, p.Index = decoded.Version, decoded.Index
+	p.Siblings = make([][]byte, len(decoded.Siblings))
+	for i := range decoded.Siblings {
+		p.Siblings[i] = decoded.Siblings[i]
+	}
+	return nil
+}
+
+/*
+	MarshalBinary: version uint8 | nbSiblings uint8 | hashSize uint8 | index uint64 | siblings
+*/
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	if len(p.Siblings) > 255 {
+		return nil, errors.New("[MarshalBinary] too many siblings")
+	}
+	hashSize := 0
+	if len(p.Siblings) != 0 {
+		hashSize = len(p.Siblings[0])
+	}
+	if hashSize > 255 {
+		return nil, errors.New("[MarshalBinary] invalid sibling size")
+	}
+	buf := []byte{p.Version, byte(len(p.Siblings)), byte(hashSize)}
+	buf = appendUint64(buf, uint64(p.Index))
+	for _, sibling := range p.Siblings {
+		if len(sibling) != hashSize {
+			return nil, errors.New("[MarshalBinary] siblings should have the same size")
+		}
+		buf = append(buf, sibling...)
+	}
+	return buf, nil
+}
+
+func (p *Proof) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 11 {
+		return errors.New("[UnmarshalBinary] invalid proof")
+	}
+	if buf[0] != ProofVersion {
+		return fmt.Errorf("[UnmarshalBinary] unsupported proof version %d", buf[0])
+	}
+	nbSiblings, hashSize := int(buf[1]), int(buf[2])
+	index := int64(binary.BigEndian.Uint64(buf[3:]))
+	buf = buf[11:]
+	if len(buf) != nbSiblings*hashSize {
+		return errors.New("[UnmarshalBinary] invalid proof")
+	}
+	siblings := make([][]byte, nbSiblings)
+	for i := range siblings {
+		siblings[i] = copyBytes(buf[i*hashSize : (i+1)*hashSize])
+	}
+	p.Version, p.Index, p.Siblings = ProofVersion, index, siblings
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyProof(t *testing.T) {
+	tree, err := NewEmptyTree(16, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	root := tree.RootNode.Value
+	// the proof of an empty tree only holds for the empty leaf
+	proof, err := tree.BuildProof(3)
+	assert.NoError(t, err)
+	assert.NoError(t, proof.Verify(root, NilHash, mimc.NewMiMC()))
+	leaf := MockState(1)[0]
+	assert.Error(t, proof.Verify(root, leaf, mimc.NewMiMC()))
+	assert.False(t, tree.VerifyMerkleProofs(append([][]byte{leaf}, proof.Siblings...), make([]int, 16)))
+
+	assert.NoError(t, tree.Update(3, leaf))
+	root = tree.RootNode.Value
+	proof, err = tree.BuildProof(3)
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyProof(root, 3, leaf, proof.Siblings, mimc.NewMiMC()))
+	// the path follows the index
+	assert.Error(t, VerifyProof(root, 2, leaf, proof.Siblings, mimc.NewMiMC()))
+	assert.Error(t, VerifyProof(root, 3+1<<16, leaf, proof.Siblings, mimc.NewMiMC()))
+	assert.Error(t, VerifyProof(root, 3, leaf, proof.Siblings[1:], mimc.NewMiMC()))
+	assert.Error(t, VerifyProof(root, 3, leaf, nil, mimc.NewMiMC()))
+
+	buf, err := proof.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Len(t, buf, 11+16*32)
+	var decoded Proof
+	assert.NoError(t, decoded.UnmarshalBinary(buf))
+	assert.Equal(t, *proof, decoded)
+	assert.Error(t, decoded.UnmarshalBinary(buf[:len(buf)-1]))
+	buf[0] = ProofVersion + 1
+	assert.Error(t, decoded.UnmarshalBinary(buf))
+
+	buf, err = json.Marshal(proof)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), `"version":1,"index":3,"siblings":["0x`)
+	decoded = Proof{}
+	assert.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, *proof, decoded)
+	assert.NoError(t, decoded.Verify(root, leaf, mimc.NewMiMC()))
+	assert.Error(t, json.Unmarshal([]byte(`{"version":2,"index":3,"siblings":[]}`), &decoded))
+}
@@ -37,19 +37,22 @@ var (
 )
 
 /*
-	Tree: sparse merkle tree
+	Tree: sparse merkle tree, only the paths to the leaves that were set are
+	materialized, a nil child is an empty subtree
 */
 type Tree struct {
 	// root Node
 	RootNode *Node
-	// leaves
-	Leaves []*Node
+	// leaves by index
+	Leaves map[int64]*Node
 	// max height
 	MaxHeight int
 	// nil hash tree
 	NilHashValueConst [][]byte
 	// hash function
 	HashFunc hash.Hash
+	// creates the hash functions of the goroutines of BatchUpdate, it is serial if nil
+	NewHashFunc func() hash.Hash
 }
 
 /*
@@ -98,9 +101,12 @@ func CreateLeafNode(hashVal []byte) *Node {
 	}
 }
 
+/*
+	InitNilHashValueConst: NilHashValueConst[i] is the root of an empty subtree of height i
+*/
 func (t *Tree) InitNilHashValueConst() (err error) {
 	nilHash := t.NilHashValueConst[0]
-	for i := 1; i < t.MaxHeight; i++ {
+	for i := 1; i <= t.MaxHeight; i++ {
 		var (
 			nHash []byte
 		)
@@ -112,92 +118,24 @@ func (t *Tree) InitNilHashValueConst() (err error) {
 }
 
 func NewEmptyTree(maxHeight int, nilHash []byte, hFunc hash.Hash) (*Tree, error) {
-	root := &Node{
-		Value:  nilHash,
-		Left:   nil,
-		Right:  nil,
-		Parent: nil,
-		Height: maxHeight,
-	}
-	// init nil hash values for different heights
-	nilHashValueConst := make([][]byte, maxHeight)
-	nilHashValueConst[0] = nilHash
-	// init tree
-	tree := &Tree{
-		RootNode:          root,
-		MaxHeight:         maxHeight,
-		Leaves:            *new([]*Node),
-		NilHashValueConst: nilHashValueConst,
-		HashFunc:          hFunc,
-	}
-	err := tree.InitNilHashValueConst()
-	if err != nil {
-		errInfo := fmt.Sprintf("[smt.NewEmptyTree] InitNilHashValueConst error: %s", err.Error())
-		log.Println(errInfo)
-		return nil, errors.New(errInfo)
-	}
-	hFunc.Reset()
-	hFunc.Write(nilHashValueConst[maxHeight-1])
-	hFunc.Write(nilHashValueConst[maxHeight-1])
-	tree.RootNode.Value = hFunc.Sum(nil)
-	hFunc.Reset()
-	return tree, nil
+	return NewTreeByMap(nil, maxHeight, nilHash, hFunc)
 }
 
 /*
-	func: NewTree
-	params: leaves []*Node, maxHeight int, nilHash []byte, hFunc hash.Hash
-    desp: Use leaf nodes to initialize the tree,
-          and call the BuildTree method through the root to initialize the hash value of the entire tree
+	func: NewTreeByMap
+	params: leaves map[int64]*Node, maxHeight int, nilHash []byte, hFunc hash.Hash
+    desp: Use leaf nodes to initialize the tree, the indexes missing from the map are empty leaves
 */
 func NewTreeByMap(leaves map[int64]*Node, maxHeight int, nilHash []byte, hFunc hash.Hash) (*Tree, error) {
-	// define variables
-	var (
-		root *Node
-	)
-	// empty tree
-	if leaves == nil {
-		return NewEmptyTree(maxHeight, nilHash, hFunc)
-	}
-	// construct root node
-	root = &Node{
-		Value:  nilHash,
-		Left:   nil,
-		Right:  nil,
-		Parent: nil,
-		Height: maxHeight,
+	if maxHeight <= 0 || maxHeight > 62 {
+		log.Println("[NewTreeByMap] invalid max height")
+		return nil, errors.New("[NewTreeByMap] invalid max height")
 	}
 	// init nil hash values for different heights
 	nilHashValueConst := make([][]byte, maxHeight+1)
 	nilHashValueConst[0] = nilHash
-	// scan map
-	maxIndex := int64(-1)
-	for index := range leaves {
-		if index > maxIndex {
-			maxIndex = index
-		}
-	}
-	if maxIndex == -1 {
-		return NewEmptyTree(maxHeight, nilHash, hFunc)
-	}
-	var nodes []*Node
-	for i := int64(0); i <= maxIndex; i++ {
-		if leaves[i] != nil {
-			nodes = append(nodes, leaves[i])
-		} else {
-			nodes = append(nodes, &Node{
-				Value:  nilHash,
-				Left:   nil,
-				Right:  nil,
-				Parent: nil,
-				Height: 0,
-			})
-		}
-	}
 	// init tree
 	tree := &Tree{
-		RootNode:          root,
-		Leaves:            nodes,
 		MaxHeight:         maxHeight,
 		NilHashValueConst: nilHashValueConst,
 		HashFunc:          hFunc,
@@ -209,7 +147,7 @@ func NewTreeByMap(leaves map[int64]*Node, maxHeight int, nilHash []byte, hFunc h
 		return nil, errors.New(errInfo)
 	}
 
-	err = tree.BuildTree(tree.Leaves)
+	err = tree.BuildTree(leaves)
 	if err != nil {
 		log.Println("[NewTree] unable to build tree: ", err)
 		return nil, err
@@ -220,50 +158,14 @@ func NewTreeByMap(leaves map[int64]*Node, maxHeight int, nilHash []byte, hFunc h
 /*
 	func: NewTree
 	params: leaves []*Node, maxHeight int, nilHash []byte, hFunc hash.Hash
-    desp: Use leaf nodes to initialize the tree,
-          and call the BuildTree method through the root to initialize the hash value of the entire tree
+    desp: Use leaf nodes to initialize the tree, leaves[i] is the leaf at index i
 */
 func NewTree(leaves []*Node, maxHeight int, nilHash []byte, hFunc hash.Hash) (*Tree, error) {
-	// define variables
-	var (
-		root *Node
-	)
-	// empty tree
-	if len(leaves) == 0 || leaves == nil {
-		return NewEmptyTree(maxHeight, nilHash, hFunc)
-	}
-	// construct root node
-	root = &Node{
-		Value:  nilHash,
-		Left:   nil,
-		Right:  nil,
-		Parent: nil,
-		Height: maxHeight,
-	}
-	// init nil hash values for different heights
-	nilHashValueConst := make([][]byte, maxHeight+1)
-	nilHashValueConst[0] = nilHash
-	// init tree
-	tree := &Tree{
-		RootNode:          root,
-		Leaves:            leaves,
-		MaxHeight:         maxHeight,
-		NilHashValueConst: nilHashValueConst,
-		HashFunc:          hFunc,
+	leavesMap := make(map[int64]*Node, len(leaves))
+	for i, leaf := range leaves {
+		leavesMap[int64(i)] = leaf
 	}
-	err := tree.InitNilHashValueConst()
-	if err != nil {
-		errInfo := fmt.Sprintf("[smt.NewTree] InitNilHashValueConst error: %s", err.Error())
-		log.Println(errInfo)
-		return nil, errors.New(errInfo)
-	}
-
-	err = tree.BuildTree(tree.Leaves)
-	if err != nil {
-		log.Println("[NewTree] unable to build tree: ", err)
-		return nil, err
-	}
-	return tree, nil
+	return NewTreeByMap(leavesMap, maxHeight, nilHash, hFunc)
 }
 
 /*
@@ -278,331 +180,140 @@ func (t *Tree) HashSubTrees(l []byte, r []byte) []byte {
 }
 
 /*
-	BuildTree: build sparse merkle tree
+	BuildTree: build the sparse merkle tree of the leaves level by level,
+	only the ancestors of the leaves are created
 */
-func (t *Tree) BuildTree(nodes []*Node) (err error) {
-	// get to the max height
-	if len(nodes) == 0 {
-		log.Println("[BuildTree] smt BuildTree error, nodes length == 0")
-		return errors.New("[BuildTree] nodes length == 0")
-	} else {
-		if nodes[0].Height == t.MaxHeight && len(nodes) == 1 {
-			t.RootNode = nodes[0]
-			return nil
+func (t *Tree) BuildTree(leaves map[int64]*Node) (err error) {
+	t.Leaves = make(map[int64]*Node, len(leaves))
+	nodes := make(map[int64]*Node, len(leaves))
+	for index, leaf := range leaves {
+		if index < 0 || index >= 1<<t.MaxHeight {
+			errInfo := fmt.Sprintf("[BuildTree] index error, index: %v is out of tree capacity: %v.", index, int64(1)<<t.MaxHeight)
+			log.Println(errInfo)
+			return errors.New(errInfo)
 		}
+		if leaf == nil {
+			continue
+		}
+		leaf.Left, leaf.Right, leaf.Parent, leaf.Height = nil, nil, nil, 0
+		t.Leaves[index] = leaf
+		nodes[index] = leaf
+	}
+	for height := 1; height <= t.MaxHeight; height++ {
+		parents := make(map[int64]*Node, len(nodes)/2+1)
+		for index, node := range nodes {
+			parent := parents[index>>1]
+			if parent == nil {
+				parent = &Node{Height: height}
+				parents[index>>1] = parent
+			}
+			if index&1 == 0 {
+				parent.Left = node
+			} else {
+				parent.Right = node
+			}
+			node.Parent = parent
+		}
+		for _, parent := range parents {
+			parent.Value = t.HashSubTrees(t.childValue(parent.Left, height-1), t.childValue(parent.Right, height-1))
+		}
+		nodes = parents
 	}
-	if len(nodes)%2 != 0 {
-		nodes = append(nodes, &Node{
-			Value:  t.NilHashValueConst[nodes[0].Height],
-			Left:   nil,
-			Right:  nil,
-			Parent: nil,
-			Height: nodes[0].Height,
-		})
-	}
-	var parents []*Node
-	for i := 0; i < len(nodes); i += 2 {
-		nodes[i].Parent = &Node{
-			Value:  t.HashSubTrees(nodes[i].Value, nodes[i+1].Value),
-			Left:   nodes[i],
-			Right:  nodes[i+1],
-			Parent: nil,
-			Height: nodes[i].Height + 1,
+	t.RootNode = nodes[0]
+	if t.RootNode == nil {
+		t.RootNode = &Node{
+			Value:  t.NilHashValueConst[t.MaxHeight],
+			Height: t.MaxHeight,
 		}
-		nodes[i+1].Parent = nodes[i].Parent
+	}
+	return nil
+}
 
-		parents = append(parents, nodes[i].Parent)
+func (t *Tree) childValue(node *Node, height int) []byte {
+	if node == nil {
+		return t.NilHashValueConst[height]
 	}
-	err = t.BuildTree(parents)
-	return err
+	return node.Value
 }
 
 /*
-	BuildMerkleProofs: construct merkle proofs
+	BuildMerkleProofs: construct merkle proofs, the siblings of the path of the
+	leaf from the leaf to the root, helpers are Right when the path is the right child
 */
 func (t *Tree) BuildMerkleProofs(index int64) (
 	rMerkleProof [][]byte,
 	rProofHelper []int,
 	err error,
 ) {
-	var proofs [][]byte
-	var proofHelpers []int
-	if index >= (1 << t.MaxHeight) {
-		errInfo := fmt.Sprintf("[BuildMerkleProofs] index error, index: %v is bigger than tree capacity: %v.",
-			index, 1<<t.MaxHeight)
+	if index < 0 || index >= (1<<t.MaxHeight) {
+		errInfo := fmt.Sprintf("[BuildMerkleProofs] index error, index: %v is out of tree capacity: %v.",
+			index, int64(1)<<t.MaxHeight)
 		log.Println(errInfo)
 		return nil, nil, errors.New(errInfo)
 	}
-	// empty tree
-	if len(t.Leaves) == 0 {
-		rProofHelper = make([]int, t.MaxHeight)
-		for i := 0; i < t.MaxHeight; i++ {
-			rProofHelper[i] = 0
-		}
-		return t.NilHashValueConst, rProofHelper, nil
-	}
-	// if index belongs to leaves
-	if index < int64(len(t.Leaves)) {
-		node := t.Leaves[index]
-		//proofs = append(proofs, node.Value)
-		for node.Parent != nil {
-			if node.Parent.Left == node {
-				if node.Parent.Right == nil {
-					proofs = append(proofs, t.NilHashValueConst[node.Height])
-				} else {
-					proofs = append(proofs, node.Parent.Right.Value)
-				}
-				proofHelpers = append(proofHelpers, Left)
-			} else if node.Parent.Right == node {
-				proofs = append(proofs, node.Parent.Left.Value)
-				proofHelpers = append(proofHelpers, Right)
-			} else {
-				errInfo := fmt.Sprintf("[BuildMerkleProofs] node error, node is neither left node nor right node.")
-				log.Println(errInfo)
-				return nil, nil, errors.New(errInfo)
+	rMerkleProof = make([][]byte, t.MaxHeight)
+	rProofHelper = make([]int, t.MaxHeight)
+	node := t.RootNode
+	for height := t.MaxHeight; height > 0; height-- {
+		var sibling *Node
+		if (index>>uint(height-1))&1 == 0 {
+			rProofHelper[height-1] = Left
+			if node != nil {
+				sibling, node = node.Right, node.Left
 			}
-			node = node.Parent
-		}
-	} else {
-		// add itself
-		//proofs = append(proofs, t.NilHashValueConst[0])
-		// get last index
-		lastIndex := int64(len(t.Leaves) - 1)
-		// get last leave node
-		node := t.Leaves[lastIndex]
-		for lastIndex+1 != index {
-			proofs = append(proofs, t.NilHashValueConst[node.Height])
-			if index%2 == 0 {
-				proofHelpers = append(proofHelpers, Right)
-			} else {
-				proofHelpers = append(proofHelpers, Left)
-			}
-			// update value
-			lastIndex /= 2
-			index /= 2
-			node = node.Parent
-		}
-		for node.Parent != nil && node.Parent.Right == node {
-			proofs = append(proofs, t.NilHashValueConst[node.Height])
-			proofHelpers = append(proofHelpers, Right)
-			node = node.Parent
-		}
-		proofs = append(proofs, node.Value)
-		proofHelpers = append(proofHelpers, Left)
-		node = node.Parent
-		for node.Parent != nil {
-			if node.Parent.Left == node {
-				if node.Parent.Right == nil {
-					proofs = append(proofs, t.NilHashValueConst[node.Height])
-				} else {
-					proofs = append(proofs, node.Parent.Right.Value)
-				}
-				proofHelpers = append(proofHelpers, Left)
-			} else if node.Parent.Right == node {
-				proofs = append(proofs, node.Parent.Left.Value)
-				proofHelpers = append(proofHelpers, Right)
-			} else {
-				errInfo := fmt.Sprintf("[BuildMerkleProofs] node error, node is neither left node nor right node.")
-				log.Println(errInfo)
-				return nil, nil, errors.New(errInfo)
+		} else {
+			rProofHelper[height-1] = Right
+			if node != nil {
+				sibling, node = node.Left, node.Right
 			}
-			node = node.Parent
 		}
+		rMerkleProof[height-1] = t.childValue(sibling, height-1)
 	}
-	return proofs, proofHelpers, nil
+	return rMerkleProof, rProofHelper, nil
 }
 
+/*
+	Update: set a leaf, the missing nodes of its path are created, it costs MaxHeight hashes
+*/
 func (t *Tree) Update(index int64, nVal []byte) (err error) {
-	if index >= 1<<t.MaxHeight {
+	if index < 0 || index >= 1<<t.MaxHeight {
 		log.Println("[Update] invalid index")
 		return errors.New("[Update] invalid index")
 	}
-	if index <= int64(len(t.Leaves)) {
-		return t.updateExistOrNext(index, nVal)
-	} else {
-		for i := int64(len(t.Leaves)); i < index; i++ {
-			err = t.updateExistOrNext(i, t.NilHashValueConst[0])
-			if err != nil {
-				log.Println("[Update] unable to update exist or next:", err)
-				return err
-			}
-		}
-		err = t.updateExistOrNext(index, nVal)
-		if err != nil {
-			log.Println("[Update] unable to update exist or next:", err)
-			return err
+	node := t.RootNode
+	for height := t.MaxHeight; height > 0; height-- {
+		child := &node.Left
+		if (index>>uint(height-1))&1 == 1 {
+			child = &node.Right
 		}
-	}
-	return nil
-}
-
-func (t *Tree) updateExistOrNext(index int64, nVal []byte) (err error) {
-	if index >= 1<<t.MaxHeight {
-		log.Println("[updateExistOrNext] invalid index")
-		return errors.New("[updateExistOrNext] invalid index")
-	}
-	// empty tree
-	if len(t.Leaves) == 0 {
-		if index != 0 {
-			log.Println("[updateExistOrNext] invalid index")
-			return errors.New("[updateExistOrNext] invalid index")
-		}
-		nodeInfo := &Node{
-			Value:  nVal,
-			Height: 0,
+		if *child == nil {
+			*child = &Node{
+				Value:  t.NilHashValueConst[height-1],
+				Parent: node,
+				Height: height - 1,
+			}
 		}
-		t.Leaves = append(t.Leaves, nodeInfo)
-		err = t.BuildTree(t.Leaves)
-		return err
+		node = *child
 	}
-	// index belong to leaves
-	if index < int64(len(t.Leaves)) {
-		node := t.Leaves[index]
-		node.Value = nVal
+	node.Value = nVal
+	t.Leaves[index] = node
+	for node.Parent != nil {
 		node = node.Parent
-		for node != nil {
-			if node.Right != nil {
-				node.Value = t.HashSubTrees(node.Left.Value, node.Right.Value)
-			} else {
-				node.Value = t.HashSubTrees(node.Left.Value, t.NilHashValueConst[node.Left.Height])
-			}
-			node = node.Parent
-		}
-	} else { // index larger than leaves
-		// that's also insert
-		if index != int64(len(t.Leaves)) {
-			return errors.New("[updateExistOrNext] the index should only be lastIndex+1")
-		}
-		// get last index
-		lastIndex := len(t.Leaves) - 1
-		// get last leave node
-		node := t.Leaves[lastIndex]
-		// even
-		if (index+1)%2 == 0 {
-			// construct node
-			nNode := &Node{
-				Value:  nVal,
-				Parent: node.Parent,
-				Height: 0,
-			}
-			node.Parent.Right = nNode
-			t.Leaves = append(t.Leaves, nNode)
-			node = node.Parent
-			for node != nil {
-				if node.Right != nil {
-					node.Value = t.HashSubTrees(node.Left.Value, node.Right.Value)
-				} else {
-					node.Value = t.HashSubTrees(node.Left.Value, t.NilHashValueConst[node.Left.Height])
-				}
-				node = node.Parent
-			}
-		} else { // odd
-			// Create new Node and append Leaves
-			var (
-				commonLength = 0
-				nearValue    = index ^ (index - 1)
-			)
-			for nearValue != 0 {
-				nearValue /= 2
-				commonLength += 1
-			}
-			// Create new Node and append Leaves
-			nNode := &Node{
-				Value:  nVal,
-				Parent: nil,
-				Height: 0,
-			}
-			t.Leaves = append(t.Leaves, nNode)
-			// search common Parent
-			for commonLength != 0 {
-				commonLength--
-				// handle searching common parentNode
-				node = node.Parent
-
-				if commonLength != 0 { // if find common parentNode, then there is no need to create a new parentNode.
-					// handle parentNode creation
-					parentNode := &Node{
-						Value:  t.HashSubTrees(nNode.Value, t.NilHashValueConst[nNode.Height]),
-						Left:   nNode,
-						Height: nNode.Height + 1,
-					}
-
-					nNode.Parent = parentNode
-					nNode = nNode.Parent
-				}
-
-			}
-			// connect common parent with nNode
-			node.Right = nNode
-			nNode.Parent = node
-
-			for node != nil {
-				if node.Right != nil {
-					node.Value = t.HashSubTrees(node.Left.Value, node.Right.Value)
-				} else {
-					node.Value = t.HashSubTrees(node.Left.Value, t.NilHashValueConst[node.Left.Height])
-				}
-				node = node.Parent
-			}
-		}
+		node.Value = t.HashSubTrees(t.childValue(node.Left, node.Height-1), t.childValue(node.Right, node.Height-1))
 	}
 	return nil
 }
 
-func (t *Tree) insert(nodes []*Node) {
-
-	if len(nodes) == 0 {
-		log.Println("[BuildTree] smt BuildTree error, nodes length == 0")
-		return
-	} else {
-		if nodes[0].Height == 0 && len(nodes) == 1 {
-			t.RootNode = nodes[0]
-			return
-		}
-	}
-	var parents []*Node
-
-	for i := 0; i < len(nodes); i += 2 {
-		if nodes[i].Parent == nil {
-			logInfo := fmt.Sprintf("[insert] boundary one: len(nodes) = %v. i = %v.", len(nodes), i)
-			log.Println(logInfo)
-			// The left node has no parent, need to create a parent node
-			nodes[i].Parent = &Node{
-				Value:  t.HashSubTrees(nodes[i].Value, t.NilHashValueConst[nodes[i].Height]),
-				Left:   nodes[i],
-				Right:  nil,
-				Parent: nil,
-				Height: nodes[i].Height - 1,
-			}
-		} else if i+1 <= len(nodes)-1 {
-			if nodes[i+1].Parent == nil && nodes[i].Parent != nil {
-				logInfo := fmt.Sprintf("[insert] boundary two:len(nodes) = %v. i = %v.", len(nodes), i)
-				log.Println(logInfo)
-				// The right node has no parent, but the left node has a parent, do not need to create a parent node
-				nodes[i+1].Parent = nodes[i].Parent
-				nodes[i+1].Parent.Right = nodes[i+1]
-				nodes[i+1].Parent.Value = t.HashSubTrees(nodes[i].Value, nodes[i+1].Value)
-			}
-		}
-
-		parents = append(parents, nodes[i].Parent)
-	}
-	t.insert(parents)
-}
-
 /*
-	VerifyMerkleProofs: verify merkle proofs
-	@inclusionProofs: inclusion proofs
-	@helperProofs: helper function
+	VerifyMerkleProofs: verify merkle proofs against the root of the tree
+	@inclusionProofs: the leaf followed by the siblings of its path
+	@helperProofs: side of the path at every height
+	Deprecated: use VerifyProof, which doesn't need the tree
 */
 func (t *Tree) VerifyMerkleProofs(inclusionProofs [][]byte, helperProofs []int) bool {
 	if len(inclusionProofs) != len(helperProofs)+1 {
 		return false
 	}
-	// empty tree
-	if len(t.Leaves) == 0 {
-		return true
-	}
 	root := t.RootNode.Value
 	node := inclusionProofs[0]
 	for i := 1; i < len(inclusionProofs); i++ {
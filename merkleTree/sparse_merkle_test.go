@@ -92,14 +92,14 @@ func TestNewTree(t *testing.T) {
 	fmt.Println("len:", len(merkleProofs))
 	fmt.Println("BuildTree proofs time:", time.Since(elapse))
 	fmt.Println("merkle proof helper:", helperMerkleProofs)
-	res := tree.VerifyMerkleProofs(merkleProofs, helperMerkleProofs)
+	res := tree.VerifyMerkleProofs(append([][]byte{hashState[4]}, merkleProofs...), helperMerkleProofs)
 	assert.Equal(t, res, true, "BuildTree merkle proofs successfully")
 	// if len(t.leaves) % 2 != 0 && index == len(t.leaves) + 1
 	merkleProofs, helperMerkleProofs, err = tree.BuildMerkleProofs(0)
 	if err != nil {
 		t.Fatal(err)
 	}
-	res = tree.VerifyMerkleProofs(merkleProofs, helperMerkleProofs)
+	res = tree.VerifyMerkleProofs(append([][]byte{hashState[0]}, merkleProofs...), helperMerkleProofs)
 	fmt.Println("merkle proof helper:", helperMerkleProofs)
 	assert.Equal(t, res, true, "BuildTree merkle proofs successfully")
 	// verify index >= len(t.leaves) + 1
@@ -109,14 +109,14 @@ func TestNewTree(t *testing.T) {
 		t.Fatal(err)
 	}
 	fmt.Println("before proofs:", merkleProofs)
-	res = tree.VerifyMerkleProofs(merkleProofs, helperMerkleProofs)
+	res = tree.VerifyMerkleProofs(append([][]byte{hashState[2]}, merkleProofs...), helperMerkleProofs)
 	fmt.Println("merkle proof helper:", helperMerkleProofs)
 	assert.Equal(t, res, true, "BuildTree merkle proofs successfully")
 	h.Reset()
 	h.Write([]byte("modify"))
 	nVal := h.Sum([]byte{})
 	fmt.Println("nVal:", nVal)
-	err = tree.updateExistOrNext(6, nVal)
+	err = tree.Update(6, nVal)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -189,13 +189,13 @@ func TestNewTreeByMap(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	isValid := tree.VerifyMerkleProofs(proofs, proofsHelper)
+	isValid := tree.VerifyMerkleProofs(append([][]byte{NilHash}, proofs...), proofsHelper)
 	assert.Equal(t, true, isValid, "invalid proof")
 	proofs, proofsHelper, err = tree.BuildMerkleProofs(110)
 	if err != nil {
 		t.Fatal(err)
 	}
-	isValid = tree.VerifyMerkleProofs(proofs, proofsHelper)
+	isValid = tree.VerifyMerkleProofs(append([][]byte{NilHash}, proofs...), proofsHelper)
 	assert.Equal(t, true, isValid, "invalid proof")
 	log.Println(common.Bytes2Hex(proofs[0]))
 	h.Reset()
@@ -210,7 +210,7 @@ func TestNewTreeByMap(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	isValid = tree.VerifyMerkleProofs(proofs, proofsHelper)
+	isValid = tree.VerifyMerkleProofs(append([][]byte{nVal}, proofs...), proofsHelper)
 	assert.Equal(t, true, isValid, "invalid proof")
 	log.Println(common.Bytes2Hex(proofs[0]))
 }
@@ -226,17 +226,17 @@ func TestNewEmptyTree(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	isValid := tree.VerifyMerkleProofs(merkleProofs, merkleProofsHelper)
+	isValid := tree.VerifyMerkleProofs(append([][]byte{nilHash}, merkleProofs...), merkleProofsHelper)
 	assert.Equal(t, true, isValid, "invalid proof")
 	h.Reset()
 	h.Write([]byte("1"))
 	nVal := h.Sum([]byte{})
-	err = tree.updateExistOrNext(0, nVal)
+	err = tree.Update(0, nVal)
 	if err != nil {
 		t.Fatal(err)
 	}
 	merkleProofs, merkleProofsHelper, err = tree.BuildMerkleProofs(0)
-	isValid = tree.VerifyMerkleProofs(merkleProofs, merkleProofsHelper)
+	isValid = tree.VerifyMerkleProofs(append([][]byte{nVal}, merkleProofs...), merkleProofsHelper)
 	assert.Equal(t, true, isValid, "invalid proof")
 }
 
@@ -266,3 +266,41 @@ func TestNewTreeByMapAndUpdate(t *testing.T) {
 	fmt.Println(common.Bytes2Hex(emptyTree.RootNode.Value))
 	fmt.Println(len(emptyTree.Leaves))
 }
+
+func TestSparseUpdate(t *testing.T) {
+	tree, err := NewEmptyTree(40, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	expected, err := NewPersistentTree(NewMemoryStorage(), 40, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Root(), tree.RootNode.Value)
+
+	leaves := MockState(4)
+	indexes := []int64{1<<40 - 1, 4000000000, 0, 4000000001}
+	for i, index := range indexes {
+		assert.NoError(t, tree.Update(index, leaves[i]))
+		assert.NoError(t, expected.Set(index, leaves[i]))
+		assert.Equal(t, expected.Root(), tree.RootNode.Value)
+	}
+	// only the paths of the leaves are materialized
+	assert.Equal(t, len(indexes), len(tree.Leaves))
+
+	for _, index := range append(indexes, 12345) {
+		proof, helper, err := tree.BuildMerkleProofs(index)
+		assert.NoError(t, err)
+		expectedProof, expectedHelper, err := expected.GetProof(index)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedProof, proof)
+		assert.Equal(t, expectedHelper, helper)
+	}
+
+	leavesMap := make(map[int64]*Node)
+	for i, index := range indexes {
+		leavesMap[index] = CreateLeafNode(leaves[i])
+	}
+	treeByMap, err := NewTreeByMap(leavesMap, 40, NilHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	assert.Equal(t, tree.RootNode.Value, treeByMap.RootNode.Value)
+
+	assert.Error(t, tree.Update(1<<40, leaves[0]))
+	assert.Error(t, tree.Update(-1, leaves[0]))
+}
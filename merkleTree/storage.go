@@ -0,0 +1,449 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkleTree
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+var ErrKeyNotFound = errors.New("[Storage] key not found")
+
+/*
+	Storage: key value store of the persistent tree nodes
+*/
+type Storage interface {
+	// Get returns ErrKeyNotFound for a missing key
+	Get(key []byte) (value []byte, err error)
+	// Write applies a batch atomically, a nil value deletes its key
+	Write(batch map[string][]byte) error
+	Close() error
+}
+
+/*
+	MemoryStorage: storage kept in memory, mostly for tests and short lived trees
+*/
+type MemoryStorage struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{values: make(map[string][]byte)}
+}
+
+func (s *MemoryStorage) Get(key []byte) (value []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, exist := s.values[string(key)]
+	if !exist {
+		return nil, ErrKeyNotFound
+	}
+	return copyBytes(value), nil
+}
+
+func (s *MemoryStorage) Write(batch map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range batch {
+		if value == nil {
+			delete(s.values, key)
+		} else {
+			s.values[key] = copyBytes(value)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+/*
+	FileStorage: append only log of batches, only the keys and the position of
+	their value are kept in memory. A batch is framed with its entries count and
+	a checksum, a batch partially written by a crash is dropped when the file is opened.
+	Deleted and overwritten values stay in the log until they take more bytes than the
+	live entries, the live entries are then rewritten in a new file as a single batch.
+	Every live key is kept in memory, the storage is meant for trees whose keys fit in it.
+
+	batch: nbEntries uint32 | entries | crc32 of nbEntries and entries
+	entry: keyLen uint32 | valueLen uint32 | key | value, valueLen is deletedValue for a deleted key
+*/
+type FileStorage struct {
+	mu    sync.RWMutex
+	path  string
+	file  *os.File
+	size  int64
+	index map[string]valuePosition
+	// bytes of the entries of the index, the rest of the file is dead
+	live int64
+}
+
+type valuePosition struct {
+	offset int64
+	length uint32
+}
+
+const deletedValue = ^uint32(0)
+
+func NewFileStorage(path string) (*FileStorage, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		log.Println("[NewFileStorage] unable to open file:", err)
+		return nil, err
+	}
+	s := &FileStorage{
+		path:  path,
+		file:  file,
+		index: make(map[string]valuePosition),
+	}
+	if err = s.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// a compaction interrupted before its rename leaves the log untouched
+	if err = os.Remove(compactionPath(path)); err != nil && !os.IsNotExist(err) {
+		log.Println("[NewFileStorage] unable to remove interrupted compaction:", err)
+	}
+	s.maybeCompact()
+	return s, nil
+}
+
+/*
+	load: replay the batches of the file and truncate it after the last complete one
+*/
+func (s *FileStorage) load() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		log.Println("[load] unable to stat file:", err)
+		return err
+	}
+	reader := bufio.NewReader(s.file)
+	offset := int64(0)
+	for {
+		positions, size, err := readBatch(reader, offset, info.Size()-offset)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("[load] dropping incomplete batch at offset", offset, ":", err)
+			break
+		}
+		for key, position := range positions {
+			s.setPosition(key, position)
+		}
+		offset += size
+	}
+	if err := s.file.Truncate(offset); err != nil {
+		log.Println("[load] unable to truncate file:", err)
+		return err
+	}
+	s.size = offset
+	return nil
+}
+
+/*
+	readBatch: read the batch at offset, remaining is the number of bytes of the file from
+	offset. The lengths of a corrupted entry are checked against it before anything is
+	allocated, and values are only streamed through the checksum
+*/
+func readBatch(reader *bufio.Reader, offset int64, remaining int64) (positions map[string]valuePosition, size int64, err error) {
+	if remaining == 0 {
+		return nil, 0, io.EOF
+	}
+	checksum := crc32.NewIEEE()
+	checkLength := func(n uint32) error {
+		if int64(n) > remaining-size {
+			return errors.New("entry goes beyond the end of the file")
+		}
+		return nil
+	}
+	read := func(n uint32) ([]byte, error) {
+		if err := checkLength(n); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		checksum.Write(buf)
+		size += int64(n)
+		return buf, nil
+	}
+	skip := func(n uint32) error {
+		if err := checkLength(n); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(checksum, reader, int64(n)); err != nil {
+			return err
+		}
+		size += int64(n)
+		return nil
+	}
+	buf, err := read(4)
+	if err != nil {
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+	nbEntries := binary.BigEndian.Uint32(buf)
+	positions = make(map[string]valuePosition)
+	for i := uint32(0); i < nbEntries; i++ {
+		if buf, err = read(8); err != nil {
+			return nil, 0, unexpectedEOF(err)
+		}
+		keyLen := binary.BigEndian.Uint32(buf[:4])
+		valueLen := binary.BigEndian.Uint32(buf[4:])
+		key, err := read(keyLen)
+		if err != nil {
+			return nil, 0, unexpectedEOF(err)
+		}
+		if valueLen == deletedValue {
+			positions[string(key)] = valuePosition{length: deletedValue}
+			continue
+		}
+		position := valuePosition{offset: offset + size, length: valueLen}
+		if err = skip(valueLen); err != nil {
+			return nil, 0, unexpectedEOF(err)
+		}
+		positions[string(key)] = position
+	}
+	sum := checksum.Sum32()
+	buf = make([]byte, 4)
+	if _, err = io.ReadFull(reader, buf); err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+	if binary.BigEndian.Uint32(buf) != sum {
+		return nil, 0, errors.New("invalid checksum")
+	}
+	return positions, size + 4, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (s *FileStorage) Get(key []byte) (value []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	position, exist := s.index[string(key)]
+	if !exist {
+		return nil, ErrKeyNotFound
+	}
+	value = make([]byte, position.length)
+	if _, err = s.file.ReadAt(value, position.offset); err != nil {
+		log.Println("[Get] unable to read value:", err)
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *FileStorage) Write(batch map[string][]byte) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(batch))
+	for key := range batch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	positions := make(map[string]valuePosition, len(batch))
+	word := make([]byte, 4)
+	writeUint32 := func(x uint32) {
+		binary.BigEndian.PutUint32(word, x)
+		buf.Write(word)
+	}
+	writeUint32(uint32(len(keys)))
+	for _, key := range keys {
+		value := batch[key]
+		writeUint32(uint32(len(key)))
+		if value == nil {
+			writeUint32(deletedValue)
+			buf.WriteString(key)
+			positions[key] = valuePosition{length: deletedValue}
+			continue
+		}
+		writeUint32(uint32(len(value)))
+		buf.WriteString(key)
+		positions[key] = valuePosition{offset: s.size + int64(buf.Len()), length: uint32(len(value))}
+		buf.Write(value)
+	}
+	writeUint32(crc32.ChecksumIEEE(buf.Bytes()))
+
+	if _, err := s.file.WriteAt(buf.Bytes(), s.size); err != nil {
+		log.Println("[Write] unable to write batch:", err)
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		log.Println("[Write] unable to sync file:", err)
+		return err
+	}
+	s.size += int64(buf.Len())
+	for key, position := range positions {
+		s.setPosition(key, position)
+	}
+	s.maybeCompact()
+	return nil
+}
+
+/*
+	setPosition: update the index and the live bytes with an entry of a batch
+*/
+func (s *FileStorage) setPosition(key string, position valuePosition) {
+	if old, exist := s.index[key]; exist {
+		s.live -= entrySize(key, old.length)
+	}
+	if position.length == deletedValue {
+		delete(s.index, key)
+		return
+	}
+	s.index[key] = position
+	s.live += entrySize(key, position.length)
+}
+
+func entrySize(key string, valueLen uint32) int64 {
+	return 8 + int64(len(key)) + int64(valueLen)
+}
+
+func compactionPath(path string) string {
+	return path + ".compact"
+}
+
+/*
+	maybeCompact: compact the log once its dead bytes exceed its live ones, the cost of a
+	compaction is paid by the dead bytes written since the previous one. The log is still
+	valid if the compaction fails, the error is only logged
+*/
+func (s *FileStorage) maybeCompact() {
+	if s.size-s.live <= s.live {
+		return
+	}
+	if err := s.compact(); err != nil {
+		log.Println("[maybeCompact] unable to compact storage:", err)
+	}
+}
+
+/*
+	compact: write the live entries in a new file as a single batch and rename it over the log
+*/
+func (s *FileStorage) compact() (err error) {
+	keys := make([]string, 0, len(s.index))
+	for key := range s.index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	path := compactionPath(s.path)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(path)
+		}
+	}()
+	checksum := crc32.NewIEEE()
+	writer := bufio.NewWriter(io.MultiWriter(file, checksum))
+	index := make(map[string]valuePosition, len(keys))
+	size := int64(0)
+	word := make([]byte, 4)
+	writeUint32 := func(x uint32) {
+		binary.BigEndian.PutUint32(word, x)
+		writer.Write(word)
+		size += 4
+	}
+	writeUint32(uint32(len(keys)))
+	for _, key := range keys {
+		position := s.index[key]
+		value := make([]byte, position.length)
+		if _, err = s.file.ReadAt(value, position.offset); err != nil {
+			return err
+		}
+		writeUint32(uint32(len(key)))
+		writeUint32(position.length)
+		writer.WriteString(key)
+		size += int64(len(key))
+		index[key] = valuePosition{offset: size, length: position.length}
+		writer.Write(value)
+		size += int64(position.length)
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(word, checksum.Sum32())
+	if _, err = file.Write(word); err != nil {
+		return err
+	}
+	size += 4
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = os.Rename(path, s.path); err != nil {
+		return err
+	}
+	if err = syncDir(filepath.Dir(s.path)); err != nil {
+		log.Println("[compact] unable to sync directory:", err)
+	}
+	s.file.Close()
+	s.file = file
+	s.size = size
+	s.index = index
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (s *FileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
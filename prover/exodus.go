@@ -0,0 +1,183 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"errors"
+	"log"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	ExodusCircuit: compiled exodus circuit, of an asset balance or of an nft when Nft is set
+*/
+type ExodusCircuit struct {
+	Nft      bool
+	HashType types.HashType
+	Config   types.CircuitConfig
+	Ccs      ConstraintSystem
+}
+
+/*
+	CompileExodusCircuit: compile ExodusConstraints into a groth16 friendly r1cs
+*/
+func CompileExodusCircuit(hashType types.HashType, config types.CircuitConfig) (exodusCircuit *ExodusCircuit, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	exodusConstraints := circuit.NewExodusConstraints(config, hashType)
+	oCcs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &exodusConstraints)
+	if err != nil {
+		log.Println("[CompileExodusCircuit] unable to compile exodus circuit:", err)
+		return nil, err
+	}
+	return &ExodusCircuit{HashType: hashType, Config: config, Ccs: oCcs}, nil
+}
+
+/*
+	CompileExodusNftCircuit: compile ExodusNftConstraints into a groth16 friendly r1cs
+*/
+func CompileExodusNftCircuit(hashType types.HashType, config types.CircuitConfig) (exodusCircuit *ExodusCircuit, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	exodusConstraints := circuit.NewExodusNftConstraints(config, hashType)
+	oCcs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &exodusConstraints)
+	if err != nil {
+		log.Println("[CompileExodusNftCircuit] unable to compile exodus nft circuit:", err)
+		return nil, err
+	}
+	return &ExodusCircuit{Nft: true, HashType: hashType, Config: config, Ccs: oCcs}, nil
+}
+
+/*
+	SetupExodus: run groth16 setup for the compiled exodus circuit, the verifying key
+	is exported with ExportSolidity
+	NOTICE: the keys generated here rely on local randomness and are for test purpose only
+*/
+func SetupExodus(exodusCircuit *ExodusCircuit) (pk ProvingKey, vk VerifyingKey, err error) {
+	if exodusCircuit == nil || exodusCircuit.Ccs == nil {
+		log.Println("[SetupExodus] invalid exodus circuit")
+		return nil, nil, errors.New("[SetupExodus] invalid exodus circuit")
+	}
+	pk, vk, err = groth16.Setup(exodusCircuit.Ccs)
+	if err != nil {
+		log.Println("[SetupExodus] unable to setup exodus circuit:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+func ProveExodus(exodusCircuit *ExodusCircuit, pk ProvingKey, oExodus *circuit.Exodus) (proof Proof, err error) {
+	if exodusCircuit == nil || exodusCircuit.Ccs == nil || exodusCircuit.Nft || pk == nil {
+		log.Println("[ProveExodus] invalid params")
+		return nil, errors.New("[ProveExodus] invalid params")
+	}
+	exodusWitness, err := circuit.SetExodusWitness(oExodus, exodusCircuit.Config)
+	if err != nil {
+		return nil, err
+	}
+	return proveExodus(exodusCircuit, pk, &exodusWitness)
+}
+
+func ProveExodusNft(exodusCircuit *ExodusCircuit, pk ProvingKey, oExodus *circuit.ExodusNft) (proof Proof, err error) {
+	if exodusCircuit == nil || exodusCircuit.Ccs == nil || !exodusCircuit.Nft || pk == nil {
+		log.Println("[ProveExodusNft] invalid params")
+		return nil, errors.New("[ProveExodusNft] invalid params")
+	}
+	exodusWitness, err := circuit.SetExodusNftWitness(oExodus, exodusCircuit.Config)
+	if err != nil {
+		return nil, err
+	}
+	return proveExodus(exodusCircuit, pk, &exodusWitness)
+}
+
+func proveExodus(exodusCircuit *ExodusCircuit, pk ProvingKey, assignment frontend.Circuit) (proof Proof, err error) {
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254)
+	if err != nil {
+		log.Println("[ProveExodus] unable to generate witness:", err)
+		return nil, err
+	}
+	proof, err = groth16.Prove(exodusCircuit.Ccs, pk, fullWitness)
+	if err != nil {
+		log.Println("[ProveExodus] unable to generate proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	VerifyExodusProof: verify an exodus proof, only the public values of oExodus are used:
+	the state root, the account index and name hash, the asset id and balance
+*/
+func VerifyExodusProof(proof Proof, vk VerifyingKey, oExodus *circuit.Exodus) (err error) {
+	if proof == nil || vk == nil || oExodus == nil || oExodus.AccountInfo == nil || oExodus.Asset == nil {
+		log.Println("[VerifyExodusProof] invalid params")
+		return errors.New("[VerifyExodusProof] invalid params")
+	}
+	exodusWitness := circuit.ExodusConstraints{
+		StateRoot:       oExodus.StateRoot,
+		AccountIndex:    oExodus.AccountInfo.AccountIndex,
+		AccountNameHash: oExodus.AccountInfo.AccountNameHash,
+		AssetId:         oExodus.Asset.AssetId,
+		Balance:         oExodus.Asset.Balance,
+	}
+	return verifyExodus(proof, vk, &exodusWitness)
+}
+
+/*
+	VerifyExodusNftProof: verify an exodus nft proof, only the public values of oExodus are used:
+	the state root, the owner account index and name hash and the nft
+*/
+func VerifyExodusNftProof(proof Proof, vk VerifyingKey, oExodus *circuit.ExodusNft) (err error) {
+	if proof == nil || vk == nil || oExodus == nil || oExodus.AccountInfo == nil || oExodus.Nft == nil {
+		log.Println("[VerifyExodusNftProof] invalid params")
+		return errors.New("[VerifyExodusNftProof] invalid params")
+	}
+	exodusWitness := circuit.ExodusNftConstraints{
+		StateRoot:       oExodus.StateRoot,
+		AccountIndex:    oExodus.AccountInfo.AccountIndex,
+		AccountNameHash: oExodus.AccountInfo.AccountNameHash,
+	}
+	exodusWitness.Nft, err = types.SetNftWitness(oExodus.Nft)
+	if err != nil {
+		return err
+	}
+	return verifyExodus(proof, vk, &exodusWitness)
+}
+
+func verifyExodus(proof Proof, vk VerifyingKey, assignment frontend.Circuit) (err error) {
+	publicWitness, err := frontend.NewWitness(assignment, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		log.Println("[VerifyExodusProof] unable to generate public witness:", err)
+		return err
+	}
+	err = groth16.Verify(proof, vk, publicWitness)
+	if err != nil {
+		log.Println("[VerifyExodusProof] invalid proof:", err)
+		return err
+	}
+	return nil
+}
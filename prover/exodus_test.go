@@ -0,0 +1,74 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbnb-crypto/state"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+func TestProveExodus(t *testing.T) {
+	s, err := state.NewStateWithConfig(1, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	sk, err := curve.GenerateEddsaPrivateKey("exodus seed for the prover tests")
+	require.NoError(t, err)
+	nameHash := big.NewInt(7).FillBytes(make([]byte, 32))
+	txInfos := []txtypes.TxInfo{
+		&txtypes.RegisterZnsTxInfo{
+			TxType: txtypes.TxTypeRegisterZns, AccountIndex: 2, AccountName: "test",
+			AccountNameHash: nameHash, PubKey: hex.EncodeToString(sk.PublicKey.Bytes()),
+		},
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: 2, AccountNameHash: nameHash, AssetId: 3, AssetAmount: big.NewInt(1000)},
+	}
+	for _, txInfo := range txInfos {
+		_, _, err = s.ApplyTx(txInfo, 0)
+		require.NoError(t, err)
+	}
+	oExodus, err := s.Exodus(2, 3)
+	require.NoError(t, err)
+
+	exodusCircuit, err := CompileExodusCircuit(types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	pk, vk, err := SetupExodus(exodusCircuit)
+	require.NoError(t, err)
+	proof, err := ProveExodus(exodusCircuit, pk, oExodus)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyExodusProof(proof, vk, oExodus))
+	oExodus.Asset.Balance = big.NewInt(1001)
+	assert.Error(t, VerifyExodusProof(proof, vk, oExodus))
+	_, err = ProveExodusNft(exodusCircuit, pk, nil)
+	assert.Error(t, err)
+
+	solPath := filepath.Join(t.TempDir(), "ExodusVerifier.sol")
+	require.NoError(t, ExportSolidity(vk, solPath))
+	sol, err := os.ReadFile(solPath)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(sol), "contract Verifier"))
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+/*
+	SaveKeys: write the proving key and verifying key in raw (uncompressed) format
+*/
+func SaveKeys(pk ProvingKey, vk VerifyingKey, pkPath, vkPath string) (err error) {
+	err = writeRawTo(pkPath, pk)
+	if err != nil {
+		log.Println("[SaveKeys] unable to write proving key:", err)
+		return err
+	}
+	err = writeRawTo(vkPath, vk)
+	if err != nil {
+		log.Println("[SaveKeys] unable to write verifying key:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	LoadKeys: read the proving key and verifying key written by SaveKeys
+*/
+func LoadKeys(pkPath, vkPath string) (pk ProvingKey, vk VerifyingKey, err error) {
+	pk = groth16.NewProvingKey(ecc.BN254)
+	err = readFrom(pkPath, pk)
+	if err != nil {
+		log.Println("[LoadKeys] unable to read proving key:", err)
+		return nil, nil, err
+	}
+	vk, err = LoadVerifyingKey(vkPath)
+	if err != nil {
+		log.Println("[LoadKeys] unable to read verifying key:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+/*
+	LoadVerifyingKey: read the verifying key written by SaveKeys
+*/
+func LoadVerifyingKey(vkPath string) (vk VerifyingKey, err error) {
+	vk = groth16.NewVerifyingKey(ecc.BN254)
+	err = readFrom(vkPath, vk)
+	if err != nil {
+		log.Println("[LoadVerifyingKey] unable to read verifying key:", err)
+		return nil, err
+	}
+	return vk, nil
+}
+
+/*
+	ExportSolidity: export the solidity verifier contract of the verifying key
+*/
+func ExportSolidity(vk VerifyingKey, solPath string) (err error) {
+	f, err := os.Create(solPath)
+	if err != nil {
+		log.Println("[ExportSolidity] unable to create file:", err)
+		return err
+	}
+	defer f.Close()
+	err = vk.ExportSolidity(f)
+	if err != nil {
+		log.Println("[ExportSolidity] unable to export solidity:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	SaveProof: write a groth16 or plonk proof
+*/
+func SaveProof(proof io.WriterTo, proofPath string) (err error) {
+	err = writeTo(proofPath, proof)
+	if err != nil {
+		log.Println("[SaveProof] unable to write proof:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	LoadProof: read a groth16 proof written by SaveProof
+*/
+func LoadProof(proofPath string) (proof Proof, err error) {
+	proof = groth16.NewProof(ecc.BN254)
+	err = readFrom(proofPath, proof)
+	if err != nil {
+		log.Println("[LoadProof] unable to read proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+type rawWriter interface {
+	WriteRawTo(w io.Writer) (int64, error)
+}
+
+func writeRawTo(path string, key rawWriter) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	_, err = key.WriteRawTo(w)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func writeTo(path string, key io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	_, err = key.WriteTo(w)
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func readFrom(path string, key io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = key.ReadFrom(bufio.NewReader(f))
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+/*
+	Manifest: circuit parameters the keys of a block circuit were generated for
+*/
+type Manifest struct {
+	Backend         string
+	Curve           string
+	TxsCount        int
+	GasAssetIds     []int64
+	GasAccountIndex int64
+	HashType        types.HashType
+	Config          types.CircuitConfig
+	NbConstraints   int
+	ProvingKey      string `json:",omitempty"` // empty for plonk, the proving key is rebuilt from the srs
+	VerifyingKey    string
+	SRS             string `json:",omitempty"`
+}
+
+/*
+	NewManifest: describe the compiled block circuit, key paths are filled by the caller
+*/
+func NewManifest(blockCircuit *BlockCircuit) *Manifest {
+	return &Manifest{
+		Backend:         blockCircuit.Backend.String(),
+		Curve:           blockCircuit.Ccs.CurveID().String(),
+		TxsCount:        blockCircuit.TxsCount,
+		GasAssetIds:     blockCircuit.GasAssetIds,
+		GasAccountIndex: blockCircuit.GasAccountIndex,
+		HashType:        blockCircuit.HashType,
+		Config:          blockCircuit.Config,
+		NbConstraints:   blockCircuit.Ccs.GetNbConstraints(),
+	}
+}
+
+/*
+	Matches: check the compiled block circuit has the shape described by the manifest
+*/
+func (manifest *Manifest) Matches(blockCircuit *BlockCircuit) bool {
+	if manifest.Backend != blockCircuit.Backend.String() ||
+		manifest.TxsCount != blockCircuit.TxsCount ||
+		manifest.GasAccountIndex != blockCircuit.GasAccountIndex ||
+		manifest.HashType != blockCircuit.HashType ||
+		manifest.Config != blockCircuit.Config ||
+		len(manifest.GasAssetIds) != len(blockCircuit.GasAssetIds) {
+		return false
+	}
+	for i := 0; i < len(manifest.GasAssetIds); i++ {
+		if manifest.GasAssetIds[i] != blockCircuit.GasAssetIds[i] {
+			return false
+		}
+	}
+	return manifest.NbConstraints == blockCircuit.Ccs.GetNbConstraints()
+}
+
+func SaveManifest(manifest *Manifest, path string) (err error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Println("[SaveManifest] unable to marshal manifest:", err)
+		return err
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		log.Println("[SaveManifest] unable to write manifest:", err)
+		return err
+	}
+	return nil
+}
+
+func LoadManifest(path string) (manifest *Manifest, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Println("[LoadManifest] unable to read manifest:", err)
+		return nil, err
+	}
+	manifest = new(Manifest)
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		log.Println("[LoadManifest] unable to unmarshal manifest:", err)
+		return nil, err
+	}
+	// manifests written before the trees were configurable are mainnet ones
+	if manifest.Config == (types.CircuitConfig{}) {
+		manifest.Config = types.MainnetConfig
+	}
+	// and the ones written before the layout of a tx was configurable have the mainnet layout
+	if manifest.Config.NbAccountsPerTx == 0 && manifest.Config.NbAccountAssetsPerAccount == 0 &&
+		manifest.Config.NbGasAssetsPerTx == 0 && manifest.Config.PubDataSizePerTx == 0 {
+		manifest.Config.NbAccountsPerTx = types.MainnetConfig.NbAccountsPerTx
+		manifest.Config.NbAccountAssetsPerAccount = types.MainnetConfig.NbAccountAssetsPerAccount
+		manifest.Config.NbGasAssetsPerTx = types.MainnetConfig.NbGasAssetsPerTx
+		manifest.Config.PubDataSizePerTx = types.MainnetConfig.PubDataSizePerTx
+	}
+	// manifests written before the hash was selectable have no HashType, MiMC is its zero value
+	if manifest.HashType != types.MiMCHashType && manifest.HashType != types.PoseidonHashType {
+		log.Println("[LoadManifest] unknown hash type")
+		return nil, errors.New("[LoadManifest] unknown hash type")
+	}
+	if manifest.TxsCount <= 0 || len(manifest.GasAssetIds) == 0 || manifest.Config.Validate() != nil {
+		log.Println("[LoadManifest] invalid manifest")
+		return nil, errors.New("[LoadManifest] invalid manifest")
+	}
+	return manifest, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+func TestManifest(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockCircuit := &BlockCircuit{
+		TxsCount:        1,
+		GasAssetIds:     []int64{0, 1},
+		GasAccountIndex: 1,
+		Config:          types.TestConfig,
+		Backend:         backend.GROTH16,
+		Ccs:             ccs,
+	}
+	manifest := NewManifest(blockCircuit)
+	manifest.ProvingKey = "zkbnb1.pk"
+	manifest.VerifyingKey = "zkbnb1.vk"
+
+	path := filepath.Join(t.TempDir(), "zkbnb1.manifest")
+	assert.Nil(t, SaveManifest(manifest, path))
+	loaded, err := LoadManifest(path)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, loaded)
+	assert.Equal(t, "groth16", loaded.Backend)
+	assert.True(t, loaded.Matches(blockCircuit))
+
+	blockCircuit.Config = types.MainnetConfig
+	assert.False(t, loaded.Matches(blockCircuit))
+	blockCircuit.Config = types.TestConfig
+	blockCircuit.GasAssetIds = []int64{0, 2}
+	assert.False(t, loaded.Matches(blockCircuit))
+	// keys of a MiMC state don't prove a Poseidon one
+	blockCircuit.GasAssetIds = []int64{0, 1}
+	blockCircuit.HashType = types.PoseidonHashType
+	assert.False(t, loaded.Matches(blockCircuit))
+}
+
+func TestLoadLegacyManifest(t *testing.T) {
+	// written before the config and the hash type were recorded
+	path := filepath.Join(t.TempDir(), "zkbnb1.manifest")
+	legacy := `{"Backend":"groth16","Curve":"bn254","TxsCount":1,"GasAssetIds":[0,1],"GasAccountIndex":1,"NbConstraints":1}`
+	assert.Nil(t, os.WriteFile(path, []byte(legacy), 0644))
+	manifest, err := LoadManifest(path)
+	assert.Nil(t, err)
+	assert.Equal(t, types.MiMCHashType, manifest.HashType)
+	assert.Equal(t, types.MainnetConfig, manifest.Config)
+
+	// written before the layout of a tx was recorded
+	depthsOnly := `{"Backend":"groth16","Curve":"bn254","TxsCount":1,"GasAssetIds":[0,1],"GasAccountIndex":1,"NbConstraints":1,` +
+		`"Config":{"AccountMerkleLevels":8,"AssetMerkleLevels":8,"NftMerkleLevels":8,"LiquidityMerkleLevels":8,"CollectionMerkleLevels":8}}`
+	assert.Nil(t, os.WriteFile(path, []byte(depthsOnly), 0644))
+	manifest, err = LoadManifest(path)
+	assert.Nil(t, err)
+	assert.Equal(t, types.TestConfig, manifest.Config)
+
+	unknown := `{"Backend":"groth16","Curve":"bn254","TxsCount":1,"GasAssetIds":[0,1],"GasAccountIndex":1,"HashType":7}`
+	assert.Nil(t, os.WriteFile(path, []byte(unknown), 0644))
+	_, err = LoadManifest(path)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"crypto/rand"
+	"errors"
+	"log"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend/cs/scs"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+type (
+	PlonkProvingKey   = plonk.ProvingKey
+	PlonkVerifyingKey = plonk.VerifyingKey
+	PlonkProof        = plonk.Proof
+	KZGSRS            = kzg.SRS
+)
+
+/*
+	CompileBlockCircuitPlonk: compile BlockConstraints into a plonk friendly sparse r1cs
+*/
+func CompileBlockCircuitPlonk(txsCount int, gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig) (blockCircuit *BlockCircuit, err error) {
+	if txsCount <= 0 {
+		log.Println("[CompileBlockCircuitPlonk] invalid txs count")
+		return nil, errors.New("[CompileBlockCircuitPlonk] invalid txs count")
+	}
+	if len(gasAssetIds) == 0 {
+		log.Println("[CompileBlockCircuitPlonk] gas asset ids should not be empty")
+		return nil, errors.New("[CompileBlockCircuitPlonk] gas asset ids should not be empty")
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	return compileBlockCircuit(txsCount, gasAssetIds, gasAccountIndex, hashType, config, backend.PLONK, scs.NewBuilder)
+}
+
+/*
+	NewKZGSRS: generate a kzg srs large enough for the compiled block circuit
+	NOTICE: the srs generated here uses local randomness and is for test purpose only,
+	a production srs must come from an MPC ceremony
+*/
+func NewKZGSRS(blockCircuit *BlockCircuit) (srs KZGSRS, err error) {
+	if blockCircuit == nil || blockCircuit.Ccs == nil || blockCircuit.Backend != backend.PLONK {
+		log.Println("[NewKZGSRS] invalid block circuit")
+		return nil, errors.New("[NewKZGSRS] invalid block circuit")
+	}
+	nbConstraints := blockCircuit.Ccs.GetNbConstraints()
+	_, _, nbPublic := blockCircuit.Ccs.GetNbVariables()
+	size := ecc.NextPowerOfTwo(uint64(nbConstraints+nbPublic)) + 3
+
+	alpha, err := rand.Int(rand.Reader, fr.Modulus())
+	if err != nil {
+		log.Println("[NewKZGSRS] unable to sample randomness:", err)
+		return nil, err
+	}
+	srs, err = kzg_bn254.NewSRS(size, alpha)
+	if err != nil {
+		log.Println("[NewKZGSRS] unable to generate srs:", err)
+		return nil, err
+	}
+	return srs, nil
+}
+
+/*
+	SetupPlonk: run plonk setup for the compiled block circuit with the given srs
+*/
+func SetupPlonk(blockCircuit *BlockCircuit, srs KZGSRS) (pk PlonkProvingKey, vk PlonkVerifyingKey, err error) {
+	if blockCircuit == nil || blockCircuit.Ccs == nil || blockCircuit.Backend != backend.PLONK || srs == nil {
+		log.Println("[SetupPlonk] invalid params")
+		return nil, nil, errors.New("[SetupPlonk] invalid params")
+	}
+	pk, vk, err = plonk.Setup(blockCircuit.Ccs, srs)
+	if err != nil {
+		log.Println("[SetupPlonk] unable to setup block circuit:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+/*
+	ProveBlockPlonk: set the block witness and generate a plonk proof for it
+*/
+func ProveBlockPlonk(blockCircuit *BlockCircuit, pk PlonkProvingKey, oBlock *circuit.Block) (proof PlonkProof, err error) {
+	if blockCircuit == nil || blockCircuit.Ccs == nil || blockCircuit.Backend != backend.PLONK || pk == nil {
+		log.Println("[ProveBlockPlonk] invalid params")
+		return nil, errors.New("[ProveBlockPlonk] invalid params")
+	}
+	fullWitness, err := newBlockWitness(blockCircuit, oBlock)
+	if err != nil {
+		log.Println("[ProveBlockPlonk] unable to generate witness:", err)
+		return nil, err
+	}
+	proof, err = plonk.Prove(blockCircuit.Ccs, pk, fullWitness)
+	if err != nil {
+		log.Println("[ProveBlockPlonk] unable to generate proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	VerifyBlockProofPlonk: verify a plonk block proof against the block commitment
+*/
+func VerifyBlockProofPlonk(proof PlonkProof, vk PlonkVerifyingKey, blockCommitment []byte) (err error) {
+	if proof == nil || vk == nil {
+		log.Println("[VerifyBlockProofPlonk] invalid params")
+		return errors.New("[VerifyBlockProofPlonk] invalid params")
+	}
+	publicWitness, err := newBlockPublicWitness(blockCommitment)
+	if err != nil {
+		log.Println("[VerifyBlockProofPlonk] unable to generate public witness:", err)
+		return err
+	}
+	err = plonk.Verify(proof, vk, publicWitness)
+	if err != nil {
+		log.Println("[VerifyBlockProofPlonk] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	SavePlonkKeys: write the plonk verifying key and the srs it was set up with
+	NOTICE: gnark v0.7 doesn't serialize the permutation evaluations of the plonk proving key,
+	a written key can't be loaded back. Since plonk setup is deterministic for a given srs,
+	provers rebuild the proving key with SetupPlonk from the stored srs instead
+*/
+func SavePlonkKeys(vk PlonkVerifyingKey, srs KZGSRS, vkPath, srsPath string) (err error) {
+	err = writeTo(vkPath, vk)
+	if err != nil {
+		log.Println("[SavePlonkKeys] unable to write verifying key:", err)
+		return err
+	}
+	err = writeTo(srsPath, srs)
+	if err != nil {
+		log.Println("[SavePlonkKeys] unable to write srs:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	LoadPlonkSRS: read the kzg srs written by SavePlonkKeys, SetupPlonk rebuilds the proving key from it
+*/
+func LoadPlonkSRS(srsPath string) (srs KZGSRS, err error) {
+	srs = kzg.NewSRS(ecc.BN254)
+	err = readFrom(srsPath, srs)
+	if err != nil {
+		log.Println("[LoadPlonkSRS] unable to read srs:", err)
+		return nil, err
+	}
+	return srs, nil
+}
+
+/*
+	LoadPlonkVerifyingKey: read the plonk verifying key written by SavePlonkKeys,
+	the kzg srs isn't serialized within the key so it is attached here
+*/
+func LoadPlonkVerifyingKey(vkPath string, srs KZGSRS) (vk PlonkVerifyingKey, err error) {
+	vk = plonk.NewVerifyingKey(ecc.BN254)
+	err = readFrom(vkPath, vk)
+	if err != nil {
+		log.Println("[LoadPlonkVerifyingKey] unable to read verifying key:", err)
+		return nil, err
+	}
+	err = restoreCosetShift(vk)
+	if err != nil {
+		log.Println("[LoadPlonkVerifyingKey] unable to restore coset shift:", err)
+		return nil, err
+	}
+	err = vk.InitKZG(srs)
+	if err != nil {
+		log.Println("[LoadPlonkVerifyingKey] unable to init verifying key:", err)
+		return nil, err
+	}
+	return vk, nil
+}
+
+/*
+	restoreCosetShift: gnark v0.7 doesn't serialize the coset shift of the plonk verifying key,
+	setup takes it from the multiplicative generator of the fft domain which doesn't depend on the size
+*/
+func restoreCosetShift(vk PlonkVerifyingKey) error {
+	v := reflect.ValueOf(vk)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("unexpected verifying key type")
+	}
+	cosetShift := v.Elem().FieldByName("CosetShift")
+	if !cosetShift.IsValid() || !cosetShift.CanSet() || cosetShift.Type() != reflect.TypeOf(fr.Element{}) {
+		return errors.New("unexpected verifying key layout")
+	}
+	cosetShift.Set(reflect.ValueOf(fft.NewDomain(2).FrMultiplicativeGen))
+	return nil
+}
+
+/*
+	LoadPlonkProof: read a plonk proof written by SaveProof
+*/
+func LoadPlonkProof(proofPath string) (proof PlonkProof, err error) {
+	proof = plonk.NewProof(ecc.BN254)
+	err = readFrom(proofPath, proof)
+	if err != nil {
+		log.Println("[LoadPlonkProof] unable to read proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/assert"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestPlonkKeysRoundTrip(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &squareCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockCircuit := &BlockCircuit{Backend: backend.PLONK, Ccs: ccs}
+	srs, err := NewKZGSRS(blockCircuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vk, err := SetupPlonk(blockCircuit, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	vkPath, srsPath := filepath.Join(dir, "vk"), filepath.Join(dir, "srs")
+	err = SavePlonkKeys(vk, srs, vkPath, srsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedSrs, err := LoadPlonkSRS(srsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedPk, _, err := SetupPlonk(blockCircuit, loadedSrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedVk, err := LoadPlonkVerifyingKey(vkPath, loadedSrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fullWitness, _ := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254)
+	proof, err := plonk.Prove(ccs, loadedPk, fullWitness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, _ := frontend.NewWitness(&squareCircuit{Y: 9}, ecc.BN254, frontend.PublicOnly())
+	assert.Nil(t, plonk.Verify(proof, loadedVk, publicWitness))
+	publicWitness, _ = frontend.NewWitness(&squareCircuit{Y: 10}, ecc.BN254, frontend.PublicOnly())
+	assert.NotNil(t, plonk.Verify(proof, loadedVk, publicWitness))
+}
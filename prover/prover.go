@@ -0,0 +1,204 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+type (
+	ConstraintSystem = frontend.CompiledConstraintSystem
+	ProvingKey       = groth16.ProvingKey
+	VerifyingKey     = groth16.VerifyingKey
+	Proof            = groth16.Proof
+)
+
+/*
+	BlockCircuit: compiled block circuit together with the parameters it was built for
+*/
+type BlockCircuit struct {
+	TxsCount        int
+	GasAssetIds     []int64
+	GasAccountIndex int64
+	HashType        types.HashType
+	Config          types.CircuitConfig
+	Backend         backend.ID
+	Ccs             ConstraintSystem
+}
+
+/*
+	NewBlockConstraints: construct an empty block circuit of the given shape
+*/
+func NewBlockConstraints(txsCount int, gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig) circuit.BlockConstraints {
+	var blockConstraints circuit.BlockConstraints
+	blockConstraints.TxsCount = txsCount
+	blockConstraints.Txs = make([]circuit.TxConstraints, txsCount)
+	for i := 0; i < txsCount; i++ {
+		blockConstraints.Txs[i] = circuit.GetZeroTxConstraint(config)
+	}
+	blockConstraints.GasAssetIds = gasAssetIds
+	blockConstraints.GasAccountIndex = gasAccountIndex
+	blockConstraints.Gas = circuit.GetZeroGasConstraints(gasAssetIds, config)
+	blockConstraints.HashType = hashType
+	blockConstraints.Config = config
+	return blockConstraints
+}
+
+/*
+	CompileBlockCircuit: compile BlockConstraints into a groth16 friendly r1cs
+*/
+func CompileBlockCircuit(txsCount int, gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig) (blockCircuit *BlockCircuit, err error) {
+	if txsCount <= 0 {
+		log.Println("[CompileBlockCircuit] invalid txs count")
+		return nil, errors.New("[CompileBlockCircuit] invalid txs count")
+	}
+	if len(gasAssetIds) == 0 {
+		log.Println("[CompileBlockCircuit] gas asset ids should not be empty")
+		return nil, errors.New("[CompileBlockCircuit] gas asset ids should not be empty")
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	return compileBlockCircuit(txsCount, gasAssetIds, gasAccountIndex, hashType, config, backend.GROTH16, r1cs.NewBuilder)
+}
+
+func compileBlockCircuit(
+	txsCount int, gasAssetIds []int64, gasAccountIndex int64, hashType types.HashType, config types.CircuitConfig,
+	backendID backend.ID, newBuilder frontend.NewBuilder,
+) (blockCircuit *BlockCircuit, err error) {
+	blockConstraints := NewBlockConstraints(txsCount, gasAssetIds, gasAccountIndex, hashType, config)
+	oCcs, err := frontend.Compile(ecc.BN254, newBuilder, &blockConstraints, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		log.Println("[CompileBlockCircuit] unable to compile block circuit:", err)
+		return nil, err
+	}
+	return &BlockCircuit{
+		TxsCount:        txsCount,
+		GasAssetIds:     gasAssetIds,
+		GasAccountIndex: gasAccountIndex,
+		HashType:        hashType,
+		Config:          config,
+		Backend:         backendID,
+		Ccs:             oCcs,
+	}, nil
+}
+
+/*
+	Setup: run groth16 setup for the compiled block circuit
+	NOTICE: the keys generated here rely on local randomness and are for test purpose only
+*/
+func Setup(blockCircuit *BlockCircuit) (pk ProvingKey, vk VerifyingKey, err error) {
+	if blockCircuit == nil || blockCircuit.Ccs == nil || blockCircuit.Backend != backend.GROTH16 {
+		log.Println("[Setup] invalid block circuit")
+		return nil, nil, errors.New("[Setup] invalid block circuit")
+	}
+	pk, vk, err = groth16.Setup(blockCircuit.Ccs)
+	if err != nil {
+		log.Println("[Setup] unable to setup block circuit:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+/*
+	ProveBlock: set the block witness and generate a groth16 proof for it
+*/
+func ProveBlock(blockCircuit *BlockCircuit, pk ProvingKey, oBlock *circuit.Block) (proof Proof, err error) {
+	if blockCircuit == nil || blockCircuit.Ccs == nil || blockCircuit.Backend != backend.GROTH16 || pk == nil {
+		log.Println("[ProveBlock] invalid params")
+		return nil, errors.New("[ProveBlock] invalid params")
+	}
+	fullWitness, err := newBlockWitness(blockCircuit, oBlock)
+	if err != nil {
+		log.Println("[ProveBlock] unable to generate witness:", err)
+		return nil, err
+	}
+	proof, err = groth16.Prove(blockCircuit.Ccs, pk, fullWitness)
+	if err != nil {
+		log.Println("[ProveBlock] unable to generate proof:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	VerifyBlockProof: verify a block proof against the block commitment
+*/
+func VerifyBlockProof(proof Proof, vk VerifyingKey, blockCommitment []byte) (err error) {
+	if proof == nil || vk == nil {
+		log.Println("[VerifyBlockProof] invalid params")
+		return errors.New("[VerifyBlockProof] invalid params")
+	}
+	publicWitness, err := newBlockPublicWitness(blockCommitment)
+	if err != nil {
+		log.Println("[VerifyBlockProof] unable to generate public witness:", err)
+		return err
+	}
+	err = groth16.Verify(proof, vk, publicWitness)
+	if err != nil {
+		log.Println("[VerifyBlockProof] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+func newBlockWitness(blockCircuit *BlockCircuit, oBlock *circuit.Block) (*witness.Witness, error) {
+	if err := checkBlockShape(blockCircuit, oBlock); err != nil {
+		return nil, err
+	}
+	blockWitness, err := circuit.SetBlockWitness(oBlock, blockCircuit.Config)
+	if err != nil {
+		return nil, err
+	}
+	blockWitness.TxsCount = blockCircuit.TxsCount
+	blockWitness.GasAssetIds = blockCircuit.GasAssetIds
+	blockWitness.GasAccountIndex = blockCircuit.GasAccountIndex
+	return frontend.NewWitness(&blockWitness, ecc.BN254)
+}
+
+func newBlockPublicWitness(blockCommitment []byte) (*witness.Witness, error) {
+	var blockWitness circuit.BlockConstraints
+	blockWitness.BlockCommitment = new(big.Int).SetBytes(blockCommitment)
+	return frontend.NewWitness(&blockWitness, ecc.BN254, frontend.PublicOnly())
+}
+
+func checkBlockShape(blockCircuit *BlockCircuit, oBlock *circuit.Block) error {
+	if oBlock == nil || oBlock.Gas == nil {
+		return errors.New("block and block gas should not be nil")
+	}
+	if len(oBlock.Txs) != blockCircuit.TxsCount {
+		return fmt.Errorf("block has %d txs, circuit expects %d", len(oBlock.Txs), blockCircuit.TxsCount)
+	}
+	if oBlock.Gas.GasAssetCount != len(blockCircuit.GasAssetIds) {
+		return fmt.Errorf("block has %d gas assets, circuit expects %d", oBlock.Gas.GasAssetCount, len(blockCircuit.GasAssetIds))
+	}
+	return nil
+}
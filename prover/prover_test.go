@@ -0,0 +1,101 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+)
+
+func emptyBlock(txsCount int, gasAssetIds []int64, config types.CircuitConfig) *circuit.Block {
+	stateRoot := make([]byte, 32)
+	oBlock := &circuit.Block{
+		BlockNumber:  1,
+		CreatedAt:    1654656781000,
+		OldStateRoot: stateRoot,
+		NewStateRoot: stateRoot,
+		Txs:          make([]*circuit.Tx, txsCount),
+		Gas: &circuit.Gas{
+			GasAssetCount:                   len(gasAssetIds),
+			AccountInfoBefore:               types.EmptyGasAccount(0, make([]byte, 32)),
+			MerkleProofsAccountAssetsBefore: make([][][]byte, len(gasAssetIds)),
+			MerkleProofsAccountBefore:       make([][]byte, config.AccountMerkleLevels),
+		},
+	}
+	for i := 0; i < txsCount; i++ {
+		oBlock.Txs[i] = circuit.EmptyTx(stateRoot, config)
+	}
+	for i := 0; i < len(gasAssetIds); i++ {
+		oBlock.Gas.AccountInfoBefore.AssetsInfo = append(oBlock.Gas.AccountInfoBefore.AssetsInfo, types.EmptyAccountAsset(gasAssetIds[i]))
+		oBlock.Gas.MerkleProofsAccountAssetsBefore[i] = make([][]byte, config.AssetMerkleLevels)
+		for j := 0; j < config.AssetMerkleLevels; j++ {
+			oBlock.Gas.MerkleProofsAccountAssetsBefore[i][j] = make([]byte, 32)
+		}
+	}
+	for i := 0; i < config.AccountMerkleLevels; i++ {
+		oBlock.Gas.MerkleProofsAccountBefore[i] = make([]byte, 32)
+	}
+	blockCommitment, err := circuit.ComputeBlockCommitment(oBlock, config)
+	if err != nil {
+		panic(err)
+	}
+	oBlock.BlockCommitment = blockCommitment
+	return oBlock
+}
+
+func TestProveBlockInvalidShape(t *testing.T) {
+	blockCircuit := &BlockCircuit{
+		TxsCount:        2,
+		GasAssetIds:     []int64{0, 1},
+		GasAccountIndex: 1,
+	}
+	assert.NotNil(t, checkBlockShape(blockCircuit, emptyBlock(1, []int64{0, 1}, types.MainnetConfig)))
+	assert.NotNil(t, checkBlockShape(blockCircuit, emptyBlock(2, []int64{0}, types.MainnetConfig)))
+	assert.Nil(t, checkBlockShape(blockCircuit, emptyBlock(2, []int64{0, 1}, types.MainnetConfig)))
+}
+
+func TestEmptyBlockWitness(t *testing.T) {
+	txsCount := 1
+	gasAssetIds := []int64{0, 1}
+	gasAccountIndex := int64(1)
+	for _, config := range []types.CircuitConfig{types.MainnetConfig, types.TestConfig} {
+		blockConstraints := NewBlockConstraints(txsCount, gasAssetIds, gasAccountIndex, types.MiMCHashType, config)
+
+		blockWitness, err := circuit.SetBlockWitness(emptyBlock(txsCount, gasAssetIds, config), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockWitness.TxsCount = txsCount
+		blockWitness.GasAssetIds = gasAssetIds
+		blockWitness.GasAccountIndex = gasAccountIndex
+		for _, backendID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+			err = test.IsSolved(&blockConstraints, &blockWitness, ecc.BN254, backendID)
+			assert.Nil(t, err)
+		}
+	}
+	// the proofs of a test block don't fit a mainnet circuit
+	_, err := circuit.SetBlockWitness(emptyBlock(txsCount, gasAssetIds, types.TestConfig), types.MainnetConfig)
+	assert.NotNil(t, err)
+}
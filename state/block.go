@@ -0,0 +1,188 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+/*
+	BlockBuilder: applies the txs of a block and seals them into a block witness
+*/
+type BlockBuilder struct {
+	BlockNumber int64
+	CreatedAt   int64
+	TxsCount    int
+
+	state        *State
+	oldStateRoot []byte
+	txs          []*circuit.Tx
+	// gas collected by the txs, indexed like GasAssetIds
+	gasDeltas []*big.Int
+	sealed    bool
+}
+
+func (s *State) NewBlock(blockNumber int64, createdAt int64, txsCount int) (*BlockBuilder, error) {
+	if txsCount <= 0 {
+		log.Println("[NewBlock] txs count should be positive")
+		return nil, errors.New("[NewBlock] txs count should be positive")
+	}
+	b := &BlockBuilder{
+		BlockNumber:  blockNumber,
+		CreatedAt:    createdAt,
+		TxsCount:     txsCount,
+		state:        s,
+		oldStateRoot: s.StateRoot(),
+		gasDeltas:    make([]*big.Int, len(s.GasAssetIds)),
+	}
+	for i := range b.gasDeltas {
+		b.gasDeltas[i] = big.NewInt(0)
+	}
+	return b, nil
+}
+
+/*
+	AddTx: apply a tx to the state, an invalid tx leaves both the state and the block untouched
+*/
+func (b *BlockBuilder) AddTx(txInfo txtypes.TxInfo) (oTx *circuit.Tx, err error) {
+	if b.sealed {
+		log.Println("[AddTx] block is sealed")
+		return nil, errors.New("[AddTx] block is sealed")
+	}
+	if len(b.txs) >= b.TxsCount {
+		log.Println("[AddTx] block is full")
+		return nil, errors.New("[AddTx] block is full")
+	}
+	oTx, gasDeltas, err := b.state.ApplyTx(txInfo, b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for i, gasAssetId := range b.state.GasAssetIds {
+		for _, gasDelta := range gasDeltas {
+			if gasDelta.AssetId == gasAssetId {
+				b.gasDeltas[i].Add(b.gasDeltas[i], gasDelta.BalanceDelta)
+			}
+		}
+	}
+	b.txs = append(b.txs, oTx)
+	return oTx, nil
+}
+
+/*
+	Seal: pad the block with empty txs, credit the gas account and compute the block commitment.
+	The gas account is credited when any tx of the block pays gas, as in VerifyBlock.
+*/
+func (b *BlockBuilder) Seal() (oBlock *circuit.Block, err error) {
+	if b.sealed {
+		log.Println("[Seal] block is sealed")
+		return nil, errors.New("[Seal] block is sealed")
+	}
+	if len(b.txs) == 0 {
+		log.Println("[Seal] block should contain at least one tx")
+		return nil, errors.New("[Seal] block should contain at least one tx")
+	}
+	s := b.state
+	txs := b.txs
+	for len(txs) < b.TxsCount {
+		txs = append(txs, circuit.EmptyTx(s.StateRoot(), s.Config))
+	}
+	needGas := false
+	for _, oTx := range txs {
+		needGas = needGas || circuit.IsLayer2Tx(oTx)
+	}
+
+	gasAccount, err := s.Account(s.GasAccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	if needGas && bytesToInt(gasAccount.AccountNameHash).Sign() == 0 {
+		log.Println("[Seal] gas account doesn't exist")
+		return nil, errors.New("[Seal] gas account doesn't exist")
+	}
+	gas := &circuit.Gas{
+		GasAssetCount: len(s.GasAssetIds),
+		AccountInfoBefore: &types.GasAccount{
+			AccountIndex:    gasAccount.AccountIndex,
+			AccountNameHash: gasAccount.AccountNameHash,
+			AccountPk:       gasAccount.AccountPk,
+			Nonce:           gasAccount.Nonce,
+			CollectionNonce: gasAccount.CollectionNonce,
+			AssetRoot:       gasAccount.AssetRoot,
+		},
+		MerkleProofsAccountAssetsBefore: make([][][]byte, len(s.GasAssetIds)),
+	}
+
+	// the gas account is proven like a tx slot, assets first then the account
+	s.journal = nil
+	err = func() error {
+		proof, err := s.merkleProof(s.accountTree, s.GasAccountIndex, s.accountLeafHash(s.account(s.GasAccountIndex), gasAccount.AssetRoot), s.Config.AccountMerkleLevels)
+		if err != nil {
+			return err
+		}
+		gas.MerkleProofsAccountBefore = proof
+		assetTree, err := s.assetTree(s.GasAccountIndex)
+		if err != nil {
+			return err
+		}
+		for i, gasAssetId := range s.GasAssetIds {
+			asset := s.Asset(s.GasAccountIndex, gasAssetId)
+			gas.AccountInfoBefore.AssetsInfo = append(gas.AccountInfoBefore.AssetsInfo, copyAsset(asset))
+			proof, err = s.merkleProof(assetTree, gasAssetId, s.assetLeafHash(asset), s.Config.AssetMerkleLevels)
+			if err != nil {
+				return err
+			}
+			gas.MerkleProofsAccountAssetsBefore[i] = proof
+			if needGas {
+				asset.Balance.Add(asset.Balance, b.gasDeltas[i])
+			}
+			if err = s.setAsset(s.GasAccountIndex, asset); err != nil {
+				return err
+			}
+		}
+		if err = s.setAccount(s.GasAccountIndex, s.account(s.GasAccountIndex)); err != nil {
+			return err
+		}
+		oBlock = &circuit.Block{
+			BlockNumber:  b.BlockNumber,
+			CreatedAt:    b.CreatedAt,
+			ChainId:      s.ChainId,
+			OldStateRoot: b.oldStateRoot,
+			NewStateRoot: s.StateRoot(),
+			Txs:          txs,
+			Gas:          gas,
+		}
+		oBlock.BlockCommitment, err = circuit.ComputeBlockCommitment(oBlock, s.Config)
+		return err
+	}()
+	if err != nil {
+		if revertErr := s.revert(); revertErr != nil {
+			log.Println("[Seal] unable to revert state:", revertErr)
+			return nil, revertErr
+		}
+		return nil, err
+	}
+	s.journal = nil
+	b.sealed = true
+	return oBlock, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+)
+
+/*
+	Exodus: witness of the balance of an account asset in the current state
+*/
+func (s *State) Exodus(accountIndex int64, assetId int64) (oExodus *circuit.Exodus, err error) {
+	if accountIndex < 0 || accountIndex > s.Config.LastAccountIndex() {
+		return nil, fmt.Errorf("[Exodus] invalid account index %d", accountIndex)
+	}
+	if assetId < 0 || assetId > s.Config.LastAccountAssetId() {
+		return nil, fmt.Errorf("[Exodus] invalid asset id %d", assetId)
+	}
+	accountInfo, err := s.Account(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	if bytesToInt(accountInfo.AccountNameHash).Sign() == 0 {
+		log.Println("[Exodus] account doesn't exist")
+		return nil, errors.New("[Exodus] account doesn't exist")
+	}
+	oExodus = &circuit.Exodus{
+		StateRoot:      s.StateRoot(),
+		AccountRoot:    s.AccountRoot(),
+		LiquidityRoot:  s.LiquidityRoot(),
+		NftRoot:        s.NftRoot(),
+		CollectionRoot: s.CollectionRoot(),
+		AccountInfo:    accountInfo,
+		Asset:          s.Asset(accountIndex, assetId),
+	}
+	assetTree, err := s.assetTree(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := s.merkleProof(assetTree, assetId, s.assetLeafHash(oExodus.Asset), s.Config.AssetMerkleLevels)
+	if err != nil {
+		return nil, err
+	}
+	oExodus.MerkleProofsAccountAsset = proof
+	proof, err = s.merkleProof(s.accountTree, accountIndex, s.accountLeafHash(s.account(accountIndex), accountInfo.AssetRoot), s.Config.AccountMerkleLevels)
+	if err != nil {
+		return nil, err
+	}
+	oExodus.MerkleProofsAccount = proof
+	return oExodus, nil
+}
+
+/*
+	ExodusNft: witness of an nft and of its owner in the current state
+*/
+func (s *State) ExodusNft(nftIndex int64) (oExodus *circuit.ExodusNft, err error) {
+	if nftIndex < 0 || nftIndex > s.Config.LastNftIndex() {
+		return nil, fmt.Errorf("[ExodusNft] invalid nft index %d", nftIndex)
+	}
+	nft := s.Nft(nftIndex)
+	if isEmptyNft(nft) {
+		log.Println("[ExodusNft] nft doesn't exist")
+		return nil, errors.New("[ExodusNft] nft doesn't exist")
+	}
+	accountInfo, err := s.Account(nft.OwnerAccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	if bytesToInt(accountInfo.AccountNameHash).Sign() == 0 {
+		log.Println("[ExodusNft] owner account doesn't exist")
+		return nil, errors.New("[ExodusNft] owner account doesn't exist")
+	}
+	oExodus = &circuit.ExodusNft{
+		StateRoot:      s.StateRoot(),
+		AccountRoot:    s.AccountRoot(),
+		LiquidityRoot:  s.LiquidityRoot(),
+		NftRoot:        s.NftRoot(),
+		CollectionRoot: s.CollectionRoot(),
+		AccountInfo:    accountInfo,
+		Nft:            nft,
+	}
+	proof, err := s.merkleProof(s.nftTree, nftIndex, s.nftLeafHash(nft), s.Config.NftMerkleLevels)
+	if err != nil {
+		return nil, err
+	}
+	oExodus.MerkleProofsNft = proof
+	proof, err = s.merkleProof(s.accountTree, nft.OwnerAccountIndex, s.accountLeafHash(s.account(nft.OwnerAccountIndex), accountInfo.AssetRoot), s.Config.AccountMerkleLevels)
+	if err != nil {
+		return nil, err
+	}
+	oExodus.MerkleProofsAccount = proof
+	return oExodus, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+func TestExodus(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	expiredAt := int64(testBlockCreatedAt + 3600000)
+	fee := big.NewInt(10)
+
+	createCollection := &txtypes.CreateCollectionTxInfo{
+		AccountIndex: alice.index, CollectionId: 1, Name: "collection",
+		MetadataHash: hex.EncodeToString(alice.nameHash), CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 0, ChainId: testChainId,
+	}
+	createCollection.Sig = signTx(t, alice, createCollection)
+	mintNft := &txtypes.MintNftTxInfo{
+		CreatorAccountIndex: alice.index, ToAccountIndex: bob.index,
+		ToAccountNameHash: hex.EncodeToString(bob.nameHash),
+		NftIndex:          0, NftContentHash: hex.EncodeToString(alice.nameHash),
+		NftCollectionId: 1, CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 1, ChainId: testChainId,
+	}
+	mintNft.Sig = signTx(t, alice, mintNft)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: alice.index, AccountNameHash: alice.nameHash, AssetId: 0, AssetAmount: big.NewInt(100000)},
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: alice.index, AccountNameHash: alice.nameHash, AssetId: 7, AssetAmount: big.NewInt(500)},
+		createCollection,
+		mintNft,
+	}
+	for i, txInfo := range txInfos {
+		_, _, err = s.ApplyTx(txInfo, testBlockCreatedAt)
+		require.NoError(t, err, "tx %d", i)
+	}
+
+	exodusConstraints := circuit.NewExodusConstraints(s.Config, s.HashType)
+	for _, assetId := range []int64{0, 7, 8} {
+		oExodus, err := s.Exodus(alice.index, assetId)
+		require.NoError(t, err)
+		assert.Equal(t, s.StateRoot(), oExodus.StateRoot)
+		witness, err := circuit.SetExodusWitness(oExodus, s.Config)
+		require.NoError(t, err)
+		assert.NoError(t, test.IsSolved(&exodusConstraints, &witness, ecc.BN254, backend.GROTH16), "asset %d", assetId)
+
+		// the balance is public
+		witness.Balance = new(big.Int).Add(oExodus.Asset.Balance, big.NewInt(1))
+		assert.Error(t, test.IsSolved(&exodusConstraints, &witness, ecc.BN254, backend.GROTH16), "asset %d", assetId)
+	}
+	assert.Equal(t, int64(99980), s.Asset(alice.index, 0).Balance.Int64())
+	// the account of another owner
+	oExodus, err := s.Exodus(alice.index, 0)
+	require.NoError(t, err)
+	witness, err := circuit.SetExodusWitness(oExodus, s.Config)
+	require.NoError(t, err)
+	witness.AccountNameHash = bob.nameHash
+	assert.Error(t, test.IsSolved(&exodusConstraints, &witness, ecc.BN254, backend.GROTH16))
+	_, err = s.Exodus(5, 0)
+	assert.Error(t, err)
+
+	exodusNftConstraints := circuit.NewExodusNftConstraints(s.Config, s.HashType)
+	oExodusNft, err := s.ExodusNft(0)
+	require.NoError(t, err)
+	assert.Equal(t, bob.index, oExodusNft.AccountInfo.AccountIndex)
+	nftWitness, err := circuit.SetExodusNftWitness(oExodusNft, s.Config)
+	require.NoError(t, err)
+	assert.NoError(t, test.IsSolved(&exodusNftConstraints, &nftWitness, ecc.BN254, backend.GROTH16))
+
+	// only the owner can exit the nft
+	oExodus, err = s.Exodus(alice.index, 0)
+	require.NoError(t, err)
+	oExodusNft.AccountInfo = oExodus.AccountInfo
+	oExodusNft.MerkleProofsAccount = oExodus.MerkleProofsAccount
+	nftWitness, err = circuit.SetExodusNftWitness(oExodusNft, s.Config)
+	require.NoError(t, err)
+	assert.Error(t, test.IsSolved(&exodusNftConstraints, &nftWitness, ecc.BN254, backend.GROTH16))
+	_, err = s.ExodusNft(1)
+	assert.Error(t, err)
+}
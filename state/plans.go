@@ -0,0 +1,1396 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+func (s *State) planRegisterZns(txInfo *txtypes.RegisterZnsTxInfo) (plan *txPlan, err error) {
+	pk, err := txtypes.ParsePublicKey(txInfo.PubKey)
+	if err != nil {
+		log.Println("[planRegisterZns] invalid public key:", err)
+		return nil, err
+	}
+	plan = s.newTxPlan(types.TxTypeRegisterZns, txInfo.AccountIndex)
+	plan.register = &account{
+		AccountNameHash: txInfo.AccountNameHash,
+		AccountPk:       pk,
+	}
+	plan.oTx.RegisterZnsTxInfo = &circuit.RegisterZnsTx{
+		AccountIndex:    txInfo.AccountIndex,
+		AccountName:     txtypes.PaddingStringToBytes32(txInfo.AccountName),
+		AccountNameHash: txInfo.AccountNameHash,
+		PubKey:          pk,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !s.isEmptyAccount(accountsBefore[0]) {
+			return errors.New("account already exists")
+		}
+		return nil
+	}
+	return plan, nil
+}
+
+func (s *State) planDeposit(txInfo *txtypes.DepositTxInfo) (plan *txPlan, err error) {
+	if txInfo.AssetAmount == nil || txInfo.AssetAmount.Sign() < 0 || txInfo.AssetAmount.BitLen() > 128 {
+		log.Println("[planDeposit] invalid asset amount")
+		return nil, errors.New("[planDeposit] invalid asset amount")
+	}
+	plan = s.newTxPlan(types.TxTypeDeposit, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.AssetId
+	plan.assetDeltas[0][0] = balanceDelta(txInfo.AssetAmount)
+	plan.oTx.DepositTxInfo = &circuit.DepositTx{
+		AccountIndex:    txInfo.AccountIndex,
+		AccountNameHash: txInfo.AccountNameHash,
+		AssetId:         txInfo.AssetId,
+		AssetAmount:     txInfo.AssetAmount,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !equalField(txInfo.AccountNameHash, accountsBefore[0].AccountNameHash) {
+			return errors.New("invalid account name hash")
+		}
+		return nil
+	}
+	return plan, nil
+}
+
+func (s *State) planDepositNft(txInfo *txtypes.DepositNftTxInfo) (plan *txPlan, err error) {
+	plan = s.newTxPlan(types.TxTypeDepositNft, txInfo.AccountIndex)
+	plan.nftIndex = txInfo.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		return &types.Nft{
+			NftIndex:            txInfo.NftIndex,
+			NftContentHash:      txInfo.NftContentHash,
+			CreatorAccountIndex: txInfo.CreatorAccountIndex,
+			OwnerAccountIndex:   txInfo.AccountIndex,
+			NftL1Address:        addressToInt(txInfo.NftL1Address),
+			NftL1TokenId:        new(big.Int).Set(txInfo.NftL1TokenId),
+			CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+			CollectionId:        txInfo.CollectionId,
+		}
+	}
+	plan.oTx.DepositNftTxInfo = &circuit.DepositNftTx{
+		AccountIndex:        txInfo.AccountIndex,
+		NftIndex:            txInfo.NftIndex,
+		NftL1Address:        addressString(txInfo.NftL1Address),
+		AccountNameHash:     txInfo.AccountNameHash,
+		NftContentHash:      txInfo.NftContentHash,
+		NftL1TokenId:        txInfo.NftL1TokenId,
+		CreatorAccountIndex: txInfo.CreatorAccountIndex,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+		CollectionId:        txInfo.CollectionId,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !isEmptyNft(nftBefore) {
+			return errors.New("nft already exists")
+		}
+		if !equalField(txInfo.AccountNameHash, accountsBefore[0].AccountNameHash) {
+			return errors.New("invalid account name hash")
+		}
+		return nil
+	}
+	return plan, nil
+}
+
+func (s *State) planTransfer(txInfo *txtypes.TransferTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedAmount, err := txtypes.ToPackedAmount(txInfo.AssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	amount, fee := unpackAmount(packedAmount), unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeTransfer, txInfo.FromAccountIndex)
+	plan.accountIndexes[1] = txInfo.ToAccountIndex
+	plan.setAssetIds(0, txInfo.AssetId, txInfo.GasFeeAssetId)
+	plan.assetIds[1][0] = txInfo.AssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(amount))
+	plan.assetDeltas[0][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.assetDeltas[1][0] = balanceDelta(amount)
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	toAccountNameHash := common.FromHex(txInfo.ToAccountNameHash)
+	plan.oTx.TransferTxInfo = &circuit.TransferTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		ToAccountIndex:    txInfo.ToAccountIndex,
+		ToAccountNameHash: toAccountNameHash,
+		AssetId:           txInfo.AssetId,
+		AssetAmount:       packedAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+		CallDataHash:      txInfo.CallDataHash,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !equalField(toAccountNameHash, accountsBefore[1].AccountNameHash) {
+			return errors.New("invalid to account name hash")
+		}
+		if amount.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient balance")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planBatchTransfer: recipient i takes the account slot i+1, the slots of
+	missing recipients are filled with the sender and receive nothing
+*/
+func (s *State) planBatchTransfer(txInfo *txtypes.BatchTransferTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeBatchTransfer, txInfo.FromAccountIndex)
+	plan.setAssetIds(0, txInfo.AssetId, txInfo.GasFeeAssetId)
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	oTxInfo := &circuit.BatchTransferTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		AssetId:           txInfo.AssetId,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+		CallDataHash:      txInfo.CallDataHash,
+	}
+	totalAmount := big.NewInt(0)
+	for i := 0; i < types.NbBatchTransferRecipients; i++ {
+		plan.assetIds[i+1][0] = txInfo.AssetId
+		oTxInfo.ToAccountIndexes[i] = txInfo.FromAccountIndex
+		oTxInfo.ToAccountNameHashes[i] = make([]byte, 32)
+		if i >= len(txInfo.Recipients) {
+			continue
+		}
+		recipient := txInfo.Recipients[i]
+		packedAmount, err := txtypes.ToPackedAmount(recipient.AssetAmount)
+		if err != nil {
+			return nil, err
+		}
+		amount := unpackAmount(packedAmount)
+		totalAmount.Add(totalAmount, amount)
+		plan.accountIndexes[i+1] = recipient.ToAccountIndex
+		plan.assetDeltas[i+1][0] = balanceDelta(amount)
+		oTxInfo.ToAccountIndexes[i] = recipient.ToAccountIndex
+		oTxInfo.ToAccountNameHashes[i] = common.FromHex(recipient.ToAccountNameHash)
+		oTxInfo.AssetAmounts[i] = packedAmount
+	}
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(totalAmount))
+	plan.assetDeltas[0][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.oTx.BatchTransferTxInfo = oTxInfo
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		for i := range txInfo.Recipients {
+			if !equalField(oTxInfo.ToAccountNameHashes[i], accountsBefore[i+1].AccountNameHash) {
+				return errors.New("invalid to account name hash")
+			}
+		}
+		if totalAmount.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient balance")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planWithdraw(txInfo *txtypes.WithdrawTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeWithdraw, txInfo.FromAccountIndex)
+	plan.setAssetIds(0, txInfo.AssetId, txInfo.GasFeeAssetId)
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(txInfo.AssetAmount))
+	plan.assetDeltas[0][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.oTx.WithdrawTxInfo = &circuit.WithdrawTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		AssetId:           txInfo.AssetId,
+		AssetAmount:       txInfo.AssetAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+		ToAddress:         addressToInt(txInfo.ToAddress),
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if txInfo.AssetAmount.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient balance")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planCreateCollection(txInfo *txtypes.CreateCollectionTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeCreateCollection, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.collectionId = txInfo.CollectionId
+	metadataHash := common.FromHex(txInfo.MetadataHash)
+	plan.collectionAfter = func(collectionBefore *types.Collection) *types.Collection {
+		return &types.Collection{
+			CollectionId:        txInfo.CollectionId,
+			OwnerAccountIndex:   txInfo.AccountIndex,
+			MetadataHash:        metadataHash,
+			CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+		}
+	}
+	plan.oTx.CreateCollectionTxInfo = &circuit.CreateCollectionTx{
+		AccountIndex:        txInfo.AccountIndex,
+		CollectionId:        txInfo.CollectionId,
+		MetadataHash:        metadataHash,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+		GasAccountIndex:     txInfo.GasAccountIndex,
+		GasFeeAssetId:       txInfo.GasFeeAssetId,
+		GasFeeAssetAmount:   packedFee,
+		ExpiredAt:           txInfo.ExpiredAt,
+		Nonce:               txInfo.Nonce,
+	}
+	collectionBefore := s.Collection(txInfo.CollectionId)
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if txInfo.CollectionId == types.NoCollectionId || txInfo.CollectionId > s.Config.LastCollectionId() {
+			return errors.New("invalid collection id")
+		}
+		if !isEmptyCollection(collectionBefore) {
+			return errors.New("collection already exists")
+		}
+		if bytesToInt(metadataHash).Sign() == 0 {
+			return errors.New("collection metadata hash should not be empty")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planMintNft(txInfo *txtypes.MintNftTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeMintNft, txInfo.CreatorAccountIndex)
+	plan.accountIndexes[1] = txInfo.ToAccountIndex
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.nftIndex = txInfo.NftIndex
+	plan.collectionId = txInfo.NftCollectionId
+	toAccountNameHash := common.FromHex(txInfo.ToAccountNameHash)
+	nftContentHash := common.FromHex(txInfo.NftContentHash)
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		return &types.Nft{
+			NftIndex:            txInfo.NftIndex,
+			NftContentHash:      nftContentHash,
+			CreatorAccountIndex: txInfo.CreatorAccountIndex,
+			OwnerAccountIndex:   txInfo.ToAccountIndex,
+			NftL1Address:        big.NewInt(0),
+			NftL1TokenId:        big.NewInt(0),
+			CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+			CollectionId:        txInfo.NftCollectionId,
+		}
+	}
+	plan.oTx.MintNftTxInfo = &circuit.MintNftTx{
+		CreatorAccountIndex: txInfo.CreatorAccountIndex,
+		ToAccountIndex:      txInfo.ToAccountIndex,
+		ToAccountNameHash:   toAccountNameHash,
+		NftIndex:            txInfo.NftIndex,
+		NftContentHash:      nftContentHash,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+		GasAccountIndex:     txInfo.GasAccountIndex,
+		GasFeeAssetId:       txInfo.GasFeeAssetId,
+		GasFeeAssetAmount:   packedFee,
+		CollectionId:        txInfo.NftCollectionId,
+		ExpiredAt:           txInfo.ExpiredAt,
+	}
+	collectionBefore := s.Collection(txInfo.NftCollectionId)
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !isEmptyNft(nftBefore) {
+			return errors.New("nft already exists")
+		}
+		if !equalField(toAccountNameHash, accountsBefore[1].AccountNameHash) {
+			return errors.New("invalid to account name hash")
+		}
+		if bytesToInt(nftContentHash).Sign() == 0 {
+			return errors.New("nft content hash should not be empty")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		if txInfo.NftCollectionId == types.NoCollectionId {
+			return nil
+		}
+		if isEmptyCollection(collectionBefore) {
+			return errors.New("collection doesn't exist")
+		}
+		if collectionBefore.OwnerAccountIndex != txInfo.CreatorAccountIndex {
+			return errors.New("account is not the owner of the collection")
+		}
+		if txInfo.CreatorTreasuryRate != collectionBefore.CreatorTreasuryRate {
+			return errors.New("creator treasury rate should be the rate of the collection")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planTransferNft(txInfo *txtypes.TransferNftTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeTransferNft, txInfo.FromAccountIndex)
+	plan.accountIndexes[1] = txInfo.ToAccountIndex
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.nftIndex = txInfo.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		nftAfter := copyNft(nftBefore)
+		nftAfter.OwnerAccountIndex = txInfo.ToAccountIndex
+		return nftAfter
+	}
+	toAccountNameHash := common.FromHex(txInfo.ToAccountNameHash)
+	plan.oTx.TransferNftTxInfo = &circuit.TransferNftTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		ToAccountIndex:    txInfo.ToAccountIndex,
+		ToAccountNameHash: toAccountNameHash,
+		NftIndex:          txInfo.NftIndex,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+		CallDataHash:      txInfo.CallDataHash,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !equalField(toAccountNameHash, accountsBefore[1].AccountNameHash) {
+			return errors.New("invalid to account name hash")
+		}
+		if nftBefore.OwnerAccountIndex != txInfo.FromAccountIndex {
+			return errors.New("account is not the owner of the nft")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planAtomicMatch: the circuit splits the amount with field divisions, the
+	creator and treasury amounts must therefore be exact and are required to
+	match the amounts of the tx
+*/
+func (s *State) planAtomicMatch(txInfo *txtypes.AtomicMatchTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	buyOffer, err := offerTx(txInfo.BuyOffer, txInfo.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	sellOffer, err := offerTx(txInfo.SellOffer, txInfo.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	// the offer ids are decomposed into 23 bits when updating the offer bits
+	if buyOffer.OfferId >= 1<<23 || sellOffer.OfferId >= 1<<23 {
+		log.Println("[planAtomicMatch] invalid offer id")
+		return nil, errors.New("[planAtomicMatch] invalid offer id")
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	amount := unpackAmount(buyOffer.AssetAmount)
+	nft := s.Nft(sellOffer.NftIndex)
+	creatorAmount, err := rateOf(amount, nft.CreatorTreasuryRate)
+	if err != nil {
+		return nil, err
+	}
+	treasuryAmount, err := rateOf(amount, buyOffer.TreasuryRate)
+	if err != nil {
+		return nil, err
+	}
+	if txInfo.CreatorAmount == nil || txInfo.CreatorAmount.Cmp(creatorAmount) != 0 ||
+		txInfo.TreasuryAmount == nil || txInfo.TreasuryAmount.Cmp(treasuryAmount) != 0 {
+		log.Println("[planAtomicMatch] invalid creator or treasury amount")
+		return nil, errors.New("[planAtomicMatch] invalid creator or treasury amount")
+	}
+	packedCreatorAmount, err := txtypes.ToPackedAmount(creatorAmount)
+	if err != nil {
+		return nil, err
+	}
+	packedTreasuryAmount, err := txtypes.ToPackedAmount(treasuryAmount)
+	if err != nil {
+		return nil, err
+	}
+	sellerAmount := new(big.Int).Sub(amount, new(big.Int).Add(creatorAmount, treasuryAmount))
+
+	plan = s.newTxPlan(types.TxTypeAtomicMatch, txInfo.AccountIndex)
+	plan.accountIndexes[1] = buyOffer.AccountIndex
+	plan.accountIndexes[2] = sellOffer.AccountIndex
+	plan.accountIndexes[3] = nft.CreatorAccountIndex
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.setAssetIds(1, buyOffer.AssetId, buyOffer.OfferId / circuit.OfferSizePerAsset)
+	plan.setAssetIds(2, sellOffer.AssetId, sellOffer.OfferId / circuit.OfferSizePerAsset)
+	plan.assetIds[3][0] = sellOffer.AssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.assetDeltas[1][0] = balanceDelta(new(big.Int).Neg(amount))
+	plan.assetDeltas[1][1] = offerDelta(buyOffer.OfferId)
+	plan.assetDeltas[2][0] = balanceDelta(sellerAmount)
+	plan.assetDeltas[2][1] = offerDelta(sellOffer.OfferId)
+	plan.assetDeltas[3][0] = balanceDelta(creatorAmount)
+	plan.gasDeltas[0] = GasDelta{AssetId: buyOffer.AssetId, BalanceDelta: treasuryAmount}
+	plan.gasDeltas[1] = GasDelta{AssetId: txInfo.GasFeeAssetId, BalanceDelta: fee}
+	for i := 2; i < types.NbGasAssetsPerTx; i++ {
+		plan.gasDeltas[i] = GasDelta{AssetId: txInfo.GasFeeAssetId, BalanceDelta: big.NewInt(0)}
+	}
+	plan.nftIndex = sellOffer.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		nftAfter := copyNft(nftBefore)
+		nftAfter.OwnerAccountIndex = buyOffer.AccountIndex
+		return nftAfter
+	}
+	plan.oTx.AtomicMatchTxInfo = &circuit.AtomicMatchTx{
+		AccountIndex:      txInfo.AccountIndex,
+		BuyOffer:          buyOffer,
+		SellOffer:         sellOffer,
+		CreatorAmount:     packedCreatorAmount,
+		TreasuryAmount:    packedTreasuryAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if buyOffer.Type != txtypes.BuyOfferType || sellOffer.Type != txtypes.SellOfferType {
+			return errors.New("invalid offer types")
+		}
+		if buyOffer.AssetId != sellOffer.AssetId || buyOffer.AssetAmount != sellOffer.AssetAmount ||
+			buyOffer.NftIndex != sellOffer.NftIndex || buyOffer.TreasuryRate != sellOffer.TreasuryRate {
+			return errors.New("buy offer doesn't match sell offer")
+		}
+		if blockCreatedAt > buyOffer.ExpiredAt || blockCreatedAt > sellOffer.ExpiredAt {
+			return errors.New("offer expired")
+		}
+		if err := s.verifyOfferSig(buyOffer, txInfo.BuyOffer.Sig, txInfo.AccountIndex, accountsBefore[1].AccountPk); err != nil {
+			return err
+		}
+		if err := s.verifyOfferSig(sellOffer, txInfo.SellOffer.Sig, txInfo.AccountIndex, accountsBefore[2].AccountPk); err != nil {
+			return err
+		}
+		if accountsBefore[1].AssetsInfo[1].OfferCanceledOrFinalized.Bit(int(buyOffer.OfferId%circuit.OfferSizePerAsset)) != 0 {
+			return errors.New("buy offer canceled or finalized")
+		}
+		if accountsBefore[2].AssetsInfo[1].OfferCanceledOrFinalized.Bit(int(sellOffer.OfferId%circuit.OfferSizePerAsset)) != 0 {
+			return errors.New("sell offer canceled or finalized")
+		}
+		if amount.Cmp(accountsBefore[1].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient buyer balance")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planCancelOffer(txInfo *txtypes.CancelOfferTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	if txInfo.OfferId >= 1<<24 {
+		log.Println("[planCancelOffer] invalid offer id")
+		return nil, errors.New("[planCancelOffer] invalid offer id")
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeCancelOffer, txInfo.AccountIndex)
+	plan.setAssetIds(0, txInfo.GasFeeAssetId, txInfo.OfferId / circuit.OfferSizePerAsset)
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.assetDeltas[0][1] = offerDelta(txInfo.OfferId)
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.oTx.CancelOfferTxInfo = &circuit.CancelOfferTx{
+		AccountIndex:      txInfo.AccountIndex,
+		OfferId:           txInfo.OfferId,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planWithdrawNft: the circuit reads the creator from the first account
+	slot, only nfts owned by their creator can be withdrawn
+*/
+func (s *State) planWithdrawNft(txInfo *txtypes.WithdrawNftTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeWithdrawNft, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.nftIndex = txInfo.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		return types.EmptyNft(txInfo.NftIndex)
+	}
+	plan.oTx.WithdrawNftTxInfo = &circuit.WithdrawNftTx{
+		AccountIndex:           txInfo.AccountIndex,
+		CreatorAccountIndex:    txInfo.CreatorAccountIndex,
+		CreatorAccountNameHash: txInfo.CreatorAccountNameHash,
+		CreatorTreasuryRate:    txInfo.CreatorTreasuryRate,
+		NftIndex:               txInfo.NftIndex,
+		NftContentHash:         txInfo.NftContentHash,
+		NftL1Address:           addressString(txInfo.NftL1Address),
+		NftL1TokenId:           txInfo.NftL1TokenId,
+		ToAddress:              addressString(txInfo.ToAddress),
+		GasAccountIndex:        txInfo.GasAccountIndex,
+		GasFeeAssetId:          txInfo.GasFeeAssetId,
+		GasFeeAssetAmount:      packedFee,
+		CollectionId:           txInfo.CollectionId,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if txInfo.CreatorAccountIndex != accountsBefore[0].AccountIndex ||
+			!equalField(txInfo.CreatorAccountNameHash, accountsBefore[0].AccountNameHash) {
+			return errors.New("invalid creator account")
+		}
+		if txInfo.CreatorAccountIndex != nftBefore.CreatorAccountIndex ||
+			txInfo.CreatorTreasuryRate != nftBefore.CreatorTreasuryRate ||
+			txInfo.CollectionId != nftBefore.CollectionId ||
+			!equalField(txInfo.NftContentHash, nftBefore.NftContentHash) ||
+			txInfo.NftL1TokenId.Cmp(nftBefore.NftL1TokenId) != 0 ||
+			addressToInt(txInfo.NftL1Address).Cmp(nftBefore.NftL1Address) != 0 {
+			return errors.New("nft info doesn't match")
+		}
+		if txInfo.AccountIndex != nftBefore.OwnerAccountIndex {
+			return errors.New("account is not the owner of the nft")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planBurnNft(txInfo *txtypes.BurnNftTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeBurnNft, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.nftIndex = txInfo.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		return types.EmptyNft(txInfo.NftIndex)
+	}
+	plan.oTx.BurnNftTxInfo = &circuit.BurnNftTx{
+		AccountIndex:      txInfo.AccountIndex,
+		NftIndex:          txInfo.NftIndex,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if isEmptyNft(nftBefore) || bytesToInt(nftBefore.NftContentHash).Sign() == 0 {
+			return errors.New("nft doesn't exist")
+		}
+		if nftBefore.OwnerAccountIndex != txInfo.AccountIndex {
+			return errors.New("account is not the owner of the nft")
+		}
+		if nftBefore.NftL1Address.Sign() != 0 {
+			return errors.New("nft deposited from layer 1 should be withdrawn instead of burnt")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planUpdateCollection(txInfo *txtypes.UpdateCollectionTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeUpdateCollection, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.collectionId = txInfo.CollectionId
+	metadataHash := common.FromHex(txInfo.MetadataHash)
+	plan.collectionAfter = func(collectionBefore *types.Collection) *types.Collection {
+		collectionAfter := copyCollection(collectionBefore)
+		collectionAfter.MetadataHash = metadataHash
+		collectionAfter.CreatorTreasuryRate = txInfo.CreatorTreasuryRate
+		return collectionAfter
+	}
+	plan.oTx.UpdateCollectionTxInfo = &circuit.UpdateCollectionTx{
+		AccountIndex:        txInfo.AccountIndex,
+		CollectionId:        txInfo.CollectionId,
+		MetadataHash:        metadataHash,
+		CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+		GasAccountIndex:     txInfo.GasAccountIndex,
+		GasFeeAssetId:       txInfo.GasFeeAssetId,
+		GasFeeAssetAmount:   packedFee,
+	}
+	collectionBefore := s.Collection(txInfo.CollectionId)
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if isEmptyCollection(collectionBefore) {
+			return errors.New("collection doesn't exist")
+		}
+		if collectionBefore.OwnerAccountIndex != txInfo.AccountIndex {
+			return errors.New("account is not the owner of the collection")
+		}
+		if bytesToInt(metadataHash).Sign() == 0 {
+			return errors.New("collection metadata hash should not be empty")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planTransferCollection(txInfo *txtypes.TransferCollectionTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeTransferCollection, txInfo.FromAccountIndex)
+	plan.accountIndexes[1] = txInfo.ToAccountIndex
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.collectionId = txInfo.CollectionId
+	plan.collectionAfter = func(collectionBefore *types.Collection) *types.Collection {
+		collectionAfter := copyCollection(collectionBefore)
+		collectionAfter.OwnerAccountIndex = txInfo.ToAccountIndex
+		return collectionAfter
+	}
+	toAccountNameHash := common.FromHex(txInfo.ToAccountNameHash)
+	plan.oTx.TransferCollectionTxInfo = &circuit.TransferCollectionTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		ToAccountIndex:    txInfo.ToAccountIndex,
+		ToAccountNameHash: toAccountNameHash,
+		CollectionId:      txInfo.CollectionId,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	collectionBefore := s.Collection(txInfo.CollectionId)
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if isEmptyCollection(collectionBefore) {
+			return errors.New("collection doesn't exist")
+		}
+		if collectionBefore.OwnerAccountIndex != txInfo.FromAccountIndex {
+			return errors.New("account is not the owner of the collection")
+		}
+		if !equalField(toAccountNameHash, accountsBefore[1].AccountNameHash) {
+			return errors.New("invalid to account name hash")
+		}
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+func (s *State) planFullExit(txInfo *txtypes.FullExitTxInfo) (plan *txPlan, err error) {
+	plan = s.newTxPlan(types.TxTypeFullExit, txInfo.AccountIndex)
+	plan.assetIds[0][0] = txInfo.AssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(txInfo.AssetAmount))
+	plan.oTx.FullExitTxInfo = &circuit.FullExitTx{
+		AccountIndex:    txInfo.AccountIndex,
+		AccountNameHash: txInfo.AccountNameHash,
+		AssetId:         txInfo.AssetId,
+		AssetAmount:     txInfo.AssetAmount,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !equalField(txInfo.AccountNameHash, accountsBefore[0].AccountNameHash) {
+			return errors.New("invalid account name hash")
+		}
+		if txInfo.AssetAmount.Cmp(accountsBefore[0].AssetsInfo[0].Balance) != 0 {
+			return errors.New("full exit amount should be the whole balance")
+		}
+		return nil
+	}
+	return plan, nil
+}
+
+/*
+	planFullExitNft: the nft is always cleared, its info is only checked when
+	the account owns it and the creator only when a creator name hash is given
+*/
+func (s *State) planFullExitNft(txInfo *txtypes.FullExitNftTxInfo) (plan *txPlan, err error) {
+	plan = s.newTxPlan(types.TxTypeFullExitNft, txInfo.AccountIndex)
+	plan.nftIndex = txInfo.NftIndex
+	plan.nftAfter = func(nftBefore *types.Nft) *types.Nft {
+		return types.EmptyNft(txInfo.NftIndex)
+	}
+	plan.oTx.FullExitNftTxInfo = &circuit.FullExitNftTx{
+		AccountIndex:           txInfo.AccountIndex,
+		AccountNameHash:        txInfo.AccountNameHash,
+		CreatorAccountIndex:    txInfo.CreatorAccountIndex,
+		CreatorAccountNameHash: txInfo.CreatorAccountNameHash,
+		CreatorTreasuryRate:    txInfo.CreatorTreasuryRate,
+		NftIndex:               txInfo.NftIndex,
+		CollectionId:           txInfo.CollectionId,
+		NftContentHash:         txInfo.NftContentHash,
+		NftL1Address:           addressString(txInfo.NftL1Address),
+		NftL1TokenId:           txInfo.NftL1TokenId,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if !equalField(txInfo.AccountNameHash, accountsBefore[0].AccountNameHash) {
+			return errors.New("invalid account name hash")
+		}
+		if bytesToInt(txInfo.CreatorAccountNameHash).Sign() != 0 &&
+			(txInfo.CreatorAccountIndex != nftBefore.CreatorAccountIndex ||
+				txInfo.CreatorTreasuryRate != nftBefore.CreatorTreasuryRate) {
+			return errors.New("invalid nft creator")
+		}
+		if txInfo.AccountIndex == nftBefore.OwnerAccountIndex &&
+			(!equalField(txInfo.NftContentHash, nftBefore.NftContentHash) ||
+				addressToInt(txInfo.NftL1Address).Cmp(nftBefore.NftL1Address) != 0 ||
+				txInfo.NftL1TokenId.Cmp(nftBefore.NftL1TokenId) != 0) {
+			return errors.New("nft info doesn't match")
+		}
+		return nil
+	}
+	return plan, nil
+}
+
+/*
+	planChangePubKey: layer 2 txs are signed by the key being replaced, priority
+	ops come from layer 1 and only need the account name hash to match
+*/
+func (s *State) planChangePubKey(txInfo *txtypes.ChangePubKeyTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	pk, err := txtypes.ParsePublicKey(txInfo.PubKey)
+	if err != nil {
+		log.Println("[planChangePubKey] invalid public key:", err)
+		return nil, err
+	}
+	plan = s.newTxPlan(types.TxTypeChangePubKey, txInfo.AccountIndex)
+	plan.pubKey = pk
+	oTxInfo := &circuit.ChangePubKeyTx{
+		AccountIndex:    txInfo.AccountIndex,
+		AccountNameHash: txInfo.AccountNameHash,
+		PubKey:          pk,
+	}
+	plan.oTx.ChangePubKeyTxInfo = oTxInfo
+	if txInfo.IsPriorityOp {
+		oTxInfo.IsPriorityOp = 1
+		plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+			if bytesToInt(accountsBefore[0].AccountNameHash).Sign() == 0 {
+				return errors.New("account doesn't exist")
+			}
+			if !equalField(txInfo.AccountNameHash, accountsBefore[0].AccountNameHash) {
+				return errors.New("invalid account name hash")
+			}
+			return nil
+		}
+		return plan, nil
+	}
+
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan.assetIds[0][0] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	oTxInfo.AccountNameHash = s.account(txInfo.AccountIndex).AccountNameHash
+	oTxInfo.GasAccountIndex = txInfo.GasAccountIndex
+	oTxInfo.GasFeeAssetId = txInfo.GasFeeAssetId
+	oTxInfo.GasFeeAssetAmount = packedFee
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if fee.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planCreatePair: priority op, the pair is written to an empty leaf and the
+	account slots are left to the treasury account
+*/
+func (s *State) planCreatePair(txInfo *txtypes.CreatePairTxInfo) (plan *txPlan, err error) {
+	if txInfo.AssetAId == txInfo.AssetBId || txInfo.LpAssetId == txInfo.AssetAId || txInfo.LpAssetId == txInfo.AssetBId {
+		log.Println("[planCreatePair] pair assets should be different")
+		return nil, errors.New("[planCreatePair] pair assets should be different")
+	}
+	for _, assetId := range []int64{txInfo.AssetAId, txInfo.AssetBId, txInfo.LpAssetId} {
+		if assetId < 0 || assetId > s.Config.LastAccountAssetId() {
+			log.Println("[planCreatePair] invalid asset id")
+			return nil, fmt.Errorf("[planCreatePair] invalid asset id %d", assetId)
+		}
+	}
+	if txInfo.TreasuryRate < 0 || txInfo.TreasuryRate > txInfo.FeeRate || txInfo.FeeRate > types.RateBase {
+		log.Println("[planCreatePair] invalid fee rates")
+		return nil, errors.New("[planCreatePair] invalid fee rates")
+	}
+	plan = s.newTxPlan(types.TxTypeCreatePair, txInfo.TreasuryAccountIndex)
+	plan.pairIndex = txInfo.PairIndex
+	plan.liquidityAfter = func(liquidityBefore *types.Liquidity) *types.Liquidity {
+		return &types.Liquidity{
+			PairIndex:            txInfo.PairIndex,
+			AssetAId:             txInfo.AssetAId,
+			AssetA:               big.NewInt(0),
+			AssetBId:             txInfo.AssetBId,
+			AssetB:               big.NewInt(0),
+			LpAssetId:            txInfo.LpAssetId,
+			LpAmount:             big.NewInt(0),
+			FeeRate:              txInfo.FeeRate,
+			TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
+			TreasuryRate:         txInfo.TreasuryRate,
+		}
+	}
+	plan.oTx.CreatePairTxInfo = &circuit.CreatePairTx{
+		PairIndex:            txInfo.PairIndex,
+		AssetAId:             txInfo.AssetAId,
+		AssetBId:             txInfo.AssetBId,
+		LpAssetId:            txInfo.LpAssetId,
+		FeeRate:              txInfo.FeeRate,
+		TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
+		TreasuryRate:         txInfo.TreasuryRate,
+	}
+	if !isEmptyLiquidity(s.Liquidity(txInfo.PairIndex)) {
+		log.Println("[planCreatePair] pair already exists")
+		return nil, errors.New("[planCreatePair] pair already exists")
+	}
+	return plan, nil
+}
+
+/*
+	planSwap: the amounts follow VerifySwapTx, the sender sells from the
+	first slot, the treasury account of the pair takes the second slot and
+	the fee is paid from the last one
+*/
+func (s *State) planSwap(txInfo *txtypes.SwapTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	liquidity, err := s.tradedLiquidity(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId, true)
+	if err != nil {
+		return nil, err
+	}
+	if liquidity.LpAmount.Sign() == 0 {
+		log.Println("[planSwap] pair has no liquidity")
+		return nil, errors.New("[planSwap] pair has no liquidity")
+	}
+	isAToB := txInfo.AssetAId == liquidity.AssetAId
+	reserveIn, reserveOut := liquidity.AssetA, liquidity.AssetB
+	if !isAToB {
+		reserveIn, reserveOut = liquidity.AssetB, liquidity.AssetA
+	}
+	rateBase := big.NewInt(types.RateBase)
+	amountIn := txInfo.AssetAAmount
+	treasuryAmount := new(big.Int).Mul(amountIn, big.NewInt(liquidity.TreasuryRate))
+	treasuryAmount.Quo(treasuryAmount, rateBase)
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(types.RateBase-liquidity.FeeRate))
+	amountOut := new(big.Int).Mul(amountInWithFee, reserveOut)
+	amountOut.Quo(amountOut, new(big.Int).Add(new(big.Int).Mul(reserveIn, rateBase), amountInWithFee))
+	if amountOut.Cmp(txInfo.AssetBMinAmount) < 0 {
+		log.Println("[planSwap] amount out is less than the min amount")
+		return nil, fmt.Errorf("[planSwap] amount out %s is less than the min amount", amountOut.String())
+	}
+	reserveInAfter := new(big.Int).Sub(new(big.Int).Add(reserveIn, amountIn), treasuryAmount)
+	if reserveInAfter.BitLen() > types.LiquidityAmountBitsSize {
+		log.Println("[planSwap] pair reserve overflows")
+		return nil, errors.New("[planSwap] pair reserve overflows")
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeSwap, txInfo.FromAccountIndex)
+	plan.accountIndexes[1] = liquidity.TreasuryAccountIndex
+	plan.setAssetIds(0, txInfo.AssetAId, txInfo.AssetBId)
+	plan.assetIds[1][0] = txInfo.AssetAId
+	plan.assetIds[3][1] = txInfo.GasFeeAssetId
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(amountIn))
+	plan.assetDeltas[0][1] = balanceDelta(amountOut)
+	plan.assetDeltas[1][0] = balanceDelta(treasuryAmount)
+	plan.assetDeltas[3][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.pairIndex = txInfo.PairIndex
+	plan.liquidityAfter = func(liquidityBefore *types.Liquidity) *types.Liquidity {
+		liquidityAfter := copyLiquidity(liquidityBefore)
+		if isAToB {
+			liquidityAfter.AssetA = reserveInAfter
+			liquidityAfter.AssetB = new(big.Int).Sub(liquidityBefore.AssetB, amountOut)
+		} else {
+			liquidityAfter.AssetB = reserveInAfter
+			liquidityAfter.AssetA = new(big.Int).Sub(liquidityBefore.AssetA, amountOut)
+		}
+		return liquidityAfter
+	}
+	plan.oTx.SwapTxInfo = &circuit.SwapTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		PairIndex:         txInfo.PairIndex,
+		AssetAId:          txInfo.AssetAId,
+		AssetAAmount:      amountIn,
+		AssetBId:          txInfo.AssetBId,
+		AssetBMinAmount:   txInfo.AssetBMinAmount,
+		AssetBAmountDelta: amountOut,
+		TreasuryAmount:    treasuryAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if amountIn.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient balance")
+		}
+		if fee.Cmp(accountsBefore[3].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planAddLiquidity: the first deposit of a pair mints sqrt(a * b) lp shares and
+	locks MinimumLiquidity of them in the pair, later ones mint the smallest share
+	of the reserves the amounts stand for
+*/
+func (s *State) planAddLiquidity(txInfo *txtypes.AddLiquidityTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	liquidity, err := s.tradedLiquidity(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId, false)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		lpAmount     *big.Int
+		lockedAmount = big.NewInt(0)
+	)
+	if liquidity.LpAmount.Sign() == 0 {
+		lockedAmount.SetInt64(types.MinimumLiquidity)
+		lpAmount = new(big.Int).Sqrt(new(big.Int).Mul(txInfo.AssetAAmount, txInfo.AssetBAmount))
+		lpAmount.Sub(lpAmount, lockedAmount)
+	} else {
+		lpAmount = new(big.Int).Mul(txInfo.AssetAAmount, liquidity.LpAmount)
+		lpAmount.Quo(lpAmount, liquidity.AssetA)
+		lpAmountB := new(big.Int).Mul(txInfo.AssetBAmount, liquidity.LpAmount)
+		lpAmountB.Quo(lpAmountB, liquidity.AssetB)
+		if lpAmountB.Cmp(lpAmount) < 0 {
+			lpAmount = lpAmountB
+		}
+	}
+	if lpAmount.Sign() <= 0 || lpAmount.Cmp(txInfo.LpMinAmount) < 0 {
+		log.Println("[planAddLiquidity] lp amount is less than the min amount")
+		return nil, fmt.Errorf("[planAddLiquidity] lp amount %s is less than the min amount", lpAmount.String())
+	}
+	assetAAfter := new(big.Int).Add(liquidity.AssetA, txInfo.AssetAAmount)
+	assetBAfter := new(big.Int).Add(liquidity.AssetB, txInfo.AssetBAmount)
+	lpAmountAfter := new(big.Int).Add(liquidity.LpAmount, lpAmount)
+	lpAmountAfter.Add(lpAmountAfter, lockedAmount)
+	for _, amount := range []*big.Int{assetAAfter, assetBAfter, lpAmountAfter} {
+		if amount.BitLen() > types.LiquidityAmountBitsSize {
+			log.Println("[planAddLiquidity] pair reserve overflows")
+			return nil, errors.New("[planAddLiquidity] pair reserve overflows")
+		}
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeAddLiquidity, txInfo.FromAccountIndex)
+	plan.setAssetIds(0, txInfo.AssetAId, txInfo.AssetBId)
+	plan.setAssetIds(3, liquidity.LpAssetId, txInfo.GasFeeAssetId)
+	plan.assetDeltas[0][0] = balanceDelta(new(big.Int).Neg(txInfo.AssetAAmount))
+	plan.assetDeltas[0][1] = balanceDelta(new(big.Int).Neg(txInfo.AssetBAmount))
+	plan.assetDeltas[3][0] = balanceDelta(lpAmount)
+	plan.assetDeltas[3][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.pairIndex = txInfo.PairIndex
+	plan.liquidityAfter = func(liquidityBefore *types.Liquidity) *types.Liquidity {
+		liquidityAfter := copyLiquidity(liquidityBefore)
+		liquidityAfter.AssetA = assetAAfter
+		liquidityAfter.AssetB = assetBAfter
+		liquidityAfter.LpAmount = lpAmountAfter
+		return liquidityAfter
+	}
+	plan.oTx.AddLiquidityTxInfo = &circuit.AddLiquidityTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		PairIndex:         txInfo.PairIndex,
+		AssetAId:          txInfo.AssetAId,
+		AssetAAmount:      txInfo.AssetAAmount,
+		AssetBId:          txInfo.AssetBId,
+		AssetBAmount:      txInfo.AssetBAmount,
+		LpMinAmount:       txInfo.LpMinAmount,
+		LpAmount:          lpAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if txInfo.AssetAAmount.Cmp(accountsBefore[0].AssetsInfo[0].Balance) > 0 ||
+			txInfo.AssetBAmount.Cmp(accountsBefore[0].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient balance")
+		}
+		if fee.Cmp(accountsBefore[3].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	planRemoveLiquidity: the lp shares are worth their part of both reserves,
+	rounded down
+*/
+func (s *State) planRemoveLiquidity(txInfo *txtypes.RemoveLiquidityTxInfo, blockCreatedAt int64) (plan *txPlan, err error) {
+	liquidity, err := s.tradedLiquidity(txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId, false)
+	if err != nil {
+		return nil, err
+	}
+	if txInfo.LpAmount.Cmp(liquidity.LpAmount) > 0 {
+		log.Println("[planRemoveLiquidity] lp amount is larger than the lp shares of the pair")
+		return nil, errors.New("[planRemoveLiquidity] lp amount is larger than the lp shares of the pair")
+	}
+	assetAAmount := new(big.Int).Mul(txInfo.LpAmount, liquidity.AssetA)
+	assetAAmount.Quo(assetAAmount, liquidity.LpAmount)
+	assetBAmount := new(big.Int).Mul(txInfo.LpAmount, liquidity.AssetB)
+	assetBAmount.Quo(assetBAmount, liquidity.LpAmount)
+	if assetAAmount.Cmp(txInfo.AssetAMinAmount) < 0 || assetBAmount.Cmp(txInfo.AssetBMinAmount) < 0 {
+		log.Println("[planRemoveLiquidity] amount is less than the min amount")
+		return nil, errors.New("[planRemoveLiquidity] amount is less than the min amount")
+	}
+	packedFee, err := txtypes.ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	fee := unpackAmount(packedFee)
+	plan = s.newTxPlan(types.TxTypeRemoveLiquidity, txInfo.FromAccountIndex)
+	plan.setAssetIds(0, txInfo.AssetAId, txInfo.AssetBId)
+	plan.setAssetIds(3, liquidity.LpAssetId, txInfo.GasFeeAssetId)
+	plan.assetDeltas[0][0] = balanceDelta(assetAAmount)
+	plan.assetDeltas[0][1] = balanceDelta(assetBAmount)
+	plan.assetDeltas[3][0] = balanceDelta(new(big.Int).Neg(txInfo.LpAmount))
+	plan.assetDeltas[3][1] = balanceDelta(new(big.Int).Neg(fee))
+	plan.setGas(txInfo.GasFeeAssetId, fee)
+	plan.pairIndex = txInfo.PairIndex
+	plan.liquidityAfter = func(liquidityBefore *types.Liquidity) *types.Liquidity {
+		liquidityAfter := copyLiquidity(liquidityBefore)
+		liquidityAfter.AssetA.Sub(liquidityAfter.AssetA, assetAAmount)
+		liquidityAfter.AssetB.Sub(liquidityAfter.AssetB, assetBAmount)
+		liquidityAfter.LpAmount.Sub(liquidityAfter.LpAmount, txInfo.LpAmount)
+		return liquidityAfter
+	}
+	plan.oTx.RemoveLiquidityTxInfo = &circuit.RemoveLiquidityTx{
+		FromAccountIndex:  txInfo.FromAccountIndex,
+		PairIndex:         txInfo.PairIndex,
+		AssetAId:          txInfo.AssetAId,
+		AssetAMinAmount:   txInfo.AssetAMinAmount,
+		AssetAAmountDelta: assetAAmount,
+		AssetBId:          txInfo.AssetBId,
+		AssetBMinAmount:   txInfo.AssetBMinAmount,
+		AssetBAmountDelta: assetBAmount,
+		LpAmount:          txInfo.LpAmount,
+		GasAccountIndex:   txInfo.GasAccountIndex,
+		GasFeeAssetId:     txInfo.GasFeeAssetId,
+		GasFeeAssetAmount: packedFee,
+	}
+	plan.verify = func(accountsBefore []*types.Account, nftBefore *types.Nft) error {
+		if txInfo.LpAmount.Cmp(accountsBefore[3].AssetsInfo[0].Balance) > 0 {
+			return errors.New("insufficient lp balance")
+		}
+		if fee.Cmp(accountsBefore[3].AssetsInfo[1].Balance) > 0 {
+			return errors.New("insufficient gas fee balance")
+		}
+		return nil
+	}
+	return plan, s.signedBy(plan, txInfo, blockCreatedAt)
+}
+
+/*
+	tradedLiquidity: pair of the tx, swaps may trade it in both directions
+*/
+func (s *State) tradedLiquidity(pairIndex int64, assetAId int64, assetBId int64, eitherDirection bool) (liquidity *types.Liquidity, err error) {
+	if pairIndex < 0 || pairIndex > s.Config.LastPairIndex() {
+		log.Println("[tradedLiquidity] invalid pair index")
+		return nil, fmt.Errorf("[tradedLiquidity] invalid pair index %d", pairIndex)
+	}
+	liquidity = s.Liquidity(pairIndex)
+	if liquidity.AssetAId == liquidity.AssetBId {
+		log.Println("[tradedLiquidity] pair doesn't exist")
+		return nil, fmt.Errorf("[tradedLiquidity] pair %d doesn't exist", pairIndex)
+	}
+	isAToB := assetAId == liquidity.AssetAId && assetBId == liquidity.AssetBId
+	isBToA := assetAId == liquidity.AssetBId && assetBId == liquidity.AssetAId
+	if !isAToB && !(eitherDirection && isBToA) {
+		log.Println("[tradedLiquidity] assets don't match the pair")
+		return nil, fmt.Errorf("[tradedLiquidity] assets don't match pair %d", pairIndex)
+	}
+	return liquidity, nil
+}
+
+/*
+	signedBy: checks shared by layer 2 txs, the tx should not be expired, the
+	nonce should be the one of the account and the signature should come
+	from the account key
+*/
+func (s *State) signedBy(plan *txPlan, txInfo txtypes.TxInfo, blockCreatedAt int64) error {
+	if blockCreatedAt > txInfo.GetExpiredAt() {
+		log.Println("[signedBy] tx expired")
+		return errors.New("[signedBy] tx expired")
+	}
+	acc := s.account(plan.accountIndexes[0])
+	if txInfo.GetNonce() != acc.Nonce {
+		log.Println("[signedBy] invalid nonce")
+		return fmt.Errorf("[signedBy] invalid nonce %d, expected %d", txInfo.GetNonce(), acc.Nonce)
+	}
+	sig, chainId, err := txSignature(txInfo)
+	if err != nil {
+		return err
+	}
+	if chainId != s.ChainId {
+		log.Println("[signedBy] tx is signed for another chain")
+		return fmt.Errorf("[signedBy] tx is signed for chain %d, expected %d", chainId, s.ChainId)
+	}
+	// the signed message is hashed from the circuit tx, as VerifyTransaction does
+	plan.oTx.Nonce = txInfo.GetNonce()
+	plan.oTx.ExpiredAt = txInfo.GetExpiredAt()
+	msgHash, err := circuit.ComputeTxHash(plan.oTx, chainId)
+	if err != nil {
+		log.Println("[signedBy] unable to compute tx hash:", err)
+		return err
+	}
+	isValid, err := acc.AccountPk.Verify(sig, toFieldBytes(msgHash), mimc.NewMiMC())
+	if err != nil || !isValid {
+		log.Println("[signedBy] invalid signature")
+		return errors.New("[signedBy] invalid signature")
+	}
+	plan.isLayer2 = true
+	plan.oTx.Signature = new(eddsa.Signature)
+	if _, err = plan.oTx.Signature.SetBytes(sig); err != nil {
+		log.Println("[signedBy] invalid signature:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	txSignature: signature of a layer 2 tx and the chain it is signed for
+*/
+func txSignature(txInfo txtypes.TxInfo) (sig []byte, chainId int64, err error) {
+	switch info := txInfo.(type) {
+	case *txtypes.TransferTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.WithdrawTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.CreateCollectionTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.MintNftTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.TransferNftTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.AtomicMatchTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.CancelOfferTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.WithdrawNftTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.ChangePubKeyTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.BatchTransferTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.SwapTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.AddLiquidityTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.RemoveLiquidityTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.BurnNftTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.UpdateCollectionTxInfo:
+		return info.Sig, info.ChainId, nil
+	case *txtypes.TransferCollectionTxInfo:
+		return info.Sig, info.ChainId, nil
+	default:
+		log.Println("[txSignature] tx is not signed")
+		return nil, 0, errors.New("[txSignature] tx is not signed")
+	}
+}
+
+/*
+	offerTx: offers submitted by their own account don't need to be signed
+*/
+func offerTx(offer *txtypes.OfferTxInfo, submitterAccountIndex int64) (oOffer *types.OfferTx, err error) {
+	packedAmount, err := txtypes.ToPackedAmount(offer.AssetAmount)
+	if err != nil {
+		return nil, err
+	}
+	oOffer = &types.OfferTx{
+		Type:         offer.Type,
+		OfferId:      offer.OfferId,
+		AccountIndex: offer.AccountIndex,
+		NftIndex:     offer.NftIndex,
+		AssetId:      offer.AssetId,
+		AssetAmount:  packedAmount,
+		ListedAt:     offer.ListedAt,
+		ExpiredAt:    offer.ExpiredAt,
+		TreasuryRate: offer.TreasuryRate,
+		Sig:          types.EmptySignature(),
+	}
+	if offer.AccountIndex == submitterAccountIndex && len(offer.Sig) == 0 {
+		return oOffer, nil
+	}
+	oOffer.Sig = new(eddsa.Signature)
+	if _, err = oOffer.Sig.SetBytes(offer.Sig); err != nil {
+		log.Println("[offerTx] invalid offer signature:", err)
+		return nil, err
+	}
+	return oOffer, nil
+}
+
+/*
+	verifyOfferSig: offers are signed for the chain of the state, their hash is computed from the circuit offer
+*/
+func (s *State) verifyOfferSig(offer *types.OfferTx, sig []byte, submitterAccountIndex int64, pk *eddsa.PublicKey) error {
+	if offer.AccountIndex == submitterAccountIndex {
+		return nil
+	}
+	msgHash, err := circuit.ComputeOfferHash(offer, s.ChainId)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(sig, toFieldBytes(msgHash), mimc.NewMiMC())
+	if err != nil || !isValid {
+		return errors.New("invalid offer signature")
+	}
+	return nil
+}
+
+/*
+	rateOf: amount * rate / RateBase, the division should be exact
+*/
+func rateOf(amount *big.Int, rate int64) (*big.Int, error) {
+	res := new(big.Int).Mul(amount, big.NewInt(rate))
+	res, rem := new(big.Int).QuoRem(res, big.NewInt(types.RateBase), new(big.Int))
+	if rem.Sign() != 0 {
+		log.Println("[rateOf] amount can't be split exactly by rate")
+		return nil, errors.New("[rateOf] amount can't be split exactly by rate")
+	}
+	return res, nil
+}
+
+/*
+	unpackAmount: same as UnpackAmount/UnpackFee, 5 bits for the exponent and
+	the remaining bits for the mantissa
+*/
+func unpackAmount(packed int64) *big.Int {
+	mantissa := big.NewInt(packed >> 5)
+	exponent := big.NewInt(packed & 31)
+	return mantissa.Mul(mantissa, new(big.Int).Exp(big.NewInt(10), exponent, nil))
+}
+
+func addressToInt(address string) *big.Int {
+	return new(big.Int).SetBytes(common.FromHex(address))
+}
+
+/*
+	addressString: string variables of the witness are parsed as numbers,
+	addresses are therefore written in hex with their prefix
+*/
+func addressString(address string) string {
+	return "0x" + addressToInt(address).Text(16)
+}
+
+func equalField(a, b []byte) bool {
+	return bytes.Equal(toFieldBytes(bytesToInt(a)), toFieldBytes(bytesToInt(b)))
+}
+
+func (s *State) isEmptyAccount(acc *types.Account) bool {
+	return bytesToInt(acc.AccountNameHash).Sign() == 0 &&
+		acc.AccountPk.A.X.IsZero() && acc.AccountPk.A.Y.IsZero() &&
+		acc.Nonce == 0 && acc.CollectionNonce == 0 &&
+		bytesToInt(acc.AssetRoot).Cmp(s.emptyAssetRoot) == 0
+}
+
+func isEmptyNft(nft *types.Nft) bool {
+	return bytesToInt(nft.NftContentHash).Sign() == 0 &&
+		nft.CreatorAccountIndex == 0 && nft.OwnerAccountIndex == 0 &&
+		nft.NftL1Address.Sign() == 0 && nft.NftL1TokenId.Sign() == 0 &&
+		nft.CreatorTreasuryRate == 0 && nft.CollectionId == 0
+}
+
+func isEmptyLiquidity(liquidity *types.Liquidity) bool {
+	return liquidity.AssetAId == 0 && liquidity.AssetA.Sign() == 0 &&
+		liquidity.AssetBId == 0 && liquidity.AssetB.Sign() == 0 &&
+		liquidity.LpAssetId == 0 && liquidity.LpAmount.Sign() == 0 &&
+		liquidity.FeeRate == 0 && liquidity.TreasuryAccountIndex == 0 && liquidity.TreasuryRate == 0
+}
+
+func isEmptyCollection(collection *types.Collection) bool {
+	return bytesToInt(collection.MetadataHash).Sign() == 0 &&
+		collection.OwnerAccountIndex == 0 && collection.CreatorTreasuryRate == 0
+}
@@ -0,0 +1,552 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/merkleTree"
+)
+
+/*
+	State: account, asset, liquidity, nft and collection trees of the layer 2, kept in the same
+	shape as the circuit so that every applied tx can be turned into a witness
+*/
+type State struct {
+	// chain the txs are signed for
+	ChainId         int64
+	GasAccountIndex int64
+	GasAssetIds     []int64
+	// hash of the trees and of their leaves
+	HashType types.HashType
+	// depth of the trees
+	Config types.CircuitConfig
+
+	accountTree    *merkleTree.Tree
+	liquidityTree  *merkleTree.Tree
+	nftTree        *merkleTree.Tree
+	collectionTree *merkleTree.Tree
+	assetTrees     map[int64]*merkleTree.Tree
+
+	accounts    map[int64]*account
+	assets      map[int64]map[int64]*types.AccountAsset
+	liquidities map[int64]*types.Liquidity
+	nfts        map[int64]*types.Nft
+	collections map[int64]*types.Collection
+
+	newHash func() hash.Hash
+	// root of an empty asset tree
+	emptyAssetRoot *big.Int
+	// nil leaves of the trees, the hash of an empty account / asset / pair / nft / collection
+	nilAccountHash    []byte
+	nilAssetHash      []byte
+	nilLiquidityHash  []byte
+	nilNftHash        []byte
+	nilCollectionHash []byte
+
+	// undo log of the tx being applied
+	journal []func() error
+}
+
+/*
+	account: account leaf without its assets, the asset root lives in the asset tree
+*/
+type account struct {
+	AccountNameHash []byte
+	AccountPk       *eddsa.PublicKey
+	Nonce           int64
+	// no longer incremented, it keeps the value hashed into the leaf before collection ids were global
+	CollectionNonce int64
+}
+
+func emptyAccount() *account {
+	return &account{
+		AccountNameHash: []byte{},
+		AccountPk:       types.EmptyPubKey(),
+	}
+}
+
+func NewState(chainId int64, gasAccountIndex int64, gasAssetIds []int64) (s *State, err error) {
+	return NewStateWithHash(chainId, gasAccountIndex, gasAssetIds, types.MiMCHashType)
+}
+
+/*
+	NewStateWithHash: state whose trees use the given hash, blocks built from it
+	are proven by a BlockConstraints with the same HashType
+*/
+func NewStateWithHash(chainId int64, gasAccountIndex int64, gasAssetIds []int64, hashType types.HashType) (s *State, err error) {
+	return NewStateWithConfig(chainId, gasAccountIndex, gasAssetIds, hashType, types.MainnetConfig)
+}
+
+/*
+	NewStateWithConfig: state whose trees have the depth of the given config,
+	blocks built from it are proven by a BlockConstraints with the same Config
+*/
+func NewStateWithConfig(chainId int64, gasAccountIndex int64, gasAssetIds []int64, hashType types.HashType, config types.CircuitConfig) (s *State, err error) {
+	if chainId < 0 || chainId >= 1<<types.ChainIdBits {
+		log.Println("[NewState] invalid chain id")
+		return nil, errors.New("[NewState] invalid chain id")
+	}
+	if len(gasAssetIds) == 0 {
+		log.Println("[NewState] gas asset ids should not be empty")
+		return nil, errors.New("[NewState] gas asset ids should not be empty")
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	if gasAccountIndex < 0 || gasAccountIndex > config.LastAccountIndex() {
+		log.Println("[NewState] invalid gas account index")
+		return nil, errors.New("[NewState] invalid gas account index")
+	}
+	for _, gasAssetId := range gasAssetIds {
+		if gasAssetId < 0 || gasAssetId > config.LastAccountAssetId() {
+			log.Println("[NewState] invalid gas asset id")
+			return nil, errors.New("[NewState] invalid gas asset id")
+		}
+	}
+	if _, err = types.NewNativeHash(hashType); err != nil {
+		return nil, err
+	}
+	emptyAssetRoot, err := types.EmptyAssetRootOf(hashType, config.AssetMerkleLevels)
+	if err != nil {
+		return nil, err
+	}
+	s = &State{
+		ChainId:         chainId,
+		GasAccountIndex: gasAccountIndex,
+		GasAssetIds:     gasAssetIds,
+		HashType:        hashType,
+		Config:          config,
+		newHash: func() hash.Hash {
+			hFunc, _ := types.NewNativeHash(hashType)
+			return hFunc
+		},
+		emptyAssetRoot: emptyAssetRoot,
+		assetTrees:     make(map[int64]*merkleTree.Tree),
+		accounts:       make(map[int64]*account),
+		assets:         make(map[int64]map[int64]*types.AccountAsset),
+		liquidities:    make(map[int64]*types.Liquidity),
+		nfts:           make(map[int64]*types.Nft),
+		collections:    make(map[int64]*types.Collection),
+	}
+	s.nilAssetHash = s.assetLeafHash(types.EmptyAccountAsset(0))
+	s.nilLiquidityHash = s.liquidityLeafHash(types.EmptyLiquidity(0))
+	s.nilNftHash = s.nftLeafHash(types.EmptyNft(0))
+	s.nilCollectionHash = s.collectionLeafHash(types.EmptyCollection(0))
+	s.nilAccountHash = s.accountLeafHash(emptyAccount(), emptyAssetRoot.FillBytes(make([]byte, 32)))
+	s.accountTree, err = merkleTree.NewEmptyTree(s.Config.AccountMerkleLevels, s.nilAccountHash, s.newHash())
+	if err != nil {
+		log.Println("[NewState] unable to create account tree:", err)
+		return nil, err
+	}
+	s.liquidityTree, err = merkleTree.NewEmptyTree(s.Config.LiquidityMerkleLevels, s.nilLiquidityHash, s.newHash())
+	if err != nil {
+		log.Println("[NewState] unable to create liquidity tree:", err)
+		return nil, err
+	}
+	s.nftTree, err = merkleTree.NewEmptyTree(s.Config.NftMerkleLevels, s.nilNftHash, s.newHash())
+	if err != nil {
+		log.Println("[NewState] unable to create nft tree:", err)
+		return nil, err
+	}
+	s.collectionTree, err = merkleTree.NewEmptyTree(s.Config.CollectionMerkleLevels, s.nilCollectionHash, s.newHash())
+	if err != nil {
+		log.Println("[NewState] unable to create collection tree:", err)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *State) AccountRoot() []byte {
+	return s.accountTree.RootNode.Value
+}
+
+func (s *State) LiquidityRoot() []byte {
+	return s.liquidityTree.RootNode.Value
+}
+
+func (s *State) NftRoot() []byte {
+	return s.nftTree.RootNode.Value
+}
+
+func (s *State) CollectionRoot() []byte {
+	return s.collectionTree.RootNode.Value
+}
+
+/*
+	StateRoot: hash(accountRoot, liquidityRoot, nftRoot, collectionRoot)
+*/
+func (s *State) StateRoot() []byte {
+	return s.stateRoot(s.AccountRoot(), s.LiquidityRoot(), s.NftRoot(), s.CollectionRoot())
+}
+
+/*
+	Account: current account info, AssetsInfo is filled with the requested assets
+*/
+func (s *State) Account(accountIndex int64, assetIds ...int64) (*types.Account, error) {
+	if len(assetIds) > s.Config.NbAccountAssetsPerAccount {
+		log.Println("[Account] too many asset ids")
+		return nil, errors.New("[Account] too many asset ids")
+	}
+	assetTree, err := s.assetTree(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	acc := s.account(accountIndex)
+	res := &types.Account{
+		AccountIndex:    accountIndex,
+		AccountNameHash: acc.AccountNameHash,
+		AccountPk:       acc.AccountPk,
+		Nonce:           acc.Nonce,
+		CollectionNonce: acc.CollectionNonce,
+		AssetRoot:       assetTree.RootNode.Value,
+		AssetsInfo:      make([]*types.AccountAsset, s.Config.NbAccountAssetsPerAccount),
+	}
+	for i := 0; i < s.Config.NbAccountAssetsPerAccount; i++ {
+		res.AssetsInfo[i] = types.EmptyAccountAsset(0)
+		if i < len(assetIds) {
+			res.AssetsInfo[i] = s.Asset(accountIndex, assetIds[i])
+		}
+	}
+	return res, nil
+}
+
+func (s *State) Asset(accountIndex int64, assetId int64) *types.AccountAsset {
+	asset := s.assets[accountIndex][assetId]
+	if asset == nil {
+		return types.EmptyAccountAsset(assetId)
+	}
+	return copyAsset(asset)
+}
+
+func (s *State) Liquidity(pairIndex int64) *types.Liquidity {
+	liquidity := s.liquidities[pairIndex]
+	if liquidity == nil {
+		return types.EmptyLiquidity(pairIndex)
+	}
+	return copyLiquidity(liquidity)
+}
+
+func (s *State) Nft(nftIndex int64) *types.Nft {
+	nft := s.nfts[nftIndex]
+	if nft == nil {
+		return types.EmptyNft(nftIndex)
+	}
+	return copyNft(nft)
+}
+
+func (s *State) Collection(collectionId int64) *types.Collection {
+	collection := s.collections[collectionId]
+	if collection == nil {
+		return types.EmptyCollection(collectionId)
+	}
+	return copyCollection(collection)
+}
+
+func (s *State) account(accountIndex int64) *account {
+	acc := s.accounts[accountIndex]
+	if acc == nil {
+		return emptyAccount()
+	}
+	cpy := *acc
+	return &cpy
+}
+
+func (s *State) assetTree(accountIndex int64) (tree *merkleTree.Tree, err error) {
+	tree = s.assetTrees[accountIndex]
+	if tree != nil {
+		return tree, nil
+	}
+	tree, err = merkleTree.NewEmptyTree(s.Config.AssetMerkleLevels, s.nilAssetHash, s.newHash())
+	if err != nil {
+		log.Println("[assetTree] unable to create asset tree:", err)
+		return nil, err
+	}
+	s.assetTrees[accountIndex] = tree
+	return tree, nil
+}
+
+/*
+	setAsset: update the asset leaf, the account leaf is updated by setAccount
+*/
+func (s *State) setAsset(accountIndex int64, asset *types.AccountAsset) (err error) {
+	tree, err := s.assetTree(accountIndex)
+	if err != nil {
+		return err
+	}
+	if err = s.updateLeaf(tree, asset.AssetId, s.assetLeafHash(asset)); err != nil {
+		log.Println("[setAsset] unable to update asset tree:", err)
+		return err
+	}
+	if s.assets[accountIndex] == nil {
+		s.assets[accountIndex] = make(map[int64]*types.AccountAsset)
+	}
+	old := s.assets[accountIndex][asset.AssetId]
+	s.assets[accountIndex][asset.AssetId] = copyAsset(asset)
+	s.journal = append(s.journal, func() error {
+		s.assets[accountIndex][asset.AssetId] = old
+		return nil
+	})
+	return nil
+}
+
+/*
+	setAccount: update the account leaf with the current root of its asset tree
+*/
+func (s *State) setAccount(accountIndex int64, acc *account) (err error) {
+	tree, err := s.assetTree(accountIndex)
+	if err != nil {
+		return err
+	}
+	if err = s.updateLeaf(s.accountTree, accountIndex, s.accountLeafHash(acc, tree.RootNode.Value)); err != nil {
+		log.Println("[setAccount] unable to update account tree:", err)
+		return err
+	}
+	old := s.accounts[accountIndex]
+	cpy := *acc
+	s.accounts[accountIndex] = &cpy
+	s.journal = append(s.journal, func() error {
+		s.accounts[accountIndex] = old
+		return nil
+	})
+	return nil
+}
+
+func (s *State) setLiquidity(liquidity *types.Liquidity) (err error) {
+	if err = s.updateLeaf(s.liquidityTree, liquidity.PairIndex, s.liquidityLeafHash(liquidity)); err != nil {
+		log.Println("[setLiquidity] unable to update liquidity tree:", err)
+		return err
+	}
+	old := s.liquidities[liquidity.PairIndex]
+	s.liquidities[liquidity.PairIndex] = copyLiquidity(liquidity)
+	s.journal = append(s.journal, func() error {
+		s.liquidities[liquidity.PairIndex] = old
+		return nil
+	})
+	return nil
+}
+
+func (s *State) setNft(nft *types.Nft) (err error) {
+	if err = s.updateLeaf(s.nftTree, nft.NftIndex, s.nftLeafHash(nft)); err != nil {
+		log.Println("[setNft] unable to update nft tree:", err)
+		return err
+	}
+	old := s.nfts[nft.NftIndex]
+	s.nfts[nft.NftIndex] = copyNft(nft)
+	s.journal = append(s.journal, func() error {
+		s.nfts[nft.NftIndex] = old
+		return nil
+	})
+	return nil
+}
+
+func (s *State) setCollection(collection *types.Collection) (err error) {
+	if err = s.updateLeaf(s.collectionTree, collection.CollectionId, s.collectionLeafHash(collection)); err != nil {
+		log.Println("[setCollection] unable to update collection tree:", err)
+		return err
+	}
+	old := s.collections[collection.CollectionId]
+	s.collections[collection.CollectionId] = copyCollection(collection)
+	s.journal = append(s.journal, func() error {
+		s.collections[collection.CollectionId] = old
+		return nil
+	})
+	return nil
+}
+
+/*
+	updateLeaf: update a leaf and record how to restore it, leaves are
+	restored as they were since an account leaf depends on its asset tree
+*/
+func (s *State) updateLeaf(tree *merkleTree.Tree, index int64, leaf []byte) error {
+	old := tree.NilHashValueConst[0]
+	if node, exist := tree.Leaves[index]; exist {
+		old = node.Value
+	}
+	if err := tree.Update(index, leaf); err != nil {
+		return err
+	}
+	s.journal = append(s.journal, func() error {
+		return tree.Update(index, old)
+	})
+	return nil
+}
+
+/*
+	revert: undo every update recorded since the journal was reset
+*/
+func (s *State) revert() error {
+	journal := s.journal
+	s.journal = nil
+	for i := len(journal) - 1; i >= 0; i-- {
+		if err := journal[i](); err != nil {
+			return err
+		}
+	}
+	s.journal = nil
+	return nil
+}
+
+/*
+	merkleProof: build the proof of a leaf, proofs are checked against the root
+	so that a broken tree never ends up in a witness.
+*/
+func (s *State) merkleProof(tree *merkleTree.Tree, index int64, leaf []byte, levels int) (proof [][]byte, err error) {
+	proof, _, err = tree.BuildMerkleProofs(index)
+	if err != nil {
+		log.Println("[merkleProof] unable to build merkle proofs:", err)
+		return nil, err
+	}
+	if len(proof) != levels {
+		log.Println("[merkleProof] invalid merkle proof length")
+		return nil, errors.New("[merkleProof] invalid merkle proof length")
+	}
+	if !bytes.Equal(s.computeRoot(leaf, proof, index), tree.RootNode.Value) {
+		log.Println("[merkleProof] merkle proof doesn't match the tree root")
+		return nil, fmt.Errorf("[merkleProof] merkle proof of leaf %d doesn't match the tree root", index)
+	}
+	return proof, nil
+}
+
+/*
+	computeRoot: same as types.UpdateMerkleProof, the helper bits are the bits of the index
+*/
+func (s *State) computeRoot(leaf []byte, proof [][]byte, index int64) []byte {
+	node := leaf
+	for i := 0; i < len(proof); i++ {
+		if (index>>uint(i))&1 == 1 {
+			node = s.hashElements(new(big.Int).SetBytes(proof[i]), new(big.Int).SetBytes(node))
+		} else {
+			node = s.hashElements(new(big.Int).SetBytes(node), new(big.Int).SetBytes(proof[i]))
+		}
+	}
+	return node
+}
+
+/*
+	hashElements: hash of field elements, each element is written as 32 bytes
+*/
+func (s *State) hashElements(elements ...*big.Int) []byte {
+	return toFieldBytes(circuit.HashElements(s.newHash(), elements...))
+}
+
+func toFieldBytes(a *big.Int) []byte {
+	return new(big.Int).Mod(a, fr.Modulus()).FillBytes(make([]byte, 32))
+}
+
+func bytesToInt(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+func (s *State) stateRoot(accountRoot, liquidityRoot, nftRoot, collectionRoot []byte) []byte {
+	return s.hashElements(bytesToInt(accountRoot), bytesToInt(liquidityRoot), bytesToInt(nftRoot), bytesToInt(collectionRoot))
+}
+
+func (s *State) assetLeafHash(asset *types.AccountAsset) []byte {
+	return toFieldBytes(circuit.ComputeAssetLeafHash(s.newHash(), asset.Balance, asset.OfferCanceledOrFinalized))
+}
+
+func (s *State) accountLeafHash(acc *account, assetRoot []byte) []byte {
+	pkX := acc.AccountPk.A.X.Bytes()
+	pkY := acc.AccountPk.A.Y.Bytes()
+	return toFieldBytes(circuit.ComputeAccountLeafHash(
+		s.newHash(),
+		bytesToInt(acc.AccountNameHash),
+		bytesToInt(pkX[:]),
+		bytesToInt(pkY[:]),
+		big.NewInt(acc.Nonce),
+		big.NewInt(acc.CollectionNonce),
+		bytesToInt(assetRoot),
+	))
+}
+
+func (s *State) liquidityLeafHash(liquidity *types.Liquidity) []byte {
+	return toFieldBytes(circuit.ComputeLiquidityLeafHash(
+		s.newHash(),
+		big.NewInt(liquidity.AssetAId),
+		liquidity.AssetA,
+		big.NewInt(liquidity.AssetBId),
+		liquidity.AssetB,
+		big.NewInt(liquidity.LpAssetId),
+		liquidity.LpAmount,
+		big.NewInt(liquidity.FeeRate),
+		big.NewInt(liquidity.TreasuryAccountIndex),
+		big.NewInt(liquidity.TreasuryRate),
+	))
+}
+
+func (s *State) nftLeafHash(nft *types.Nft) []byte {
+	return toFieldBytes(circuit.ComputeNftLeafHash(
+		s.newHash(),
+		big.NewInt(nft.CreatorAccountIndex),
+		big.NewInt(nft.OwnerAccountIndex),
+		bytesToInt(nft.NftContentHash),
+		nft.NftL1Address,
+		nft.NftL1TokenId,
+		big.NewInt(nft.CreatorTreasuryRate),
+		big.NewInt(nft.CollectionId),
+	))
+}
+
+func (s *State) collectionLeafHash(collection *types.Collection) []byte {
+	return toFieldBytes(circuit.ComputeCollectionLeafHash(
+		s.newHash(),
+		big.NewInt(collection.OwnerAccountIndex),
+		bytesToInt(collection.MetadataHash),
+		big.NewInt(collection.CreatorTreasuryRate),
+	))
+}
+
+func copyAsset(asset *types.AccountAsset) *types.AccountAsset {
+	return &types.AccountAsset{
+		AssetId:                  asset.AssetId,
+		Balance:                  new(big.Int).Set(asset.Balance),
+		OfferCanceledOrFinalized: new(big.Int).Set(asset.OfferCanceledOrFinalized),
+	}
+}
+
+func copyNft(nft *types.Nft) *types.Nft {
+	cpy := *nft
+	cpy.NftL1Address = new(big.Int).Set(nft.NftL1Address)
+	cpy.NftL1TokenId = new(big.Int).Set(nft.NftL1TokenId)
+	return &cpy
+}
+
+func copyCollection(collection *types.Collection) *types.Collection {
+	cpy := *collection
+	cpy.MetadataHash = append([]byte{}, collection.MetadataHash...)
+	return &cpy
+}
+
+func copyLiquidity(liquidity *types.Liquidity) *types.Liquidity {
+	cpy := *liquidity
+	cpy.AssetA = new(big.Int).Set(liquidity.AssetA)
+	cpy.AssetB = new(big.Int).Set(liquidity.AssetB)
+	cpy.LpAmount = new(big.Int).Set(liquidity.LpAmount)
+	return &cpy
+}
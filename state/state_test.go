@@ -0,0 +1,1064 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/debugger"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbnb-crypto/prover"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+// block time and chain used by TxConstraints
+const (
+	testBlockCreatedAt = 1633400952228
+	testChainId        = 1
+)
+
+type testAccount struct {
+	index    int64
+	nameHash []byte
+	sk       *txtypes.PrivateKey
+}
+
+func newTestAccount(t *testing.T, index int64, name string) *testAccount {
+	sk, err := curve.GenerateEddsaPrivateKey(name + " seed for the state tests")
+	require.NoError(t, err)
+	hFunc := mimc.NewMiMC()
+	hFunc.Write(txtypes.PaddingStringToBytes32(name))
+	return &testAccount{index: index, nameHash: hFunc.Sum(nil), sk: sk}
+}
+
+func (acc *testAccount) register() *txtypes.RegisterZnsTxInfo {
+	return &txtypes.RegisterZnsTxInfo{
+		TxType:          txtypes.TxTypeRegisterZns,
+		AccountIndex:    acc.index,
+		AccountName:     "test",
+		AccountNameHash: acc.nameHash,
+		PubKey:          hex.EncodeToString(acc.sk.PublicKey.Bytes()),
+	}
+}
+
+func (acc *testAccount) transfer(t *testing.T, to *testAccount, amount int64, fee int64, nonce int64) *txtypes.TransferTxInfo {
+	hFunc := mimc.NewMiMC()
+	txInfo := &txtypes.TransferTxInfo{
+		FromAccountIndex:  acc.index,
+		ToAccountIndex:    to.index,
+		ToAccountNameHash: hex.EncodeToString(to.nameHash),
+		AssetId:           0,
+		AssetAmount:       big.NewInt(amount),
+		GasAccountIndex:   1,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(fee),
+		CallDataHash:      hFunc.Sum(nil),
+		ExpiredAt:         testBlockCreatedAt + 3600000,
+		Nonce:             nonce,
+		ChainId:           testChainId,
+	}
+	msgHash, err := txInfo.Hash(hFunc)
+	require.NoError(t, err)
+	txInfo.Sig, err = acc.sk.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+	return txInfo
+}
+
+func assertTxSolved(t *testing.T, s *State, oTx *circuit.Tx) {
+	txConstraints := circuit.GetZeroTxConstraint(s.Config)
+	txConstraints.ChainId = testChainId
+	txConstraints.BlockCreatedAt = testBlockCreatedAt
+	witness, err := circuit.SetTxWitness(oTx, s.Config)
+	require.NoError(t, err)
+	assert.NoError(t, test.IsSolved(&txConstraints, &witness, ecc.BN254, backend.GROTH16))
+	// the native checker agrees with the circuit
+	checker, err := debugger.NewChecker(s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	require.NoError(t, err)
+	assert.NoError(t, checker.CheckTx(oTx, testChainId, testBlockCreatedAt))
+}
+
+func assertBlockSolved(t *testing.T, s *State, oBlock *circuit.Block) {
+	txsCount := len(oBlock.Txs)
+	blockConstraints := prover.NewBlockConstraints(txsCount, s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	witness, err := circuit.SetBlockWitness(oBlock, s.Config)
+	require.NoError(t, err)
+	witness.TxsCount = txsCount
+	witness.GasAssetIds = s.GasAssetIds
+	witness.GasAccountIndex = s.GasAccountIndex
+	assert.NoError(t, test.IsSolved(&blockConstraints, &witness, ecc.BN254, backend.GROTH16))
+	checker, err := debugger.NewChecker(s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	require.NoError(t, err)
+	assert.NoError(t, checker.CheckBlock(oBlock))
+}
+
+func TestTxConfig(t *testing.T) {
+	assert.Equal(t, txtypes.MainnetConfig, TxConfig(types.MainnetConfig))
+	// the recipients of a batch transfer are the account slots after the sender
+	assert.Equal(t, types.NbBatchTransferRecipients, txtypes.MainnetConfig.MaxBatchTransferRecipients)
+	assert.Equal(t, types.NbBatchTransferRecipients, TxConfig(types.TestConfig).MaxBatchTransferRecipients)
+
+	// a test network rejects indexes beyond its trees
+	transfer := &txtypes.TransferTxInfo{FromAccountIndex: 1, ToAccountIndex: 256}
+	assert.EqualError(t, transfer.ValidateWithConfig(TxConfig(types.TestConfig)), "ToAccountIndex should not be larger than 255")
+	// lp shares are the upper half of the asset ids
+	createPair := &txtypes.CreatePairTxInfo{PairIndex: 3, LpAssetId: 131}
+	assert.NoError(t, createPair.ValidateWithConfig(TxConfig(types.TestConfig)))
+	createPair.LpAssetId = 3
+	assert.EqualError(t, createPair.ValidateWithConfig(TxConfig(types.TestConfig)), "LpAssetId should be 131")
+}
+
+func TestEmptyState(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	acc, err := s.Account(5)
+	require.NoError(t, err)
+	assert.True(t, s.isEmptyAccount(acc))
+	assert.Equal(t, 0, bytesToInt(acc.AssetRoot).Cmp(types.EmptyAssetRoot))
+	assert.True(t, isEmptyNft(s.Nft(5)))
+}
+
+func TestApplyTx(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 5)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		alice.transfer(t, bob, 1000, 10, 0),
+	}
+	for i, txInfo := range txInfos {
+		stateRoot := s.StateRoot()
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assert.Equal(t, stateRoot, oTx.StateRootBefore)
+		assert.Equal(t, s.StateRoot(), oTx.StateRootAfter)
+		assertTxSolved(t, s, oTx)
+	}
+	assert.Equal(t, int64(98990), s.Asset(alice.index, 0).Balance.Int64())
+	assert.Equal(t, int64(1000), s.Asset(bob.index, 0).Balance.Int64())
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), s.Asset(gas.index, 0).Balance.Int64())
+	assert.Equal(t, s.StateRoot(), oBlock.NewStateRoot)
+	assert.Len(t, oBlock.Txs, 5)
+	assert.Equal(t, oBlock.OldStateRoot, oBlock.Txs[0].StateRootBefore)
+	for i := 1; i < len(oBlock.Txs); i++ {
+		assert.Equal(t, oBlock.Txs[i-1].StateRootAfter, oBlock.Txs[i].StateRootBefore)
+	}
+	assertBlockSolved(t, s, oBlock)
+}
+
+func TestGasWithPaddedBlock(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+	for _, txInfo := range []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+	} {
+		_, err = b.AddTx(txInfo)
+		require.NoError(t, err)
+	}
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+
+	// a transfer followed by an empty tx still credits the gas account
+	b, err = s.NewBlock(2, testBlockCreatedAt, 2)
+	require.NoError(t, err)
+	_, err = b.AddTx(alice.transfer(t, bob, 1000, 10, 0))
+	require.NoError(t, err)
+	oBlock, err = b.Seal()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(types.TxTypeEmptyTx), oBlock.Txs[1].TxType)
+	assert.Equal(t, int64(98990), s.Asset(alice.index, 0).Balance.Int64())
+	assert.Equal(t, int64(10), s.Asset(gas.index, 0).Balance.Int64())
+	assert.Equal(t, s.StateRoot(), oBlock.NewStateRoot)
+	assertBlockSolved(t, s, oBlock)
+}
+
+func TestPoseidonState(t *testing.T) {
+	s, err := NewStateWithHash(testChainId, 1, []int64{0}, types.PoseidonHashType)
+	require.NoError(t, err)
+	acc, err := s.Account(5)
+	require.NoError(t, err)
+	assert.True(t, s.isEmptyAccount(acc))
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		alice.transfer(t, gas, 1000, 10, 0),
+	}
+	for i, txInfo := range txInfos {
+		_, err = b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+	}
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+
+	// the mimc circuit rejects the poseidon state
+	blockConstraints := prover.NewBlockConstraints(len(oBlock.Txs), s.GasAssetIds, s.GasAccountIndex, types.MiMCHashType, s.Config)
+	witness, err := circuit.SetBlockWitness(oBlock, s.Config)
+	require.NoError(t, err)
+	witness.TxsCount = len(oBlock.Txs)
+	witness.GasAssetIds = s.GasAssetIds
+	witness.GasAccountIndex = s.GasAccountIndex
+	assert.Error(t, test.IsSolved(&blockConstraints, &witness, ecc.BN254, backend.GROTH16))
+
+	_, err = NewStateWithHash(testChainId, 1, []int64{0}, types.HashType(2))
+	assert.Error(t, err)
+}
+
+func TestTestConfigState(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	acc, err := s.Account(5)
+	require.NoError(t, err)
+	assert.True(t, s.isEmptyAccount(acc))
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		alice.transfer(t, gas, 1000, 10, 0),
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// the trees of the test config only have 256 leaves
+	_, _, err = s.ApplyTx(newTestAccount(t, 256, "bob").register(), testBlockCreatedAt)
+	assert.Error(t, err)
+	// txs are validated against the trees of the config, not the mainnet ones
+	_, _, err = s.ApplyTx(alice.transfer(t, newTestAccount(t, 256, "bob"), 1000, 10, 1), testBlockCreatedAt)
+	assert.EqualError(t, err, "ToAccountIndex should not be larger than 255")
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+
+	_, err = NewStateWithConfig(testChainId, 1, []int64{256}, types.MiMCHashType, types.TestConfig)
+	assert.Error(t, err)
+	_, err = NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.CircuitConfig{AccountMerkleLevels: 33, AssetMerkleLevels: 8, NftMerkleLevels: 8})
+	assert.Error(t, err)
+}
+
+func TestWideLayoutState(t *testing.T) {
+	config := types.TestConfig
+	config.NbAccountsPerTx++
+	config.NbAccountAssetsPerAccount++
+	config.NbGasAssetsPerTx++
+	config.PubDataSizePerTx += 2
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, config)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		alice.transfer(t, gas, 1000, 10, 0),
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assert.Len(t, oTx.AccountsInfoBefore, config.NbAccountsPerTx)
+		assertTxSolved(t, s, oTx)
+	}
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+
+	// a circuit with the default layout doesn't take the witness
+	_, err = circuit.SetBlockWitness(oBlock, types.TestConfig)
+	assert.Error(t, err)
+}
+
+func TestApplyInvalidTx(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	for _, txInfo := range []txtypes.TxInfo{alice.register(), bob.register()} {
+		_, _, err = s.ApplyTx(txInfo, testBlockCreatedAt)
+		require.NoError(t, err)
+	}
+	stateRoot := s.StateRoot()
+
+	// already registered
+	_, _, err = s.ApplyTx(alice.register(), testBlockCreatedAt)
+	assert.Error(t, err)
+	// insufficient balance, rejected after the slots are updated
+	_, _, err = s.ApplyTx(alice.transfer(t, bob, 1000, 10, 0), testBlockCreatedAt)
+	assert.Error(t, err)
+	// invalid nonce
+	_, _, err = s.ApplyTx(alice.transfer(t, bob, 1000, 10, 1), testBlockCreatedAt)
+	assert.Error(t, err)
+	// signed by another account
+	txInfo := bob.transfer(t, bob, 1000, 10, 0)
+	txInfo.FromAccountIndex = alice.index
+	_, _, err = s.ApplyTx(txInfo, testBlockCreatedAt)
+	assert.Error(t, err)
+
+	assert.Equal(t, stateRoot, s.StateRoot())
+	acc, err := s.Account(alice.index)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), acc.Nonce)
+}
+
+func TestChainId(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 4)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+	}
+	for i, txInfo := range txInfos {
+		_, err = b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+	}
+	// signed for another chain
+	txInfo := alice.transfer(t, gas, 1000, 10, 0)
+	txInfo.ChainId = 56
+	txInfo.Sig = signTx(t, alice, txInfo)
+	_, err = b.AddTx(txInfo)
+	assert.Error(t, err)
+
+	_, err = b.AddTx(alice.transfer(t, gas, 1000, 10, 0))
+	require.NoError(t, err)
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assert.Equal(t, int64(testChainId), oBlock.ChainId)
+	assertBlockSolved(t, s, oBlock)
+
+	// the signatures don't match the chain committed by the block
+	oBlock.ChainId = 56
+	oBlock.BlockCommitment, err = circuit.ComputeBlockCommitment(oBlock, s.Config)
+	require.NoError(t, err)
+	blockConstraints := prover.NewBlockConstraints(len(oBlock.Txs), s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	witness, err := circuit.SetBlockWitness(oBlock, s.Config)
+	require.NoError(t, err)
+	witness.TxsCount = len(oBlock.Txs)
+	witness.GasAssetIds = s.GasAssetIds
+	witness.GasAccountIndex = s.GasAccountIndex
+	assert.Error(t, test.IsSolved(&blockConstraints, &witness, ecc.BN254, backend.GROTH16))
+
+	_, err = NewStateWithConfig(1<<32, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	assert.Error(t, err)
+}
+
+func signTx(t *testing.T, acc *testAccount, txInfo txtypes.TxInfo) []byte {
+	msgHash, err := txInfo.Hash(mimc.NewMiMC())
+	require.NoError(t, err)
+	sig, err := acc.sk.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+	return sig
+}
+
+func TestApplyNftTxs(t *testing.T) {
+	s, err := NewState(testChainId, 1, []int64{0})
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	expiredAt := int64(testBlockCreatedAt + 3600000)
+	contentHash := alice.nameHash
+	l1Address := "0x5cc7d8A2F1d6d4B3AE1bB4E5ea2Dd6AfEcc2A5d8"
+	fee := big.NewInt(10)
+
+	createCollection := &txtypes.CreateCollectionTxInfo{
+		AccountIndex: alice.index, CollectionId: 1, Name: "collection",
+		MetadataHash: hex.EncodeToString(alice.nameHash), CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 0, ChainId: testChainId,
+	}
+	createCollection.Sig = signTx(t, alice, createCollection)
+	mintNft := &txtypes.MintNftTxInfo{
+		CreatorAccountIndex: alice.index, ToAccountIndex: alice.index,
+		ToAccountNameHash: hex.EncodeToString(alice.nameHash),
+		NftIndex:          0, NftContentHash: hex.EncodeToString(contentHash),
+		NftCollectionId: 1, CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 1, ChainId: testChainId,
+	}
+	mintNft.Sig = signTx(t, alice, mintNft)
+	buyOffer := &txtypes.OfferTxInfo{
+		Type: txtypes.BuyOfferType, OfferId: 0, AccountIndex: bob.index, NftIndex: 0,
+		AssetId: 0, AssetAmount: big.NewInt(10000), ListedAt: testBlockCreatedAt,
+		ExpiredAt: expiredAt, TreasuryRate: 200, ChainId: testChainId,
+	}
+	buyOffer.Sig = signTx(t, bob, buyOffer)
+	sellOffer := &txtypes.OfferTxInfo{
+		Type: txtypes.SellOfferType, OfferId: 0, AccountIndex: alice.index, NftIndex: 0,
+		AssetId: 0, AssetAmount: big.NewInt(10000), ListedAt: testBlockCreatedAt,
+		ExpiredAt: expiredAt, TreasuryRate: 200, ChainId: testChainId,
+	}
+	sellOffer.Sig = signTx(t, alice, sellOffer)
+	atomicMatch := &txtypes.AtomicMatchTxInfo{
+		AccountIndex: alice.index, BuyOffer: buyOffer, SellOffer: sellOffer,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		CreatorAmount: big.NewInt(100), TreasuryAmount: big.NewInt(200),
+		ExpiredAt: expiredAt, Nonce: 2, ChainId: testChainId,
+	}
+	atomicMatch.Sig = signTx(t, alice, atomicMatch)
+	transferNft := &txtypes.TransferNftTxInfo{
+		FromAccountIndex: bob.index, ToAccountIndex: alice.index,
+		ToAccountNameHash: hex.EncodeToString(alice.nameHash), NftIndex: 0,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		CallDataHash: mimc.NewMiMC().Sum(nil), ExpiredAt: expiredAt, Nonce: 0, ChainId: testChainId,
+	}
+	transferNft.Sig = signTx(t, bob, transferNft)
+	cancelOffer := &txtypes.CancelOfferTxInfo{
+		AccountIndex: alice.index, OfferId: 1,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 3, ChainId: testChainId,
+	}
+	cancelOffer.Sig = signTx(t, alice, cancelOffer)
+	withdrawNft := &txtypes.WithdrawNftTxInfo{
+		AccountIndex: alice.index, CreatorAccountIndex: alice.index,
+		CreatorAccountNameHash: alice.nameHash, CreatorTreasuryRate: 100,
+		NftIndex: 0, NftContentHash: contentHash, NftL1Address: "0", NftL1TokenId: big.NewInt(0),
+		CollectionId: 1, ToAddress: l1Address,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 4, ChainId: testChainId,
+	}
+	withdrawNft.Sig = signTx(t, alice, withdrawNft)
+	withdraw := &txtypes.WithdrawTxInfo{
+		FromAccountIndex: alice.index, AssetId: 0, AssetAmount: big.NewInt(100),
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ToAddress: l1Address, ExpiredAt: expiredAt, Nonce: 5, ChainId: testChainId,
+	}
+	withdraw.Sig = signTx(t, alice, withdraw)
+
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: alice.index, AccountNameHash: alice.nameHash, AssetId: 0, AssetAmount: big.NewInt(100000)},
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: bob.index, AccountNameHash: bob.nameHash, AssetId: 0, AssetAmount: big.NewInt(100000)},
+		createCollection,
+		mintNft,
+		atomicMatch,
+		transferNft,
+		cancelOffer,
+		withdrawNft,
+		withdraw,
+		&txtypes.DepositNftTxInfo{
+			TxType: txtypes.TxTypeDepositNft, AccountIndex: bob.index, AccountNameHash: bob.nameHash,
+			NftIndex: 1, NftContentHash: contentHash, NftL1Address: l1Address, NftL1TokenId: big.NewInt(7),
+			CreatorAccountIndex: alice.index, CreatorTreasuryRate: 100, CollectionId: 0,
+		},
+		&txtypes.FullExitNftTxInfo{
+			TxType: txtypes.TxTypeFullExitNft, AccountIndex: bob.index, AccountNameHash: bob.nameHash,
+			NftIndex: 1, NftContentHash: contentHash, NftL1Address: l1Address, NftL1TokenId: big.NewInt(7),
+			CreatorAccountIndex: alice.index, CreatorAccountNameHash: alice.nameHash, CreatorTreasuryRate: 100,
+		},
+		&txtypes.FullExitTxInfo{TxType: txtypes.TxTypeFullExit, AccountIndex: bob.index, AccountNameHash: bob.nameHash, AssetId: 0, AssetAmount: big.NewInt(89990)},
+	}
+	gasDelta := big.NewInt(0)
+	for i, txInfo := range txInfos {
+		oTx, gasDeltas, err := s.ApplyTx(txInfo, testBlockCreatedAt)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+		for _, delta := range gasDeltas {
+			gasDelta.Add(gasDelta, delta.BalanceDelta)
+		}
+	}
+	// 7 fees and the treasury amount of the match
+	assert.Equal(t, int64(270), gasDelta.Int64())
+	// 100000 - 6 fees - 100 withdrawn + 9700 from the sale + 100 as creator
+	assert.Equal(t, int64(109640), s.Asset(alice.index, 0).Balance.Int64())
+	assert.Equal(t, int64(0), s.Asset(bob.index, 0).Balance.Int64())
+	assert.True(t, isEmptyNft(s.Nft(0)))
+	assert.True(t, isEmptyNft(s.Nft(1)))
+	assert.Equal(t, int64(1), s.Asset(alice.index, 0).OfferCanceledOrFinalized.Int64()&1)
+	assert.Equal(t, int64(3), s.Asset(alice.index, 0).OfferCanceledOrFinalized.Int64())
+	assert.Equal(t, int64(1), s.Asset(bob.index, 0).OfferCanceledOrFinalized.Int64())
+}
+
+func TestChangePubKey(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	// same account with the keys it rotates to
+	aliceL2 := &testAccount{index: alice.index, nameHash: alice.nameHash, sk: newTestAccount(t, 2, "alice layer 2").sk}
+	aliceL1 := &testAccount{index: alice.index, nameHash: alice.nameHash, sk: newTestAccount(t, 2, "alice layer 1").sk}
+
+	changePubKey := &txtypes.ChangePubKeyTxInfo{
+		AccountIndex:      alice.index,
+		PubKey:            hex.EncodeToString(aliceL2.sk.PublicKey.Bytes()),
+		GasAccountIndex:   1,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(10),
+		ExpiredAt:         testBlockCreatedAt + 3600000,
+		Nonce:             0,
+		ChainId:           testChainId,
+	}
+	changePubKey.Sig = signTx(t, alice, changePubKey)
+	priorityOp := &txtypes.ChangePubKeyTxInfo{
+		AccountIndex:    alice.index,
+		AccountNameHash: alice.nameHash,
+		PubKey:          hex.EncodeToString(aliceL1.sk.PublicKey.Bytes()),
+		IsPriorityOp:    true,
+	}
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 6)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		changePubKey,
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// the old key can't sign anymore
+	_, err = b.AddTx(alice.transfer(t, gas, 1000, 10, 1))
+	assert.Error(t, err)
+	// priority ops are checked against the account name hash
+	invalidPriorityOp := *priorityOp
+	invalidPriorityOp.AccountNameHash = gas.nameHash
+	_, err = b.AddTx(&invalidPriorityOp)
+	assert.Error(t, err)
+
+	oTx, err := b.AddTx(priorityOp)
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	assert.True(t, circuit.IsOnChainOp(oTx))
+	_, err = b.AddTx(aliceL2.transfer(t, gas, 1000, 10, 1))
+	assert.Error(t, err)
+	oTx, err = b.AddTx(aliceL1.transfer(t, gas, 1000, 10, 1))
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+	acc, err := s.Account(alice.index)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), acc.Nonce)
+	assert.True(t, acc.AccountPk.Equal(&aliceL1.sk.PublicKey))
+	// 2 fees and the transfer
+	assert.Equal(t, int64(1020), s.Asset(gas.index, 0).Balance.Int64())
+}
+
+func TestBatchTransfer(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	carol := newTestAccount(t, 4, "carol")
+
+	batchTransfer := func(nonce int64, recipients ...*txtypes.BatchTransferRecipient) *txtypes.BatchTransferTxInfo {
+		txInfo := &txtypes.BatchTransferTxInfo{
+			FromAccountIndex:  alice.index,
+			Recipients:        recipients,
+			AssetId:           0,
+			GasAccountIndex:   1,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: big.NewInt(10),
+			CallDataHash:      mimc.NewMiMC().Sum(nil),
+			ExpiredAt:         testBlockCreatedAt + 3600000,
+			Nonce:             nonce,
+			ChainId:           testChainId,
+		}
+		txInfo.Sig = signTx(t, alice, txInfo)
+		return txInfo
+	}
+	recipient := func(to *testAccount, amount int64) *txtypes.BatchTransferRecipient {
+		return &txtypes.BatchTransferRecipient{
+			ToAccountIndex:    to.index,
+			ToAccountNameHash: hex.EncodeToString(to.nameHash),
+			AssetAmount:       big.NewInt(amount),
+		}
+	}
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 7)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		carol.register(),
+		&txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		},
+		batchTransfer(0, recipient(bob, 1000), recipient(carol, 2000), recipient(gas, 3000)),
+		// the unused slots are filled with the sender
+		batchTransfer(1, recipient(carol, 500)),
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// recipients are checked against their name hash
+	invalidRecipient := recipient(bob, 1000)
+	invalidRecipient.ToAccountNameHash = hex.EncodeToString(carol.nameHash)
+	_, err = b.AddTx(batchTransfer(2, invalidRecipient))
+	assert.Error(t, err)
+	// the total amount should be covered by the balance
+	_, err = b.AddTx(batchTransfer(2, recipient(bob, 50000), recipient(carol, 50000)))
+	assert.Error(t, err)
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+	assert.Equal(t, int64(93480), s.Asset(alice.index, 0).Balance.Int64())
+	assert.Equal(t, int64(1000), s.Asset(bob.index, 0).Balance.Int64())
+	assert.Equal(t, int64(2500), s.Asset(carol.index, 0).Balance.Int64())
+	// the transfer and 2 fees
+	assert.Equal(t, int64(3020), s.Asset(gas.index, 0).Balance.Int64())
+}
+
+func TestLiquidity(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+
+	deposit := func(assetId int64, amount int64) *txtypes.DepositTxInfo {
+		return &txtypes.DepositTxInfo{
+			TxType:          txtypes.TxTypeDeposit,
+			AccountIndex:    alice.index,
+			AccountNameHash: alice.nameHash,
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(amount),
+		}
+	}
+	createPair := &txtypes.CreatePairTxInfo{
+		TxType:               txtypes.TxTypeCreatePair,
+		PairIndex:            0,
+		AssetAId:             1,
+		AssetBId:             2,
+		LpAssetId:            types.TestConfig.FirstLpAssetId(),
+		FeeRate:              30,
+		TreasuryAccountIndex: bob.index,
+		TreasuryRate:         10,
+	}
+	addLiquidity := func(nonce int64, pairIndex int64, assetAAmount, assetBAmount int64) *txtypes.AddLiquidityTxInfo {
+		txInfo := &txtypes.AddLiquidityTxInfo{
+			FromAccountIndex:  alice.index,
+			PairIndex:         pairIndex,
+			AssetAId:          1,
+			AssetAAmount:      big.NewInt(assetAAmount),
+			AssetBId:          2,
+			AssetBAmount:      big.NewInt(assetBAmount),
+			LpMinAmount:       big.NewInt(0),
+			GasAccountIndex:   1,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: big.NewInt(10),
+			ExpiredAt:         testBlockCreatedAt + 3600000,
+			Nonce:             nonce,
+			ChainId:           testChainId,
+		}
+		txInfo.Sig = signTx(t, alice, txInfo)
+		return txInfo
+	}
+	swap := func(nonce int64, assetAId, assetBId int64, amount int64, minAmount int64) *txtypes.SwapTxInfo {
+		txInfo := &txtypes.SwapTxInfo{
+			FromAccountIndex:  alice.index,
+			PairIndex:         0,
+			AssetAId:          assetAId,
+			AssetAAmount:      big.NewInt(amount),
+			AssetBId:          assetBId,
+			AssetBMinAmount:   big.NewInt(minAmount),
+			GasAccountIndex:   1,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: big.NewInt(10),
+			ExpiredAt:         testBlockCreatedAt + 3600000,
+			Nonce:             nonce,
+			ChainId:           testChainId,
+		}
+		txInfo.Sig = signTx(t, alice, txInfo)
+		return txInfo
+	}
+	removeLiquidity := &txtypes.RemoveLiquidityTxInfo{
+		FromAccountIndex:  alice.index,
+		PairIndex:         0,
+		AssetAId:          1,
+		AssetAMinAmount:   big.NewInt(10000),
+		AssetBId:          2,
+		AssetBMinAmount:   big.NewInt(40000),
+		LpAmount:          big.NewInt(20000),
+		GasAccountIndex:   1,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(10),
+		ExpiredAt:         testBlockCreatedAt + 3600000,
+		Nonce:             4,
+		ChainId:           testChainId,
+	}
+	removeLiquidity.Sig = signTx(t, alice, removeLiquidity)
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 13)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		deposit(0, 1000),
+		deposit(1, 1000000),
+		deposit(2, 4000000),
+		createPair,
+		// the first deposit mints sqrt(a * b) lp shares, MinimumLiquidity of them are locked
+		addLiquidity(0, 0, 100000, 400000),
+		// the smallest share is minted, the whole amounts are deposited
+		addLiquidity(1, 0, 10000, 50000),
+		swap(2, 1, 2, 1000, 4000),
+		// pairs are traded in both directions
+		swap(3, 2, 1, 2000, 0),
+		removeLiquidity,
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// a pair is created once
+	_, err = b.AddTx(createPair)
+	assert.Error(t, err)
+	// the lp asset id is derived from the pair index
+	otherPair := *createPair
+	otherPair.PairIndex = 1
+	_, err = b.AddTx(&otherPair)
+	assert.Error(t, err)
+	// lp shares can't be deposited
+	_, err = b.AddTx(deposit(types.TestConfig.FirstLpAssetId(), 1000))
+	assert.Error(t, err)
+	// the first deposit of a pair should mint more than MinimumLiquidity lp shares
+	otherPair.LpAssetId = types.TestConfig.FirstLpAssetId() + 1
+	oTx, err := b.AddTx(&otherPair)
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	_, err = b.AddTx(addLiquidity(5, 1, 1000, 1000))
+	assert.Error(t, err)
+	// the amount out should cover the min amount
+	_, err = b.AddTx(swap(5, 1, 2, 1000, 5000))
+	assert.Error(t, err)
+	// the assets should be the ones of the pair
+	_, err = b.AddTx(swap(5, 1, 3, 1000, 0))
+	assert.Error(t, err)
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+	liquidity := s.Liquidity(0)
+	assert.Equal(t, int64(100460), liquidity.AssetA.Int64())
+	assert.Equal(t, int64(407233), liquidity.AssetB.Int64())
+	assert.Equal(t, int64(200000), liquidity.LpAmount.Int64())
+	assert.Equal(t, int64(899539), s.Asset(alice.index, 1).Balance.Int64())
+	assert.Equal(t, int64(3592765), s.Asset(alice.index, 2).Balance.Int64())
+	assert.Equal(t, int64(200000-types.MinimumLiquidity), s.Asset(alice.index, liquidity.LpAssetId).Balance.Int64())
+	// the treasury part of the swap fees
+	assert.Equal(t, int64(1), s.Asset(bob.index, 1).Balance.Int64())
+	assert.Equal(t, int64(2), s.Asset(bob.index, 2).Balance.Int64())
+	assert.Equal(t, int64(50), s.Asset(gas.index, 0).Balance.Int64())
+}
+
+func TestBurnNft(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	expiredAt := int64(testBlockCreatedAt + 3600000)
+	fee := big.NewInt(10)
+
+	createCollection := &txtypes.CreateCollectionTxInfo{
+		AccountIndex: alice.index, CollectionId: 1, Name: "collection",
+		MetadataHash: hex.EncodeToString(alice.nameHash), CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 0, ChainId: testChainId,
+	}
+	createCollection.Sig = signTx(t, alice, createCollection)
+	mintNft := &txtypes.MintNftTxInfo{
+		CreatorAccountIndex: alice.index, ToAccountIndex: alice.index,
+		ToAccountNameHash: hex.EncodeToString(alice.nameHash),
+		NftIndex:          0, NftContentHash: hex.EncodeToString(alice.nameHash),
+		NftCollectionId: 1, CreatorTreasuryRate: 100,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 1, ChainId: testChainId,
+	}
+	mintNft.Sig = signTx(t, alice, mintNft)
+	burnNft := func(acc *testAccount, nftIndex int64, nonce int64) *txtypes.BurnNftTxInfo {
+		txInfo := &txtypes.BurnNftTxInfo{
+			AccountIndex: acc.index, NftIndex: nftIndex,
+			GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+			ExpiredAt: expiredAt, Nonce: nonce, ChainId: testChainId,
+		}
+		txInfo.Sig = signTx(t, acc, txInfo)
+		return txInfo
+	}
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 10)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: alice.index, AccountNameHash: alice.nameHash, AssetId: 0, AssetAmount: big.NewInt(1000)},
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: bob.index, AccountNameHash: bob.nameHash, AssetId: 0, AssetAmount: big.NewInt(1000)},
+		createCollection,
+		mintNft,
+		&txtypes.DepositNftTxInfo{
+			TxType: txtypes.TxTypeDepositNft, AccountIndex: bob.index, AccountNameHash: bob.nameHash,
+			NftIndex: 1, NftContentHash: bob.nameHash, NftL1Address: "0x5cc7d8A2F1d6d4B3AE1bB4E5ea2Dd6AfEcc2A5d8", NftL1TokenId: big.NewInt(7),
+			CreatorAccountIndex: alice.index, CreatorTreasuryRate: 100, CollectionId: 0,
+		},
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// only the owner can burn the nft
+	_, err = b.AddTx(burnNft(bob, 0, 0))
+	assert.Error(t, err)
+	// an nft deposited from layer 1 is withdrawn, not burnt
+	_, err = b.AddTx(burnNft(bob, 1, 0))
+	assert.Error(t, err)
+	// nor can a missing nft be burnt
+	_, err = b.AddTx(burnNft(alice, 2, 2))
+	assert.Error(t, err)
+	oTx, err := b.AddTx(burnNft(alice, 0, 2))
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	// the checker and the circuit reject the burn of a layer 1 nft
+	l1Nft := *oTx.NftBefore
+	l1Nft.NftL1Address = big.NewInt(7)
+	l1Burn := *oTx
+	l1Burn.NftBefore = &l1Nft
+	checker, err := debugger.NewChecker(s.GasAssetIds, s.GasAccountIndex, s.HashType, s.Config)
+	require.NoError(t, err)
+	var txErr *debugger.TxError
+	require.ErrorAs(t, checker.CheckTx(&l1Burn, testChainId, testBlockCreatedAt), &txErr)
+	assert.Equal(t, debugger.RuleNft, txErr.Rule)
+	assert.Error(t, checker.SolveTx(&l1Burn, testChainId, testBlockCreatedAt))
+	// the leaf is empty after the burn
+	_, err = b.AddTx(burnNft(alice, 0, 3))
+	assert.Error(t, err)
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+	assert.True(t, isEmptyNft(s.Nft(0)))
+	assert.Equal(t, int64(970), s.Asset(alice.index, 0).Balance.Int64())
+	assert.Equal(t, int64(30), s.Asset(gas.index, 0).Balance.Int64())
+}
+
+func TestCollection(t *testing.T) {
+	s, err := NewStateWithConfig(testChainId, 1, []int64{0}, types.MiMCHashType, types.TestConfig)
+	require.NoError(t, err)
+	gas := newTestAccount(t, 1, "gas")
+	alice := newTestAccount(t, 2, "alice")
+	bob := newTestAccount(t, 3, "bob")
+	expiredAt := int64(testBlockCreatedAt + 3600000)
+	fee := big.NewInt(10)
+
+	createCollection := func(acc *testAccount, collectionId int64, nonce int64) *txtypes.CreateCollectionTxInfo {
+		txInfo := &txtypes.CreateCollectionTxInfo{
+			AccountIndex: acc.index, CollectionId: collectionId, Name: "collection",
+			MetadataHash: hex.EncodeToString(acc.nameHash), CreatorTreasuryRate: 100,
+			GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+			ExpiredAt: expiredAt, Nonce: nonce, ChainId: testChainId,
+		}
+		txInfo.Sig = signTx(t, acc, txInfo)
+		return txInfo
+	}
+	updateCollection := &txtypes.UpdateCollectionTxInfo{
+		AccountIndex: alice.index, CollectionId: 5,
+		MetadataHash: hex.EncodeToString(bob.nameHash), CreatorTreasuryRate: 250,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 1, ChainId: testChainId,
+	}
+	updateCollection.Sig = signTx(t, alice, updateCollection)
+	transferCollection := &txtypes.TransferCollectionTxInfo{
+		FromAccountIndex: alice.index, ToAccountIndex: bob.index,
+		ToAccountNameHash: hex.EncodeToString(bob.nameHash), CollectionId: 5,
+		GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+		ExpiredAt: expiredAt, Nonce: 2, ChainId: testChainId,
+	}
+	transferCollection.Sig = signTx(t, alice, transferCollection)
+	mintNft := func(acc *testAccount, nftIndex int64, collectionId int64, creatorTreasuryRate int64, nonce int64) *txtypes.MintNftTxInfo {
+		txInfo := &txtypes.MintNftTxInfo{
+			CreatorAccountIndex: acc.index, ToAccountIndex: acc.index,
+			ToAccountNameHash: hex.EncodeToString(acc.nameHash),
+			NftIndex:          nftIndex, NftContentHash: hex.EncodeToString(acc.nameHash),
+			NftCollectionId: collectionId, CreatorTreasuryRate: creatorTreasuryRate,
+			GasAccountIndex: gas.index, GasFeeAssetId: 0, GasFeeAssetAmount: fee,
+			ExpiredAt: expiredAt, Nonce: nonce, ChainId: testChainId,
+		}
+		txInfo.Sig = signTx(t, acc, txInfo)
+		return txInfo
+	}
+
+	b, err := s.NewBlock(1, testBlockCreatedAt, 10)
+	require.NoError(t, err)
+	txInfos := []txtypes.TxInfo{
+		gas.register(),
+		alice.register(),
+		bob.register(),
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: alice.index, AccountNameHash: alice.nameHash, AssetId: 0, AssetAmount: big.NewInt(1000)},
+		&txtypes.DepositTxInfo{TxType: txtypes.TxTypeDeposit, AccountIndex: bob.index, AccountNameHash: bob.nameHash, AssetId: 0, AssetAmount: big.NewInt(1000)},
+		createCollection(alice, 5, 0),
+	}
+	for i, txInfo := range txInfos {
+		oTx, err := b.AddTx(txInfo)
+		require.NoError(t, err, "tx %d", i)
+		assertTxSolved(t, s, oTx)
+	}
+	// collection ids are global, bob can't take the id of alice's collection
+	_, err = b.AddTx(createCollection(bob, 5, 0))
+	assert.Error(t, err)
+	// collection 0 is no collection, it can't be created
+	_, err = b.AddTx(createCollection(bob, types.NoCollectionId, 0))
+	assert.Error(t, err)
+	// nor mint into it
+	stateRoot := s.StateRoot()
+	_, err = b.AddTx(mintNft(bob, 0, 5, 100, 0))
+	assert.EqualError(t, err, "account is not the owner of the collection")
+	assert.Equal(t, stateRoot, s.StateRoot())
+	assert.True(t, isEmptyNft(s.Nft(0)))
+	oTx, err := b.AddTx(updateCollection)
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	assert.Equal(t, bob.nameHash, s.Collection(5).MetadataHash)
+	assert.Equal(t, int64(250), s.Collection(5).CreatorTreasuryRate)
+	oTx, err = b.AddTx(transferCollection)
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	assert.Equal(t, bob.index, s.Collection(5).OwnerAccountIndex)
+	// only the new owner can mint into the collection
+	_, err = b.AddTx(mintNft(alice, 0, 5, 250, 3))
+	assert.EqualError(t, err, "account is not the owner of the collection")
+	// with the creator treasury rate of the collection
+	_, err = b.AddTx(mintNft(bob, 0, 5, 100, 0))
+	assert.EqualError(t, err, "creator treasury rate should be the rate of the collection")
+	oTx, err = b.AddTx(mintNft(bob, 0, 5, 250, 0))
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	// anyone can mint without a collection
+	oTx, err = b.AddTx(mintNft(alice, 1, types.NoCollectionId, 100, 3))
+	require.NoError(t, err)
+	assertTxSolved(t, s, oTx)
+	assert.Equal(t, int64(types.NoCollectionId), s.Nft(1).CollectionId)
+
+	oBlock, err := b.Seal()
+	require.NoError(t, err)
+	assertBlockSolved(t, s, oBlock)
+	assert.Equal(t, bob.index, s.Nft(0).OwnerAccountIndex)
+	assert.Equal(t, int64(50), s.Asset(gas.index, 0).Balance.Int64())
+}
@@ -0,0 +1,389 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbnb-crypto/circuit"
+	"github.com/bnb-chain/zkbnb-crypto/circuit/types"
+	"github.com/bnb-chain/zkbnb-crypto/wasm/txtypes"
+)
+
+/*
+	GasDelta: amount of an asset collected by the gas account
+*/
+type GasDelta struct {
+	AssetId      int64
+	BalanceDelta *big.Int
+}
+
+// asset slots left unused by a tx
+const unusedAssetId = -1
+
+/*
+	assetDelta: change of an asset slot, OfferIndex is the offer bit to set, -1 for none
+*/
+type assetDelta struct {
+	BalanceDelta *big.Int
+	OfferIndex   int64
+}
+
+/*
+	txPlan: the slots a tx touches and how they change, it follows the layout
+	VerifyTransaction expects for every tx type
+*/
+type txPlan struct {
+	oTx *circuit.Tx
+	// account slots, as many as the config has
+	accountIndexes []int64
+	assetIds       [][]int64
+	assetDeltas    [][]*assetDelta
+	// liquidity slot
+	pairIndex      int64
+	liquidityAfter func(liquidityBefore *types.Liquidity) *types.Liquidity
+	// nft slot
+	nftIndex int64
+	nftAfter func(nftBefore *types.Nft) *types.Nft
+	// collection slot
+	collectionId    int64
+	collectionAfter func(collectionBefore *types.Collection) *types.Collection
+	// changes of the first account
+	isLayer2 bool
+	register *account
+	pubKey   *eddsa.PublicKey
+	// checks on the accounts and nft before the tx
+	verify    func(accountsBefore []*types.Account, nftBefore *types.Nft) error
+	gasDeltas [types.NbGasAssetsPerTx]GasDelta
+}
+
+func (s *State) newTxPlan(txType uint8, accountIndex int64) *txPlan {
+	plan := &txPlan{
+		oTx: &circuit.Tx{
+			TxType:    txType,
+			Signature: types.EmptySignature(),
+		},
+		accountIndexes: make([]int64, s.Config.NbAccountsPerTx),
+		assetIds:       make([][]int64, s.Config.NbAccountsPerTx),
+		assetDeltas:    make([][]*assetDelta, s.Config.NbAccountsPerTx),
+	}
+	for i := 0; i < s.Config.NbAccountsPerTx; i++ {
+		plan.accountIndexes[i] = accountIndex
+		plan.assetIds[i] = make([]int64, s.Config.NbAccountAssetsPerAccount)
+		plan.assetDeltas[i] = make([]*assetDelta, s.Config.NbAccountAssetsPerAccount)
+		for j := 0; j < s.Config.NbAccountAssetsPerAccount; j++ {
+			plan.assetIds[i][j] = unusedAssetId
+		}
+	}
+	for i := 0; i < types.NbGasAssetsPerTx; i++ {
+		plan.gasDeltas[i] = GasDelta{AssetId: s.GasAssetIds[0], BalanceDelta: big.NewInt(0)}
+	}
+	return plan
+}
+
+func (plan *txPlan) setGas(gasFeeAssetId int64, gasFeeAssetAmount *big.Int) {
+	plan.gasDeltas[0] = GasDelta{AssetId: gasFeeAssetId, BalanceDelta: gasFeeAssetAmount}
+	for i := 1; i < types.NbGasAssetsPerTx; i++ {
+		plan.gasDeltas[i] = GasDelta{AssetId: gasFeeAssetId, BalanceDelta: big.NewInt(0)}
+	}
+}
+
+func (plan *txPlan) setAssetIds(accountSlot int, assetIds ...int64) {
+	copy(plan.assetIds[accountSlot], assetIds)
+}
+
+func balanceDelta(amount *big.Int) *assetDelta {
+	return &assetDelta{BalanceDelta: amount, OfferIndex: -1}
+}
+
+func offerDelta(offerId int64) *assetDelta {
+	return &assetDelta{BalanceDelta: big.NewInt(0), OfferIndex: offerId % circuit.OfferSizePerAsset}
+}
+
+/*
+	TxConfig: bounds the txs of a network with this config are validated against
+*/
+func TxConfig(c types.CircuitConfig) txtypes.Config {
+	return txtypes.Config{
+		MaxAccountIndex: c.LastAccountIndex(),
+		MaxAssetId:      c.LastAccountAssetId(),
+		MaxNftIndex:     c.LastNftIndex(),
+		MaxCollectionId: c.LastCollectionId(),
+		MaxPairIndex:    c.LastPairIndex(),
+		FirstLpAssetId:  c.FirstLpAssetId(),
+
+		MaxBatchTransferRecipients: types.NbBatchTransferRecipients,
+	}
+}
+
+/*
+	ApplyTx: apply a tx to the state and return its witness with the gas
+	collected by the tx. The state is left untouched if the tx is invalid.
+*/
+func (s *State) ApplyTx(txInfo txtypes.TxInfo, blockCreatedAt int64) (oTx *circuit.Tx, gasDeltas [types.NbGasAssetsPerTx]GasDelta, err error) {
+	if err = txInfo.ValidateWithConfig(TxConfig(s.Config)); err != nil {
+		log.Println("[ApplyTx] invalid tx:", err)
+		return nil, gasDeltas, err
+	}
+	var plan *txPlan
+	switch info := txInfo.(type) {
+	case *txtypes.RegisterZnsTxInfo:
+		plan, err = s.planRegisterZns(info)
+	case *txtypes.DepositTxInfo:
+		plan, err = s.planDeposit(info)
+	case *txtypes.DepositNftTxInfo:
+		plan, err = s.planDepositNft(info)
+	case *txtypes.TransferTxInfo:
+		plan, err = s.planTransfer(info, blockCreatedAt)
+	case *txtypes.WithdrawTxInfo:
+		plan, err = s.planWithdraw(info, blockCreatedAt)
+	case *txtypes.CreateCollectionTxInfo:
+		plan, err = s.planCreateCollection(info, blockCreatedAt)
+	case *txtypes.MintNftTxInfo:
+		plan, err = s.planMintNft(info, blockCreatedAt)
+	case *txtypes.TransferNftTxInfo:
+		plan, err = s.planTransferNft(info, blockCreatedAt)
+	case *txtypes.AtomicMatchTxInfo:
+		plan, err = s.planAtomicMatch(info, blockCreatedAt)
+	case *txtypes.CancelOfferTxInfo:
+		plan, err = s.planCancelOffer(info, blockCreatedAt)
+	case *txtypes.WithdrawNftTxInfo:
+		plan, err = s.planWithdrawNft(info, blockCreatedAt)
+	case *txtypes.FullExitTxInfo:
+		plan, err = s.planFullExit(info)
+	case *txtypes.FullExitNftTxInfo:
+		plan, err = s.planFullExitNft(info)
+	case *txtypes.ChangePubKeyTxInfo:
+		plan, err = s.planChangePubKey(info, blockCreatedAt)
+	case *txtypes.BatchTransferTxInfo:
+		plan, err = s.planBatchTransfer(info, blockCreatedAt)
+	case *txtypes.CreatePairTxInfo:
+		plan, err = s.planCreatePair(info)
+	case *txtypes.SwapTxInfo:
+		plan, err = s.planSwap(info, blockCreatedAt)
+	case *txtypes.AddLiquidityTxInfo:
+		plan, err = s.planAddLiquidity(info, blockCreatedAt)
+	case *txtypes.RemoveLiquidityTxInfo:
+		plan, err = s.planRemoveLiquidity(info, blockCreatedAt)
+	case *txtypes.BurnNftTxInfo:
+		plan, err = s.planBurnNft(info, blockCreatedAt)
+	case *txtypes.UpdateCollectionTxInfo:
+		plan, err = s.planUpdateCollection(info, blockCreatedAt)
+	case *txtypes.TransferCollectionTxInfo:
+		plan, err = s.planTransferCollection(info, blockCreatedAt)
+	default:
+		log.Println("[ApplyTx] unsupported tx type")
+		return nil, gasDeltas, errors.New("[ApplyTx] unsupported tx type")
+	}
+	if err != nil {
+		return nil, gasDeltas, err
+	}
+	// VerifyBlock requires the gas of every tx to be paid in one of the gas assets
+	if !s.isGasPaid(plan.gasDeltas) {
+		log.Println("[ApplyTx] gas should be paid in one of the gas assets")
+		return nil, gasDeltas, errors.New("[ApplyTx] gas should be paid in one of the gas assets")
+	}
+	oTx, err = s.apply(plan)
+	if err != nil {
+		return nil, gasDeltas, err
+	}
+	return oTx, plan.gasDeltas, nil
+}
+
+func (s *State) isGasPaid(gasDeltas [types.NbGasAssetsPerTx]GasDelta) bool {
+	for _, gasAssetId := range s.GasAssetIds {
+		for _, gasDelta := range gasDeltas {
+			if gasDelta.AssetId == gasAssetId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+	apply: walk the account slots, their assets, the liquidity, nft and collection slots in the order
+	of VerifyTransaction, every slot is proven against the root left by the
+	previous one so that an account or asset used twice stays consistent
+*/
+func (s *State) apply(plan *txPlan) (oTx *circuit.Tx, err error) {
+	oTx = plan.oTx
+	s.journal = nil
+	oTx.AccountRootBefore = s.AccountRoot()
+	oTx.LiquidityRootBefore = s.LiquidityRoot()
+	oTx.NftRootBefore = s.NftRoot()
+	oTx.CollectionRootBefore = s.CollectionRoot()
+	oTx.StateRootBefore = s.StateRoot()
+	err = s.applySlots(plan)
+	if err != nil {
+		if revertErr := s.revert(); revertErr != nil {
+			log.Println("[apply] unable to revert state:", revertErr)
+			return nil, revertErr
+		}
+		return nil, err
+	}
+	s.journal = nil
+	oTx.StateRootAfter = s.StateRoot()
+	return oTx, nil
+}
+
+func (s *State) applySlots(plan *txPlan) (err error) {
+	oTx := plan.oTx
+	oTx.AccountsInfoBefore = make([]*types.Account, s.Config.NbAccountsPerTx)
+	oTx.MerkleProofsAccountAssetsBefore = make([][][][]byte, s.Config.NbAccountsPerTx)
+	oTx.MerkleProofsAccountBefore = make([][][]byte, s.Config.NbAccountsPerTx)
+	for i := 0; i < s.Config.NbAccountsPerTx; i++ {
+		accountIndex := plan.accountIndexes[i]
+		if accountIndex < 0 || accountIndex > s.Config.LastAccountIndex() {
+			return fmt.Errorf("[applySlots] invalid account index %d", accountIndex)
+		}
+		accountBefore, err := s.Account(accountIndex)
+		if err != nil {
+			return err
+		}
+		acc := s.account(accountIndex)
+		proof, err := s.merkleProof(s.accountTree, accountIndex, s.accountLeafHash(acc, accountBefore.AssetRoot), s.Config.AccountMerkleLevels)
+		if err != nil {
+			return err
+		}
+		oTx.MerkleProofsAccountBefore[i] = proof
+		assetTree, err := s.assetTree(accountIndex)
+		if err != nil {
+			return err
+		}
+		oTx.MerkleProofsAccountAssetsBefore[i] = make([][][]byte, s.Config.NbAccountAssetsPerAccount)
+		for j := 0; j < s.Config.NbAccountAssetsPerAccount; j++ {
+			assetId := plan.assetIds[i][j]
+			if assetId == unusedAssetId {
+				assetId = s.unusedAssetId(accountIndex, i, j)
+			}
+			if assetId < 0 || assetId > s.Config.LastAccountAssetId() {
+				return fmt.Errorf("[applySlots] invalid asset id %d", assetId)
+			}
+			asset := s.Asset(accountIndex, assetId)
+			accountBefore.AssetsInfo[j] = copyAsset(asset)
+			proof, err = s.merkleProof(assetTree, assetId, s.assetLeafHash(asset), s.Config.AssetMerkleLevels)
+			if err != nil {
+				return err
+			}
+			oTx.MerkleProofsAccountAssetsBefore[i][j] = proof
+			if delta := plan.assetDeltas[i][j]; delta != nil {
+				asset.Balance.Add(asset.Balance, delta.BalanceDelta)
+				if delta.OfferIndex >= 0 {
+					asset.OfferCanceledOrFinalized.SetBit(asset.OfferCanceledOrFinalized, int(delta.OfferIndex), 1)
+				}
+			}
+			if err = s.setAsset(accountIndex, asset); err != nil {
+				return err
+			}
+		}
+		if i == 0 {
+			if plan.register != nil {
+				acc.AccountNameHash = plan.register.AccountNameHash
+				acc.AccountPk = plan.register.AccountPk
+			}
+			if plan.pubKey != nil {
+				acc.AccountPk = plan.pubKey
+			}
+			if plan.isLayer2 {
+				acc.Nonce++
+			}
+		}
+		if err = s.setAccount(accountIndex, acc); err != nil {
+			return err
+		}
+		oTx.AccountsInfoBefore[i] = accountBefore
+	}
+	if plan.pairIndex < 0 || plan.pairIndex > s.Config.LastPairIndex() {
+		return fmt.Errorf("[applySlots] invalid pair index %d", plan.pairIndex)
+	}
+	liquidityBefore := s.Liquidity(plan.pairIndex)
+	proof, err := s.merkleProof(s.liquidityTree, plan.pairIndex, s.liquidityLeafHash(liquidityBefore), s.Config.LiquidityMerkleLevels)
+	if err != nil {
+		return err
+	}
+	oTx.MerkleProofsLiquidityBefore = proof
+	if plan.liquidityAfter != nil {
+		if err = s.setLiquidity(plan.liquidityAfter(liquidityBefore)); err != nil {
+			return err
+		}
+	}
+	oTx.LiquidityBefore = liquidityBefore
+
+	if plan.nftIndex < 0 || plan.nftIndex > s.Config.LastNftIndex() {
+		return fmt.Errorf("[applySlots] invalid nft index %d", plan.nftIndex)
+	}
+	nftBefore := s.Nft(plan.nftIndex)
+	proof, err = s.merkleProof(s.nftTree, plan.nftIndex, s.nftLeafHash(nftBefore), s.Config.NftMerkleLevels)
+	if err != nil {
+		return err
+	}
+	oTx.MerkleProofsNftBefore = proof
+	if plan.nftAfter != nil {
+		if err = s.setNft(plan.nftAfter(nftBefore)); err != nil {
+			return err
+		}
+	}
+	oTx.NftBefore = nftBefore
+
+	if plan.collectionId < 0 || plan.collectionId > s.Config.LastCollectionId() {
+		return fmt.Errorf("[applySlots] invalid collection id %d", plan.collectionId)
+	}
+	collectionBefore := s.Collection(plan.collectionId)
+	proof, err = s.merkleProof(s.collectionTree, plan.collectionId, s.collectionLeafHash(collectionBefore), s.Config.CollectionMerkleLevels)
+	if err != nil {
+		return err
+	}
+	oTx.MerkleProofsCollectionBefore = proof
+	if plan.collectionAfter != nil {
+		if err = s.setCollection(plan.collectionAfter(collectionBefore)); err != nil {
+			return err
+		}
+	}
+	oTx.CollectionBefore = collectionBefore
+
+	// the circuit checks the tx against the accounts and nft before the tx
+	if plan.verify != nil {
+		if err = plan.verify(oTx.AccountsInfoBefore, nftBefore); err != nil {
+			log.Println("[applySlots] invalid tx:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	unusedAssetId: asset of a slot the tx doesn't use. VerifyAtomicMatchTx
+	checks the first offer bit of the second asset of the buyer and seller
+	slots for every tx type, these slots are given an asset with the bit unset.
+*/
+func (s *State) unusedAssetId(accountIndex int64, accountSlot int, assetSlot int) int64 {
+	if assetSlot != 1 || (accountSlot != 1 && accountSlot != 2) {
+		return 0
+	}
+	assetId := int64(0)
+	for s.Asset(accountIndex, assetId).OfferCanceledOrFinalized.Bit(0) != 0 {
+		assetId++
+	}
+	return assetId
+}
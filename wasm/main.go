@@ -46,7 +46,14 @@ func main() {
 	// transaction
 	// asset
 	js.Global().Set("signTransfer", src2.TransferTx())
+	js.Global().Set("signBatchTransfer", src2.BatchTransferTx())
 	js.Global().Set("signWithdraw", src2.WithdrawTx())
+	// account
+	js.Global().Set("signChangePubKey", src2.ChangePubKeyTx())
+	// liquidity
+	js.Global().Set("signSwap", src2.SwapTx())
+	js.Global().Set("signAddLiquidity", src2.AddLiquidityTx())
+	js.Global().Set("signRemoveLiquidity", src2.RemoveLiquidityTx())
 
 	// nft
 	js.Global().Set("signAtomicMatch", src2.AtomicMatchTx())
@@ -56,5 +63,8 @@ func main() {
 	js.Global().Set("signMintNft", src2.MintNftTx())
 	js.Global().Set("signTransferNft", src2.TransferNftTx())
 	js.Global().Set("signWithdrawNft", src2.WithdrawNftTx())
+	js.Global().Set("signBurnNft", src2.BurnNftTx())
+	js.Global().Set("signUpdateCollection", src2.UpdateCollectionTx())
+	js.Global().Set("signTransferCollection", src2.TransferCollectionTx())
 	<-make(chan bool)
 }
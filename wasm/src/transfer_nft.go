@@ -29,16 +29,17 @@ import (
 
 func TransferNftTx() js.Func {
 	helperFunc := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		if len(args) != 2 {
+		if len(args) != 3 {
 			return "invalid mint nft params"
 		}
 		seed := args[0].String()
-		segmentStr := args[1].String()
+		chainId := int64(args[1].Int())
+		segmentStr := args[2].String()
 		sk, err := curve.GenerateEddsaPrivateKey(seed)
 		if err != nil {
 			return err.Error()
 		}
-		txInfo, err := txtypes.ConstructTransferNftTxInfo(sk, segmentStr)
+		txInfo, err := txtypes.ConstructTransferNftTxInfo(sk, chainId, segmentStr)
 		if err != nil {
 			log.Println("[MintNftTx] unable to construct generic transfer:", err)
 			return err.Error()
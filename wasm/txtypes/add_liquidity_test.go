@@ -0,0 +1,87 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateAddLiquidityTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *AddLiquidityTxInfo
+	}{
+		// AssetAAmount
+		{
+			fmt.Errorf("AssetAAmount should not be nil"),
+			&AddLiquidityTxInfo{
+				FromAccountIndex: 1,
+			},
+		},
+		// AssetBAmount
+		{
+			fmt.Errorf("AssetBAmount should be larger than %s", minLiquidityAmount.String()),
+			&AddLiquidityTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(100),
+				AssetBId:         2,
+				AssetBAmount:     big.NewInt(0),
+			},
+		},
+		// LpMinAmount
+		{
+			fmt.Errorf("LpMinAmount should not be larger than %s", maxLiquidityAmount.String()),
+			&AddLiquidityTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(100),
+				AssetBId:         2,
+				AssetBAmount:     big.NewInt(100),
+				LpMinAmount:      new(big.Int).Add(maxLiquidityAmount, big.NewInt(1)),
+			},
+		},
+		// ChainId
+		{
+			fmt.Errorf("ChainId should not be larger than %d", maxChainId),
+			&AddLiquidityTxInfo{
+				FromAccountIndex:  1,
+				AssetAId:          1,
+				AssetAAmount:      big.NewInt(100),
+				AssetBId:          2,
+				AssetBAmount:      big.NewInt(100),
+				LpMinAmount:       big.NewInt(0),
+				GasFeeAssetAmount: big.NewInt(100),
+				ChainId:           maxChainId + 1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestAddLiquidityTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("add liquidity seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"from_account_index":2,"pair_index":1,"asset_a_id":1,"asset_a_amount":"1000","asset_b_id":2,"asset_b_amount":"4000","lp_min_amount":"2000","gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructAddLiquidityTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the min lp amount is part of the signed message
+	txInfo.LpMinAmount = big.NewInt(1000)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
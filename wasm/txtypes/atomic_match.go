@@ -49,7 +49,7 @@ type AtomicMatchSegmentFormat struct {
 /*
 	ConstructMintNftTxInfo: construct mint nft tx, sign txInfo
 */
-func ConstructAtomicMatchTxInfo(sk *PrivateKey, segmentStr string) (txInfo *AtomicMatchTxInfo, err error) {
+func ConstructAtomicMatchTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *AtomicMatchTxInfo, err error) {
 	var segmentFormat *AtomicMatchSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -82,6 +82,7 @@ func ConstructAtomicMatchTxInfo(sk *PrivateKey, segmentStr string) (txInfo *Atom
 		GasAccountIndex:   segmentFormat.GasAccountIndex,
 		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
 		Nonce:             segmentFormat.Nonce,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Sig:               nil,
@@ -114,25 +115,30 @@ type AtomicMatchTxInfo struct {
 	GasFeeAssetAmount *big.Int
 	CreatorAmount     *big.Int
 	TreasuryAmount    *big.Int
+	ChainId           int64
 	Nonce             int64
 	ExpiredAt         int64
 	Sig               []byte
 }
 
 func (txInfo *AtomicMatchTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *AtomicMatchTxInfo) ValidateWithConfig(config Config) error {
 	// AccountIndex
 	if txInfo.AccountIndex < minAccountIndex {
 		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.AccountIndex > maxAccountIndex {
-		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// BuyOffer
 	if txInfo.BuyOffer == nil {
 		return fmt.Errorf("BuyOffer should not be nil")
 	}
-	if err := txInfo.BuyOffer.Validate(); err != nil {
+	if err := txInfo.BuyOffer.ValidateWithConfig(config); err != nil {
 		return fmt.Errorf("BuyOffer is invalid, %s", err.Error())
 	}
 
@@ -140,24 +146,28 @@ func (txInfo *AtomicMatchTxInfo) Validate() error {
 	if txInfo.SellOffer == nil {
 		return fmt.Errorf("SellOffer should not be nil")
 	}
-	if err := txInfo.SellOffer.Validate(); err != nil {
+	if err := txInfo.SellOffer.ValidateWithConfig(config); err != nil {
 		return fmt.Errorf("SellOffer is invalid, %s", err.Error())
 	}
+	// offers are signed for the chain of the tx
+	if txInfo.BuyOffer.ChainId != txInfo.ChainId || txInfo.SellOffer.ChainId != txInfo.ChainId {
+		return fmt.Errorf("ChainId of the offers should be %d", txInfo.ChainId)
+	}
 
 	// GasAccountIndex
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// GasFeeAssetId
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// GasFeeAssetAmount
@@ -176,6 +186,14 @@ func (txInfo *AtomicMatchTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	return nil
 }
 
@@ -238,7 +256,7 @@ func (txInfo *AtomicMatchTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err erro
 		log.Println("[ComputeTransferMsgHash] unable to packed amount:", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.BuyOffer.Type, txInfo.BuyOffer.OfferId, txInfo.BuyOffer.AccountIndex, txInfo.BuyOffer.NftIndex)
 	WriteInt64IntoBuf(&buf, txInfo.BuyOffer.AssetId, packedBuyAmount, txInfo.BuyOffer.ListedAt, txInfo.BuyOffer.ExpiredAt)
@@ -53,6 +53,15 @@ func TestValidateAtomicMatchTxInfo(t *testing.T) {
 				BuyOffer:     validOffer,
 			},
 		},
+		{
+			fmt.Errorf("ChainId of the offers should be %d", 56),
+			&AtomicMatchTxInfo{
+				AccountIndex: 1,
+				BuyOffer:     validOffer,
+				SellOffer:    validOffer,
+				ChainId:      56,
+			},
+		},
 		// GasAccountIndex
 		{
 			fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex),
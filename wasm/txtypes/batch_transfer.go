@@ -0,0 +1,327 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package txtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbnb-crypto/ffmath"
+)
+
+type BatchTransferRecipientSegmentFormat struct {
+	ToAccountIndex    int64  `json:"to_account_index"`
+	ToAccountNameHash string `json:"to_account_name"`
+	AssetAmount       string `json:"asset_amount"`
+}
+
+type BatchTransferSegmentFormat struct {
+	FromAccountIndex  int64                                  `json:"from_account_index"`
+	Recipients        []*BatchTransferRecipientSegmentFormat `json:"recipients"`
+	AssetId           int64                                  `json:"asset_id"`
+	GasAccountIndex   int64                                  `json:"gas_account_index"`
+	GasFeeAssetId     int64                                  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string                                 `json:"gas_fee_asset_amount"`
+	Memo              string                                 `json:"memo"`
+	CallData          string                                 `json:"call_data"`
+	ExpiredAt         int64                                  `json:"expired_at"`
+	Nonce             int64                                  `json:"nonce"`
+}
+
+func ConstructBatchTransferTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *BatchTransferTxInfo, err error) {
+	var segmentFormat *BatchTransferSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructBatchTransferTxInfo] err info:", err)
+		return nil, err
+	}
+	recipients := make([]*BatchTransferRecipient, len(segmentFormat.Recipients))
+	for i, recipient := range segmentFormat.Recipients {
+		if recipient == nil {
+			log.Println("[ConstructBatchTransferTxInfo] recipient should not be nil")
+			return nil, errors.New("[ConstructBatchTransferTxInfo] recipient should not be nil")
+		}
+		assetAmount, err := StringToBigInt(recipient.AssetAmount)
+		if err != nil {
+			log.Println("[ConstructBatchTransferTxInfo] unable to convert string to big int:", err)
+			return nil, err
+		}
+		assetAmount, _ = CleanPackedAmount(assetAmount)
+		recipients[i] = &BatchTransferRecipient{
+			ToAccountIndex:    recipient.ToAccountIndex,
+			ToAccountNameHash: recipient.ToAccountNameHash,
+			AssetAmount:       assetAmount,
+		}
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructBatchTransferTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &BatchTransferTxInfo{
+		FromAccountIndex:  segmentFormat.FromAccountIndex,
+		Recipients:        recipients,
+		AssetId:           segmentFormat.AssetId,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		Memo:              segmentFormat.Memo,
+		CallData:          segmentFormat.CallData,
+		ChainId:           chainId,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		Sig:               nil,
+	}
+	// compute call data hash
+	hFunc := mimc.NewMiMC()
+	hFunc.Write([]byte(txInfo.CallData))
+	callDataHash := hFunc.Sum(nil)
+	txInfo.CallDataHash = callDataHash
+	hFunc.Reset()
+	// compute msg hash
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		log.Println("[ConstructBatchTransferTxInfo] unable to compute hash:", err.Error())
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructBatchTransferTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+type BatchTransferRecipient struct {
+	ToAccountIndex    int64
+	ToAccountNameHash string
+	AssetAmount       *big.Int
+}
+
+/*
+	BatchTransferTxInfo: transfer one asset to up to Config.MaxBatchTransferRecipients accounts with a single signature
+*/
+type BatchTransferTxInfo struct {
+	FromAccountIndex  int64
+	Recipients        []*BatchTransferRecipient
+	AssetId           int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	Memo              string
+	CallData          string
+	CallDataHash      []byte
+	ChainId           int64
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *BatchTransferTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *BatchTransferTxInfo) ValidateWithConfig(config Config) error {
+	if txInfo.FromAccountIndex < minAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if len(txInfo.Recipients) == 0 {
+		return fmt.Errorf("Recipients should not be empty")
+	}
+	if len(txInfo.Recipients) > config.MaxBatchTransferRecipients {
+		return fmt.Errorf("Recipients should not be more than %d", config.MaxBatchTransferRecipients)
+	}
+	for i, recipient := range txInfo.Recipients {
+		if recipient == nil {
+			return fmt.Errorf("Recipients[%d] should not be nil", i)
+		}
+		if recipient.ToAccountIndex < minAccountIndex {
+			return fmt.Errorf("Recipients[%d].ToAccountIndex should not be less than %d", i, minAccountIndex)
+		}
+		if recipient.ToAccountIndex > config.MaxAccountIndex {
+			return fmt.Errorf("Recipients[%d].ToAccountIndex should not be larger than %d", i, config.MaxAccountIndex)
+		}
+		if !IsValidHash(recipient.ToAccountNameHash) {
+			return fmt.Errorf("Recipients[%d].ToAccountNameHash(%s) is invalid", i, recipient.ToAccountNameHash)
+		}
+		if recipient.AssetAmount == nil {
+			return fmt.Errorf("Recipients[%d].AssetAmount should not be nil", i)
+		}
+		if recipient.AssetAmount.Cmp(minAssetAmount) < 0 {
+			return fmt.Errorf("Recipients[%d].AssetAmount should not be less than %s", i, minAssetAmount.String())
+		}
+		if recipient.AssetAmount.Cmp(maxAssetAmount) > 0 {
+			return fmt.Errorf("Recipients[%d].AssetAmount should not be larger than %s", i, maxAssetAmount.String())
+		}
+	}
+
+	if txInfo.AssetId < minAssetId {
+		return fmt.Errorf("AssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetId > config.MaxAssetId {
+		return fmt.Errorf("AssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
+	// CallDataHash
+	if !IsValidHashBytes(txInfo.CallDataHash) {
+		return fmt.Errorf("CallDataHash(%s) is invalid", hex.EncodeToString(txInfo.CallDataHash))
+	}
+
+	return nil
+}
+
+func (txInfo *BatchTransferTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *BatchTransferTxInfo) GetTxType() int {
+	return TxTypeBatchTransfer
+}
+
+func (txInfo *BatchTransferTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *BatchTransferTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *BatchTransferTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+/*
+	Hash: the recipients are hashed like the account slots of the circuit, unused slots
+	are sent to the sender with a zero name hash and amount. The number of signed slots
+	doesn't depend on the trees of a network, it is the one of MainnetConfig
+*/
+func (txInfo *BatchTransferTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	if len(txInfo.Recipients) > MainnetConfig.MaxBatchTransferRecipients {
+		log.Println("[ComputeBatchTransferMsgHash] too many recipients")
+		return nil, errors.New("[ComputeBatchTransferMsgHash] too many recipients")
+	}
+	var buf bytes.Buffer
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeBatchTransferMsgHash] unable to packed amount", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	for i := 0; i < MainnetConfig.MaxBatchTransferRecipients; i++ {
+		if i >= len(txInfo.Recipients) {
+			WriteInt64IntoBuf(&buf, txInfo.FromAccountIndex, txInfo.AssetId, 0)
+			buf.Write(make([]byte, 32))
+			continue
+		}
+		recipient := txInfo.Recipients[i]
+		if recipient == nil {
+			log.Println("[ComputeBatchTransferMsgHash] recipient should not be nil")
+			return nil, errors.New("[ComputeBatchTransferMsgHash] recipient should not be nil")
+		}
+		packedAmount, err := ToPackedAmount(recipient.AssetAmount)
+		if err != nil {
+			log.Println("[ComputeBatchTransferMsgHash] unable to packed amount", err.Error())
+			return nil, err
+		}
+		WriteInt64IntoBuf(&buf, recipient.ToAccountIndex, txInfo.AssetId, packedAmount)
+		buf.Write(ffmath.Mod(new(big.Int).SetBytes(common.FromHex(recipient.ToAccountNameHash)), curve.Modulus).FillBytes(make([]byte, 32)))
+	}
+	buf.Write(ffmath.Mod(new(big.Int).SetBytes(txInfo.CallDataHash), curve.Modulus).FillBytes(make([]byte, 32)))
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+func (txInfo *BatchTransferTxInfo) GetGas() (int64, int64, *big.Int) {
+	return txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount
+}
@@ -0,0 +1,129 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateBatchTransferTxInfo(t *testing.T) {
+	nameHash := "0x" + hex.EncodeToString(append([]byte{1}, make([]byte, HashLength-1)...))
+	recipient := &BatchTransferRecipient{ToAccountIndex: 2, ToAccountNameHash: nameHash, AssetAmount: big.NewInt(100)}
+
+	testCases := []struct {
+		err      error
+		testCase *BatchTransferTxInfo
+	}{
+		// FromAccountIndex
+		{
+			fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex),
+			&BatchTransferTxInfo{
+				FromAccountIndex: minAccountIndex - 1,
+			},
+		},
+		// Recipients
+		{
+			fmt.Errorf("Recipients should not be empty"),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+			},
+		},
+		{
+			fmt.Errorf("Recipients should not be more than %d", maxBatchTransferRecipients),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients:       []*BatchTransferRecipient{recipient, recipient, recipient, recipient},
+			},
+		},
+		{
+			fmt.Errorf("Recipients[%d].ToAccountIndex should not be larger than %d", 1, maxAccountIndex),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients: []*BatchTransferRecipient{
+					recipient,
+					{ToAccountIndex: maxAccountIndex + 1},
+				},
+			},
+		},
+		{
+			fmt.Errorf("Recipients[%d].ToAccountNameHash(%s) is invalid", 0, "0x00"),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients: []*BatchTransferRecipient{
+					{ToAccountIndex: 2, ToAccountNameHash: "0x00"},
+				},
+			},
+		},
+		{
+			fmt.Errorf("Recipients[%d].AssetAmount should not be larger than %s", 0, maxAssetAmount.String()),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients: []*BatchTransferRecipient{
+					{ToAccountIndex: 2, ToAccountNameHash: nameHash, AssetAmount: big.NewInt(0).Add(maxAssetAmount, big.NewInt(1))},
+				},
+			},
+		},
+		// AssetId
+		{
+			fmt.Errorf("AssetId should not be larger than %d", maxAssetId),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients:       []*BatchTransferRecipient{recipient},
+				AssetId:          maxAssetId + 1,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&BatchTransferTxInfo{
+				FromAccountIndex: 1,
+				Recipients:       []*BatchTransferRecipient{recipient},
+				GasFeeAssetId:    3,
+			},
+		},
+		// Nonce
+		{
+			fmt.Errorf("Nonce should not be less than %d", minNonce),
+			&BatchTransferTxInfo{
+				FromAccountIndex:  1,
+				Recipients:        []*BatchTransferRecipient{recipient},
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             -1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestBatchTransferTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("batch transfer seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+	nameHash := "0x" + hex.EncodeToString(append([]byte{1}, make([]byte, HashLength-1)...))
+
+	segment := fmt.Sprintf(`{"from_account_index":2,"recipients":[{"to_account_index":3,"to_account_name":"%s","asset_amount":"100"},{"to_account_index":4,"to_account_name":"%s","asset_amount":"200"}],"asset_id":0,"gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","call_data":"batch","expired_at":%d,"nonce":3}`,
+		nameHash, nameHash, time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructBatchTransferTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// every recipient is part of the signed message
+	txInfo.Recipients[1].AssetAmount = big.NewInt(300)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+	txInfo.Recipients[1].AssetAmount = big.NewInt(200)
+	txInfo.Recipients = txInfo.Recipients[:1]
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+type BurnNftSegmentFormat struct {
+	AccountIndex      int64  `json:"account_index"`
+	NftIndex          int64  `json:"nft_index"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64  `json:"expired_at"`
+	Nonce             int64  `json:"nonce"`
+}
+
+func ConstructBurnNftTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *BurnNftTxInfo, err error) {
+	var segmentFormat *BurnNftSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructBurnNftTxInfo] err info:", err)
+		return nil, err
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructBurnNftTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &BurnNftTxInfo{
+		AccountIndex:      segmentFormat.AccountIndex,
+		NftIndex:          segmentFormat.NftIndex,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		Sig:               nil,
+	}
+	hFunc := mimc.NewMiMC()
+	// compute msg hash
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		log.Println("[ConstructBurnNftTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructBurnNftTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	BurnNftTxInfo: the owner of an nft destroys it, only the nft index is signed
+*/
+type BurnNftTxInfo struct {
+	AccountIndex      int64
+	NftIndex          int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ChainId           int64
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *BurnNftTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *BurnNftTxInfo) ValidateWithConfig(config Config) error {
+	// AccountIndex
+	if txInfo.AccountIndex < minAccountIndex {
+		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	// NftIndex
+	if txInfo.NftIndex < minNftIndex {
+		return fmt.Errorf("NftIndex should not be less than %d", minNftIndex)
+	}
+	if txInfo.NftIndex > config.MaxNftIndex {
+		return fmt.Errorf("NftIndex should not be larger than %d", config.MaxNftIndex)
+	}
+
+	// GasAccountIndex
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	// GasFeeAssetId
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	// GasFeeAssetAmount
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	// Nonce
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
+	return nil
+}
+
+func (txInfo *BurnNftTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *BurnNftTxInfo) GetTxType() int {
+	return TxTypeBurnNft
+}
+
+func (txInfo *BurnNftTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *BurnNftTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *BurnNftTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func (txInfo *BurnNftTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeBurnNftMsgHash] unable to packed amount", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.NftIndex)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+func (txInfo *BurnNftTxInfo) GetGas() (int64, int64, *big.Int) {
+	return txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount
+}
@@ -0,0 +1,79 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateBurnNftTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *BurnNftTxInfo
+	}{
+		// AccountIndex
+		{
+			fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex),
+			&BurnNftTxInfo{
+				AccountIndex: minAccountIndex - 1,
+			},
+		},
+		// NftIndex
+		{
+			fmt.Errorf("NftIndex should not be larger than %d", maxNftIndex),
+			&BurnNftTxInfo{
+				AccountIndex: 1,
+				NftIndex:     maxNftIndex + 1,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&BurnNftTxInfo{
+				AccountIndex:    1,
+				NftIndex:        5,
+				GasAccountIndex: 1,
+			},
+		},
+		// Nonce
+		{
+			fmt.Errorf("Nonce should not be less than %d", minNonce),
+			&BurnNftTxInfo{
+				AccountIndex:      1,
+				NftIndex:          5,
+				GasAccountIndex:   1,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             -1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestBurnNftTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("burn nft seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"account_index":2,"nft_index":5,"gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructBurnNftTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the nft index is part of the signed message
+	txInfo.NftIndex = 6
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
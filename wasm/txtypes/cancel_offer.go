@@ -39,7 +39,7 @@ type CancelOfferSegmentFormat struct {
 	Nonce             int64  `json:"nonce"`
 }
 
-func ConstructCancelOfferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *CancelOfferTxInfo, err error) {
+func ConstructCancelOfferTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *CancelOfferTxInfo, err error) {
 	var segmentFormat *CancelOfferSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -58,6 +58,7 @@ func ConstructCancelOfferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *Canc
 		GasAccountIndex:   segmentFormat.GasAccountIndex,
 		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Nonce:             segmentFormat.Nonce,
 		Sig:               nil,
@@ -87,18 +88,23 @@ type CancelOfferTxInfo struct {
 	GasAccountIndex   int64
 	GasFeeAssetId     int64
 	GasFeeAssetAmount *big.Int
+	ChainId           int64
 	ExpiredAt         int64
 	Nonce             int64
 	Sig               []byte
 }
 
 func (txInfo *CancelOfferTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *CancelOfferTxInfo) ValidateWithConfig(config Config) error {
 	// AccountIndex
 	if txInfo.AccountIndex < minAccountIndex {
 		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.AccountIndex > maxAccountIndex {
-		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// OfferId
@@ -110,16 +116,16 @@ func (txInfo *CancelOfferTxInfo) Validate() error {
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// GasFeeAssetId
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// GasFeeAssetAmount
@@ -138,6 +144,14 @@ func (txInfo *CancelOfferTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	return nil
 }
 
@@ -189,7 +203,7 @@ func (txInfo *CancelOfferTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err erro
 		log.Println("[ComputeTransferMsgHash] unable to packed amount:", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.OfferId)
 	hFunc.Write(buf.Bytes())
@@ -0,0 +1,271 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+type ChangePubKeySegmentFormat struct {
+	AccountIndex      int64  `json:"account_index"`
+	PubKey            string `json:"pub_key"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64  `json:"expired_at"`
+	Nonce             int64  `json:"nonce"`
+}
+
+/*
+	ConstructChangePubKeyTxInfo: the tx is signed by the current key of the account, sk,
+	and sets the key of the account to the pub key of the segment
+*/
+func ConstructChangePubKeyTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *ChangePubKeyTxInfo, err error) {
+	var segmentFormat *ChangePubKeySegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] err info:", err)
+		return nil, err
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &ChangePubKeyTxInfo{
+		AccountIndex:      segmentFormat.AccountIndex,
+		PubKey:            segmentFormat.PubKey,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		Sig:               nil,
+	}
+	// compute msg hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	ChangePubKeyTxInfo: replace the layer 2 key of an account. Layer 2 txs are signed
+	by the current key and pay a gas fee, priority ops are sent on layer 1 and only
+	carry the account, its name hash and the new key.
+*/
+type ChangePubKeyTxInfo struct {
+	AccountIndex int64
+	PubKey       string
+
+	// Get from layer1 events.
+	IsPriorityOp    bool
+	AccountNameHash []byte
+
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ChainId           int64
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *ChangePubKeyTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *ChangePubKeyTxInfo) ValidateWithConfig(config Config) error {
+	// AccountIndex
+	if txInfo.AccountIndex < minAccountIndex {
+		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	// PubKey
+	if _, err := ParsePublicKey(txInfo.PubKey); err != nil {
+		return fmt.Errorf("PubKey is invalid")
+	}
+
+	if txInfo.IsPriorityOp {
+		// AccountNameHash
+		if !IsValidHashBytes(txInfo.AccountNameHash) {
+			return fmt.Errorf("AccountNameHash is invalid")
+		}
+		// priority ops don't pay gas
+		if txInfo.GasAccountIndex != 0 || txInfo.GasFeeAssetId != 0 ||
+			(txInfo.GasFeeAssetAmount != nil && txInfo.GasFeeAssetAmount.Sign() != 0) {
+			return fmt.Errorf("priority ops should not pay gas")
+		}
+		return nil
+	}
+
+	// GasAccountIndex
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	// GasFeeAssetId
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	// GasFeeAssetAmount
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	// Nonce
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
+	return nil
+}
+
+/*
+	VerifySignature: pubKey is the current key of the account, not the new one
+*/
+func (txInfo *ChangePubKeyTxInfo) VerifySignature(pubKey string) error {
+	if txInfo.IsPriorityOp {
+		return nil
+	}
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetTxType() int {
+	return TxTypeChangePubKey
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetFromAccountIndex() int64 {
+	if txInfo.IsPriorityOp {
+		return NilAccountIndex
+	}
+	return txInfo.AccountIndex
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetNonce() int64 {
+	if txInfo.IsPriorityOp {
+		return NilNonce
+	}
+	return txInfo.Nonce
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetExpiredAt() int64 {
+	if txInfo.IsPriorityOp {
+		return NilExpiredAt
+	}
+	return txInfo.ExpiredAt
+}
+
+func (txInfo *ChangePubKeyTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	if txInfo.IsPriorityOp {
+		return msgHash, errors.New("priority ops are not signed")
+	}
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeChangePubKeyMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	pk, err := ParsePublicKey(txInfo.PubKey)
+	if err != nil {
+		log.Println("[ComputeChangePubKeyMsgHash] invalid public key:", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	pkX := pk.A.X.Bytes()
+	pkY := pk.A.Y.Bytes()
+	buf.Write(pkX[:])
+	buf.Write(pkY[:])
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetGas() (int64, int64, *big.Int) {
+	if txInfo.IsPriorityOp {
+		return NilAccountIndex, NilAssetId, nil
+	}
+	return txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount
+}
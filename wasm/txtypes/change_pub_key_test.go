@@ -0,0 +1,136 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateChangePubKeyTxInfo(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("change pub key seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+	nameHash := make([]byte, HashLength)
+	nameHash[0] = 1
+
+	testCases := []struct {
+		err      error
+		testCase *ChangePubKeyTxInfo
+	}{
+		// AccountIndex
+		{
+			fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex),
+			&ChangePubKeyTxInfo{
+				AccountIndex: minAccountIndex - 1,
+			},
+		},
+		{
+			fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex),
+			&ChangePubKeyTxInfo{
+				AccountIndex: maxAccountIndex + 1,
+			},
+		},
+		// PubKey
+		{
+			fmt.Errorf("PubKey is invalid"),
+			&ChangePubKeyTxInfo{
+				AccountIndex: 1,
+				PubKey:       "1234",
+			},
+		},
+		// priority op
+		{
+			fmt.Errorf("AccountNameHash is invalid"),
+			&ChangePubKeyTxInfo{
+				AccountIndex: 1,
+				PubKey:       pubKey,
+				IsPriorityOp: true,
+			},
+		},
+		{
+			fmt.Errorf("priority ops should not pay gas"),
+			&ChangePubKeyTxInfo{
+				AccountIndex:      1,
+				PubKey:            pubKey,
+				IsPriorityOp:      true,
+				AccountNameHash:   nameHash,
+				GasFeeAssetAmount: big.NewInt(100),
+			},
+		},
+		{
+			nil,
+			&ChangePubKeyTxInfo{
+				AccountIndex:    1,
+				PubKey:          pubKey,
+				IsPriorityOp:    true,
+				AccountNameHash: nameHash,
+			},
+		},
+		// GasFeeAssetId
+		{
+			fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId),
+			&ChangePubKeyTxInfo{
+				AccountIndex:    1,
+				PubKey:          pubKey,
+				GasAccountIndex: 0,
+				GasFeeAssetId:   maxAssetId + 1,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&ChangePubKeyTxInfo{
+				AccountIndex:    1,
+				PubKey:          pubKey,
+				GasAccountIndex: 0,
+				GasFeeAssetId:   3,
+			},
+		},
+		// Nonce
+		{
+			fmt.Errorf("Nonce should not be less than %d", minNonce),
+			&ChangePubKeyTxInfo{
+				AccountIndex:      1,
+				PubKey:            pubKey,
+				GasAccountIndex:   0,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             -1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestChangePubKeyTxInfoSignature(t *testing.T) {
+	oldSk, err := curve.GenerateEddsaPrivateKey("old change pub key seed")
+	require.NoError(t, err)
+	newSk, err := curve.GenerateEddsaPrivateKey("new change pub key seed")
+	require.NoError(t, err)
+	oldPubKey := hex.EncodeToString(oldSk.PublicKey.Bytes())
+	newPubKey := hex.EncodeToString(newSk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"account_index":2,"pub_key":"%s","gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		newPubKey, time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructChangePubKeyTxInfo(oldSk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	// signed by the current key of the account
+	require.NoError(t, txInfo.VerifySignature(oldPubKey))
+	require.Error(t, txInfo.VerifySignature(newPubKey))
+
+	// the new key is part of the signed message
+	txInfo.PubKey = oldPubKey
+	require.Error(t, txInfo.VerifySignature(oldPubKey))
+}
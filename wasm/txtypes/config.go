@@ -0,0 +1,47 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package txtypes
+
+/*
+	Config: largest account index, asset id, nft index, collection id and pair index
+	of a network, a tx addressing a leaf beyond its trees is rejected. Asset ids from
+	FirstLpAssetId on are the lp shares of the pairs, they can't be deposited nor
+	withdrawn. MaxBatchTransferRecipients is the number of account slots of a tx
+	after the sender.
+*/
+type Config struct {
+	MaxAccountIndex int64
+	MaxAssetId      int64
+	MaxNftIndex     int64
+	MaxCollectionId int64
+	MaxPairIndex    int64
+	FirstLpAssetId  int64
+
+	MaxBatchTransferRecipients int
+}
+
+// bounds of the mainnet trees, Validate checks txs against them
+var MainnetConfig = Config{
+	MaxAccountIndex: maxAccountIndex,
+	MaxAssetId:      maxAssetId,
+	MaxNftIndex:     maxNftIndex,
+	MaxCollectionId: maxCollectionId,
+	MaxPairIndex:    maxPairIndex,
+	FirstLpAssetId:  firstLpAssetId,
+
+	MaxBatchTransferRecipients: maxBatchTransferRecipients,
+}
@@ -31,10 +31,6 @@ type (
 	PrivateKey = eddsa.PrivateKey
 )
 
-const (
-	ChainId = 1
-)
-
 const (
 	NilNonce        = -1
 	NilExpiredAt    = math.MaxInt64
@@ -58,6 +54,15 @@ const (
 	TxTypeFullExit
 	TxTypeFullExitNft
 	TxTypeOffer
+	TxTypeChangePubKey
+	TxTypeBatchTransfer
+	TxTypeCreatePair
+	TxTypeSwap
+	TxTypeAddLiquidity
+	TxTypeRemoveLiquidity
+	TxTypeBurnNft
+	TxTypeUpdateCollection
+	TxTypeTransferCollection
 )
 
 const (
@@ -69,14 +74,27 @@ const (
 	minAssetId int64 = 0
 	maxAssetId int64 = (1 << 16) - 1
 
+	// the upper half of the asset ids are lp shares, the lp asset id of a pair is
+	// firstLpAssetId + PairIndex
+	firstLpAssetId int64 = 1 << 15
+
 	minNftIndex int64 = 0
 	maxNftIndex int64 = (1 << 40) - 1
 
 	minCollectionId int64 = 0
 	maxCollectionId int64 = (1 << 16) - 1
+	// collection 0 mints nfts without a collection, it is never created
+	minCreatedCollectionId int64 = 1
+
+	minPairIndex int64 = 0
+	maxPairIndex int64 = (1 << 16) - 1
 
 	minNonce int64 = 0
 
+	// chain ids are uint32 on layer 1
+	minChainId int64 = 0
+	maxChainId int64 = (1 << 32) - 1
+
 	minTreasuryRate int64 = 0
 	maxTreasuryRate int64 = 10000
 
@@ -84,6 +102,10 @@ const (
 	maxCollectionNameLength int = 50
 
 	maxCollectionIntroductionLength int = 1000
+
+	// the first account of a tx is the sender, the other accounts receive the transfers,
+	// it is types.NbBatchTransferRecipients of the circuit
+	maxBatchTransferRecipients int = 3
 )
 
 var (
@@ -92,4 +114,8 @@ var (
 
 	minAssetAmount = big.NewInt(0)
 	maxAssetAmount = util.PackedAmountMaxAmount
+
+	// reserves and lp shares of a pair are uint112
+	minLiquidityAmount = big.NewInt(0)
+	maxLiquidityAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
 )
@@ -27,23 +27,29 @@ import (
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbnb-crypto/ffmath"
 )
 
 type CreateCollectionSegmentFormat struct {
-	AccountIndex      int64  `json:"account_index"`
-	Name              string `json:"name"`
-	Introduction      string `json:"introduction"`
-	GasAccountIndex   int64  `json:"gas_account_index"`
-	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
-	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
-	ExpiredAt         int64  `json:"expired_at"`
-	Nonce             int64  `json:"nonce"`
+	AccountIndex        int64  `json:"account_index"`
+	Name                string `json:"name"`
+	Introduction        string `json:"introduction"`
+	MetadataHash        string `json:"metadata_hash"`
+	CreatorTreasuryRate int64  `json:"creator_treasury_rate"`
+	GasAccountIndex     int64  `json:"gas_account_index"`
+	GasFeeAssetId       int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount   string `json:"gas_fee_asset_amount"`
+	ExpiredAt           int64  `json:"expired_at"`
+	Nonce               int64  `json:"nonce"`
 }
 
 /*
 	ConstructCreateCollectionTxInfo: construct mint nft tx, sign txInfo
 */
-func ConstructCreateCollectionTxInfo(sk *PrivateKey, segmentStr string) (txInfo *CreateCollectionTxInfo, err error) {
+func ConstructCreateCollectionTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *CreateCollectionTxInfo, err error) {
 	var segmentFormat *CreateCollectionSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -57,15 +63,18 @@ func ConstructCreateCollectionTxInfo(sk *PrivateKey, segmentStr string) (txInfo
 	}
 	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
 	txInfo = &CreateCollectionTxInfo{
-		AccountIndex:      segmentFormat.AccountIndex,
-		Name:              segmentFormat.Name,
-		Introduction:      segmentFormat.Introduction,
-		GasAccountIndex:   segmentFormat.GasAccountIndex,
-		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
-		GasFeeAssetAmount: gasFeeAmount,
-		ExpiredAt:         segmentFormat.ExpiredAt,
-		Nonce:             segmentFormat.Nonce,
-		Sig:               nil,
+		AccountIndex:        segmentFormat.AccountIndex,
+		Name:                segmentFormat.Name,
+		Introduction:        segmentFormat.Introduction,
+		MetadataHash:        segmentFormat.MetadataHash,
+		CreatorTreasuryRate: segmentFormat.CreatorTreasuryRate,
+		GasAccountIndex:     segmentFormat.GasAccountIndex,
+		GasFeeAssetId:       segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount:   gasFeeAmount,
+		ChainId:             chainId,
+		ExpiredAt:           segmentFormat.ExpiredAt,
+		Nonce:               segmentFormat.Nonce,
+		Sig:                 nil,
 	}
 	// compute call data hash
 	hFunc := mimc.NewMiMC()
@@ -87,25 +96,40 @@ func ConstructCreateCollectionTxInfo(sk *PrivateKey, segmentStr string) (txInfo
 }
 
 type CreateCollectionTxInfo struct {
-	AccountIndex      int64
-	CollectionId      int64
-	Name              string
-	Introduction      string
-	GasAccountIndex   int64
-	GasFeeAssetId     int64
-	GasFeeAssetAmount *big.Int
-	ExpiredAt         int64
-	Nonce             int64
-	Sig               []byte
+	AccountIndex        int64
+	CollectionId        int64
+	Name                string
+	Introduction        string
+	MetadataHash        string
+	CreatorTreasuryRate int64
+	GasAccountIndex     int64
+	GasFeeAssetId       int64
+	GasFeeAssetAmount   *big.Int
+	ChainId             int64
+	ExpiredAt           int64
+	Nonce               int64
+	Sig                 []byte
 }
 
 func (txInfo *CreateCollectionTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *CreateCollectionTxInfo) ValidateWithConfig(config Config) error {
 	// AccountIndex
 	if txInfo.AccountIndex < minAccountIndex {
 		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.AccountIndex > maxAccountIndex {
-		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	// CollectionId
+	if txInfo.CollectionId < minCreatedCollectionId {
+		return fmt.Errorf("CollectionId should not be less than %d", minCreatedCollectionId)
+	}
+	if txInfo.CollectionId > config.MaxCollectionId {
+		return fmt.Errorf("CollectionId should not be larger than %d", config.MaxCollectionId)
 	}
 
 	// Name
@@ -121,20 +145,33 @@ func (txInfo *CreateCollectionTxInfo) Validate() error {
 		return fmt.Errorf("length of Introduction should not be larger than %d", maxCollectionIntroductionLength)
 	}
 
+	// MetadataHash
+	if !IsValidHash(txInfo.MetadataHash) {
+		return fmt.Errorf("MetadataHash(%s) is invalid", txInfo.MetadataHash)
+	}
+
+	// CreatorTreasuryRate
+	if txInfo.CreatorTreasuryRate < minTreasuryRate {
+		return fmt.Errorf("CreatorTreasuryRate should not be less than %d", minTreasuryRate)
+	}
+	if txInfo.CreatorTreasuryRate > maxTreasuryRate {
+		return fmt.Errorf("CreatorTreasuryRate should not be larger than %d", maxTreasuryRate)
+	}
+
 	// GasAccountIndex
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// GasFeeAssetId
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// GasFeeAssetAmount
@@ -153,6 +190,14 @@ func (txInfo *CreateCollectionTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	return nil
 }
 
@@ -204,8 +249,10 @@ func (txInfo *CreateCollectionTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err
 		log.Println("[ComputeTransferMsgHash] unable to packed amount", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.AccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.CreatorTreasuryRate)
+	WriteBigIntIntoBuf(&buf, ffmath.Mod(new(big.Int).SetBytes(common.FromHex(txInfo.MetadataHash)), curve.Modulus))
 	hFunc.Write(buf.Bytes())
 	msgHash = hFunc.Sum(nil)
 	return msgHash, nil
@@ -1,6 +1,8 @@
 package txtypes
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strings"
@@ -28,6 +30,21 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				AccountIndex: maxAccountIndex + 1,
 			},
 		},
+		// CollectionId
+		{
+			fmt.Errorf("CollectionId should not be less than %d", minCreatedCollectionId),
+			&CreateCollectionTxInfo{
+				AccountIndex: 1,
+				CollectionId: 0,
+			},
+		},
+		{
+			fmt.Errorf("CollectionId should not be larger than %d", maxCollectionId),
+			&CreateCollectionTxInfo{
+				AccountIndex: 1,
+				CollectionId: maxCollectionId + 1,
+			},
+		},
 		// Name
 		{
 			fmt.Errorf("length of Name should not be less than %d", minCollectionNameLength),
@@ -55,6 +72,29 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				Introduction: strings.Repeat("s", maxCollectionIntroductionLength+1),
 			},
 		},
+		// MetadataHash
+		{
+			fmt.Errorf("MetadataHash(0000000000000000000000000000000000000000000000000000000000000000) is invalid"),
+			&CreateCollectionTxInfo{
+				AccountIndex: 1,
+				CollectionId: 5,
+				Name:         "test name",
+				Introduction: "test introduction",
+				MetadataHash: hex.EncodeToString(bytes.Repeat([]byte{0}, 32)),
+			},
+		},
+		// CreatorTreasuryRate
+		{
+			fmt.Errorf("CreatorTreasuryRate should not be larger than %d", maxTreasuryRate),
+			&CreateCollectionTxInfo{
+				AccountIndex:        1,
+				CollectionId:        5,
+				Name:                "test name",
+				Introduction:        "test introduction",
+				MetadataHash:        hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CreatorTreasuryRate: maxTreasuryRate + 1,
+			},
+		},
 		// GasAccountIndex
 		{
 			fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex),
@@ -63,6 +103,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:    5,
 				Name:            "test name",
 				Introduction:    "test introduction",
+				MetadataHash:    hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex: minAccountIndex - 1,
 			},
 		},
@@ -73,6 +114,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:    5,
 				Name:            "test name",
 				Introduction:    "test introduction",
+				MetadataHash:    hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex: maxAccountIndex + 1,
 			},
 		},
@@ -84,6 +126,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:    5,
 				Name:            "test name",
 				Introduction:    "test introduction",
+				MetadataHash:    hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex: 0,
 				GasFeeAssetId:   minAssetId - 1,
 			},
@@ -95,6 +138,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:    5,
 				Name:            "test name",
 				Introduction:    "test introduction",
+				MetadataHash:    hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex: 0,
 				GasFeeAssetId:   maxAssetId + 1,
 			},
@@ -107,6 +151,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:    5,
 				Name:            "test name",
 				Introduction:    "test introduction",
+				MetadataHash:    hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex: 0,
 				GasFeeAssetId:   3,
 			},
@@ -118,6 +163,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:      5,
 				Name:              "test name",
 				Introduction:      "test introduction",
+				MetadataHash:      hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex:   0,
 				GasFeeAssetId:     3,
 				GasFeeAssetAmount: big.NewInt(-1),
@@ -130,6 +176,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:      5,
 				Name:              "test name",
 				Introduction:      "test introduction",
+				MetadataHash:      hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex:   0,
 				GasFeeAssetId:     3,
 				GasFeeAssetAmount: big.NewInt(0).Add(maxPackedFeeAmount, big.NewInt(1)),
@@ -143,6 +190,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:      5,
 				Name:              "test name",
 				Introduction:      "test introduction",
+				MetadataHash:      hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex:   0,
 				GasFeeAssetId:     3,
 				GasFeeAssetAmount: big.NewInt(100),
@@ -158,6 +206,7 @@ func TestValidateCreateCollectionTxInfo(t *testing.T) {
 				CollectionId:      5,
 				Name:              "test name",
 				Introduction:      "test introduction",
+				MetadataHash:      hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
 				GasAccountIndex:   0,
 				GasFeeAssetId:     3,
 				GasFeeAssetAmount: big.NewInt(100),
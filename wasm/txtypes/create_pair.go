@@ -0,0 +1,69 @@
+package txtypes
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+type CreatePairTxInfo struct {
+	TxType uint8
+
+	// Get from layer1 events.
+	PairIndex            int64
+	AssetAId             int64
+	AssetBId             int64
+	LpAssetId            int64
+	FeeRate              int64
+	TreasuryAccountIndex int64
+	TreasuryRate         int64
+}
+
+func (txInfo *CreatePairTxInfo) GetTxType() int {
+	return TxTypeCreatePair
+}
+
+func (txInfo *CreatePairTxInfo) Validate() error {
+	return nil
+}
+
+func (txInfo *CreatePairTxInfo) ValidateWithConfig(config Config) error {
+	if txInfo.PairIndex < minPairIndex {
+		return fmt.Errorf("PairIndex should not be less than %d", minPairIndex)
+	}
+	if txInfo.PairIndex > config.MaxPairIndex {
+		return fmt.Errorf("PairIndex should not be larger than %d", config.MaxPairIndex)
+	}
+	if txInfo.LpAssetId != config.FirstLpAssetId+txInfo.PairIndex {
+		return fmt.Errorf("LpAssetId should be %d", config.FirstLpAssetId+txInfo.PairIndex)
+	}
+	if txInfo.LpAssetId > config.MaxAssetId {
+		return fmt.Errorf("LpAssetId should not be larger than %d", config.MaxAssetId)
+	}
+	return nil
+}
+
+func (txInfo *CreatePairTxInfo) VerifySignature(pubKey string) error {
+	return nil
+}
+
+func (txInfo *CreatePairTxInfo) GetFromAccountIndex() int64 {
+	return NilAccountIndex
+}
+
+func (txInfo *CreatePairTxInfo) GetNonce() int64 {
+	return NilNonce
+}
+
+func (txInfo *CreatePairTxInfo) GetExpiredAt() int64 {
+	return NilExpiredAt
+}
+
+func (txInfo *CreatePairTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	return msgHash, errors.New("not support")
+}
+
+func (txInfo *CreatePairTxInfo) GetGas() (int64, int64, *big.Int) {
+	return NilAccountIndex, NilAssetId, nil
+}
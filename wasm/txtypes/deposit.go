@@ -2,6 +2,7 @@ package txtypes
 
 import (
 	"errors"
+	"fmt"
 	"hash"
 	"math/big"
 )
@@ -26,6 +27,16 @@ func (txInfo *DepositTxInfo) Validate() error {
 	return nil
 }
 
+func (txInfo *DepositTxInfo) ValidateWithConfig(config Config) error {
+	if txInfo.AssetId < minAssetId {
+		return fmt.Errorf("AssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetId >= config.FirstLpAssetId {
+		return fmt.Errorf("AssetId should be less than %d, lp shares can't be deposited", config.FirstLpAssetId)
+	}
+	return nil
+}
+
 func (txInfo *DepositTxInfo) VerifySignature(pubKey string) error {
 	return nil
 }
@@ -33,6 +33,10 @@ func (txInfo *DepositNftTxInfo) Validate() error {
 	return nil
 }
 
+func (txInfo *DepositNftTxInfo) ValidateWithConfig(config Config) error {
+	return nil
+}
+
 func (txInfo *DepositNftTxInfo) VerifySignature(pubKey string) error {
 	return nil
 }
@@ -26,6 +26,10 @@ func (txInfo *FullExitTxInfo) Validate() error {
 	return nil
 }
 
+func (txInfo *FullExitTxInfo) ValidateWithConfig(config Config) error {
+	return nil
+}
+
 func (txInfo *FullExitTxInfo) VerifySignature(pubKey string) error {
 	return nil
 }
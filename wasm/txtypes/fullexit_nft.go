@@ -32,6 +32,10 @@ func (txInfo *FullExitNftTxInfo) Validate() error {
 	return nil
 }
 
+func (txInfo *FullExitNftTxInfo) ValidateWithConfig(config Config) error {
+	return nil
+}
+
 func (txInfo *FullExitNftTxInfo) VerifySignature(pubKey string) error {
 	return nil
 }
@@ -10,6 +10,8 @@ type TxInfo interface {
 
 	Validate() error
 
+	ValidateWithConfig(config Config) error
+
 	VerifySignature(pubKey string) error
 
 	GetFromAccountIndex() int64
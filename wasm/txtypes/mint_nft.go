@@ -47,7 +47,7 @@ type MintNftSegmentFormat struct {
 	Nonce               int64  `json:"nonce"`
 }
 
-func ConstructMintNftTxInfo(sk *PrivateKey, segmentStr string) (txInfo *MintNftTxInfo, err error) {
+func ConstructMintNftTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *MintNftTxInfo, err error) {
 	var segmentFormat *MintNftSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -70,6 +70,7 @@ func ConstructMintNftTxInfo(sk *PrivateKey, segmentStr string) (txInfo *MintNftT
 		GasAccountIndex:     segmentFormat.GasAccountIndex,
 		GasFeeAssetId:       segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount:   gasFeeAmount,
+		ChainId:             chainId,
 		Nonce:               segmentFormat.Nonce,
 		ExpiredAt:           segmentFormat.ExpiredAt,
 		Sig:                 nil,
@@ -104,26 +105,31 @@ type MintNftTxInfo struct {
 	GasAccountIndex     int64
 	GasFeeAssetId       int64
 	GasFeeAssetAmount   *big.Int
+	ChainId             int64
 	ExpiredAt           int64
 	Nonce               int64
 	Sig                 []byte
 }
 
 func (txInfo *MintNftTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *MintNftTxInfo) ValidateWithConfig(config Config) error {
 	// CreatorAccountIndex
 	if txInfo.CreatorAccountIndex < minAccountIndex {
 		return fmt.Errorf("CreatorAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.CreatorAccountIndex > maxAccountIndex {
-		return fmt.Errorf("CreatorAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.CreatorAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("CreatorAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// ToAccountIndex
 	if txInfo.ToAccountIndex < minAccountIndex {
 		return fmt.Errorf("ToAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.ToAccountIndex > maxAccountIndex {
-		return fmt.Errorf("ToAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.ToAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("ToAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// ToAccountNameHash
@@ -140,8 +146,8 @@ func (txInfo *MintNftTxInfo) Validate() error {
 	if txInfo.NftCollectionId < minCollectionId {
 		return fmt.Errorf("NftCollectionId should not be less than %d", minCollectionId)
 	}
-	if txInfo.NftCollectionId > maxCollectionId {
-		return fmt.Errorf("NftCollectionId should not be larger than %d", maxCollectionId)
+	if txInfo.NftCollectionId > config.MaxCollectionId {
+		return fmt.Errorf("NftCollectionId should not be larger than %d", config.MaxCollectionId)
 	}
 
 	// CreatorTreasuryRate
@@ -156,16 +162,16 @@ func (txInfo *MintNftTxInfo) Validate() error {
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// GasFeeAssetId
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// GasFeeAssetAmount
@@ -184,6 +190,14 @@ func (txInfo *MintNftTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	return nil
 }
 
@@ -235,7 +249,7 @@ func (txInfo *MintNftTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
 		log.Println("[ComputeTransferMsgHash] unable to packed amount", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.CreatorAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.CreatorAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.ToAccountIndex, txInfo.CreatorTreasuryRate, txInfo.NftCollectionId)
 	WriteBigIntIntoBuf(&buf, ffmath.Mod(new(big.Int).SetBytes(common.FromHex(txInfo.ToAccountNameHash)), curve.Modulus))
@@ -46,7 +46,7 @@ type OfferSegmentFormat struct {
 	TreasuryRate int64  `json:"treasury_rate"`
 }
 
-func ConstructOfferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *OfferTxInfo, err error) {
+func ConstructOfferTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *OfferTxInfo, err error) {
 	var segmentFormat *OfferSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -69,6 +69,7 @@ func ConstructOfferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *OfferTxInf
 		ListedAt:     segmentFormat.ListedAt,
 		ExpiredAt:    segmentFormat.ExpiredAt,
 		TreasuryRate: segmentFormat.TreasuryRate,
+		ChainId:      chainId,
 		Sig:          nil,
 	}
 	// compute call data hash
@@ -99,10 +100,15 @@ type OfferTxInfo struct {
 	ListedAt     int64
 	ExpiredAt    int64
 	TreasuryRate int64
+	ChainId      int64
 	Sig          []byte
 }
 
 func (txInfo *OfferTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *OfferTxInfo) ValidateWithConfig(config Config) error {
 	// Type
 	if txInfo.Type != BuyOfferType && txInfo.Type != SellOfferType {
 		return fmt.Errorf("Type should only be buy(%d) and sell(%d)", BuyOfferType, SellOfferType)
@@ -117,24 +123,24 @@ func (txInfo *OfferTxInfo) Validate() error {
 	if txInfo.AccountIndex < minAccountIndex {
 		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.AccountIndex > maxAccountIndex {
-		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.AccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// NftIndex
 	if txInfo.NftIndex < minNftIndex {
 		return fmt.Errorf("NftIndex should not be less than %d", minNftIndex)
 	}
-	if txInfo.NftIndex > maxNftIndex {
-		return fmt.Errorf("NftIndex should not be larger than %d", maxNftIndex)
+	if txInfo.NftIndex > config.MaxNftIndex {
+		return fmt.Errorf("NftIndex should not be larger than %d", config.MaxNftIndex)
 	}
 
 	// AssetId
 	if txInfo.AssetId < minAssetId {
 		return fmt.Errorf("AssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.AssetId > maxAssetId {
-		return fmt.Errorf("AssetId should not be larger than %d", maxAssetId)
+	if txInfo.AssetId > config.MaxAssetId {
+		return fmt.Errorf("AssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// AssetAmount
@@ -160,6 +166,14 @@ func (txInfo *OfferTxInfo) Validate() error {
 	if txInfo.TreasuryRate > maxTreasuryRate {
 		return fmt.Errorf("TreasuryRate should not be larger than %d", maxTreasuryRate)
 	}
+
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
 	return nil
 }
 
@@ -213,7 +227,7 @@ func (txInfo *OfferTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
 	}
 	WriteInt64IntoBuf(&buf, txInfo.Type, txInfo.OfferId, txInfo.AccountIndex, txInfo.NftIndex)
 	WriteInt64IntoBuf(&buf, txInfo.AssetId, packedAmount, txInfo.ListedAt, txInfo.ExpiredAt)
-	WriteInt64IntoBuf(&buf, txInfo.TreasuryRate)
+	WriteInt64IntoBuf(&buf, txInfo.TreasuryRate, txInfo.ChainId)
 	hFunc.Write(buf.Bytes())
 	msgHash = hFunc.Sum(nil)
 	return msgHash, nil
@@ -24,6 +24,10 @@ func (txInfo *RegisterZnsTxInfo) Validate() error {
 	return nil
 }
 
+func (txInfo *RegisterZnsTxInfo) ValidateWithConfig(config Config) error {
+	return nil
+}
+
 func (txInfo *RegisterZnsTxInfo) VerifySignature(pubKey string) error {
 	return nil
 }
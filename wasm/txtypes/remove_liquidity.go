@@ -0,0 +1,295 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+type RemoveLiquiditySegmentFormat struct {
+	FromAccountIndex  int64  `json:"from_account_index"`
+	PairIndex         int64  `json:"pair_index"`
+	AssetAId          int64  `json:"asset_a_id"`
+	AssetAMinAmount   string `json:"asset_a_min_amount"`
+	AssetBId          int64  `json:"asset_b_id"`
+	AssetBMinAmount   string `json:"asset_b_min_amount"`
+	LpAmount          string `json:"lp_amount"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64  `json:"expired_at"`
+	Nonce             int64  `json:"nonce"`
+}
+
+func ConstructRemoveLiquidityTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *RemoveLiquidityTxInfo, err error) {
+	var segmentFormat *RemoveLiquiditySegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] err info:", err)
+		return nil, err
+	}
+	assetAMinAmount, err := StringToBigInt(segmentFormat.AssetAMinAmount)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBMinAmount, err := StringToBigInt(segmentFormat.AssetBMinAmount)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	lpAmount, err := StringToBigInt(segmentFormat.LpAmount)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &RemoveLiquidityTxInfo{
+		FromAccountIndex:  segmentFormat.FromAccountIndex,
+		PairIndex:         segmentFormat.PairIndex,
+		AssetAId:          segmentFormat.AssetAId,
+		AssetAMinAmount:   assetAMinAmount,
+		AssetBId:          segmentFormat.AssetBId,
+		AssetBMinAmount:   assetBMinAmount,
+		LpAmount:          lpAmount,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		Sig:               nil,
+	}
+	hFunc := mimc.NewMiMC()
+	// compute msg hash
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructRemoveLiquidityTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	RemoveLiquidityTxInfo: burn LpAmount lp shares of the pair for at least
+	AssetAMinAmount and AssetBMinAmount, the amounts are not packed
+*/
+type RemoveLiquidityTxInfo struct {
+	FromAccountIndex  int64
+	PairIndex         int64
+	AssetAId          int64
+	AssetAMinAmount   *big.Int
+	AssetBId          int64
+	AssetBMinAmount   *big.Int
+	LpAmount          *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ChainId           int64
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *RemoveLiquidityTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *RemoveLiquidityTxInfo) ValidateWithConfig(config Config) error {
+	if txInfo.FromAccountIndex < minAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if txInfo.PairIndex < minPairIndex {
+		return fmt.Errorf("PairIndex should not be less than %d", minPairIndex)
+	}
+	if txInfo.PairIndex > config.MaxPairIndex {
+		return fmt.Errorf("PairIndex should not be larger than %d", config.MaxPairIndex)
+	}
+
+	if txInfo.AssetAId < minAssetId {
+		return fmt.Errorf("AssetAId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetAId > config.MaxAssetId {
+		return fmt.Errorf("AssetAId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.AssetAMinAmount == nil {
+		return fmt.Errorf("AssetAMinAmount should not be nil")
+	}
+	if txInfo.AssetAMinAmount.Cmp(minLiquidityAmount) < 0 {
+		return fmt.Errorf("AssetAMinAmount should not be less than %s", minLiquidityAmount.String())
+	}
+	if txInfo.AssetAMinAmount.Cmp(maxLiquidityAmount) > 0 {
+		return fmt.Errorf("AssetAMinAmount should not be larger than %s", maxLiquidityAmount.String())
+	}
+
+	if txInfo.AssetBId < minAssetId {
+		return fmt.Errorf("AssetBId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetBId > config.MaxAssetId {
+		return fmt.Errorf("AssetBId should not be larger than %d", config.MaxAssetId)
+	}
+	if txInfo.AssetBId == txInfo.AssetAId {
+		return fmt.Errorf("AssetBId should not be the same as AssetAId")
+	}
+
+	if txInfo.AssetBMinAmount == nil {
+		return fmt.Errorf("AssetBMinAmount should not be nil")
+	}
+	if txInfo.AssetBMinAmount.Cmp(minLiquidityAmount) < 0 {
+		return fmt.Errorf("AssetBMinAmount should not be less than %s", minLiquidityAmount.String())
+	}
+	if txInfo.AssetBMinAmount.Cmp(maxLiquidityAmount) > 0 {
+		return fmt.Errorf("AssetBMinAmount should not be larger than %s", maxLiquidityAmount.String())
+	}
+
+	if txInfo.LpAmount == nil {
+		return fmt.Errorf("LpAmount should not be nil")
+	}
+	if txInfo.LpAmount.Cmp(minLiquidityAmount) <= 0 {
+		return fmt.Errorf("LpAmount should be larger than %s", minLiquidityAmount.String())
+	}
+	if txInfo.LpAmount.Cmp(maxLiquidityAmount) > 0 {
+		return fmt.Errorf("LpAmount should not be larger than %s", maxLiquidityAmount.String())
+	}
+
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
+	return nil
+}
+
+func (txInfo *RemoveLiquidityTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *RemoveLiquidityTxInfo) GetTxType() int {
+	return TxTypeRemoveLiquidity
+}
+
+func (txInfo *RemoveLiquidityTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *RemoveLiquidityTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *RemoveLiquidityTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func (txInfo *RemoveLiquidityTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeRemoveLiquidityMsgHash] unable to packed amount: ", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId)
+	WriteBigIntIntoBuf(&buf, txInfo.LpAmount)
+	WriteBigIntIntoBuf(&buf, txInfo.AssetAMinAmount)
+	WriteBigIntIntoBuf(&buf, txInfo.AssetBMinAmount)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+func (txInfo *RemoveLiquidityTxInfo) GetGas() (int64, int64, *big.Int) {
+	return txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount
+}
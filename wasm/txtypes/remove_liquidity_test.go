@@ -0,0 +1,73 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateRemoveLiquidityTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *RemoveLiquidityTxInfo
+	}{
+		// AssetAMinAmount
+		{
+			fmt.Errorf("AssetAMinAmount should not be less than %s", minLiquidityAmount.String()),
+			&RemoveLiquidityTxInfo{
+				FromAccountIndex: 1,
+				AssetAMinAmount:  big.NewInt(-1),
+			},
+		},
+		// LpAmount
+		{
+			fmt.Errorf("LpAmount should not be nil"),
+			&RemoveLiquidityTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAMinAmount:  big.NewInt(0),
+				AssetBId:         2,
+				AssetBMinAmount:  big.NewInt(0),
+			},
+		},
+		{
+			fmt.Errorf("LpAmount should be larger than %s", minLiquidityAmount.String()),
+			&RemoveLiquidityTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAMinAmount:  big.NewInt(0),
+				AssetBId:         2,
+				AssetBMinAmount:  big.NewInt(0),
+				LpAmount:         big.NewInt(0),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestRemoveLiquidityTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("remove liquidity seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"from_account_index":2,"pair_index":1,"asset_a_id":1,"asset_a_min_amount":"100","asset_b_id":2,"asset_b_min_amount":"400","lp_amount":"200","gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructRemoveLiquidityTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the lp amount is part of the signed message
+	txInfo.LpAmount = big.NewInt(300)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright © 2022 ZkBNB Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+type SwapSegmentFormat struct {
+	FromAccountIndex  int64  `json:"from_account_index"`
+	PairIndex         int64  `json:"pair_index"`
+	AssetAId          int64  `json:"asset_a_id"`
+	AssetAAmount      string `json:"asset_a_amount"`
+	AssetBId          int64  `json:"asset_b_id"`
+	AssetBMinAmount   string `json:"asset_b_min_amount"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64  `json:"expired_at"`
+	Nonce             int64  `json:"nonce"`
+}
+
+func ConstructSwapTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *SwapTxInfo, err error) {
+	var segmentFormat *SwapSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] err info:", err)
+		return nil, err
+	}
+	assetAAmount, err := StringToBigInt(segmentFormat.AssetAAmount)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBMinAmount, err := StringToBigInt(segmentFormat.AssetBMinAmount)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &SwapTxInfo{
+		FromAccountIndex:  segmentFormat.FromAccountIndex,
+		PairIndex:         segmentFormat.PairIndex,
+		AssetAId:          segmentFormat.AssetAId,
+		AssetAAmount:      assetAAmount,
+		AssetBId:          segmentFormat.AssetBId,
+		AssetBMinAmount:   assetBMinAmount,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		Sig:               nil,
+	}
+	hFunc := mimc.NewMiMC()
+	// compute msg hash
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructSwapTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	SwapTxInfo: sell AssetAAmount of asset A to the pair for at least
+	AssetBMinAmount of asset B, the amounts are not packed
+*/
+type SwapTxInfo struct {
+	FromAccountIndex  int64
+	PairIndex         int64
+	AssetAId          int64
+	AssetAAmount      *big.Int
+	AssetBId          int64
+	AssetBMinAmount   *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ChainId           int64
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *SwapTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *SwapTxInfo) ValidateWithConfig(config Config) error {
+	if txInfo.FromAccountIndex < minAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if txInfo.PairIndex < minPairIndex {
+		return fmt.Errorf("PairIndex should not be less than %d", minPairIndex)
+	}
+	if txInfo.PairIndex > config.MaxPairIndex {
+		return fmt.Errorf("PairIndex should not be larger than %d", config.MaxPairIndex)
+	}
+
+	if txInfo.AssetAId < minAssetId {
+		return fmt.Errorf("AssetAId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetAId > config.MaxAssetId {
+		return fmt.Errorf("AssetAId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.AssetAAmount == nil {
+		return fmt.Errorf("AssetAAmount should not be nil")
+	}
+	if txInfo.AssetAAmount.Cmp(minLiquidityAmount) <= 0 {
+		return fmt.Errorf("AssetAAmount should be larger than %s", minLiquidityAmount.String())
+	}
+	if txInfo.AssetAAmount.Cmp(maxLiquidityAmount) > 0 {
+		return fmt.Errorf("AssetAAmount should not be larger than %s", maxLiquidityAmount.String())
+	}
+
+	if txInfo.AssetBId < minAssetId {
+		return fmt.Errorf("AssetBId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetBId > config.MaxAssetId {
+		return fmt.Errorf("AssetBId should not be larger than %d", config.MaxAssetId)
+	}
+	if txInfo.AssetBId == txInfo.AssetAId {
+		return fmt.Errorf("AssetBId should not be the same as AssetAId")
+	}
+
+	if txInfo.AssetBMinAmount == nil {
+		return fmt.Errorf("AssetBMinAmount should not be nil")
+	}
+	if txInfo.AssetBMinAmount.Cmp(minLiquidityAmount) < 0 {
+		return fmt.Errorf("AssetBMinAmount should not be less than %s", minLiquidityAmount.String())
+	}
+	if txInfo.AssetBMinAmount.Cmp(maxLiquidityAmount) > 0 {
+		return fmt.Errorf("AssetBMinAmount should not be larger than %s", maxLiquidityAmount.String())
+	}
+
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
+	}
+
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
+	}
+
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
+	return nil
+}
+
+func (txInfo *SwapTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := txInfo.Hash(hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *SwapTxInfo) GetTxType() int {
+	return TxTypeSwap
+}
+
+func (txInfo *SwapTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *SwapTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *SwapTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func (txInfo *SwapTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeSwapMsgHash] unable to packed amount: ", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.PairIndex, txInfo.AssetAId, txInfo.AssetBId)
+	WriteBigIntIntoBuf(&buf, txInfo.AssetAAmount)
+	WriteBigIntIntoBuf(&buf, txInfo.AssetBMinAmount)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+func (txInfo *SwapTxInfo) GetGas() (int64, int64, *big.Int) {
+	return txInfo.GasAccountIndex, txInfo.GasFeeAssetId, txInfo.GasFeeAssetAmount
+}
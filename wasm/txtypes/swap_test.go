@@ -0,0 +1,111 @@
+package txtypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateSwapTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *SwapTxInfo
+	}{
+		// PairIndex
+		{
+			fmt.Errorf("PairIndex should not be larger than %d", maxPairIndex),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndex:        maxPairIndex + 1,
+			},
+		},
+		// AssetAAmount
+		{
+			fmt.Errorf("AssetAAmount should be larger than %s", minLiquidityAmount.String()),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				AssetAAmount:     big.NewInt(0),
+			},
+		},
+		{
+			fmt.Errorf("AssetAAmount should not be larger than %s", maxLiquidityAmount.String()),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				AssetAAmount:     new(big.Int).Add(maxLiquidityAmount, big.NewInt(1)),
+			},
+		},
+		// AssetBId
+		{
+			fmt.Errorf("AssetBId should not be the same as AssetAId"),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(100),
+				AssetBId:         1,
+			},
+		},
+		// AssetBMinAmount
+		{
+			fmt.Errorf("AssetBMinAmount should not be nil"),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(100),
+				AssetBId:         2,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&SwapTxInfo{
+				FromAccountIndex: 1,
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(100),
+				AssetBId:         2,
+				AssetBMinAmount:  big.NewInt(0),
+			},
+		},
+		// Nonce
+		{
+			fmt.Errorf("Nonce should not be less than %d", minNonce),
+			&SwapTxInfo{
+				FromAccountIndex:  1,
+				AssetAId:          1,
+				AssetAAmount:      big.NewInt(100),
+				AssetBId:          2,
+				AssetBMinAmount:   big.NewInt(0),
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             -1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestSwapTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("swap seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"from_account_index":2,"pair_index":1,"asset_a_id":1,"asset_a_amount":"1000","asset_b_id":2,"asset_b_min_amount":"900","gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructSwapTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the min amount is part of the signed message
+	txInfo.AssetBMinAmount = big.NewInt(800)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
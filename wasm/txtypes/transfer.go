@@ -49,7 +49,7 @@ type TransferSegmentFormat struct {
 	Nonce             int64  `json:"nonce"`
 }
 
-func ConstructTransferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *TransferTxInfo, err error) {
+func ConstructTransferTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *TransferTxInfo, err error) {
 	var segmentFormat *TransferSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -79,6 +79,7 @@ func ConstructTransferTxInfo(sk *PrivateKey, segmentStr string) (txInfo *Transfe
 		GasFeeAssetAmount: gasFeeAmount,
 		Memo:              segmentFormat.Memo,
 		CallData:          segmentFormat.CallData,
+		ChainId:           chainId,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Nonce:             segmentFormat.Nonce,
 		Sig:               nil,
@@ -118,31 +119,36 @@ type TransferTxInfo struct {
 	Memo              string
 	CallData          string
 	CallDataHash      []byte
+	ChainId           int64
 	ExpiredAt         int64
 	Nonce             int64
 	Sig               []byte
 }
 
 func (txInfo *TransferTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *TransferTxInfo) ValidateWithConfig(config Config) error {
 	if txInfo.FromAccountIndex < minAccountIndex {
 		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.FromAccountIndex > maxAccountIndex {
-		return fmt.Errorf("FromAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	if txInfo.ToAccountIndex < minAccountIndex {
 		return fmt.Errorf("ToAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.ToAccountIndex > maxAccountIndex {
-		return fmt.Errorf("ToAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.ToAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("ToAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	if txInfo.AssetId < minAssetId {
 		return fmt.Errorf("AssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.AssetId > maxAssetId {
-		return fmt.Errorf("AssetId should not be larger than %d", maxAssetId)
+	if txInfo.AssetId > config.MaxAssetId {
+		return fmt.Errorf("AssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	if txInfo.AssetAmount == nil {
@@ -158,15 +164,15 @@ func (txInfo *TransferTxInfo) Validate() error {
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	if txInfo.GasFeeAssetAmount == nil {
@@ -183,6 +189,13 @@ func (txInfo *TransferTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	// ToAccountNameHash
 	if !IsValidHash(txInfo.ToAccountNameHash) {
 		return fmt.Errorf("ToAccountNameHash(%s) is invalid", txInfo.ToAccountNameHash)
@@ -249,7 +262,7 @@ func (txInfo *TransferTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error)
 		log.Println("[ComputeTransferMsgHash] unable to packed amount", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.ToAccountIndex, txInfo.AssetId, packedAmount)
 	buf.Write(ffmath.Mod(new(big.Int).SetBytes(common.FromHex(txInfo.ToAccountNameHash)), curve.Modulus).FillBytes(make([]byte, 32)))
@@ -0,0 +1,92 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateTransferCollectionTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *TransferCollectionTxInfo
+	}{
+		// FromAccountIndex
+		{
+			fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex),
+			&TransferCollectionTxInfo{
+				FromAccountIndex: minAccountIndex - 1,
+			},
+		},
+		// ToAccountIndex
+		{
+			fmt.Errorf("ToAccountIndex should not be larger than %d", maxAccountIndex),
+			&TransferCollectionTxInfo{
+				FromAccountIndex: 1,
+				ToAccountIndex:   maxAccountIndex + 1,
+			},
+		},
+		// ToAccountNameHash
+		{
+			fmt.Errorf("ToAccountNameHash(0000000000000000000000000000000000000000000000000000000000000000) is invalid"),
+			&TransferCollectionTxInfo{
+				FromAccountIndex:  1,
+				ToAccountIndex:    2,
+				ToAccountNameHash: hex.EncodeToString(bytes.Repeat([]byte{0}, 32)),
+			},
+		},
+		// CollectionId
+		{
+			fmt.Errorf("CollectionId should not be larger than %d", maxCollectionId),
+			&TransferCollectionTxInfo{
+				FromAccountIndex:  1,
+				ToAccountIndex:    2,
+				ToAccountNameHash: hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CollectionId:      maxCollectionId + 1,
+			},
+		},
+		// true
+		{
+			nil,
+			&TransferCollectionTxInfo{
+				FromAccountIndex:  1,
+				ToAccountIndex:    2,
+				ToAccountNameHash: hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CollectionId:      5,
+				GasAccountIndex:   1,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestTransferCollectionTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("transfer collection seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"from_account_index":2,"to_account_index":3,"to_account_name":"%s","collection_id":5,"gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		hex.EncodeToString(bytes.Repeat([]byte{1}, 32)), time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructTransferCollectionTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the recipient is part of the signed message
+	txInfo.ToAccountIndex = 4
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
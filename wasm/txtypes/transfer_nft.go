@@ -47,7 +47,7 @@ type TransferNftSegmentFormat struct {
 	Nonce             int64  `json:"nonce"`
 }
 
-func ConstructTransferNftTxInfo(sk *PrivateKey, segmentStr string) (txInfo *TransferNftTxInfo, err error) {
+func ConstructTransferNftTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *TransferNftTxInfo, err error) {
 	var segmentFormat *TransferNftSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -68,6 +68,7 @@ func ConstructTransferNftTxInfo(sk *PrivateKey, segmentStr string) (txInfo *Tran
 		GasAccountIndex:   segmentFormat.GasAccountIndex,
 		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount: gasFeeAmount,
+		ChainId:           chainId,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Nonce:             segmentFormat.Nonce,
 		Sig:               nil,
@@ -104,26 +105,31 @@ type TransferNftTxInfo struct {
 	GasFeeAssetAmount *big.Int
 	CallData          string
 	CallDataHash      []byte
+	ChainId           int64
 	ExpiredAt         int64
 	Nonce             int64
 	Sig               []byte
 }
 
 func (txInfo *TransferNftTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *TransferNftTxInfo) ValidateWithConfig(config Config) error {
 	// FromAccountIndex
 	if txInfo.FromAccountIndex < minAccountIndex {
 		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.FromAccountIndex > maxAccountIndex {
-		return fmt.Errorf("FromAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// ToAccountIndex
 	if txInfo.ToAccountIndex < minAccountIndex {
 		return fmt.Errorf("ToAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.ToAccountIndex > maxAccountIndex {
-		return fmt.Errorf("ToAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.ToAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("ToAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// ToAccountNameHash
@@ -135,24 +141,24 @@ func (txInfo *TransferNftTxInfo) Validate() error {
 	if txInfo.NftIndex < minNftIndex {
 		return fmt.Errorf("NftIndex should not be less than %d", minNftIndex)
 	}
-	if txInfo.NftIndex > maxNftIndex {
-		return fmt.Errorf("NftIndex should not be larger than %d", maxNftIndex)
+	if txInfo.NftIndex > config.MaxNftIndex {
+		return fmt.Errorf("NftIndex should not be larger than %d", config.MaxNftIndex)
 	}
 
 	// GasAccountIndex
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	// GasFeeAssetId
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	// GasFeeAssetAmount
@@ -176,6 +182,14 @@ func (txInfo *TransferNftTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	// ChainId
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	return nil
 }
 
@@ -227,7 +241,7 @@ func (txInfo *TransferNftTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err erro
 		log.Println("[ComputeTransferMsgHash] unable to packed amount", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.ToAccountIndex, txInfo.NftIndex)
 	buf.Write(ffmath.Mod(new(big.Int).SetBytes(common.FromHex(txInfo.ToAccountNameHash)), curve.Modulus).FillBytes(make([]byte, 32)))
@@ -199,6 +199,37 @@ func TestValidateTransferTxInfo(t *testing.T) {
 				Nonce:             -1,
 			},
 		},
+		// ChainId
+		{
+			fmt.Errorf("ChainId should not be less than %d", minChainId),
+			&TransferTxInfo{
+				FromAccountIndex:  1,
+				ToAccountIndex:    1,
+				AssetId:           1,
+				AssetAmount:       big.NewInt(1),
+				GasAccountIndex:   0,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             1,
+				ChainId:           minChainId - 1,
+			},
+		},
+		{
+			fmt.Errorf("ChainId should not be larger than %d", maxChainId),
+			&TransferTxInfo{
+				FromAccountIndex:  1,
+				ToAccountIndex:    1,
+				AssetId:           1,
+				AssetAmount:       big.NewInt(1),
+				GasAccountIndex:   0,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             1,
+				ChainId:           maxChainId + 1,
+			},
+		},
 		// ToAccountNameHash
 		{
 			fmt.Errorf("ToAccountNameHash(0000000000000000000000000000000000000000000000000000000000000000) is invalid"),
@@ -0,0 +1,103 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbnb-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateUpdateCollectionTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *UpdateCollectionTxInfo
+	}{
+		// AccountIndex
+		{
+			fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex),
+			&UpdateCollectionTxInfo{
+				AccountIndex: minAccountIndex - 1,
+			},
+		},
+		// CollectionId
+		{
+			fmt.Errorf("CollectionId should not be larger than %d", maxCollectionId),
+			&UpdateCollectionTxInfo{
+				AccountIndex: 1,
+				CollectionId: maxCollectionId + 1,
+			},
+		},
+		// MetadataHash
+		{
+			fmt.Errorf("MetadataHash(0000000000000000000000000000000000000000000000000000000000000000) is invalid"),
+			&UpdateCollectionTxInfo{
+				AccountIndex: 1,
+				CollectionId: 5,
+				MetadataHash: hex.EncodeToString(bytes.Repeat([]byte{0}, 32)),
+			},
+		},
+		// CreatorTreasuryRate
+		{
+			fmt.Errorf("CreatorTreasuryRate should not be larger than %d", maxTreasuryRate),
+			&UpdateCollectionTxInfo{
+				AccountIndex:        1,
+				CollectionId:        5,
+				MetadataHash:        hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CreatorTreasuryRate: maxTreasuryRate + 1,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&UpdateCollectionTxInfo{
+				AccountIndex:        1,
+				CollectionId:        5,
+				MetadataHash:        hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CreatorTreasuryRate: 100,
+				GasAccountIndex:     1,
+			},
+		},
+		// true
+		{
+			nil,
+			&UpdateCollectionTxInfo{
+				AccountIndex:        1,
+				CollectionId:        5,
+				MetadataHash:        hex.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+				CreatorTreasuryRate: 100,
+				GasAccountIndex:     1,
+				GasFeeAssetAmount:   big.NewInt(100),
+				ExpiredAt:           time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:               1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestUpdateCollectionTxInfoSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("update collection seed")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+
+	segment := fmt.Sprintf(`{"account_index":2,"collection_id":5,"metadata_hash":"%s","creator_treasury_rate":100,"gas_account_index":1,"gas_fee_asset_id":0,"gas_fee_asset_amount":"100","expired_at":%d,"nonce":3}`,
+		hex.EncodeToString(bytes.Repeat([]byte{1}, 32)), time.Now().Add(time.Hour).UnixMilli())
+	txInfo, err := ConstructUpdateCollectionTxInfo(sk, 56, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+
+	// the metadata hash is part of the signed message
+	txInfo.MetadataHash = hex.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	require.Error(t, txInfo.VerifySignature(pubKey))
+}
@@ -38,7 +38,7 @@ func WriteInt64IntoBuf(buf *bytes.Buffer, inputs ...int64) {
 		log.Fatalln("[WriteInt64IntoBuf] too many inputs")
 	}
 	// The variable of bn254 curve is less than 2^254, avoid overflow here.
-	if len(inputs) == 4 && inputs[0] >= 2^62 {
+	if len(inputs) == 4 && inputs[0] >= 1<<62 {
 		log.Fatalln("[WriteInt64IntoBuf] inputs overflow")
 	}
 
@@ -41,7 +41,7 @@ type WithdrawSegmentFormat struct {
 	Nonce             int64  `json:"nonce"`
 }
 
-func ConstructWithdrawTxInfo(sk *PrivateKey, segmentStr string) (txInfo *WithdrawTxInfo, err error) {
+func ConstructWithdrawTxInfo(sk *PrivateKey, chainId int64, segmentStr string) (txInfo *WithdrawTxInfo, err error) {
 	var segmentFormat *WithdrawSegmentFormat
 	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
 	if err != nil {
@@ -68,6 +68,7 @@ func ConstructWithdrawTxInfo(sk *PrivateKey, segmentStr string) (txInfo *Withdra
 		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount: gasFeeAmount,
 		ToAddress:         segmentFormat.ToAddress,
+		ChainId:           chainId,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Nonce:             segmentFormat.Nonce,
 		Sig:               nil,
@@ -99,24 +100,32 @@ type WithdrawTxInfo struct {
 	GasFeeAssetId     int64
 	GasFeeAssetAmount *big.Int
 	ToAddress         string
+	ChainId           int64
 	ExpiredAt         int64
 	Nonce             int64
 	Sig               []byte
 }
 
 func (txInfo *WithdrawTxInfo) Validate() error {
+	return txInfo.ValidateWithConfig(MainnetConfig)
+}
+
+func (txInfo *WithdrawTxInfo) ValidateWithConfig(config Config) error {
 	if txInfo.FromAccountIndex < minAccountIndex {
 		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.FromAccountIndex > maxAccountIndex {
-		return fmt.Errorf("FromAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.FromAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	if txInfo.AssetId < minAssetId {
 		return fmt.Errorf("AssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.AssetId > maxAssetId {
-		return fmt.Errorf("AssetId should not be larger than %d", maxAssetId)
+	if txInfo.AssetId > config.MaxAssetId {
+		return fmt.Errorf("AssetId should not be larger than %d", config.MaxAssetId)
+	}
+	if txInfo.AssetId >= config.FirstLpAssetId {
+		return fmt.Errorf("AssetId should be less than %d, lp shares can't be withdrawn", config.FirstLpAssetId)
 	}
 
 	if txInfo.AssetAmount == nil {
@@ -132,15 +141,15 @@ func (txInfo *WithdrawTxInfo) Validate() error {
 	if txInfo.GasAccountIndex < minAccountIndex {
 		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
 	}
-	if txInfo.GasAccountIndex > maxAccountIndex {
-		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	if txInfo.GasAccountIndex > config.MaxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", config.MaxAccountIndex)
 	}
 
 	if txInfo.GasFeeAssetId < minAssetId {
 		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
 	}
-	if txInfo.GasFeeAssetId > maxAssetId {
-		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	if txInfo.GasFeeAssetId > config.MaxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", config.MaxAssetId)
 	}
 
 	if txInfo.GasFeeAssetAmount == nil {
@@ -157,6 +166,13 @@ func (txInfo *WithdrawTxInfo) Validate() error {
 		return fmt.Errorf("Nonce should not be less than %d", minNonce)
 	}
 
+	if txInfo.ChainId < minChainId {
+		return fmt.Errorf("ChainId should not be less than %d", minChainId)
+	}
+	if txInfo.ChainId > maxChainId {
+		return fmt.Errorf("ChainId should not be larger than %d", maxChainId)
+	}
+
 	// ToAddress
 	if !IsValidL1Address(txInfo.ToAddress) {
 		return fmt.Errorf("ToAddress(%s) is invalid", txInfo.ToAddress)
@@ -213,7 +229,7 @@ func (txInfo *WithdrawTxInfo) Hash(hFunc hash.Hash) (msgHash []byte, err error)
 		log.Println("[ComputeTransferMsgHash] unable to packed amount: ", err.Error())
 		return nil, err
 	}
-	WriteInt64IntoBuf(&buf, ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.ChainId, txInfo.FromAccountIndex, txInfo.Nonce, txInfo.ExpiredAt)
 	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex, txInfo.GasFeeAssetId, packedFee)
 	WriteInt64IntoBuf(&buf, txInfo.AssetId)
 	WriteBigIntIntoBuf(&buf, txInfo.AssetAmount)
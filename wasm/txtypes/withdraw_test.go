@@ -59,6 +59,13 @@ func TestValidateWithdrawTxInfo(t *testing.T) {
 				AssetId:          maxAssetId + 1,
 			},
 		},
+		{
+			fmt.Errorf("AssetId should be less than %d, lp shares can't be withdrawn", firstLpAssetId),
+			&WithdrawTxInfo{
+				FromAccountIndex: 1,
+				AssetId:          firstLpAssetId,
+			},
+		},
 		// AssetAmount
 		{
 			fmt.Errorf("AssetAmount should not be nil"),